@@ -0,0 +1,9 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+// Package fake contains a fake credential and a fake server for running clients offline, in tests
+// and examples. Import it as azfake to avoid confusion with other packages named fake.
+package fake
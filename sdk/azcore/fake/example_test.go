@@ -0,0 +1,45 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package fake_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/fake"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+func ExampleServer() {
+	srv := fake.NewServer()
+	srv.Handle(http.MethodGet, "/widgets/{name}", fake.JSONResponse(http.StatusOK, map[string]string{"color": "blue"}))
+
+	// a client's constructor builds a pipeline like this one from its options
+	pl := runtime.NewPipeline("example", "v1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(&fake.TokenCredential{}, []string{"scope"}, nil)},
+	}, &policy.ClientOptions{Transport: srv})
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://contoso.com/widgets/w1")
+	if err != nil {
+		panic(err)
+	}
+	resp, err := pl.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	var widget map[string]string
+	if err := runtime.UnmarshalAsJSON(resp, &widget); err != nil {
+		panic(err)
+	}
+	fmt.Println(widget["color"])
+	fmt.Println(srv.Requests()[0].Header.Get("Authorization"))
+	// Output:
+	// blue
+	// Bearer fake_token
+}
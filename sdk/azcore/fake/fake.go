@@ -0,0 +1,240 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package fake
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// Token is the access token TokenCredential returns.
+const Token = "fake_token"
+
+// TokenCredential is a credential that returns Token for every request, without authenticating.
+// The zero value is ready to use.
+type TokenCredential struct {
+	mu  sync.Mutex
+	err error
+}
+
+// SetError makes GetToken return err. Pass nil to make it return Token again.
+func (c *TokenCredential) SetError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// GetToken implements the azcore.TokenCredential interface for the TokenCredential type.
+func (c *TokenCredential) GetToken(ctx context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return azcore.AccessToken{}, c.err
+	}
+	return azcore.AccessToken{Token: Token, ExpiresOn: time.Now().Add(24 * time.Hour)}, nil
+}
+
+// Responder returns the response to a request the Server received.
+type Responder func(req *http.Request) (*http.Response, error)
+
+// Request is a request the Server received.
+type Request struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	Body   []byte
+}
+
+// Server is a policy.Transporter that returns responses from the responders of its routes instead of
+// sending requests. Set it as ClientOptions.Transport. Don't use this type directly, use NewServer() instead.
+type Server struct {
+	mu       sync.Mutex
+	routes   []route
+	requests []Request
+}
+
+type route struct {
+	method    string
+	segments  []string
+	responder Responder
+}
+
+// NewServer creates a Server without routes.
+func NewServer() *Server {
+	return &Server{}
+}
+
+// Handle routes requests with method to responder when their URL path matches path. A path segment in
+// braces, such as "{name}" in "/secrets/{name}", matches any segment. Literal segments match regardless
+// of case and the URL's host and query are ignored. When several routes match a request, the first one
+// registered wins; registering the same method and path again replaces the route's responder.
+func (s *Server) Handle(method string, path string, responder Responder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := route{method: method, segments: splitPath(path), responder: responder}
+	for i := range s.routes {
+		if s.routes[i].method == r.method && equalSegments(s.routes[i].segments, r.segments) {
+			s.routes[i] = r
+			return
+		}
+	}
+	s.routes = append(s.routes, r)
+}
+
+// Requests returns the requests the Server received, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Do implements the policy.Transporter interface for the Server type. It returns the response of the first
+// matching route's responder, or a response with status 404 when no route matches.
+func (s *Server) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: req.Method, URL: req.URL, Header: req.Header.Clone(), Body: body})
+	var responder Responder
+	segments := splitPath(req.URL.Path)
+	for _, r := range s.routes {
+		if r.method == req.Method && matchSegments(r.segments, segments) {
+			responder = r.responder
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if responder == nil {
+		responder = JSONResponse(http.StatusNotFound, map[string]interface{}{
+			"error": map[string]string{
+				"code":    "NoFakeResponder",
+				"message": fmt.Sprintf("no responder for %s %s", req.Method, req.URL.Path),
+			},
+		})
+	}
+	resp, err := responder(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Request = req
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	if resp.Body == nil {
+		resp.Body = http.NoBody
+	}
+	return resp, nil
+}
+
+// JSONResponse returns a Responder that responds with statusCode and v marshaled to JSON.
+func JSONResponse(statusCode int, v interface{}) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		body, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode:    statusCode,
+			Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+		}, nil
+	}
+}
+
+// StatusResponse returns a Responder that responds with statusCode and no body.
+func StatusResponse(statusCode int) Responder {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: statusCode,
+			Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+			Header:     http.Header{},
+			Body:       http.NoBody,
+		}, nil
+	}
+}
+
+// Sequence returns a Responder that calls responders in turn, one per request, and keeps calling the last one
+// after the others have been used. Use it for operations that poll, or to make a request fail before succeeding.
+func Sequence(responders ...Responder) Responder {
+	var mu sync.Mutex
+	next := 0
+	return func(req *http.Request) (*http.Response, error) {
+		if len(responders) == 0 {
+			return nil, fmt.Errorf("no responders in sequence for %s %s", req.Method, req.URL.Path)
+		}
+		mu.Lock()
+		r := responders[next]
+		if next < len(responders)-1 {
+			next++
+		}
+		mu.Unlock()
+		return r(req)
+	}
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func isParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+	for i, p := range pattern {
+		if isParameter(p) {
+			if segments[i] == "" {
+				return false
+			}
+		} else if !strings.EqualFold(p, segments[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalSegments(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if isParameter(a[i]) != isParameter(b[i]) || (!isParameter(a[i]) && !strings.EqualFold(a[i], b[i])) {
+			return false
+		}
+	}
+	return true
+}
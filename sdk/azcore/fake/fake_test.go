@@ -0,0 +1,118 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPipeline(cred azcore.TokenCredential, srv *Server) runtime.Pipeline {
+	return runtime.NewPipeline("fake", "v1.0.0", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{runtime.NewBearerTokenPolicy(cred, []string{"scope"}, nil)},
+	}, &policy.ClientOptions{
+		Retry:     policy.RetryOptions{RetryDelay: time.Millisecond},
+		Transport: srv,
+	})
+}
+
+func TestServer(t *testing.T) {
+	srv := NewServer()
+	srv.Handle(http.MethodGet, "/secrets/{name}", JSONResponse(http.StatusOK, map[string]string{"value": "secret"}))
+	srv.Handle(http.MethodPut, "/secrets/{name}", StatusResponse(http.StatusNoContent))
+	pl := newTestPipeline(&TokenCredential{}, srv)
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://fake.vault.azure.net/Secrets/a?api-version=7.3")
+	require.NoError(t, err)
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var v map[string]string
+	require.NoError(t, runtime.UnmarshalAsJSON(resp, &v))
+	require.Equal(t, "secret", v["value"])
+
+	req, err = runtime.NewRequest(context.Background(), http.MethodPut, "https://fake.vault.azure.net/secrets/a")
+	require.NoError(t, err)
+	require.NoError(t, req.SetBody(streaming.NopCloser(strings.NewReader(`{"value":"new"}`)), "application/json"))
+	resp, err = pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// no route matches
+	req, err = runtime.NewRequest(context.Background(), http.MethodGet, "https://fake.vault.azure.net/secrets/a/b")
+	require.NoError(t, err)
+	resp, err = pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Contains(t, runtime.NewResponseError(resp).Error(), "NoFakeResponder")
+
+	requests := srv.Requests()
+	require.Len(t, requests, 3)
+	require.Equal(t, http.MethodPut, requests[1].Method)
+	require.Equal(t, `{"value":"new"}`, string(requests[1].Body))
+	for _, r := range requests {
+		require.Equal(t, "Bearer "+Token, r.Header.Get("Authorization"))
+	}
+}
+
+func TestServerHandleReplaces(t *testing.T) {
+	srv := NewServer()
+	srv.Handle(http.MethodGet, "/items/{id}", StatusResponse(http.StatusOK))
+	srv.Handle(http.MethodGet, "/items/{other}", StatusResponse(http.StatusAccepted))
+	resp, err := srv.Do(httptestRequest(t, "https://contoso.com/items/1"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.NotNil(t, resp.Request)
+}
+
+func TestSequence(t *testing.T) {
+	srv := NewServer()
+	srv.Handle(http.MethodGet, "/", Sequence(StatusResponse(http.StatusServiceUnavailable), StatusResponse(http.StatusOK)))
+	pl := newTestPipeline(&TokenCredential{}, srv)
+
+	for i := 0; i < 2; i++ {
+		req, err := runtime.NewRequest(context.Background(), http.MethodGet, "https://contoso.com")
+		require.NoError(t, err)
+		resp, err := pl.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	// the retry policy retried the first response; the last responder repeats
+	require.Len(t, srv.Requests(), 3)
+
+	_, err := Sequence()(httptestRequest(t, "https://contoso.com"))
+	require.Error(t, err)
+}
+
+func TestTokenCredentialError(t *testing.T) {
+	cred := &TokenCredential{}
+	fail := errors.New("fail")
+	cred.SetError(fail)
+	_, err := cred.GetToken(context.Background(), policy.TokenRequestOptions{})
+	require.ErrorIs(t, err, fail)
+	cred.SetError(nil)
+	tk, err := cred.GetToken(context.Background(), policy.TokenRequestOptions{})
+	require.NoError(t, err)
+	require.Equal(t, Token, tk.Token)
+	require.True(t, tk.ExpiresOn.After(time.Now()))
+}
+
+func httptestRequest(t *testing.T, url string) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	return req
+}
@@ -0,0 +1,63 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package exported
+
+import "sync"
+
+// KeyCredential contains an authentication key used to authenticate to an Azure service.
+// Exported as azcore.KeyCredential.
+type KeyCredential struct {
+	mu  sync.RWMutex
+	key string
+}
+
+// NewKeyCredential creates a new instance of [KeyCredential] with the specified values.
+//   - key is the authentication key
+func NewKeyCredential(key string) *KeyCredential {
+	return &KeyCredential{key: key}
+}
+
+// Update replaces the existing key with the specified value.
+func (k *KeyCredential) Update(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.key = key
+}
+
+// KeyCredentialGet returns the key for cred.
+func KeyCredentialGet(cred *KeyCredential) string {
+	cred.mu.RLock()
+	defer cred.mu.RUnlock()
+	return cred.key
+}
+
+// SASCredential contains a shared access signature used to authenticate to an Azure service.
+// Exported as azcore.SASCredential.
+type SASCredential struct {
+	mu  sync.RWMutex
+	sas string
+}
+
+// NewSASCredential creates a new instance of [SASCredential] with the specified values.
+//   - sas is the shared access signature
+func NewSASCredential(sas string) *SASCredential {
+	return &SASCredential{sas: sas}
+}
+
+// Update replaces the existing shared access signature with the specified value.
+func (s *SASCredential) Update(sas string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sas = sas
+}
+
+// SASCredentialGet returns the shared access signature for cred.
+func SASCredentialGet(cred *SASCredential) string {
+	cred.mu.RLock()
+	defer cred.mu.RUnlock()
+	return cred.sas
+}
@@ -12,9 +12,11 @@ const (
 )
 
 const (
+	HeaderAcceptEncoding         = "Accept-Encoding"
 	HeaderAuthorization          = "Authorization"
 	HeaderAuxiliaryAuthorization = "x-ms-authorization-auxiliary"
 	HeaderAzureAsync             = "Azure-AsyncOperation"
+	HeaderContentEncoding        = "Content-Encoding"
 	HeaderContentLength          = "Content-Length"
 	HeaderContentType            = "Content-Type"
 	HeaderLocation               = "Location"
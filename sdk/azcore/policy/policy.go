@@ -49,6 +49,9 @@ type ClientOptions struct {
 	// PerRetryPolicies contains custom policies to inject into the pipeline.
 	// Each policy is executed once per request, and for each retry of that request.
 	PerRetryPolicies []Policy
+
+	// PanicRecovery configures recovery from panics in the pipeline's policies and transport.
+	PanicRecovery PanicRecoveryOptions
 }
 
 // LogOptions configures the logging policy's behavior.
@@ -95,6 +98,35 @@ type RetryOptions struct {
 	// The default value is the status codes in StatusCodesForRetry.
 	// Specifying an empty slice will cause retries to happen only for transport errors.
 	StatusCodes []int
+
+	// OnRetry is called after an attempt fails and before the policy waits to retry it.
+	// Use it to log or count retries. It isn't called for the final attempt.
+	// OnRetry must not block; the policy calls it synchronously.
+	OnRetry func(RetryInfo)
+}
+
+// RetryInfo describes a failed attempt the retry policy is about to retry.
+type RetryInfo struct {
+	// Attempt is the number of the attempt that failed, starting at one.
+	Attempt int32
+
+	// Delay is how long the policy waits before the next attempt.
+	Delay time.Duration
+
+	// StatusCode is the HTTP status code of the failed attempt's response.
+	// It's zero when the attempt didn't receive a response.
+	StatusCode int
+
+	// Err is the error returned by the failed attempt, if any.
+	Err error
+}
+
+// PanicRecoveryOptions configures how a pipeline handles panics in its policies and transport.
+type PanicRecoveryOptions struct {
+	// Enabled converts a panic in a policy or the transport into a *runtime.PanicError, which the request
+	// returns instead of crashing the process. The request isn't retried. The default value is false.
+	// NOTE: a recovered policy may have left shared state inconsistent, so log the error and treat it as a bug.
+	Enabled bool
 }
 
 // TelemetryOptions configures the telemetry policy's behavior.
@@ -113,7 +145,92 @@ type TokenRequestOptions struct {
 	Scopes []string
 }
 
+// Authorizer authorizes requests with a scheme the authentication policy doesn't implement, for example
+// a shared access signature computed for each request from a connection string's key.
+type Authorizer interface {
+	// Authorize authorizes req, typically by setting its Authorization header.
+	Authorize(req *Request) error
+}
+
+// RequestSigner signs requests, for example with an HMAC of the request's method, URL and headers computed with a
+// shared key, as storage services require. A pipeline calls its signers after every policy that can change a
+// request, so the signature covers the request as it's sent.
+type RequestSigner interface {
+	// SignRequest signs req, typically by setting its Authorization header. It's called for each try of a
+	// request, so it must replace the signature of an earlier try rather than add to it.
+	SignRequest(req *Request) error
+}
+
+// RequestSignerFunc is a function that implements RequestSigner.
+type RequestSignerFunc func(req *Request) error
+
+// SignRequest implements the RequestSigner interface on RequestSignerFunc.
+func (f RequestSignerFunc) SignRequest(req *Request) error {
+	return f(req)
+}
+
+// AuthenticationOptions configures the authentication policy's behavior.
+type AuthenticationOptions struct {
+	// Scopes are the permission scopes of the tokens requested from an azcore.TokenCredential.
+	Scopes []string
+
+	// KeyHeader is the header in which the key of an *azcore.KeyCredential is sent, for example "api-key".
+	// It's ignored when SignWithKey is set.
+	KeyHeader string
+
+	// SignWithKey authorizes requests with the key of an *azcore.KeyCredential by signing them, as shared
+	// key schemes do, instead of sending the key in KeyHeader. The signature doesn't cover changes policies
+	// after the authentication policy make; sign in a runtime.NewKeyCredentialSigner added to
+	// runtime.PipelineOptions.Signers to cover them.
+	SignWithKey func(req *Request, key string) error
+
+	// SASHeader is the header in which the signature of an *azcore.SASCredential is sent, for example
+	// "Authorization". The default is to append the signature to the request's query string.
+	SASHeader string
+}
+
 // BearerTokenOptions configures the bearer token policy's behavior.
 type BearerTokenOptions struct {
 	// placeholder for future options
 }
+
+// CompressionOptions configures the compression policy's behavior.
+type CompressionOptions struct {
+	// MinSize is the smallest request body, in bytes, the policy compresses.
+	// The default value is 1024.
+	MinSize int64
+
+	// ContentTypes are the media types of the request bodies the policy compresses.
+	// The default value compresses JSON bodies: "application/json" and types with a "+json" suffix.
+	ContentTypes []string
+
+	// DisableResponseDecompression prevents the policy from requesting and decompressing
+	// gzip-encoded responses.
+	DisableResponseDecompression bool
+}
+
+// ConcurrencyLimitOptions configures the concurrency limit policy's behavior.
+type ConcurrencyLimitOptions struct {
+	// InitialLimit is the number of concurrent requests to a host the policy allows at first.
+	// The default value is 8.
+	InitialLimit int
+
+	// MinLimit is the lowest the limit can fall. The default value is 1.
+	MinLimit int
+
+	// MaxLimit is the highest the limit can rise. The default value is 256.
+	MaxLimit int
+
+	// LatencyTolerance is how many times longer than the shortest recently observed latency a
+	// request to a host can take before the policy considers the host overloaded and lowers its limit.
+	// The default value is 2.
+	LatencyTolerance float64
+
+	// Backoff is the factor the limit is multiplied by when the host is overloaded, or throttles
+	// a request with status 429 or 503. It must be between 0 and 1. The default value is 0.75.
+	Backoff float64
+
+	// OnLimitChange, when set, is called with the host and its new limit whenever the limit for a
+	// host changes. OnLimitChange must not block; the policy calls it synchronously.
+	OnLimitChange func(host string, limit int)
+}
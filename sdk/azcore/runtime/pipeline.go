@@ -17,6 +17,11 @@ import (
 type PipelineOptions struct {
 	AllowedHeaders, AllowedQueryParameters []string
 	PerCall, PerRetry                      []policy.Policy
+
+	// Signers sign each try of a request, in order, after all policies that can change it, including
+	// ClientOptions.PerRetryPolicies and the headers added with WithHTTPHeader. An error from a signer
+	// fails the request without retrying it.
+	Signers []policy.RequestSigner
 }
 
 // Pipeline represents a primitive for sending HTTP requests and receiving responses.
@@ -55,11 +60,18 @@ func NewPipeline(module, version string, plOpts PipelineOptions, options *policy
 	policies = append(policies, plOpts.PerRetry...)
 	policies = append(policies, cp.PerRetryPolicies...)
 	policies = append(policies, NewLogPolicy(&cp.Logging))
-	policies = append(policies, policyFunc(httpHeaderPolicy), policyFunc(bodyDownloadPolicy))
+	policies = append(policies, policyFunc(httpHeaderPolicy))
+	if len(plOpts.Signers) > 0 {
+		policies = append(policies, &requestSigningPolicy{signers: plOpts.Signers})
+	}
+	policies = append(policies, policyFunc(uploadProgressPolicy), policyFunc(bodyDownloadPolicy))
 	transport := cp.Transport
 	if transport == nil {
 		transport = defaultHTTPClient
 	}
+	if cp.PanicRecovery.Enabled {
+		policies, transport = withPanicRecovery(policies, transport, cp.Logging.AllowedQueryParams)
+	}
 	return exported.NewPipeline(transport, policies...)
 }
 
@@ -0,0 +1,98 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/exported"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// NewAuthenticationPolicy creates a policy that authorizes requests with credential, choosing the scheme by the
+// credential's type, so a client can accept any of the credentials its service supports through the same
+// constructor and ClientOptions. Add the policy to PipelineOptions.PerRetry.
+//   - an azcore.TokenCredential authorizes requests with bearer tokens for options.Scopes, like NewBearerTokenPolicy
+//   - an *azcore.KeyCredential authorizes requests with options.SignWithKey, or by sending the key in options.KeyHeader
+//   - an *azcore.SASCredential authorizes requests by sending the signature in options.SASHeader, or in the query string
+//   - a policy.Authorizer authorizes requests itself
+//
+// An error is returned for other credentials, and when options don't say how to send a key.
+// Pass nil for options to accept the default values.
+func NewAuthenticationPolicy(credential interface{}, options *policy.AuthenticationOptions) (policy.Policy, error) {
+	if options == nil {
+		options = &policy.AuthenticationOptions{}
+	}
+	switch cred := credential.(type) {
+	case nil:
+		return nil, errors.New("credential can't be nil")
+	case azcore.TokenCredential:
+		return NewBearerTokenPolicy(cred, options.Scopes, nil), nil
+	case *azcore.KeyCredential:
+		if options.SignWithKey == nil && options.KeyHeader == "" {
+			return nil, errors.New("authenticating with a key requires AuthenticationOptions.KeyHeader or AuthenticationOptions.SignWithKey")
+		}
+		return &keyCredentialPolicy{cred: cred, header: options.KeyHeader, sign: options.SignWithKey}, nil
+	case *azcore.SASCredential:
+		return &sasCredentialPolicy{cred: cred, header: options.SASHeader}, nil
+	case policy.Authorizer:
+		return policyFunc(func(req *policy.Request) (*http.Response, error) {
+			if err := cred.Authorize(req); err != nil {
+				return nil, err
+			}
+			return req.Next()
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported credential type %T", credential)
+	}
+}
+
+// keyCredentialPolicy authorizes requests with an *azcore.KeyCredential
+type keyCredentialPolicy struct {
+	cred   *azcore.KeyCredential
+	header string
+	sign   func(*policy.Request, string) error
+}
+
+func (k *keyCredentialPolicy) Do(req *policy.Request) (*http.Response, error) {
+	key := exported.KeyCredentialGet(k.cred)
+	if k.sign != nil {
+		if err := k.sign(req, key); err != nil {
+			return nil, err
+		}
+	} else {
+		req.Raw().Header.Set(k.header, key)
+	}
+	return req.Next()
+}
+
+// sasCredentialPolicy authorizes requests with an *azcore.SASCredential
+type sasCredentialPolicy struct {
+	cred   *azcore.SASCredential
+	header string
+}
+
+func (s *sasCredentialPolicy) Do(req *policy.Request) (*http.Response, error) {
+	sas := exported.SASCredentialGet(s.cred)
+	if s.header != "" {
+		req.Raw().Header.Set(s.header, sas)
+		return req.Next()
+	}
+	// retries reuse the request, so the signature goes in a clone to keep it from being appended again
+	req = req.Clone(req.Raw().Context())
+	sas = strings.TrimPrefix(sas, "?")
+	if u := req.Raw().URL; u.RawQuery == "" {
+		u.RawQuery = sas
+	} else {
+		u.RawQuery += "&" + sas
+	}
+	return req.Next()
+}
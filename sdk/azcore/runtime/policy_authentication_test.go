@@ -0,0 +1,148 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/shared"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/internal/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type authorizerFunc func(req *policy.Request) error
+
+func (a authorizerFunc) Authorize(req *policy.Request) error {
+	return a(req)
+}
+
+func authenticationTestPipeline(t *testing.T, srv policy.Transporter, credential interface{}, options *policy.AuthenticationOptions) Pipeline {
+	p, err := NewAuthenticationPolicy(credential, options)
+	require.NoError(t, err)
+	return NewPipeline("testmodule", "v0.1.0", PipelineOptions{PerRetry: []policy.Policy{p}}, &policy.ClientOptions{
+		Retry:     policy.RetryOptions{RetryDelay: time.Millisecond},
+		Transport: srv,
+	})
+}
+
+func TestAuthenticationPolicyTokenCredential(t *testing.T) {
+	srv, close := mock.NewTLSServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	pl := authenticationTestPipeline(t, srv, mockCredential{}, &policy.AuthenticationOptions{Scopes: []string{scope}})
+	req, err := NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, shared.BearerTokenPrefix+tokenValue, resp.Request.Header.Get(shared.HeaderAuthorization))
+}
+
+func TestAuthenticationPolicyKeyCredential(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	cred := azcore.NewKeyCredential("key1")
+	pl := authenticationTestPipeline(t, srv, cred, &policy.AuthenticationOptions{KeyHeader: "api-key"})
+	req, err := NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, "key1", resp.Request.Header.Get("api-key"))
+
+	// a rotated key is used by the next request
+	cred.Update("key2")
+	req, err = NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	resp, err = pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, "key2", resp.Request.Header.Get("api-key"))
+}
+
+func TestAuthenticationPolicySignWithKey(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	sign := func(req *policy.Request, key string) error {
+		req.Raw().Header.Set(shared.HeaderAuthorization, "SharedKey account:"+key+":"+req.Raw().Method)
+		return nil
+	}
+	pl := authenticationTestPipeline(t, srv, azcore.NewKeyCredential("key"), &policy.AuthenticationOptions{KeyHeader: "ignored", SignWithKey: sign})
+	req, err := NewRequest(context.Background(), http.MethodPut, srv.URL())
+	require.NoError(t, err)
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, "SharedKey account:key:PUT", resp.Request.Header.Get(shared.HeaderAuthorization))
+	require.Empty(t, resp.Request.Header.Get("ignored"))
+
+	// a signing error fails the request
+	signErr := errors.New("can't sign")
+	pl = authenticationTestPipeline(t, srv, azcore.NewKeyCredential("key"), &policy.AuthenticationOptions{
+		SignWithKey: func(*policy.Request, string) error { return signErr },
+	})
+	req, err = NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	_, err = pl.Do(req)
+	require.ErrorIs(t, err, signErr)
+}
+
+func TestAuthenticationPolicySASCredential(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusServiceUnavailable))
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	pl := authenticationTestPipeline(t, srv, azcore.NewSASCredential("?sv=2021&sig=abc"), nil)
+	req, err := NewRequest(context.Background(), http.MethodGet, srv.URL()+"/container?restype=container")
+	require.NoError(t, err)
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, 2, srv.Requests())
+	// the retry sends the signature once
+	require.Equal(t, "restype=container&sv=2021&sig=abc", resp.Request.URL.RawQuery)
+
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	pl = authenticationTestPipeline(t, srv, azcore.NewSASCredential("SharedAccessSignature sr=x&sig=y"), &policy.AuthenticationOptions{SASHeader: shared.HeaderAuthorization})
+	req, err = NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	resp, err = pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, "SharedAccessSignature sr=x&sig=y", resp.Request.Header.Get(shared.HeaderAuthorization))
+	require.Empty(t, resp.Request.URL.RawQuery)
+}
+
+func TestAuthenticationPolicyAuthorizer(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	auth := authorizerFunc(func(req *policy.Request) error {
+		req.Raw().Header.Set(shared.HeaderAuthorization, "custom")
+		return nil
+	})
+	pl := authenticationTestPipeline(t, srv, auth, nil)
+	req, err := NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, "custom", resp.Request.Header.Get(shared.HeaderAuthorization))
+}
+
+func TestAuthenticationPolicyErrors(t *testing.T) {
+	_, err := NewAuthenticationPolicy(nil, nil)
+	require.Error(t, err)
+	_, err = NewAuthenticationPolicy("key", nil)
+	require.Error(t, err)
+	// a key credential needs to know how to send the key
+	_, err = NewAuthenticationPolicy(azcore.NewKeyCredential("key"), nil)
+	require.Error(t, err)
+}
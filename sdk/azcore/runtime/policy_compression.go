@@ -0,0 +1,160 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/exported"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/shared"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const (
+	defaultCompressionMinSize = 1024
+	encodingGzip              = "gzip"
+)
+
+type compressionPolicy struct {
+	options policy.CompressionOptions
+
+	// hosts which rejected a compressed request body
+	rejectedBy sync.Map
+}
+
+// NewCompressionPolicy creates a policy that gzip-compresses request bodies and decompresses
+// gzip-encoded responses. Add it to ClientOptions.PerCallPolicies for services that accept
+// compressed request bodies. When a host rejects a compressed body with status 415 (Unsupported
+// Media Type), the policy sends the request again uncompressed and stops compressing requests
+// to that host. Pass nil to accept the default values.
+func NewCompressionPolicy(o *policy.CompressionOptions) policy.Policy {
+	if o == nil {
+		o = &policy.CompressionOptions{}
+	}
+	p := &compressionPolicy{options: *o}
+	if p.options.MinSize <= 0 {
+		p.options.MinSize = defaultCompressionMinSize
+	}
+	return p
+}
+
+func (p *compressionPolicy) Do(req *policy.Request) (*http.Response, error) {
+	if !p.options.DisableResponseDecompression && req.Raw().Header.Get(shared.HeaderAcceptEncoding) == "" {
+		req.Raw().Header.Set(shared.HeaderAcceptEncoding, encodingGzip)
+	}
+
+	original := req.Body()
+	compressed, err := p.compress(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := req.Next()
+	if compressed && err == nil && resp.StatusCode == http.StatusUnsupportedMediaType {
+		// the host doesn't accept compressed bodies; send the original
+		p.rejectedBy.Store(req.Raw().URL.Host, true)
+		Drain(resp)
+		req.Raw().Header.Del(shared.HeaderContentEncoding)
+		if err := req.SetBody(original, req.Raw().Header.Get(shared.HeaderContentType)); err != nil {
+			return nil, err
+		}
+		resp, err = req.Next()
+	}
+	if err != nil || p.options.DisableResponseDecompression {
+		return resp, err
+	}
+	return resp, decompress(resp)
+}
+
+// compress replaces the request's body with a gzip-compressed copy when the body qualifies.
+// The original body is left open, so it can be sent when the host rejects the compressed one.
+func (p *compressionPolicy) compress(req *policy.Request) (bool, error) {
+	body := req.Body()
+	if body == nil || req.Raw().ContentLength < p.options.MinSize || req.Raw().Header.Get(shared.HeaderContentEncoding) != "" {
+		return false, nil
+	}
+	if _, rejected := p.rejectedBy.Load(req.Raw().URL.Host); rejected {
+		return false, nil
+	}
+	contentType := req.Raw().Header.Get(shared.HeaderContentType)
+	if !p.compressible(contentType) {
+		return false, nil
+	}
+	if err := req.RewindBody(); err != nil {
+		return false, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.Copy(zw, body); err != nil {
+		return false, err
+	}
+	if err := zw.Close(); err != nil {
+		return false, err
+	}
+	if err := req.SetBody(exported.NopCloser(bytes.NewReader(buf.Bytes())), contentType); err != nil {
+		return false, err
+	}
+	req.Raw().Header.Set(shared.HeaderContentEncoding, encodingGzip)
+	return true, nil
+}
+
+func (p *compressionPolicy) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if p.options.ContentTypes == nil {
+		return mediaType == shared.ContentTypeAppJSON || strings.HasSuffix(mediaType, "+json")
+	}
+	for _, ct := range p.options.ContentTypes {
+		if strings.EqualFold(mediaType, ct) {
+			return true
+		}
+	}
+	return false
+}
+
+// decompress replaces a gzip-encoded response body with a reader of the decoded content
+func decompress(resp *http.Response) error {
+	if !strings.EqualFold(resp.Header.Get(shared.HeaderContentEncoding), encodingGzip) || resp.Body == nil || resp.Body == http.NoBody {
+		return nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err == io.EOF {
+		// empty body
+		return nil
+	} else if err != nil {
+		resp.Body.Close()
+		return err
+	}
+	resp.Body = &gzipResponseBody{zr: zr, body: resp.Body}
+	resp.Header.Del(shared.HeaderContentEncoding)
+	resp.Header.Del(shared.HeaderContentLength)
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+type gzipResponseBody struct {
+	zr   *gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipResponseBody) Read(p []byte) (int, error) {
+	return b.zr.Read(p)
+}
+
+func (b *gzipResponseBody) Close() error {
+	return b.body.Close()
+}
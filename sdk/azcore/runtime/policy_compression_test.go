@@ -0,0 +1,158 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/exported"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// compressionTransport records requests and returns the responses of respond
+type compressionTransport struct {
+	requests []recordedRequest
+	respond  func(req *http.Request) *http.Response
+}
+
+func (c *compressionTransport) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	}
+	c.requests = append(c.requests, recordedRequest{header: req.Header.Clone(), body: body})
+	resp := c.respond(req)
+	resp.Request = req
+	return resp, nil
+}
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func gunzipBytes(t *testing.T, b []byte) []byte {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return decoded
+}
+
+func newCompressionTestRequest(t *testing.T, body string, contentType string) *policy.Request {
+	req, err := NewRequest(context.Background(), http.MethodPut, "https://contoso.com/resource")
+	require.NoError(t, err)
+	require.NoError(t, req.SetBody(exported.NopCloser(strings.NewReader(body)), contentType))
+	return req
+}
+
+func TestCompressionPolicy(t *testing.T) {
+	payload := `{"value":"` + strings.Repeat("a", 2048) + `"}`
+	transport := &compressionTransport{respond: func(req *http.Request) *http.Response {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Encoding": []string{"gzip"}},
+			Body:       io.NopCloser(bytes.NewReader(gzipBytes(t, []byte("response")))),
+		}
+	}}
+	pl := exported.NewPipeline(transport, NewCompressionPolicy(nil))
+
+	resp, err := pl.Do(newCompressionTestRequest(t, payload, "application/json"))
+	require.NoError(t, err)
+
+	require.Len(t, transport.requests, 1)
+	sent := transport.requests[0]
+	require.Equal(t, "gzip", sent.header.Get("Content-Encoding"))
+	require.Equal(t, "gzip", sent.header.Get("Accept-Encoding"))
+	require.Equal(t, "application/json", sent.header.Get("Content-Type"))
+	require.Less(t, len(sent.body), len(payload))
+	require.Equal(t, payload, string(gunzipBytes(t, sent.body)))
+
+	body, err := Payload(resp)
+	require.NoError(t, err)
+	require.Equal(t, "response", string(body))
+	require.Empty(t, resp.Header.Get("Content-Encoding"))
+	require.True(t, resp.Uncompressed)
+}
+
+func TestCompressionPolicySkipsIneligibleBodies(t *testing.T) {
+	transport := &compressionTransport{respond: func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}
+	}}
+	pl := exported.NewPipeline(transport, NewCompressionPolicy(&policy.CompressionOptions{
+		MinSize:                      10,
+		DisableResponseDecompression: true,
+	}))
+
+	for _, test := range []struct{ body, contentType string }{
+		{body: `{"a":1}`, contentType: "application/json"},
+		{body: strings.Repeat("a", 100), contentType: "application/octet-stream"},
+	} {
+		_, err := pl.Do(newCompressionTestRequest(t, test.body, test.contentType))
+		require.NoError(t, err)
+	}
+
+	// the policy compresses "+json" types by default
+	_, err := pl.Do(newCompressionTestRequest(t, strings.Repeat("a", 100), "application/merge-patch+json"))
+	require.NoError(t, err)
+
+	require.Len(t, transport.requests, 3)
+	for i, r := range transport.requests {
+		require.Empty(t, r.header.Get("Accept-Encoding"))
+		if i < 2 {
+			require.Empty(t, r.header.Get("Content-Encoding"))
+		} else {
+			require.Equal(t, "gzip", r.header.Get("Content-Encoding"))
+		}
+	}
+}
+
+func TestCompressionPolicyUnsupportedMediaType(t *testing.T) {
+	payload := `{"value":"` + strings.Repeat("a", 2048) + `"}`
+	transport := &compressionTransport{respond: func(req *http.Request) *http.Response {
+		if req.Header.Get("Content-Encoding") != "" {
+			return &http.Response{StatusCode: http.StatusUnsupportedMediaType, Body: http.NoBody}
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}
+	}}
+	pl := exported.NewPipeline(transport, NewCompressionPolicy(nil))
+
+	resp, err := pl.Do(newCompressionTestRequest(t, payload, "application/json"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Len(t, transport.requests, 2)
+	require.Equal(t, "gzip", transport.requests[0].header.Get("Content-Encoding"))
+	require.Empty(t, transport.requests[1].header.Get("Content-Encoding"))
+	require.Equal(t, payload, string(transport.requests[1].body))
+
+	// the policy remembers the host doesn't accept compressed bodies
+	_, err = pl.Do(newCompressionTestRequest(t, payload, "application/json"))
+	require.NoError(t, err)
+	require.Len(t, transport.requests, 3)
+	require.Empty(t, transport.requests[2].header.Get("Content-Encoding"))
+}
@@ -0,0 +1,205 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const (
+	defaultConcurrencyInitialLimit     = 8
+	defaultConcurrencyMinLimit         = 1
+	defaultConcurrencyMaxLimit         = 256
+	defaultConcurrencyLatencyTolerance = 2
+	defaultConcurrencyBackoff          = 0.75
+
+	// concurrencyLatencyWindow is the number of responses after which a host's shortest latency is
+	// replaced by the shortest latency of those responses, so the baseline follows changes in the service
+	concurrencyLatencyWindow = 100
+)
+
+type concurrencyLimitPolicy struct {
+	options policy.ConcurrencyLimitOptions
+
+	// hosts maps a host to its *concurrencyLimiter
+	hosts sync.Map
+}
+
+// NewConcurrencyLimitPolicy creates a policy that limits the number of concurrent requests to each host and
+// adapts the limits to the latency of the host's responses. A limit grows by about one request per round trip while
+// requests use at least half of it and latency stays within policy.ConcurrencyLimitOptions.LatencyTolerance of the shortest
+// recently observed latency. It shrinks by ConcurrencyLimitOptions.Backoff when latency rises above that, or the
+// host throttles a request or doesn't respond. Requests over the limit wait for a request to the host to finish,
+// or for their context to be done. This lets bulk operations find the request rate a service sustains without
+// a fixed number of workers.
+//
+// Add the policy to ClientOptions.PerRetryPolicies, so that each try counts against the limit and retry delays
+// don't. Share a policy between clients to share its limits. Pass nil to accept the default values.
+func NewConcurrencyLimitPolicy(o *policy.ConcurrencyLimitOptions) policy.Policy {
+	if o == nil {
+		o = &policy.ConcurrencyLimitOptions{}
+	}
+	p := &concurrencyLimitPolicy{options: *o}
+	if p.options.MinLimit <= 0 {
+		p.options.MinLimit = defaultConcurrencyMinLimit
+	}
+	if p.options.MaxLimit <= 0 {
+		p.options.MaxLimit = defaultConcurrencyMaxLimit
+	}
+	if p.options.MaxLimit < p.options.MinLimit {
+		p.options.MaxLimit = p.options.MinLimit
+	}
+	if p.options.InitialLimit <= 0 {
+		p.options.InitialLimit = defaultConcurrencyInitialLimit
+	}
+	if p.options.InitialLimit < p.options.MinLimit {
+		p.options.InitialLimit = p.options.MinLimit
+	} else if p.options.InitialLimit > p.options.MaxLimit {
+		p.options.InitialLimit = p.options.MaxLimit
+	}
+	if p.options.LatencyTolerance <= 1 {
+		p.options.LatencyTolerance = defaultConcurrencyLatencyTolerance
+	}
+	if p.options.Backoff <= 0 || p.options.Backoff >= 1 {
+		p.options.Backoff = defaultConcurrencyBackoff
+	}
+	return p
+}
+
+func (p *concurrencyLimitPolicy) Do(req *policy.Request) (*http.Response, error) {
+	host := req.Raw().URL.Host
+	l := p.limiter(host)
+	if err := l.acquire(req.Raw().Context()); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := req.Next()
+	latency := time.Since(start)
+
+	overloaded := false
+	if err != nil {
+		// a request canceled by its caller says nothing about the host
+		overloaded = !errors.Is(err, context.Canceled)
+	} else if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		overloaded = true
+	}
+	if changed, limit := l.release(latency, overloaded, err == nil); changed && p.options.OnLimitChange != nil {
+		p.options.OnLimitChange(host, limit)
+	}
+	return resp, err
+}
+
+func (p *concurrencyLimitPolicy) limiter(host string) *concurrencyLimiter {
+	if l, ok := p.hosts.Load(host); ok {
+		return l.(*concurrencyLimiter)
+	}
+	l, _ := p.hosts.LoadOrStore(host, &concurrencyLimiter{
+		options: &p.options,
+		limit:   float64(p.options.InitialLimit),
+		changed: make(chan struct{}),
+	})
+	return l.(*concurrencyLimiter)
+}
+
+// concurrencyLimiter is the adaptive limit of one host
+type concurrencyLimiter struct {
+	options *policy.ConcurrencyLimitOptions
+
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+
+	// changed is closed, and replaced, when a request finishes, to wake the requests waiting for the limit
+	changed chan struct{}
+
+	// minLatency is the shortest latency of the previous window, and windowMin that of the current one
+	minLatency time.Duration
+	windowMin  time.Duration
+	samples    int
+
+	lastDecrease time.Time
+}
+
+func (l *concurrencyLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees the slot of a finished request and adjusts the limit. It returns whether the
+// integer limit changed, and the limit.
+func (l *concurrencyLimiter) release(latency time.Duration, overloaded bool, responded bool) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// the limit is worth raising only when requests use a good part of it
+	saturated := l.inFlight*2 >= int(l.limit)
+	l.inFlight--
+	close(l.changed)
+	l.changed = make(chan struct{})
+
+	before := int(l.limit)
+	if responded {
+		l.observe(latency)
+	}
+	if !overloaded && responded && float64(latency) > l.options.LatencyTolerance*float64(l.minLatency) {
+		overloaded = true
+	}
+
+	now := time.Now()
+	if overloaded {
+		// all the requests in flight when the host became overloaded report it; decrease once per round trip
+		if now.Sub(l.lastDecrease) > latency {
+			l.limit *= l.options.Backoff
+			if l.limit < float64(l.options.MinLimit) {
+				l.limit = float64(l.options.MinLimit)
+			}
+			l.lastDecrease = now
+		}
+	} else if saturated {
+		// additive increase: about one request per round trip
+		l.limit += 1 / l.limit
+		if l.limit > float64(l.options.MaxLimit) {
+			l.limit = float64(l.options.MaxLimit)
+		}
+	}
+	return int(l.limit) != before, int(l.limit)
+}
+
+func (l *concurrencyLimiter) observe(latency time.Duration) {
+	if l.minLatency == 0 || latency < l.minLatency {
+		l.minLatency = latency
+	}
+	if l.windowMin == 0 || latency < l.windowMin {
+		l.windowMin = latency
+	}
+	l.samples++
+	if l.samples == concurrencyLatencyWindow {
+		l.minLatency = l.windowMin
+		l.windowMin = 0
+		l.samples = 0
+	}
+}
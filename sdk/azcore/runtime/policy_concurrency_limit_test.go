@@ -0,0 +1,165 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/exported"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingTransport holds requests until release is closed and records the most requests in flight at once
+type blockingTransport struct {
+	inFlight, maxInFlight int32
+	release               chan struct{}
+	status                int
+}
+
+func (b *blockingTransport) Do(req *http.Request) (*http.Response, error) {
+	n := atomic.AddInt32(&b.inFlight, 1)
+	defer atomic.AddInt32(&b.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&b.maxInFlight)
+		if n <= max || atomic.CompareAndSwapInt32(&b.maxInFlight, max, n) {
+			break
+		}
+	}
+	<-b.release
+	return &http.Response{StatusCode: b.status, Body: http.NoBody, Request: req}, nil
+}
+
+func TestConcurrencyLimitPolicy(t *testing.T) {
+	transport := &blockingTransport{release: make(chan struct{}), status: http.StatusOK}
+	pl := exported.NewPipeline(transport, NewConcurrencyLimitPolicy(&policy.ConcurrencyLimitOptions{InitialLimit: 2}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := NewRequest(context.Background(), http.MethodGet, "https://contoso.com/resource")
+			require.NoError(t, err)
+			_, err = pl.Do(req)
+			require.NoError(t, err)
+		}()
+	}
+
+	// wait for the requests under the limit to reach the transport
+	for atomic.LoadInt32(&transport.inFlight) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	require.EqualValues(t, 2, atomic.LoadInt32(&transport.inFlight))
+
+	close(transport.release)
+	wg.Wait()
+	require.LessOrEqual(t, atomic.LoadInt32(&transport.maxInFlight), int32(3))
+}
+
+func TestConcurrencyLimitPolicyContextDone(t *testing.T) {
+	transport := &blockingTransport{release: make(chan struct{}), status: http.StatusOK}
+	pl := exported.NewPipeline(transport, NewConcurrencyLimitPolicy(&policy.ConcurrencyLimitOptions{InitialLimit: 1}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req, err := NewRequest(context.Background(), http.MethodGet, "https://contoso.com/resource")
+		require.NoError(t, err)
+		_, err = pl.Do(req)
+		require.NoError(t, err)
+	}()
+	for atomic.LoadInt32(&transport.inFlight) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := NewRequest(ctx, http.MethodGet, "https://contoso.com/resource")
+	require.NoError(t, err)
+	_, err = pl.Do(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// requests to other hosts have their own limit
+	other, err := NewRequest(context.Background(), http.MethodGet, "https://fabrikam.com/resource")
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(transport.release)
+	}()
+	_, err = pl.Do(other)
+	require.NoError(t, err)
+	<-done
+}
+
+func TestConcurrencyLimitPolicyThrottled(t *testing.T) {
+	transport := &blockingTransport{release: make(chan struct{}), status: http.StatusTooManyRequests}
+	close(transport.release)
+	var limits []int
+	pl := exported.NewPipeline(transport, NewConcurrencyLimitPolicy(&policy.ConcurrencyLimitOptions{
+		InitialLimit: 8,
+		Backoff:      0.5,
+		OnLimitChange: func(host string, limit int) {
+			require.Equal(t, "contoso.com", host)
+			limits = append(limits, limit)
+		},
+	}))
+	for i := 0; i < 3; i++ {
+		req, err := NewRequest(context.Background(), http.MethodGet, "https://contoso.com/resource")
+		require.NoError(t, err)
+		resp, err := pl.Do(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		time.Sleep(time.Millisecond)
+	}
+	require.Equal(t, []int{4, 2, 1}, limits)
+}
+
+func TestConcurrencyLimiterAdapts(t *testing.T) {
+	options := &policy.ConcurrencyLimitOptions{MinLimit: 1, MaxLimit: 3, LatencyTolerance: 2, Backoff: 0.5}
+	l := &concurrencyLimiter{options: options, limit: 2, changed: make(chan struct{})}
+
+	// requests that use the limit raise it by about one per round trip
+	roundTrip := func() (changed bool, limit int) {
+		for i := int(l.limit); l.inFlight < i; {
+			require.NoError(t, l.acquire(context.Background()))
+		}
+		for l.inFlight > 0 {
+			if c, n := l.release(10*time.Millisecond, false, true); c {
+				changed, limit = c, n
+			}
+		}
+		return
+	}
+	changed, _ := roundTrip()
+	require.False(t, changed)
+	changed, limit := roundTrip()
+	require.True(t, changed)
+	require.Equal(t, 3, limit)
+
+	// the limit doesn't exceed MaxLimit
+	for i := 0; i < 10; i++ {
+		roundTrip()
+	}
+	require.Equal(t, 3.0, l.limit)
+
+	// latency over the tolerance lowers the limit, once per round trip
+	require.NoError(t, l.acquire(context.Background()))
+	require.NoError(t, l.acquire(context.Background()))
+	changed, limit = l.release(25*time.Millisecond, false, true)
+	require.True(t, changed)
+	require.Equal(t, 1, limit)
+	changed, _ = l.release(25*time.Millisecond, false, true)
+	require.False(t, changed)
+	require.Equal(t, 1.5, l.limit)
+}
@@ -0,0 +1,138 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	goruntime "runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/log"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/internal/errorinfo"
+)
+
+// PanicError is returned by a request when a policy or the transport of a pipeline whose
+// ClientOptions.PanicRecovery.Enabled is true panics.
+type PanicError struct {
+	// Policy is the type of the policy or transport that panicked, for example "*mypackage.myPolicy".
+	Policy string
+
+	// Method is the HTTP method of the request.
+	Method string
+
+	// URL is the URL of the request. Like in logs, the values of query parameters not in
+	// ClientOptions.Logging.AllowedQueryParams are redacted.
+	URL string
+
+	// Value is the value passed to panic.
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+// Error implements the error interface for type PanicError.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s panicked handling %s %s: %v", e.Policy, e.Method, e.URL, e.Value)
+}
+
+// NonRetriable indicates the request shouldn't be retried.
+func (e *PanicError) NonRetriable() {
+	// marker method
+}
+
+var _ errorinfo.NonRetriable = (*PanicError)(nil)
+
+// panicRecoveryPolicy converts panics in the policy it wraps into a *PanicError
+type panicRecoveryPolicy struct {
+	name      string
+	next      policy.Policy
+	allowedQP map[string]struct{}
+}
+
+func (p *panicRecoveryPolicy) Do(req *policy.Request) (resp *http.Response, err error) {
+	defer recoverPanic(p.name, req.Raw(), p.allowedQP, &resp, &err)
+	return p.next.Do(req)
+}
+
+// panicRecoveryTransport converts panics in the transport it wraps into a *PanicError
+type panicRecoveryTransport struct {
+	name      string
+	next      policy.Transporter
+	allowedQP map[string]struct{}
+}
+
+func (t *panicRecoveryTransport) Do(req *http.Request) (resp *http.Response, err error) {
+	defer recoverPanic(t.name, req, t.allowedQP, &resp, &err)
+	return t.next.Do(req)
+}
+
+// withPanicRecovery wraps each policy and the transport so a panic fails the request instead of crashing the process.
+// Wrapping each policy, rather than only the first, identifies the one that panicked.
+func withPanicRecovery(policies []policy.Policy, transport policy.Transporter, allowedQueryParams []string) ([]policy.Policy, policy.Transporter) {
+	allowedQP := map[string]struct{}{
+		"api-version": {},
+	}
+	for _, qp := range allowedQueryParams {
+		allowedQP[strings.ToLower(qp)] = struct{}{}
+	}
+	wrapped := make([]policy.Policy, len(policies))
+	for i, p := range policies {
+		wrapped[i] = &panicRecoveryPolicy{name: policyName(p), next: p, allowedQP: allowedQP}
+	}
+	return wrapped, &panicRecoveryTransport{name: fmt.Sprintf("%T", transport), next: transport, allowedQP: allowedQP}
+}
+
+// recoverPanic must be deferred. It converts a panic into a *PanicError returned by the deferring function.
+func recoverPanic(name string, req *http.Request, allowedQP map[string]struct{}, resp **http.Response, err *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	pe := &PanicError{
+		Policy: name,
+		Method: req.Method,
+		URL:    sanitizedURL(req, allowedQP),
+		Value:  v,
+		Stack:  debug.Stack(),
+	}
+	log.Writef(log.EventRequest, "recovered from panic: %s\n%s", pe.Error(), pe.Stack)
+	*resp = nil
+	*err = pe
+}
+
+// policyName returns the type of p, or the name of the function of a policyFunc
+func policyName(p policy.Policy) string {
+	if pf, ok := p.(policyFunc); ok {
+		if fn := goruntime.FuncForPC(reflect.ValueOf(pf).Pointer()); fn != nil {
+			return fn.Name()
+		}
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+// sanitizedURL returns the URL of req without user information and with the values of query parameters
+// not in allowedQP redacted
+func sanitizedURL(req *http.Request, allowedQP map[string]struct{}) string {
+	if req.URL == nil {
+		return ""
+	}
+	u := *req.URL
+	u.User = nil
+	qp := u.Query()
+	for k := range qp {
+		if _, ok := allowedQP[strings.ToLower(k)]; !ok {
+			qp.Set(k, redactedValue)
+		}
+	}
+	u.RawQuery = qp.Encode()
+	return u.String()
+}
@@ -0,0 +1,66 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/exported"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/internal/errorinfo"
+)
+
+// NewKeyCredentialSigner creates a request signer that signs requests with the current key of credential, so
+// clients that sign with a shared key can add it to PipelineOptions.Signers and their keys can be rotated
+// with KeyCredential.Update.
+func NewKeyCredentialSigner(credential *azcore.KeyCredential, sign func(req *policy.Request, key string) error) (policy.RequestSigner, error) {
+	if credential == nil {
+		return nil, errors.New("credential can't be nil")
+	}
+	if sign == nil {
+		return nil, errors.New("sign can't be nil")
+	}
+	return policy.RequestSignerFunc(func(req *policy.Request) error {
+		return sign(req, exported.KeyCredentialGet(credential))
+	}), nil
+}
+
+// requestSigningPolicy runs the pipeline's request signers after the policies that can change requests
+type requestSigningPolicy struct {
+	signers []policy.RequestSigner
+}
+
+func (p *requestSigningPolicy) Do(req *policy.Request) (*http.Response, error) {
+	for _, signer := range p.signers {
+		if err := signer.SignRequest(req); err != nil {
+			// signing errors, such as a malformed key, are usually permanent, so the request isn't retried
+			return nil, &requestSigningError{err: err}
+		}
+	}
+	return req.Next()
+}
+
+type requestSigningError struct {
+	err error
+}
+
+func (e *requestSigningError) Error() string {
+	return fmt.Sprintf("signing the request: %s", e.err.Error())
+}
+
+func (e *requestSigningError) NonRetriable() {
+	// marker method
+}
+
+func (e *requestSigningError) Unwrap() error {
+	return e.err
+}
+
+var _ errorinfo.NonRetriable = (*requestSigningError)(nil)
@@ -0,0 +1,115 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/shared"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/internal/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestSignersRunAfterPolicies(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusServiceUnavailable))
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+
+	tries := 0
+	perRetry := policyFunc(func(req *policy.Request) (*http.Response, error) {
+		req.Raw().Header.Set("x-ms-client-policy", "set")
+		return req.Next()
+	})
+	// the signature covers the headers of the other policies and of the context
+	signer := policy.RequestSignerFunc(func(req *policy.Request) error {
+		tries++
+		h := req.Raw().Header
+		req.Raw().Header.Set(shared.HeaderAuthorization, strings.Join([]string{h.Get("x-ms-client-policy"), h.Get("x-ms-context")}, ","))
+		return nil
+	})
+	pl := NewPipeline("testmodule", "v0.1.0", PipelineOptions{Signers: []policy.RequestSigner{signer}}, &policy.ClientOptions{
+		PerRetryPolicies: []policy.Policy{perRetry},
+		Retry:            policy.RetryOptions{RetryDelay: time.Millisecond},
+		Transport:        srv,
+	})
+	ctx := WithHTTPHeader(context.Background(), http.Header{"x-ms-context": []string{"ctx"}})
+	req, err := NewRequest(ctx, http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "set,ctx", resp.Request.Header.Get(shared.HeaderAuthorization))
+
+	// each try is signed
+	require.Equal(t, 2, tries)
+}
+
+func TestRequestSignerError(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+
+	tries := 0
+	signErr := errors.New("can't sign")
+	signer := policy.RequestSignerFunc(func(*policy.Request) error {
+		tries++
+		return signErr
+	})
+	pl := NewPipeline("testmodule", "v0.1.0", PipelineOptions{Signers: []policy.RequestSigner{signer}}, &policy.ClientOptions{
+		Retry:     policy.RetryOptions{RetryDelay: time.Millisecond},
+		Transport: srv,
+	})
+	req, err := NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	_, err = pl.Do(req)
+	require.ErrorIs(t, err, signErr)
+
+	// the request isn't retried or sent
+	require.Equal(t, 1, tries)
+	require.Equal(t, 0, srv.Requests())
+}
+
+func TestKeyCredentialSigner(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+
+	cred := azcore.NewKeyCredential("key1")
+	signer, err := NewKeyCredentialSigner(cred, func(req *policy.Request, key string) error {
+		req.Raw().Header.Set(shared.HeaderAuthorization, "SharedKey account:"+key)
+		return nil
+	})
+	require.NoError(t, err)
+	pl := NewPipeline("testmodule", "v0.1.0", PipelineOptions{Signers: []policy.RequestSigner{signer}}, &policy.ClientOptions{Transport: srv})
+	req, err := NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, "SharedKey account:key1", resp.Request.Header.Get(shared.HeaderAuthorization))
+
+	// a rotated key is used by the next request
+	cred.Update("key2")
+	req, err = NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	resp, err = pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, "SharedKey account:key2", resp.Request.Header.Get(shared.HeaderAuthorization))
+
+	_, err = NewKeyCredentialSigner(nil, func(*policy.Request, string) error { return nil })
+	require.Error(t, err)
+	_, err = NewKeyCredentialSigner(cred, nil)
+	require.Error(t, err)
+}
@@ -13,6 +13,7 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/log"
@@ -111,13 +112,17 @@ func (p *retryPolicy) Do(req *policy.Request) (resp *http.Response, err error) {
 		// For each try, seek to the beginning of the Body stream. We do this even for the 1st try because
 		// the stream may not be at offset 0 when we first get it and we want the same behavior for the
 		// 1st try as for additional tries.
-		err = req.RewindBody()
+		if rwbody != nil {
+			err = rwbody.rewind(try, req.RewindBody)
+		} else {
+			err = req.RewindBody()
+		}
 		if err != nil {
 			return
 		}
 		// RewindBody() restores Raw().Body to its original state, so set our rewindable after
 		if rwbody != nil {
-			req.Raw().Body = rwbody
+			req.Raw().Body = &tryRequestBody{rwbody: rwbody, try: try}
 		}
 
 		if options.TryTimeout == 0 {
@@ -175,6 +180,14 @@ func (p *retryPolicy) Do(req *policy.Request) (resp *http.Response, err error) {
 		if delay <= 0 {
 			delay = calcDelay(options, try)
 		}
+
+		if options.OnRetry != nil {
+			info := policy.RetryInfo{Attempt: try, Delay: delay, Err: err}
+			if resp != nil {
+				info.StatusCode = resp.StatusCode
+			}
+			options.OnRetry(info)
+		}
 		log.Writef(log.EventRetryPolicy, "End Try #%d, Delay=%v", try, delay)
 		select {
 		case <-time.After(delay):
@@ -198,18 +211,47 @@ func WithRetryOptions(parent context.Context, options policy.RetryOptions) conte
 // This struct is used when sending a body to the network
 type retryableRequestBody struct {
 	body io.ReadSeeker // Seeking is required to support retries
+
+	// mu protects body and try. a transport can keep reading the body of a
+	// failed try after returning, so reads are limited to the current try.
+	mu  sync.Mutex
+	try int32
 }
 
-// Read reads a block of data from an inner stream and reports progress
-func (b *retryableRequestBody) Read(p []byte) (n int, err error) {
-	return b.body.Read(p)
+// rewind makes try the only one allowed to read the body and rewinds it
+func (b *retryableRequestBody) rewind(try int32, rewindBody func() error) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.try = try
+	return rewindBody()
 }
 
-func (b *retryableRequestBody) Seek(offset int64, whence int) (offsetFromStart int64, err error) {
-	return b.body.Seek(offset, whence)
+// tryRequestBody is the request body for a single try
+type tryRequestBody struct {
+	rwbody *retryableRequestBody
+	try    int32
+}
+
+// Read reads a block of data from the inner stream, unless a later try has rewound it
+func (b *tryRequestBody) Read(p []byte) (n int, err error) {
+	b.rwbody.mu.Lock()
+	defer b.rwbody.mu.Unlock()
+	if b.rwbody.try != b.try {
+		return 0, errors.New("the request body is being sent by a subsequent try")
+	}
+	return b.rwbody.body.Read(p)
+}
+
+func (b *tryRequestBody) Seek(offset int64, whence int) (offsetFromStart int64, err error) {
+	b.rwbody.mu.Lock()
+	defer b.rwbody.mu.Unlock()
+	if b.rwbody.try != b.try {
+		return 0, errors.New("the request body is being sent by a subsequent try")
+	}
+	return b.rwbody.body.Seek(offset, whence)
 }
 
-func (b *retryableRequestBody) Close() error {
+func (b *tryRequestBody) Close() error {
 	// We don't want the underlying transport to close the request body on transient failures so this is a nop.
 	// The retry policy closes the request body upon success.
 	return nil
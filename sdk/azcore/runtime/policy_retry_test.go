@@ -724,3 +724,55 @@ func (n *nilRespInjector) Do(req *http.Request) (*http.Response, error) {
 	}
 	return n.t.Do(req)
 }
+
+func TestRetryPolicyOnRetry(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusServiceUnavailable))
+	srv.AppendError(errors.New("bogus error"))
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	var infos []policy.RetryInfo
+	opt := testRetryOptions()
+	opt.OnRetry = func(info policy.RetryInfo) {
+		infos = append(infos, info)
+	}
+	pl := exported.NewPipeline(srv, NewRetryPolicy(opt))
+	req, err := NewRequest(context.Background(), http.MethodGet, srv.URL())
+	require.NoError(t, err)
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, infos, 2)
+	require.EqualValues(t, 1, infos[0].Attempt)
+	require.Equal(t, http.StatusServiceUnavailable, infos[0].StatusCode)
+	require.NoError(t, infos[0].Err)
+	require.Greater(t, infos[0].Delay, time.Duration(0))
+	require.EqualValues(t, 2, infos[1].Attempt)
+	require.Zero(t, infos[1].StatusCode)
+	require.Error(t, infos[1].Err)
+}
+
+func TestRetryableRequestBodySupersededTry(t *testing.T) {
+	body := newRewindTrackingBody("stuff")
+	rwbody := &retryableRequestBody{body: body}
+	rewind := func() error {
+		_, err := body.Seek(0, io.SeekStart)
+		return err
+	}
+
+	require.NoError(t, rwbody.rewind(1, rewind))
+	first := &tryRequestBody{rwbody: rwbody, try: 1}
+	b := make([]byte, 2)
+	n, err := first.Read(b)
+	require.NoError(t, err)
+	require.Equal(t, "st", string(b[:n]))
+
+	// a transport still reading the first try's body can't disturb the second try
+	require.NoError(t, rwbody.rewind(2, rewind))
+	second := &tryRequestBody{rwbody: rwbody, try: 2}
+	_, err = first.Read(b)
+	require.Error(t, err)
+	n, err = second.Read(b)
+	require.NoError(t, err)
+	require.Equal(t, "st", string(b[:n]))
+}
@@ -0,0 +1,60 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/shared"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// uploadProgressPolicy reports the progress of sending request bodies to the callback
+// specified with WithUploadProgress. It runs once per try, so progress restarts from zero
+// when a request is retried.
+func uploadProgressPolicy(req *policy.Request) (*http.Response, error) {
+	progress, ok := req.Raw().Context().Value(shared.CtxWithUploadProgressKey{}).(func(int64, int64))
+	if !ok || progress == nil || req.Raw().Body == nil || req.Raw().Body == http.NoBody {
+		return req.Next()
+	}
+	req.Raw().Body = &uploadProgressBody{
+		body:     req.Raw().Body,
+		total:    req.Raw().ContentLength,
+		progress: progress,
+	}
+	return req.Next()
+}
+
+// WithUploadProgress adds the specified callback to the parent context. Clients call it
+// as the bodies of requests made with the context are sent, with the number of bytes
+// sent and the length of the body, or -1 if the length is unknown.
+// When a request is retried, the callback is called again starting from zero bytes.
+func WithUploadProgress(parent context.Context, progress func(bytesTransferred int64, totalBytes int64)) context.Context {
+	return context.WithValue(parent, shared.CtxWithUploadProgressKey{}, progress)
+}
+
+type uploadProgressBody struct {
+	body     io.ReadCloser
+	total    int64
+	sent     int64
+	progress func(int64, int64)
+}
+
+func (b *uploadProgressBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.sent += int64(n)
+		b.progress(b.sent, b.total)
+	}
+	return n, err
+}
+
+func (b *uploadProgressBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,59 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/internal/exported"
+	"github.com/Azure/azure-sdk-for-go/sdk/internal/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadProgressPolicy(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusInternalServerError))
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	pl := exported.NewPipeline(srv, NewRetryPolicy(testRetryOptions()), policyFunc(uploadProgressPolicy))
+
+	type progress struct{ transferred, total int64 }
+	var reported []progress
+	ctx := WithUploadProgress(context.Background(), func(bytesTransferred int64, totalBytes int64) {
+		reported = append(reported, progress{bytesTransferred, totalBytes})
+	})
+
+	req, err := NewRequest(ctx, http.MethodPut, srv.URL())
+	require.NoError(t, err)
+	require.NoError(t, req.SetBody(newRewindTrackingBody("stuff"), "text/plain"))
+
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// progress restarts when the request is retried
+	require.Equal(t, []progress{{5, 5}, {5, 5}}, reported)
+}
+
+func TestUploadProgressPolicyNoCallback(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+	pl := exported.NewPipeline(srv, policyFunc(uploadProgressPolicy))
+
+	req, err := NewRequest(context.Background(), http.MethodPut, srv.URL())
+	require.NoError(t, err)
+	body := newRewindTrackingBody("stuff")
+	require.NoError(t, req.SetBody(body, "text/plain"))
+
+	resp, err := pl.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, body, req.Raw().Body)
+}
@@ -7,6 +7,7 @@
 package runtime
 
 import (
+	"context"
 	"crypto/tls"
 	"net"
 	"net/http"
@@ -16,12 +17,21 @@ import (
 var defaultHTTPClient *http.Client
 
 func init() {
-	defaultTransport := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+	defaultHTTPClient = &http.Client{
+		Transport: newDefaultTransport(nil),
+	}
+}
+
+// newDefaultTransport creates an *http.Transport with the default settings. If dialContext
+// is nil, connections are dialed with a net.Dialer.
+func newDefaultTransport(dialContext func(ctx context.Context, network string, address string) (net.Conn, error)) *http.Transport {
+	if dialContext == nil {
+		dialContext = newNetDialer().DialContext
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialContext,
 		ForceAttemptHTTP2:     true,
 		MaxIdleConns:          100,
 		IdleConnTimeout:       90 * time.Second,
@@ -31,7 +41,11 @@ func init() {
 			MinVersion: tls.VersionTLS12,
 		},
 	}
-	defaultHTTPClient = &http.Client{
-		Transport: defaultTransport,
+}
+
+func newNetDialer() *net.Dialer {
+	return &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
 	}
 }
@@ -0,0 +1,194 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+const defaultResolvedAddressesTTL = 5 * time.Minute
+
+// DialerOptions contains the optional values for NewDialer.
+type DialerOptions struct {
+	// PinnedAddresses maps host names to the IP addresses used to connect to them.
+	// Pinned hosts are never resolved. Host names are case-insensitive.
+	PinnedAddresses map[string][]string
+
+	// Resolve resolves host names that aren't pinned. It returns the host's IP addresses
+	// and how long they can be used before the host is resolved again.
+	// The default resolves hosts using net.DefaultResolver and the TTL.
+	Resolve func(ctx context.Context, host string) (addresses []string, ttl time.Duration, err error)
+
+	// TTL is how long addresses returned by the default resolver are used.
+	// The default value is five minutes.
+	TTL time.Duration
+}
+
+// Dialer connects to hosts using pinned or cached IP addresses, so that connections can be
+// established while DNS is unavailable. When resolving a host fails, the addresses it
+// previously resolved to are used even though their TTL has expired.
+// Use Dialer.DialContext as the DialContext of an http.Transport or with NewTransport.
+// Dialer is safe for concurrent use.
+type Dialer struct {
+	pinned  map[string][]string
+	resolve func(ctx context.Context, host string) ([]string, time.Duration, error)
+	dialer  *net.Dialer
+
+	mu    sync.Mutex
+	cache map[string]resolvedAddresses
+
+	// now exists so tests can control the clock
+	now func() time.Time
+}
+
+type resolvedAddresses struct {
+	addresses []string
+	expires   time.Time
+}
+
+// NewDialer creates a Dialer.
+// options: pass nil to accept the default values.
+func NewDialer(options *DialerOptions) *Dialer {
+	if options == nil {
+		options = &DialerOptions{}
+	}
+
+	d := &Dialer{
+		pinned:  map[string][]string{},
+		resolve: options.Resolve,
+		dialer:  newNetDialer(),
+		cache:   map[string]resolvedAddresses{},
+		now:     time.Now,
+	}
+
+	for host, addresses := range options.PinnedAddresses {
+		d.pinned[strings.ToLower(host)] = addresses
+	}
+
+	if d.resolve == nil {
+		ttl := options.TTL
+
+		if ttl <= 0 {
+			ttl = defaultResolvedAddressesTTL
+		}
+
+		d.resolve = func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			addresses, err := net.DefaultResolver.LookupHost(ctx, host)
+			return addresses, ttl, err
+		}
+	}
+
+	return d
+}
+
+// DialContext connects to the address on the named network. It has the same signature as
+// net.Dialer.DialContext. The address's IP addresses are tried in order until a connection succeeds.
+func (d *Dialer) DialContext(ctx context.Context, network string, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if net.ParseIP(host) != nil {
+		return d.dialer.DialContext(ctx, network, address)
+	}
+
+	addresses, err := d.addresses(ctx, host)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var dialErr error
+
+	for _, addr := range addresses {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(addr, port))
+
+		if err == nil {
+			return conn, nil
+		}
+
+		dialErr = err
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, dialErr
+}
+
+// addresses returns the IP addresses for host
+func (d *Dialer) addresses(ctx context.Context, host string) ([]string, error) {
+	key := strings.ToLower(host)
+
+	if addresses, ok := d.pinned[key]; ok {
+		if len(addresses) == 0 {
+			return nil, fmt.Errorf("no addresses are pinned for host %s", host)
+		}
+
+		return addresses, nil
+	}
+
+	d.mu.Lock()
+	cached, ok := d.cache[key]
+	d.mu.Unlock()
+
+	if ok && d.now().Before(cached.expires) {
+		return cached.addresses, nil
+	}
+
+	addresses, ttl, err := d.resolve(ctx, host)
+
+	if err == nil && len(addresses) == 0 {
+		err = errors.New("no addresses were returned")
+	}
+
+	if err != nil {
+		if ok {
+			// DNS is unavailable, keep using the addresses the host last resolved to
+			return cached.addresses, nil
+		}
+
+		return nil, fmt.Errorf("failed to resolve host %s: %w", host, err)
+	}
+
+	d.mu.Lock()
+	d.cache[key] = resolvedAddresses{addresses: addresses, expires: d.now().Add(ttl)}
+	d.mu.Unlock()
+
+	return addresses, nil
+}
+
+// TransportOptions contains the optional values for NewTransport.
+type TransportOptions struct {
+	// DialContext, when set, is used to create connections, for instance Dialer.DialContext.
+	DialContext func(ctx context.Context, network string, address string) (net.Conn, error)
+}
+
+// NewTransport creates a policy.Transporter with the same settings as the default
+// transport, for use as policy.ClientOptions.Transport.
+// options: pass nil to accept the default values.
+func NewTransport(options *TransportOptions) policy.Transporter {
+	if options == nil {
+		options = &TransportOptions{}
+	}
+
+	return &http.Client{
+		Transport: newDefaultTransport(options.DialContext),
+	}
+}
@@ -0,0 +1,105 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/internal/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialerPinnedAddresses(t *testing.T) {
+	srv, close := mock.NewServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusOK))
+
+	u, err := url.Parse(srv.URL())
+	require.NoError(t, err)
+	port := u.Port()
+
+	dialer := NewDialer(&DialerOptions{
+		PinnedAddresses: map[string][]string{"Vault.Example.Invalid": {"127.0.0.1"}},
+		Resolve: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			t.Fatal("pinned hosts shouldn't be resolved")
+			return nil, 0, nil
+		},
+	})
+
+	conn, err := dialer.DialContext(context.Background(), "tcp", net.JoinHostPort("vault.example.invalid", port))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
+
+func TestDialerResolveFailover(t *testing.T) {
+	resolved := 0
+	var resolveErr error
+
+	dialer := NewDialer(&DialerOptions{
+		Resolve: func(ctx context.Context, host string) ([]string, time.Duration, error) {
+			resolved++
+			return []string{"127.0.0.1"}, time.Minute, resolveErr
+		},
+	})
+
+	now := time.Now()
+	dialer.now = func() time.Time { return now }
+
+	addresses, err := dialer.addresses(context.Background(), "vault.example.invalid")
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1"}, addresses)
+	require.Equal(t, 1, resolved)
+
+	// cached until the TTL expires
+	_, err = dialer.addresses(context.Background(), "VAULT.example.invalid")
+	require.NoError(t, err)
+	require.Equal(t, 1, resolved)
+
+	// when resolution fails, the expired addresses are used
+	now = now.Add(2 * time.Minute)
+	resolveErr = errors.New("DNS is down")
+	addresses, err = dialer.addresses(context.Background(), "vault.example.invalid")
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1"}, addresses)
+	require.Equal(t, 2, resolved)
+
+	// without previously resolved addresses the error is returned
+	_, err = dialer.addresses(context.Background(), "other.example.invalid")
+	require.ErrorIs(t, err, resolveErr)
+}
+
+func TestNewTransportWithDialer(t *testing.T) {
+	srv, close := mock.NewTLSServer()
+	defer close()
+	srv.AppendResponse(mock.WithStatusCode(http.StatusTeapot))
+
+	dialed := false
+	transport := NewTransport(&TransportOptions{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialed = true
+			return (&net.Dialer{}).DialContext(ctx, network, address)
+		},
+	})
+
+	// the mock server uses a self-signed certificate
+	transport.(*http.Client).Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify = true
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL(), nil)
+	require.NoError(t, err)
+
+	resp, err := transport.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusTeapot, resp.StatusCode)
+	require.True(t, dialed)
+}
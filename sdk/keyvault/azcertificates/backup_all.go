@@ -0,0 +1,245 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	shared "github.com/Azure/azure-sdk-for-go/sdk/keyvault/internal"
+)
+
+// backupArchiveMagic begins every archive written by BackupAllCertificates. The last byte is the format version.
+var backupArchiveMagic = []byte("AZKVCERT\x01")
+
+const (
+	defaultConcurrency = 4
+
+	// maxBackupRecordSize bounds the records RestoreAllCertificates reads, so a corrupt length can't exhaust memory
+	maxBackupRecordSize = 64 << 20
+)
+
+// ErrInvalidBackupArchive is returned by RestoreAllCertificates when the data isn't an archive written by
+// BackupAllCertificates, or the archive is truncated.
+var ErrInvalidBackupArchive = errors.New("invalid certificate backup archive")
+
+// BackupAllCertificatesOptions contains optional parameters for Client.BackupAllCertificates
+type BackupAllCertificatesOptions struct {
+	// Filter, when set, limits the backup to the certificates it returns true for.
+	Filter func(*CertificateItem) bool
+
+	// MaxConcurrency is the maximum number of certificates backed up at once. The default value is 4.
+	MaxConcurrency int
+}
+
+// BackupAllCertificatesResponse contains response fields for Client.BackupAllCertificates
+type BackupAllCertificatesResponse struct {
+	// Certificates are the names of the certificates written to the archive, sorted.
+	Certificates []string
+
+	// Errors maps the names of the certificates that couldn't be backed up to the errors that prevented it.
+	Errors map[string]error
+}
+
+// BackupAllCertificates backs up every certificate in the vault with BackupCertificate and writes the backups
+// to w as an archive RestoreAllCertificates reads. Failing to back up a certificate doesn't stop the others
+// from being backed up; the response reports the failures. An error is returned when listing the certificates
+// or writing to w fails, in which case the archive is incomplete. This operation requires the certificates/list
+// and certificates/backup permissions. Pass nil for options to accept default values.
+func (c *Client) BackupAllCertificates(ctx context.Context, w io.Writer, options *BackupAllCertificatesOptions) (BackupAllCertificatesResponse, error) {
+	if options == nil {
+		options = &BackupAllCertificatesOptions{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultConcurrency
+	}
+
+	var names []string
+	pager := c.NewListPropertiesOfCertificatesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return BackupAllCertificatesResponse{}, err
+		}
+		for _, item := range page.Certificates {
+			if item == nil {
+				continue
+			}
+			_, name, _ := shared.ParseID(item.ID)
+			if name == nil {
+				continue
+			}
+			if options.Filter != nil && !options.Filter(item) {
+				continue
+			}
+			names = append(names, *name)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(backupArchiveMagic); err != nil {
+		return BackupAllCertificatesResponse{}, err
+	}
+
+	resp := BackupAllCertificatesResponse{Errors: map[string]error{}}
+	var mu sync.Mutex
+	var writeErr error
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			backup, err := c.BackupCertificate(ctx, name, nil)
+			mu.Lock()
+			defer mu.Unlock()
+			if writeErr != nil {
+				return
+			}
+			if err != nil {
+				resp.Errors[name] = err
+				return
+			}
+			if writeErr = writeBackupRecord(bw, name, backup.Value); writeErr == nil {
+				resp.Certificates = append(resp.Certificates, name)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	if writeErr != nil {
+		return resp, writeErr
+	}
+	// an empty name ends the archive, so truncated archives can be detected
+	if err := writeBackupRecord(bw, "", nil); err != nil {
+		return resp, err
+	}
+	sort.Strings(resp.Certificates)
+	return resp, bw.Flush()
+}
+
+// RestoreAllCertificatesOptions contains optional parameters for Client.RestoreAllCertificates
+type RestoreAllCertificatesOptions struct {
+	// MaxConcurrency is the maximum number of certificates restored at once. The default value is 4.
+	MaxConcurrency int
+}
+
+// RestoreAllCertificatesResponse contains response fields for Client.RestoreAllCertificates
+type RestoreAllCertificatesResponse struct {
+	// Certificates are the names of the certificates restored, sorted.
+	Certificates []string
+
+	// Errors maps the names of the certificates that couldn't be restored to the errors that prevented it.
+	// Restoring fails, for example, when the vault already has a certificate with the same name.
+	Errors map[string]error
+}
+
+// RestoreAllCertificates restores the certificates in an archive written by BackupAllCertificates with
+// RestoreCertificateBackup. Failing to restore a certificate doesn't stop the others from being restored; the
+// response reports the failures. An error is returned when reading r fails or the archive is invalid, wrapping
+// ErrInvalidBackupArchive in the latter case; the certificates read before the problem are still restored. This
+// operation requires the certificates/restore permission. Pass nil for options to accept default values.
+func (c *Client) RestoreAllCertificates(ctx context.Context, r io.Reader, options *RestoreAllCertificatesOptions) (RestoreAllCertificatesResponse, error) {
+	if options == nil {
+		options = &RestoreAllCertificatesOptions{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultConcurrency
+	}
+
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(backupArchiveMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != string(backupArchiveMagic) {
+		return RestoreAllCertificatesResponse{}, fmt.Errorf("%w: unknown header", ErrInvalidBackupArchive)
+	}
+
+	resp := RestoreAllCertificatesResponse{Errors: map[string]error{}}
+	var mu sync.Mutex
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	var readErr error
+	for {
+		name, backup, err := readBackupRecord(br)
+		if err != nil {
+			readErr = err
+			break
+		}
+		if name == "" {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string, backup []byte) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			_, err := c.RestoreCertificateBackup(ctx, backup, nil)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				resp.Errors[name] = err
+				return
+			}
+			resp.Certificates = append(resp.Certificates, name)
+		}(name, backup)
+	}
+	wg.Wait()
+
+	sort.Strings(resp.Certificates)
+	return resp, readErr
+}
+
+// writeBackupRecord writes a name and a backup, each preceded by its big-endian uint32 length
+func writeBackupRecord(w io.Writer, name string, backup []byte) error {
+	for _, b := range [][]byte{[]byte(name), backup} {
+		if err := binary.Write(w, binary.BigEndian, uint32(len(b))); err != nil {
+			return err
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readBackupRecord(r io.Reader) (string, []byte, error) {
+	var fields [2][]byte
+	for i := range fields {
+		var n uint32
+		if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+			return "", nil, backupReadError(err)
+		}
+		if n > maxBackupRecordSize {
+			return "", nil, fmt.Errorf("%w: record of %d bytes", ErrInvalidBackupArchive, n)
+		}
+		fields[i] = make([]byte, n)
+		if _, err := io.ReadFull(r, fields[i]); err != nil {
+			return "", nil, backupReadError(err)
+		}
+	}
+	return string(fields[0]), fields[1], nil
+}
+
+func backupReadError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: truncated", ErrInvalidBackupArchive)
+	}
+	return err
+}
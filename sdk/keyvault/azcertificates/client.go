@@ -9,7 +9,10 @@ package azcertificates
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
@@ -23,14 +26,23 @@ import (
 // Don't use this type directly, use NewClient() instead.
 type Client struct {
 	genClient *generated.KeyVaultClient
+	pl        runtime.Pipeline
 	vaultURL  string
 }
 
 // ClientOptions are optional parameters for NewClient
 type ClientOptions struct {
 	azcore.ClientOptions
+
+	// APIVersion is the version of the Key Vault service API the client requests, one of "7.2", "7.3" and "7.4".
+	// The default is "7.4". Set it to use a service that doesn't support the latest version, such as Azure
+	// Stack Hub's. Options that require a later version than the client's return an error.
+	APIVersion string
 }
 
+// supportedAPIVersions are the service versions a client can request
+var supportedAPIVersions = []string{"7.2", "7.3", "7.4"}
+
 // converts ClientOptions to generated *generated.ConnectionOptions
 func (c *ClientOptions) toConnectionOptions() *policy.ClientOptions {
 	if c == nil {
@@ -49,8 +61,20 @@ func (c *ClientOptions) toConnectionOptions() *policy.ClientOptions {
 
 // NewClient creates an instance of a Client for a Key Vault Certificate URL.
 func NewClient(vaultURL string, credential azcore.TokenCredential, options *ClientOptions) (*Client, error) {
+	apiVersion := generated.DefaultAPIVersion
+	if options != nil && options.APIVersion != "" {
+		apiVersion = options.APIVersion
+		supported := false
+		for _, v := range supportedAPIVersions {
+			supported = supported || v == apiVersion
+		}
+		if !supported {
+			return nil, fmt.Errorf("unsupported API version %q; supported versions are %s", apiVersion, strings.Join(supportedAPIVersions, ", "))
+		}
+	}
 	genOptions := options.toConnectionOptions()
 
+	genOptions.PerCallPolicies = append(genOptions.PerCallPolicies, rawResponsePolicy{})
 	genOptions.PerRetryPolicies = append(
 		genOptions.PerRetryPolicies,
 		shared.NewKeyVaultChallengePolicy(credential),
@@ -59,11 +83,41 @@ func NewClient(vaultURL string, credential azcore.TokenCredential, options *Clie
 	pl := runtime.NewPipeline(generated.ModuleName, generated.ModuleVersion, runtime.PipelineOptions{}, genOptions)
 
 	return &Client{
-		genClient: generated.NewKeyVaultClient(pl),
+		genClient: generated.NewKeyVaultClientWithAPIVersion(pl, apiVersion),
+		pl:        pl,
 		vaultURL:  vaultURL,
 	}, nil
 }
 
+// requireAPIVersion returns an error naming feature when the client's API version is earlier than minVersion
+func (c *Client) requireAPIVersion(minVersion string, feature string) error {
+	// the supported versions have the same number of digits, so they compare as strings
+	if v := c.genClient.APIVersion(); v < minVersion {
+		return fmt.Errorf("%s requires API version %s or later; the client uses %s", feature, minVersion, v)
+	}
+	return nil
+}
+
+// rawResponseKey is the context key of the **http.Response rawResponsePolicy stores a response in
+type rawResponseKey struct{}
+
+// withRawResponse returns a context in which the client stores the final HTTP response of a request in resp.
+// Unlike runtime.WithCaptureResponse, it doesn't hide a capture the caller set up in ctx.
+func withRawResponse(ctx context.Context, resp **http.Response) context.Context {
+	return context.WithValue(ctx, rawResponseKey{}, resp)
+}
+
+// rawResponsePolicy stores responses for withRawResponse. It runs per call, so it sees the response of the last try.
+type rawResponsePolicy struct{}
+
+func (rawResponsePolicy) Do(req *policy.Request) (*http.Response, error) {
+	resp, err := req.Next()
+	if out, ok := req.Raw().Context().Value(rawResponseKey{}).(**http.Response); ok && err == nil {
+		*out = resp
+	}
+	return resp, err
+}
+
 // BeginCreateCertificateOptions contains optional parameters for Client.BeginCreateCertificate
 type BeginCreateCertificateOptions struct {
 	// Determines whether the object is enabled.
@@ -72,10 +126,29 @@ type BeginCreateCertificateOptions struct {
 	// Application specific metadata in the form of key-value pairs
 	Tags map[string]*string
 
-	// ResumeToken is a token for resuming long running operations from a previous poller
+	// ResumeToken is a token for resuming long running operations from a previous poller, or one returned by
+	// Operation.ResumeToken
 	ResumeToken string
+
+	// IdempotencyToken is a client-generated value, such as a UUID, that makes retrying BeginCreateCertificate safe.
+	// It's stored in the new certificate version's IdempotencyTokenTag tag. When the certificate's latest version
+	// already has this token, BeginCreateCertificate doesn't create another version and the returned poller tracks
+	// the existing version's create operation instead. Reuse the token when retrying a call whose outcome is unknown,
+	// for instance after a transient network failure.
+	IdempotencyToken *string
+
+	// ReturnCSROnPending makes the poller finish when the certificate is waiting to be signed by an issuer
+	// Key Vault doesn't integrate with, such as the issuer named "Unknown", instead of polling until the
+	// signed certificate is merged. The response's PendingOperation then has the operation and its CSR.
+	ReturnCSROnPending bool
+
+	// SkipPolicyValidation skips checking the policy with Policy.Validate before sending the request.
+	SkipPolicyValidation bool
 }
 
+// IdempotencyTokenTag is the name of the tag BeginCreateCertificate stores BeginCreateCertificateOptions.IdempotencyToken in.
+const IdempotencyTokenTag = "azcertificates-idempotency-token"
+
 func (b BeginCreateCertificateOptions) toGenerated() *generated.KeyVaultClientCreateCertificateOptions {
 	return &generated.KeyVaultClientCreateCertificateOptions{}
 }
@@ -83,6 +156,11 @@ func (b BeginCreateCertificateOptions) toGenerated() *generated.KeyVaultClientCr
 // CreateCertificateResponse contains response fields for Client.BeginCreateCertificate
 type CreateCertificateResponse struct {
 	CertificateWithPolicy
+
+	// PendingOperation is set instead of CertificateWithPolicy when BeginCreateCertificateOptions.ReturnCSROnPending
+	// is true and the certificate is waiting to be signed. Its CSR is the signing request to send to the issuer;
+	// call MergeCertificate with the signed certificate to complete the operation.
+	PendingOperation *Operation
 }
 
 // BeginCreateCertificate creates a new certificate resource, if a certificate with this name already exists, a new version is created. This operation requires the certificates/create permission.
@@ -90,6 +168,11 @@ func (c *Client) BeginCreateCertificate(ctx context.Context, certificateName str
 	if options == nil {
 		options = &BeginCreateCertificateOptions{}
 	}
+	if !options.SkipPolicyValidation && options.ResumeToken == "" {
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+	}
 
 	handler := beginCreateCertificateOperation{
 		poll: func(ctx context.Context, endpoint string) (*http.Response, error) {
@@ -104,16 +187,49 @@ func (c *Client) BeginCreateCertificate(ctx context.Context, certificateName str
 			if err != nil {
 				return CreateCertificateResponse{}, err
 			}
-			return CreateCertificateResponse(resp), nil
+			return CreateCertificateResponse{CertificateWithPolicy: resp.CertificateWithPolicy}, nil
 		},
+		returnCSR: options.ReturnCSROnPending,
 	}
 
 	if options.ResumeToken != "" {
+		rt, err := c.parseResumeToken(options.ResumeToken, resumeTokenOperationCreate, certificateName)
+		if err != nil {
+			return nil, err
+		}
+		if rt != nil {
+			handler.PollURL = rt.PollURL
+			return runtime.NewPoller(nil, c.genClient.Pipeline(), &runtime.NewPollerOptions[CreateCertificateResponse]{
+				Handler: &handler,
+			})
+		}
 		return runtime.NewPollerFromResumeToken(options.ResumeToken, c.genClient.Pipeline(), &runtime.NewPollerFromResumeTokenOptions[CreateCertificateResponse]{
 			Handler: &handler,
 		})
 	}
 
+	tags := options.Tags
+	if options.IdempotencyToken != nil {
+		opResp, status, err := c.getCreateOperationWithToken(ctx, certificateName, *options.IdempotencyToken)
+		if err != nil {
+			return nil, err
+		}
+		if opResp != nil {
+			// the certificate was created by a previous call with this token
+			handler.PollURL = opResp.Request.URL.String()
+			handler.Status = status
+			return runtime.NewPoller(opResp, c.genClient.Pipeline(), &runtime.NewPollerOptions[CreateCertificateResponse]{
+				Handler: &handler,
+			})
+		}
+
+		tags = make(map[string]*string, len(options.Tags)+1)
+		for k, v := range options.Tags {
+			tags[k] = v
+		}
+		tags[IdempotencyTokenTag] = options.IdempotencyToken
+	}
+
 	var rawResp *http.Response
 	ctx = runtime.WithCaptureResponse(ctx, &rawResp)
 	createResp, err := c.genClient.CreateCertificate(
@@ -122,7 +238,7 @@ func (c *Client) BeginCreateCertificate(ctx context.Context, certificateName str
 		certificateName,
 		generated.CertificateCreateParameters{
 			CertificatePolicy:     policy.toGeneratedCertificateCreateParameters(),
-			Tags:                  options.Tags,
+			Tags:                  tags,
 			CertificateAttributes: &generated.CertificateAttributes{Enabled: options.Enabled},
 		},
 		options.toGenerated(),
@@ -137,11 +253,45 @@ func (c *Client) BeginCreateCertificate(ctx context.Context, certificateName str
 	}
 	handler.PollURL = pollURL
 	handler.Status = *createResp.Status
+	handler.observe(certificateOperationFromGenerated(createResp.CertificateOperation))
 	return runtime.NewPoller(rawResp, c.genClient.Pipeline(), &runtime.NewPollerOptions[CreateCertificateResponse]{
 		Handler: &handler,
 	})
 }
 
+// getCreateOperationWithToken returns the response and status of the certificate's create operation when the
+// certificate's latest version has the idempotency token. It returns a nil response when there's no such operation.
+func (c *Client) getCreateOperationWithToken(ctx context.Context, certificateName string, token string) (*http.Response, string, error) {
+	var opResp *http.Response
+	op, err := c.genClient.GetCertificateOperation(runtime.WithCaptureResponse(ctx, &opResp), c.vaultURL, certificateName, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	if op.Status == nil {
+		return nil, "", errors.New("missing status")
+	}
+
+	latest, err := c.GetCertificate(ctx, certificateName, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	if latest.Properties == nil {
+		return nil, "", nil
+	}
+	if t := latest.Properties.Tags[IdempotencyTokenTag]; t == nil || *t != token {
+		return nil, "", nil
+	}
+	return opResp, *op.Status, nil
+}
+
 // GetCertificateOptions contains optional parameters for Client.GetCertificate
 type GetCertificateOptions struct {
 	Version string
@@ -150,6 +300,9 @@ type GetCertificateOptions struct {
 // GetCertificateResponse contains response fields for Client.GetCertificate
 type GetCertificateResponse struct {
 	CertificateWithPolicy
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // GetCertificate gets information about a specific certificate. This operation requires the certificates/get permission.
@@ -158,7 +311,8 @@ func (c *Client) GetCertificate(ctx context.Context, certificateName string, opt
 		options = &GetCertificateOptions{}
 	}
 
-	resp, err := c.genClient.GetCertificate(ctx, c.vaultURL, certificateName, options.Version, nil)
+	var rawResp *http.Response
+	resp, err := c.genClient.GetCertificate(withRawResponse(ctx, &rawResp), c.vaultURL, certificateName, options.Version, nil)
 	if err != nil {
 		return GetCertificateResponse{}, err
 	}
@@ -173,6 +327,7 @@ func (c *Client) GetCertificate(ctx context.Context, certificateName string, opt
 			SecretID:    resp.Sid,
 			Policy:      certificatePolicyFromGenerated(resp.Policy),
 		},
+		RawResponse: rawResp,
 	}, nil
 }
 
@@ -188,11 +343,15 @@ func (g *GetCertificateOperationOptions) toGenerated() *generated.KeyVaultClient
 // GetCertificateOperationResponse contains response field for Client.GetCertificateOperation
 type GetCertificateOperationResponse struct {
 	Operation
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // GetCertificateOperation gets the creation operation associated with a specified certificate. This operation requires the certificates/get permission.
 func (c *Client) GetCertificateOperation(ctx context.Context, certificateName string, options *GetCertificateOperationOptions) (GetCertificateOperationResponse, error) {
-	resp, err := c.genClient.GetCertificateOperation(ctx, c.vaultURL, certificateName, options.toGenerated())
+	var rawResp *http.Response
+	resp, err := c.genClient.GetCertificateOperation(withRawResponse(ctx, &rawResp), c.vaultURL, certificateName, options.toGenerated())
 	if err != nil {
 		return GetCertificateOperationResponse{}, err
 	}
@@ -209,12 +368,14 @@ func (c *Client) GetCertificateOperation(ctx context.Context, certificateName st
 			Target:                resp.Target,
 			ID:                    resp.ID,
 		},
+		RawResponse: rawResp,
 	}, nil
 }
 
 // BeginDeleteCertificateOptions contains optional parameters for Client.BeginDeleteCertificate
 type BeginDeleteCertificateOptions struct {
-	// ResumeToken is a string to begin polling from a previous operation
+	// ResumeToken is a string to begin polling from a previous operation, such as one returned by
+	// DeleteCertificateResumeToken
 	ResumeToken string
 }
 
@@ -266,6 +427,15 @@ func (c *Client) BeginDeleteCertificate(ctx context.Context, certificateName str
 	}
 
 	if options.ResumeToken != "" {
+		rt, err := c.parseResumeToken(options.ResumeToken, resumeTokenOperationDelete, certificateName)
+		if err != nil {
+			return nil, err
+		}
+		if rt != nil {
+			return runtime.NewPoller(nil, c.genClient.Pipeline(), &runtime.NewPollerOptions[DeleteCertificateResponse]{
+				Handler: &handler,
+			})
+		}
 		return runtime.NewPollerFromResumeToken(options.ResumeToken, c.genClient.Pipeline(), &runtime.NewPollerFromResumeTokenOptions[DeleteCertificateResponse]{
 			Handler: &handler,
 		})
@@ -293,18 +463,20 @@ func (p *PurgeDeletedCertificateOptions) toGenerated() *generated.KeyVaultClient
 
 // PurgeDeletedCertificateResponse contains response fields for Client.PurgeDeletedCertificate
 type PurgeDeletedCertificateResponse struct {
-	// placeholder for future reponse fields
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // PurgeDeletedCertificate operation performs an irreversible deletion of the specified certificate, without possibility for recovery. The operation
 // is not available if the recovery level does not specify 'Purgeable'. This operation requires the certificate/purge permission.
 func (c *Client) PurgeDeletedCertificate(ctx context.Context, certificateName string, options *PurgeDeletedCertificateOptions) (PurgeDeletedCertificateResponse, error) {
-	_, err := c.genClient.PurgeDeletedCertificate(ctx, c.vaultURL, certificateName, options.toGenerated())
+	var rawResp *http.Response
+	_, err := c.genClient.PurgeDeletedCertificate(withRawResponse(ctx, &rawResp), c.vaultURL, certificateName, options.toGenerated())
 	if err != nil {
 		return PurgeDeletedCertificateResponse{}, err
 	}
 
-	return PurgeDeletedCertificateResponse{}, nil
+	return PurgeDeletedCertificateResponse{RawResponse: rawResp}, nil
 }
 
 // GetDeletedCertificateOptions contains optional parameters for Client.GetDeletedCertificate
@@ -319,12 +491,16 @@ func (g *GetDeletedCertificateOptions) toGenerated() *generated.KeyVaultClientGe
 // GetDeletedCertificateResponse contains response field for Client.GetDeletedCertificate
 type GetDeletedCertificateResponse struct {
 	DeletedCertificate
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // GetDeletedCertificate retrieves the deleted certificate information plus its attributes, such as retention interval, scheduled permanent deletion
 // and the current deletion recovery level. This operation requires the certificates/get permission.
 func (c *Client) GetDeletedCertificate(ctx context.Context, certificateName string, options *GetDeletedCertificateOptions) (GetDeletedCertificateResponse, error) {
-	resp, err := c.genClient.GetDeletedCertificate(ctx, c.vaultURL, certificateName, options.toGenerated())
+	var rawResp *http.Response
+	resp, err := c.genClient.GetDeletedCertificate(withRawResponse(ctx, &rawResp), c.vaultURL, certificateName, options.toGenerated())
 	if err != nil {
 		return GetDeletedCertificateResponse{}, err
 	}
@@ -344,6 +520,7 @@ func (c *Client) GetDeletedCertificate(ctx context.Context, certificateName stri
 			Policy:             certificatePolicyFromGenerated(resp.Policy),
 			SecretID:           resp.Sid,
 		},
+		RawResponse: rawResp,
 	}, nil
 }
 
@@ -360,18 +537,23 @@ func (b *BackupCertificateOptions) toGenerated() *generated.KeyVaultClientBackup
 type BackupCertificateResponse struct {
 	// READ-ONLY; The backup blob containing the backed up certificate.
 	Value []byte
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // BackupCertificate requests that a backup of the specified certificate be downloaded to the client. All versions of the certificate will be downloaded.
 // This operation requires the certificates/backup permission.
 func (c *Client) BackupCertificate(ctx context.Context, certificateName string, options *BackupCertificateOptions) (BackupCertificateResponse, error) {
-	resp, err := c.genClient.BackupCertificate(ctx, c.vaultURL, certificateName, options.toGenerated())
+	var rawResp *http.Response
+	resp, err := c.genClient.BackupCertificate(withRawResponse(ctx, &rawResp), c.vaultURL, certificateName, options.toGenerated())
 	if err != nil {
 		return BackupCertificateResponse{}, err
 	}
 
 	return BackupCertificateResponse{
-		Value: resp.Value,
+		Value:       resp.Value,
+		RawResponse: rawResp,
 	}, nil
 }
 
@@ -386,6 +568,10 @@ type ImportCertificateOptions struct {
 	// If the private key in base64EncodedCertificate is encrypted, the password used for encryption.
 	Password *string
 
+	// PreserveCertOrder specifies whether the certificate chain keeps the order it has in the imported
+	// certificate. By default, Key Vault puts the leaf certificate first.
+	PreserveCertOrder *bool
+
 	// Application specific metadata in the form of key-value pairs
 	Tags map[string]*string
 }
@@ -393,6 +579,9 @@ type ImportCertificateOptions struct {
 // ImportCertificateResponse contains response fields for Client.ImportCertificate
 type ImportCertificateResponse struct {
 	CertificateWithPolicy
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // ImportCertificate imports an existing valid certificate, containing a private key, into Azure Key Vault. This operation requires the
@@ -402,8 +591,14 @@ func (c *Client) ImportCertificate(ctx context.Context, certificateName string,
 	if options == nil {
 		options = &ImportCertificateOptions{}
 	}
+	if options.PreserveCertOrder != nil {
+		if err := c.requireAPIVersion("7.4", "PreserveCertOrder"); err != nil {
+			return ImportCertificateResponse{}, err
+		}
+	}
+	var rawResp *http.Response
 	resp, err := c.genClient.ImportCertificate(
-		ctx,
+		withRawResponse(ctx, &rawResp),
 		c.vaultURL,
 		certificateName,
 		generated.CertificateImportParameters{
@@ -413,6 +608,7 @@ func (c *Client) ImportCertificate(ctx context.Context, certificateName string,
 			},
 			CertificatePolicy: options.CertificatePolicy.toGeneratedCertificateCreateParameters(),
 			Password:          options.Password,
+			PreserveCertOrder: options.PreserveCertOrder,
 			Tags:              options.Tags,
 		},
 		&generated.KeyVaultClientImportCertificateOptions{},
@@ -431,12 +627,105 @@ func (c *Client) ImportCertificate(ctx context.Context, certificateName string,
 			SecretID:    resp.Sid,
 			Policy:      certificatePolicyFromGenerated(resp.Policy),
 		},
+		RawResponse: rawResp,
 	}, nil
 }
 
+// BeginImportCertificateOptions contains optional parameters for Client.BeginImportCertificate
+type BeginImportCertificateOptions struct {
+	// The management policy for the certificate.
+	CertificatePolicy *Policy
+
+	// Determines whether the object is enabled.
+	Enabled *bool
+
+	// If the private key in base64EncodedCertificate is encrypted, the password used for encryption.
+	Password *string
+
+	// PreserveCertOrder specifies whether the certificate chain keeps the order it has in the imported
+	// certificate. By default, Key Vault puts the leaf certificate first.
+	PreserveCertOrder *bool
+
+	// ResumeToken is a string to begin polling from a previous operation
+	ResumeToken string
+
+	// Application specific metadata in the form of key-value pairs
+	Tags map[string]*string
+}
+
+// BeginImportCertificate imports a certificate like ImportCertificate, and returns a poller that waits for the certificate
+// operation the import starts, if any, to finish. An import whose policy makes Key Vault issue a new version of the
+// certificate, for example with an issuer, can leave an operation in progress, in which case the certificate
+// ImportCertificate returns isn't the final one. The poller's result is the certificate once the operation completes,
+// or the imported certificate when there's no operation in progress. This operation requires the certificates/import
+// and certificates/get permissions.
+func (c *Client) BeginImportCertificate(ctx context.Context, certificateName string, certificate []byte, options *BeginImportCertificateOptions) (*runtime.Poller[ImportCertificateResponse], error) {
+	if options == nil {
+		options = &BeginImportCertificateOptions{}
+	}
+
+	handler := beginImportCertificateOperation{
+		poll: func(ctx context.Context, endpoint string) (*http.Response, error) {
+			req, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+			if err != nil {
+				return nil, err
+			}
+			return c.genClient.Pipeline().Do(req)
+		},
+		result: func(ctx context.Context) (ImportCertificateResponse, error) {
+			resp, err := c.GetCertificate(ctx, certificateName, nil)
+			if err != nil {
+				return ImportCertificateResponse{}, err
+			}
+			return ImportCertificateResponse{CertificateWithPolicy: resp.CertificateWithPolicy}, nil
+		},
+	}
+
+	if options.ResumeToken != "" {
+		return runtime.NewPollerFromResumeToken(options.ResumeToken, c.genClient.Pipeline(), &runtime.NewPollerFromResumeTokenOptions[ImportCertificateResponse]{
+			Handler: &handler,
+		})
+	}
+
+	var rawResp *http.Response
+	imported, err := c.ImportCertificate(runtime.WithCaptureResponse(ctx, &rawResp), certificateName, certificate, &ImportCertificateOptions{
+		CertificatePolicy: options.CertificatePolicy,
+		Enabled:           options.Enabled,
+		Password:          options.Password,
+		PreserveCertOrder: options.PreserveCertOrder,
+		Tags:              options.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	handler.imported = &imported
+	handler.Status = "completed"
+
+	var opResp *http.Response
+	op, err := c.genClient.GetCertificateOperation(runtime.WithCaptureResponse(ctx, &opResp), c.vaultURL, certificateName, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if !errors.As(err, &respErr) || respErr.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+	} else if op.Status != nil && *op.Status == "inProgress" {
+		handler.PollURL = opResp.Request.URL.String()
+		handler.Status = *op.Status
+		rawResp = opResp
+	}
+
+	return runtime.NewPoller(rawResp, c.genClient.Pipeline(), &runtime.NewPollerOptions[ImportCertificateResponse]{
+		Handler: &handler,
+	})
+}
+
 // ListPropertiesOfCertificatesOptions contains optional parameters for Client.ListCertificates
 type ListPropertiesOfCertificatesOptions struct {
-	// placeholder for future optional parameters.
+	// IncludePending specifies whether to include certificates that have no issued version yet.
+	IncludePending *bool
+
+	// MaxResults is the maximum number of items in a page. The service returns up to 25 by default.
+	MaxResults *int32
 }
 
 // ListPropertiesOfCertificatesResponse contains response fields for ListCertificatesPager.NextPage
@@ -446,6 +735,9 @@ type ListPropertiesOfCertificatesResponse struct {
 
 	// NextLink is a link to the next page of results
 	NextLink *string
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // convert internal Response to ListCertificatesPage
@@ -456,6 +748,7 @@ func listCertsPageFromGenerated(i generated.KeyVaultClientGetCertificatesRespons
 		vals = append(vals, &CertificateItem{
 			Properties: propertiesFromGenerated(v.Attributes, v.Tags, v.ID, v.X509Thumbprint),
 			ID:         v.ID,
+			Status:     certificateStatus(v.X509Thumbprint),
 		})
 	}
 
@@ -470,24 +763,34 @@ func listCertsPageFromGenerated(i generated.KeyVaultClientGetCertificatesRespons
 // base certificate identifier, attributes, and tags are provided in the response. Individual versions of a
 // certificate are not listed in the response. This operation requires the certificates/list permission.
 func (c *Client) NewListPropertiesOfCertificatesPager(options *ListPropertiesOfCertificatesOptions) *runtime.Pager[ListPropertiesOfCertificatesResponse] {
-	pager := c.genClient.NewGetCertificatesPager(c.vaultURL, nil)
+	if options == nil {
+		options = &ListPropertiesOfCertificatesOptions{}
+	}
+	pager := c.genClient.NewGetCertificatesPager(c.vaultURL, &generated.KeyVaultClientGetCertificatesOptions{
+		IncludePending: options.IncludePending,
+		Maxresults:     options.MaxResults,
+	})
 	return runtime.NewPager(runtime.PagingHandler[ListPropertiesOfCertificatesResponse]{
 		More: func(page ListPropertiesOfCertificatesResponse) bool {
 			return pager.More()
 		},
 		Fetcher: func(ctx context.Context, cur *ListPropertiesOfCertificatesResponse) (ListPropertiesOfCertificatesResponse, error) {
-			page, err := pager.NextPage(ctx)
+			var rawResp *http.Response
+			page, err := pager.NextPage(withRawResponse(ctx, &rawResp))
 			if err != nil {
 				return ListPropertiesOfCertificatesResponse{}, err
 			}
-			return listCertsPageFromGenerated(page), nil
+			resp := listCertsPageFromGenerated(page)
+			resp.RawResponse = rawResp
+			return resp, nil
 		},
 	})
 }
 
 // ListPropertiesOfCertificateVersionsOptions contains optional parameters for Client.ListCertificateVersions
 type ListPropertiesOfCertificateVersionsOptions struct {
-	// placeholder for future optional parameters.
+	// MaxResults is the maximum number of items in a page. The service returns up to 25 by default.
+	MaxResults *int32
 }
 
 // ListPropertiesOfCertificateVersionsResponse contains response fields for ListCertificateVersionsPager.NextPage
@@ -497,6 +800,9 @@ type ListPropertiesOfCertificateVersionsResponse struct {
 
 	// NextLink is a link to the next page of results to fetch
 	NextLink *string
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // create ListCertificatesPage from generated pager
@@ -519,21 +825,110 @@ func listCertificateVersionsPageFromGenerated(i generated.KeyVaultClientGetCerti
 // attributes are provided in the response. No values are returned for the certificates. This operation
 // requires the certificates/list permission.
 func (c *Client) NewListPropertiesOfCertificateVersionsPager(certificateName string, options *ListPropertiesOfCertificateVersionsOptions) *runtime.Pager[ListPropertiesOfCertificateVersionsResponse] {
-	pager := c.genClient.NewGetCertificateVersionsPager(c.vaultURL, certificateName, nil)
+	if options == nil {
+		options = &ListPropertiesOfCertificateVersionsOptions{}
+	}
+	pager := c.genClient.NewGetCertificateVersionsPager(c.vaultURL, certificateName, &generated.KeyVaultClientGetCertificateVersionsOptions{Maxresults: options.MaxResults})
 	return runtime.NewPager(runtime.PagingHandler[ListPropertiesOfCertificateVersionsResponse]{
 		More: func(page ListPropertiesOfCertificateVersionsResponse) bool {
 			return pager.More()
 		},
 		Fetcher: func(ctx context.Context, cur *ListPropertiesOfCertificateVersionsResponse) (ListPropertiesOfCertificateVersionsResponse, error) {
-			page, err := pager.NextPage(ctx)
+			var rawResp *http.Response
+			page, err := pager.NextPage(withRawResponse(ctx, &rawResp))
 			if err != nil {
 				return ListPropertiesOfCertificateVersionsResponse{}, err
 			}
-			return listCertificateVersionsPageFromGenerated(page), nil
+			resp := listCertificateVersionsPageFromGenerated(page)
+			resp.RawResponse = rawResp
+			return resp, nil
 		},
 	})
 }
 
+// GetAllCertificateVersionsOptions contains optional parameters for Client.GetAllCertificateVersions
+type GetAllCertificateVersionsOptions struct {
+	// MaxConcurrency is the maximum number of versions retrieved at once. The default value is 4.
+	MaxConcurrency int
+}
+
+// GetAllCertificateVersionsResponse contains response fields for Client.GetAllCertificateVersions
+type GetAllCertificateVersionsResponse struct {
+	// Certificates are the certificate's versions, in the order the service lists them.
+	Certificates []CertificateWithPolicy
+}
+
+// GetAllCertificateVersions gets every version of a certificate, including its CER contents and policy. It lists
+// the versions with NewListPropertiesOfCertificateVersionsPager and gets each one with GetCertificate, getting up to
+// GetAllCertificateVersionsOptions.MaxConcurrency versions at once. It returns the first error, after which it stops
+// getting versions. This operation requires the certificates/list and certificates/get permissions. Pass nil for
+// options to accept default values.
+func (c *Client) GetAllCertificateVersions(ctx context.Context, certificateName string, options *GetAllCertificateVersionsOptions) (GetAllCertificateVersionsResponse, error) {
+	if options == nil {
+		options = &GetAllCertificateVersionsOptions{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultConcurrency
+	}
+
+	var versions []string
+	pager := c.NewListPropertiesOfCertificateVersionsPager(certificateName, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return GetAllCertificateVersionsResponse{}, err
+		}
+		for _, item := range page.Certificates {
+			if item == nil {
+				continue
+			}
+			if _, _, version := shared.ParseID(item.ID); version != nil {
+				versions = append(versions, *version)
+			}
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	certs := make([]CertificateWithPolicy, len(versions))
+	var once sync.Once
+	var firstErr error
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i, version := range versions {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, version string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			resp, err := c.GetCertificate(ctx, certificateName, &GetCertificateOptions{Version: version})
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			certs[i] = resp.CertificateWithPolicy
+		}(i, version)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return GetAllCertificateVersionsResponse{}, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return GetAllCertificateVersionsResponse{}, err
+	}
+	return GetAllCertificateVersionsResponse{Certificates: certs}, nil
+}
+
 // CreateIssuerOptions contains optional parameters for Client.CreateIssuer
 type CreateIssuerOptions struct {
 	// Determines whether the issuer is enabled.
@@ -556,6 +951,9 @@ func (c *CreateIssuerOptions) toGenerated() *generated.KeyVaultClientSetCertific
 // CreateIssuerResponse contains response fields for Client.CreateIssuer
 type CreateIssuerResponse struct {
 	Issuer
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // CreateIssuer adds or updates the specified certificate issuer. This operation requires the certificates/setissuers permission.
@@ -585,8 +983,9 @@ func (c *Client) CreateIssuer(ctx context.Context, issuerName string, provider s
 		}
 	}
 
+	var rawResp *http.Response
 	resp, err := c.genClient.SetCertificateIssuer(
-		ctx,
+		withRawResponse(ctx, &rawResp),
 		c.vaultURL,
 		issuerName,
 		generated.CertificateIssuerSetParameters{
@@ -633,6 +1032,7 @@ func (c *Client) CreateIssuer(ctx context.Context, issuerName string, provider s
 
 	_, _, name := shared.ParseID(resp.ID)
 	cr.Issuer.Name = name
+	cr.RawResponse = rawResp
 	return cr, nil
 }
 
@@ -648,12 +1048,16 @@ func (g *GetIssuerOptions) toGenerated() *generated.KeyVaultClientGetCertificate
 // GetIssuerResponse contains response fields for ClientGetIssuer
 type GetIssuerResponse struct {
 	Issuer
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // GetIssuer returns the specified certificate issuer resources in the specified key vault. This operation
 // requires the certificates/manageissuers/getissuers permission.
 func (c *Client) GetIssuer(ctx context.Context, issuerName string, options *GetIssuerOptions) (GetIssuerResponse, error) {
-	resp, err := c.genClient.GetCertificateIssuer(ctx, c.vaultURL, issuerName, options.toGenerated())
+	var rawResp *http.Response
+	resp, err := c.genClient.GetCertificateIssuer(withRawResponse(ctx, &rawResp), c.vaultURL, issuerName, options.toGenerated())
 	if err != nil {
 		return GetIssuerResponse{}, err
 	}
@@ -689,12 +1093,14 @@ func (c *Client) GetIssuer(ctx context.Context, issuerName string, options *GetI
 
 	_, _, name := shared.ParseID(resp.ID)
 	g.Issuer.Name = name
+	g.RawResponse = rawResp
 	return g, nil
 }
 
 // ListPropertiesOfIssuersOptions contains optional parameters for Client.ListIssuers
 type ListPropertiesOfIssuersOptions struct {
-	// placeholder for future optional parameters
+	// MaxResults is the maximum number of items in a page. The service returns up to 25 by default.
+	MaxResults *int32
 }
 
 // ListPropertiesOfIssuersResponse contains response fields for ListPropertiesOfIssuersPager.NextPage
@@ -704,6 +1110,9 @@ type ListPropertiesOfIssuersResponse struct {
 
 	// NextLink is the next link of pages to fetch
 	NextLink *string
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // convert internal Response to ListPropertiesOfIssuersPage
@@ -720,17 +1129,23 @@ func listIssuersPageFromGenerated(i generated.KeyVaultClientGetCertificateIssuer
 // NewListPropertiesOfIssuersPager returns a pager that can be used to get the set of certificate issuer resources in the specified key vault. This operation
 // requires the certificates/manageissuers/getissuers permission.
 func (c *Client) NewListPropertiesOfIssuersPager(options *ListPropertiesOfIssuersOptions) *runtime.Pager[ListPropertiesOfIssuersResponse] {
-	pager := c.genClient.NewGetCertificateIssuersPager(c.vaultURL, nil)
+	if options == nil {
+		options = &ListPropertiesOfIssuersOptions{}
+	}
+	pager := c.genClient.NewGetCertificateIssuersPager(c.vaultURL, &generated.KeyVaultClientGetCertificateIssuersOptions{Maxresults: options.MaxResults})
 	return runtime.NewPager(runtime.PagingHandler[ListPropertiesOfIssuersResponse]{
 		More: func(page ListPropertiesOfIssuersResponse) bool {
 			return pager.More()
 		},
 		Fetcher: func(ctx context.Context, cur *ListPropertiesOfIssuersResponse) (ListPropertiesOfIssuersResponse, error) {
-			page, err := pager.NextPage(ctx)
+			var rawResp *http.Response
+			page, err := pager.NextPage(withRawResponse(ctx, &rawResp))
 			if err != nil {
 				return ListPropertiesOfIssuersResponse{}, err
 			}
-			return listIssuersPageFromGenerated(page), nil
+			resp := listIssuersPageFromGenerated(page)
+			resp.RawResponse = rawResp
+			return resp, nil
 		},
 	})
 }
@@ -747,11 +1162,15 @@ func (d *DeleteIssuerOptions) toGenerated() *generated.KeyVaultClientDeleteCerti
 // DeleteIssuerResponse contains response fields for Client.DeleteIssuer
 type DeleteIssuerResponse struct {
 	Issuer
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // DeleteIssuer permanently removes the specified certificate issuer from the vault. This operation requires the certificates/manageissuers/deleteissuers permission.
 func (c *Client) DeleteIssuer(ctx context.Context, issuerName string, options *DeleteIssuerOptions) (DeleteIssuerResponse, error) {
-	resp, err := c.genClient.DeleteCertificateIssuer(ctx, c.vaultURL, issuerName, options.toGenerated())
+	var rawResp *http.Response
+	resp, err := c.genClient.DeleteCertificateIssuer(withRawResponse(ctx, &rawResp), c.vaultURL, issuerName, options.toGenerated())
 	if err != nil {
 		return DeleteIssuerResponse{}, err
 	}
@@ -787,6 +1206,7 @@ func (c *Client) DeleteIssuer(ctx context.Context, issuerName string, options *D
 
 	_, _, name := shared.ParseID(resp.ID)
 	d.Issuer.Name = name
+	d.RawResponse = rawResp
 	return d, nil
 }
 
@@ -837,13 +1257,17 @@ func (i *Issuer) toUpdateParameters() generated.CertificateIssuerUpdateParameter
 // UpdateIssuerResponse contains response fields for Client.UpdateIssuer
 type UpdateIssuerResponse struct {
 	Issuer
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // UpdateIssuer performs an update on the specified certificate issuer entity. This operation requires
 // the certificates/setissuers permission.
 func (c *Client) UpdateIssuer(ctx context.Context, certificateIssuer Issuer, options *UpdateIssuerOptions) (UpdateIssuerResponse, error) {
+	var rawResp *http.Response
 	resp, err := c.genClient.UpdateCertificateIssuer(
-		ctx,
+		withRawResponse(ctx, &rawResp),
 		c.vaultURL,
 		*certificateIssuer.Name,
 		certificateIssuer.toUpdateParameters(),
@@ -883,6 +1307,7 @@ func (c *Client) UpdateIssuer(ctx context.Context, certificateIssuer Issuer, opt
 	}
 	_, _, name := shared.ParseID(resp.ID)
 	u.Issuer.Name = name
+	u.RawResponse = rawResp
 	return u, nil
 }
 
@@ -898,13 +1323,17 @@ func (s *SetContactsOptions) toGenerated() *generated.KeyVaultClientSetCertifica
 // SetContactsResponse contains response fields for Client.CreateContacts
 type SetContactsResponse struct {
 	Contacts
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // SetContacts sets the certificate contacts for the specified key vault. This operation requires the certificates/managecontacts permission.
 func (c *Client) SetContacts(ctx context.Context, contacts []*Contact, options *SetContactsOptions) (SetContactsResponse, error) {
 	contactList := Contacts{ContactList: contacts}
+	var rawResp *http.Response
 	resp, err := c.genClient.SetCertificateContacts(
-		ctx,
+		withRawResponse(ctx, &rawResp),
 		c.vaultURL,
 		contactList.toGenerated(),
 		options.toGenerated(),
@@ -919,6 +1348,7 @@ func (c *Client) SetContacts(ctx context.Context, contacts []*Contact, options *
 			ID:          resp.ID,
 			ContactList: contactListFromGenerated(resp.ContactList),
 		},
+		RawResponse: rawResp,
 	}, nil
 }
 
@@ -934,12 +1364,16 @@ func (g *GetContactsOptions) toGenerated() *generated.KeyVaultClientGetCertifica
 // GetContactsResponse contains response fields for Client.GetContacts
 type GetContactsResponse struct {
 	Contacts
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // GetContacts returns the set of certificate contact resources in the specified key vault. This operation
 // requires the certificates/managecontacts permission.
 func (c *Client) GetContacts(ctx context.Context, options *GetContactsOptions) (GetContactsResponse, error) {
-	resp, err := c.genClient.GetCertificateContacts(ctx, c.vaultURL, options.toGenerated())
+	var rawResp *http.Response
+	resp, err := c.genClient.GetCertificateContacts(withRawResponse(ctx, &rawResp), c.vaultURL, options.toGenerated())
 	if err != nil {
 		return GetContactsResponse{}, err
 	}
@@ -949,6 +1383,7 @@ func (c *Client) GetContacts(ctx context.Context, options *GetContactsOptions) (
 			ID:          resp.ID,
 			ContactList: contactListFromGenerated(resp.ContactList),
 		},
+		RawResponse: rawResp,
 	}, nil
 }
 
@@ -964,11 +1399,15 @@ func (d *DeleteContactsOptions) toGenerated() *generated.KeyVaultClientDeleteCer
 // DeleteContactsResponse contains response field for Client.DeleteContacts
 type DeleteContactsResponse struct {
 	Contacts
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // DeleteContacts deletes the certificate contacts for a specified key vault certificate. This operation requires the certificates/managecontacts permission.
 func (c *Client) DeleteContacts(ctx context.Context, options *DeleteContactsOptions) (DeleteContactsResponse, error) {
-	resp, err := c.genClient.DeleteCertificateContacts(ctx, c.vaultURL, options.toGenerated())
+	var rawResp *http.Response
+	resp, err := c.genClient.DeleteCertificateContacts(withRawResponse(ctx, &rawResp), c.vaultURL, options.toGenerated())
 	if err != nil {
 		return DeleteContactsResponse{}, err
 	}
@@ -978,12 +1417,14 @@ func (c *Client) DeleteContacts(ctx context.Context, options *DeleteContactsOpti
 			ContactList: contactListFromGenerated(resp.ContactList),
 			ID:          resp.ID,
 		},
+		RawResponse: rawResp,
 	}, nil
 }
 
 // UpdateCertificatePolicyOptions contains optional parameters for Client.UpdateCertificatePolicy
 type UpdateCertificatePolicyOptions struct {
-	// placeholder for future optional parameters.
+	// SkipPolicyValidation skips checking the policy with Policy.Validate before sending the request.
+	SkipPolicyValidation bool
 }
 
 func (u *UpdateCertificatePolicyOptions) toGenerated() *generated.KeyVaultClientUpdateCertificatePolicyOptions {
@@ -993,12 +1434,21 @@ func (u *UpdateCertificatePolicyOptions) toGenerated() *generated.KeyVaultClient
 // UpdateCertificatePolicyResponse contains response fields for Client.UpdateCertificatePolicy
 type UpdateCertificatePolicyResponse struct {
 	Policy
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // UpdateCertificatePolicy sets specified members in the certificate policy, leave others as null. This operation requires the certificates/update permission.
 func (c *Client) UpdateCertificatePolicy(ctx context.Context, certificateName string, policy Policy, options *UpdateCertificatePolicyOptions) (UpdateCertificatePolicyResponse, error) {
+	if options == nil || !options.SkipPolicyValidation {
+		if err := policy.Validate(); err != nil {
+			return UpdateCertificatePolicyResponse{}, err
+		}
+	}
+	var rawResp *http.Response
 	resp, err := c.genClient.UpdateCertificatePolicy(
-		ctx,
+		withRawResponse(ctx, &rawResp),
 		c.vaultURL,
 		certificateName,
 		*policy.toGeneratedCertificateCreateParameters(),
@@ -1010,7 +1460,8 @@ func (c *Client) UpdateCertificatePolicy(ctx context.Context, certificateName st
 	}
 
 	return UpdateCertificatePolicyResponse{
-		Policy: *certificatePolicyFromGenerated(&resp.CertificatePolicy),
+		Policy:      *certificatePolicyFromGenerated(&resp.CertificatePolicy),
+		RawResponse: rawResp,
 	}, nil
 }
 
@@ -1026,12 +1477,16 @@ func (g *GetCertificatePolicyOptions) toGenerated() *generated.KeyVaultClientGet
 // GetCertificatePolicyResponse contains response fields for Client.GetCertificatePolicy
 type GetCertificatePolicyResponse struct {
 	Policy
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // GetCertificatePolicy returns the specified certificate policy resources in the specified key vault. This operation requires the certificates/get permission.
 func (c *Client) GetCertificatePolicy(ctx context.Context, certificateName string, options *GetCertificatePolicyOptions) (GetCertificatePolicyResponse, error) {
+	var rawResp *http.Response
 	resp, err := c.genClient.GetCertificatePolicy(
-		ctx,
+		withRawResponse(ctx, &rawResp),
 		c.vaultURL,
 		certificateName,
 		options.toGenerated(),
@@ -1041,7 +1496,8 @@ func (c *Client) GetCertificatePolicy(ctx context.Context, certificateName strin
 	}
 
 	return GetCertificatePolicyResponse{
-		Policy: *certificatePolicyFromGenerated(&resp.CertificatePolicy),
+		Policy:      *certificatePolicyFromGenerated(&resp.CertificatePolicy),
+		RawResponse: rawResp,
 	}, nil
 }
 
@@ -1054,6 +1510,9 @@ type UpdateCertificatePropertiesOptions struct {
 // UpdateCertificatePropertiesResponse contains response fields for Client.UpdateCertificateProperties
 type UpdateCertificatePropertiesResponse struct {
 	Certificate
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // UpdateCertificateProperties applies the specified update on the given certificate; the only elements updated are the certificate's
@@ -1066,8 +1525,9 @@ func (c *Client) UpdateCertificateProperties(ctx context.Context, properties Pro
 	if properties.Version != nil {
 		version = *properties.Version
 	}
+	var rawResp *http.Response
 	resp, err := c.genClient.UpdateCertificate(
-		ctx,
+		withRawResponse(ctx, &rawResp),
 		c.vaultURL,
 		name,
 		version,
@@ -1082,6 +1542,7 @@ func (c *Client) UpdateCertificateProperties(ctx context.Context, properties Pro
 	}
 	return UpdateCertificatePropertiesResponse{
 		Certificate: certificateFromGenerated(&resp.CertificateBundle),
+		RawResponse: rawResp,
 	}, nil
 }
 
@@ -1098,6 +1559,9 @@ func (m *MergeCertificateOptions) toGenerated() *generated.KeyVaultClientMergeCe
 // MergeCertificateResponse contains response fields for Client.MergeCertificate
 type MergeCertificateResponse struct {
 	CertificateWithPolicy
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // MergeCertificate operation performs the merging of a certificate or certificate chain with a key pair currently available in the service. This operation requires the certificates/create permission.
@@ -1109,8 +1573,9 @@ func (c *Client) MergeCertificate(ctx context.Context, certificateName string, c
 	if options.Properties != nil && options.Properties.Tags != nil {
 		tags = options.Properties.Tags
 	}
+	var rawResp *http.Response
 	resp, err := c.genClient.MergeCertificate(
-		ctx, c.vaultURL,
+		withRawResponse(ctx, &rawResp), c.vaultURL,
 		certificateName,
 		generated.CertificateMergeParameters{
 			X509Certificates:      certificates,
@@ -1133,6 +1598,7 @@ func (c *Client) MergeCertificate(ctx context.Context, certificateName string, c
 			SecretID:    resp.Sid,
 			Policy:      certificatePolicyFromGenerated(resp.Policy),
 		},
+		RawResponse: rawResp,
 	}, nil
 }
 
@@ -1148,14 +1614,18 @@ func (r *RestoreCertificateBackupOptions) toGenerated() *generated.KeyVaultClien
 // RestoreCertificateBackupResponse contains response fields for Client.RestoreCertificateBackup
 type RestoreCertificateBackupResponse struct {
 	CertificateWithPolicy
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // RestoreCertificateBackup performs the reversal of the Delete operation. The operation is applicable in vaults
 // enabled for soft-delete, and must be issued during the retention interval (available in the deleted certificate's attributes).
 // This operation requires the certificates/recover permission.
 func (c *Client) RestoreCertificateBackup(ctx context.Context, certificateBackup []byte, options *RestoreCertificateBackupOptions) (RestoreCertificateBackupResponse, error) {
+	var rawResp *http.Response
 	resp, err := c.genClient.RestoreCertificate(
-		ctx,
+		withRawResponse(ctx, &rawResp),
 		c.vaultURL,
 		generated.CertificateRestoreParameters{CertificateBundleBackup: certificateBackup},
 		options.toGenerated(),
@@ -1174,12 +1644,14 @@ func (c *Client) RestoreCertificateBackup(ctx context.Context, certificateBackup
 			SecretID:    resp.Sid,
 			Policy:      certificatePolicyFromGenerated(resp.Policy),
 		},
+		RawResponse: rawResp,
 	}, nil
 }
 
 // BeginRecoverDeletedCertificateOptions contains optional parameters for Client.BeginRecoverDeletedCertificate
 type BeginRecoverDeletedCertificateOptions struct {
-	// ResumeToken is a token for resuming long running operations from a previous call.
+	// ResumeToken is a token for resuming long running operations from a previous call, such as one returned by
+	// RecoverDeletedCertificateResumeToken.
 	ResumeToken string
 }
 
@@ -1217,6 +1689,15 @@ func (c *Client) BeginRecoverDeletedCertificate(ctx context.Context, certificate
 	}
 
 	if options.ResumeToken != "" {
+		rt, err := c.parseResumeToken(options.ResumeToken, resumeTokenOperationRecover, certificateName)
+		if err != nil {
+			return nil, err
+		}
+		if rt != nil {
+			return runtime.NewPoller(nil, c.genClient.Pipeline(), &runtime.NewPollerOptions[RecoverDeletedCertificateResponse]{
+				Handler: &handler,
+			})
+		}
 		return runtime.NewPollerFromResumeToken(options.ResumeToken, c.genClient.Pipeline(), &runtime.NewPollerFromResumeTokenOptions[RecoverDeletedCertificateResponse]{
 			Handler: &handler,
 		})
@@ -1240,6 +1721,9 @@ type ListDeletedCertificatesResponse struct {
 
 	// NextLink gives the next page of items to fetch
 	NextLink *string
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 func listDeletedCertsPageFromGenerated(g generated.KeyVaultClientGetDeletedCertificatesResponse) ListDeletedCertificatesResponse {
@@ -1257,6 +1741,7 @@ func listDeletedCertsPageFromGenerated(g generated.KeyVaultClientGetDeletedCerti
 				RecoveryID:         c.RecoveryID,
 				DeletedOn:          c.DeletedDate,
 				ScheduledPurgeDate: c.ScheduledPurgeDate,
+				Status:             certificateStatus(c.X509Thumbprint),
 			}
 		}
 	}
@@ -1269,46 +1754,84 @@ func listDeletedCertsPageFromGenerated(g generated.KeyVaultClientGetDeletedCerti
 
 // ListDeletedCertificatesOptions contains optional parameters for Client.ListDeletedCertificates
 type ListDeletedCertificatesOptions struct {
-	// placeholder for future optional parameters
+	// IncludePending specifies whether to include certificates that had no issued version when they were deleted.
+	IncludePending *bool
+
+	// MaxResults is the maximum number of items in a page. The service returns up to 25 by default.
+	MaxResults *int32
 }
 
 // NewListDeletedCertificatesPager retrieves the certificates in the current vault which are in a deleted state and ready for recovery or purging.
 // This operation includes deletion-specific information. This operation requires the certificates/get/list permission. This operation can
 // only be enabled on soft-delete enabled vaults.
 func (c *Client) NewListDeletedCertificatesPager(options *ListDeletedCertificatesOptions) *runtime.Pager[ListDeletedCertificatesResponse] {
-	pager := c.genClient.NewGetDeletedCertificatesPager(c.vaultURL, nil)
+	if options == nil {
+		options = &ListDeletedCertificatesOptions{}
+	}
+	pager := c.genClient.NewGetDeletedCertificatesPager(c.vaultURL, &generated.KeyVaultClientGetDeletedCertificatesOptions{
+		IncludePending: options.IncludePending,
+		Maxresults:     options.MaxResults,
+	})
 	return runtime.NewPager(runtime.PagingHandler[ListDeletedCertificatesResponse]{
 		More: func(page ListDeletedCertificatesResponse) bool {
 			return pager.More()
 		},
 		Fetcher: func(ctx context.Context, cur *ListDeletedCertificatesResponse) (ListDeletedCertificatesResponse, error) {
-			page, err := pager.NextPage(ctx)
+			var rawResp *http.Response
+			page, err := pager.NextPage(withRawResponse(ctx, &rawResp))
 			if err != nil {
 				return ListDeletedCertificatesResponse{}, err
 			}
-			return listDeletedCertsPageFromGenerated(page), nil
+			resp := listDeletedCertsPageFromGenerated(page)
+			resp.RawResponse = rawResp
+			return resp, nil
 		},
 	})
 }
 
-// CancelCertificateOperationOptions contains optional parameters for Client.CancelCertificateOperation
-type CancelCertificateOperationOptions struct {
-	// placeholder for future optional parameters.
+// BeginCancelCertificateOperationOptions contains optional parameters for Client.BeginCancelCertificateOperation
+type BeginCancelCertificateOperationOptions struct {
+	// ResumeToken is a string to begin polling from a previous operation
+	ResumeToken string
 }
 
-func (c *CancelCertificateOperationOptions) toGenerated() *generated.KeyVaultClientUpdateCertificateOperationOptions {
+func (c *BeginCancelCertificateOperationOptions) toGenerated() *generated.KeyVaultClientUpdateCertificateOperationOptions {
 	return &generated.KeyVaultClientUpdateCertificateOperationOptions{}
 }
 
-// CancelCertificateOperationResponse contains response fields for Client.CancelCertificateOperation
+// CancelCertificateOperationResponse contains response fields for Client.BeginCancelCertificateOperation
 type CancelCertificateOperationResponse struct {
 	Operation
 }
 
-// CancelCertificateOperation cancels a certificate creation operation that is already in progress. This operation requires the certificates/update permission.
-func (c *Client) CancelCertificateOperation(ctx context.Context, certificateName string, options *CancelCertificateOperationOptions) (CancelCertificateOperationResponse, error) {
+// BeginCancelCertificateOperation requests the cancellation of a certificate creation operation that is in progress,
+// and returns a poller that waits for the operation to stop. The poller's result is the operation in its final state:
+// its Status is "cancelled", or "completed" or "failed" when the operation finished before it could be cancelled.
+// This operation requires the certificates/update and certificates/get permissions.
+func (c *Client) BeginCancelCertificateOperation(ctx context.Context, certificateName string, options *BeginCancelCertificateOperationOptions) (*runtime.Poller[CancelCertificateOperationResponse], error) {
+	if options == nil {
+		options = &BeginCancelCertificateOperationOptions{}
+	}
+
+	handler := beginCancelCertificateOperation{
+		poll: func(ctx context.Context, endpoint string) (*http.Response, error) {
+			req, err := runtime.NewRequest(ctx, http.MethodGet, endpoint)
+			if err != nil {
+				return nil, err
+			}
+			return c.genClient.Pipeline().Do(req)
+		},
+	}
+
+	if options.ResumeToken != "" {
+		return runtime.NewPollerFromResumeToken(options.ResumeToken, c.genClient.Pipeline(), &runtime.NewPollerFromResumeTokenOptions[CancelCertificateOperationResponse]{
+			Handler: &handler,
+		})
+	}
+
+	var rawResp *http.Response
 	resp, err := c.genClient.UpdateCertificateOperation(
-		ctx,
+		runtime.WithCaptureResponse(ctx, &rawResp),
 		c.vaultURL,
 		certificateName,
 		generated.CertificateOperationUpdateParameter{
@@ -1317,12 +1840,20 @@ func (c *Client) CancelCertificateOperation(ctx context.Context, certificateName
 		options.toGenerated(),
 	)
 	if err != nil {
-		return CancelCertificateOperationResponse{}, err
+		return nil, err
+	}
+	if resp.Status == nil {
+		return nil, errors.New("missing status")
 	}
 
-	return CancelCertificateOperationResponse{
-		Operation: certificateOperationFromGenerated(resp.CertificateOperation),
-	}, nil
+	// the operation is read at the URL it's updated at
+	handler.PollURL = rawResp.Request.URL.String()
+	handler.Status = *resp.Status
+	op := certificateOperationFromGenerated(resp.CertificateOperation)
+	handler.op = &op
+	return runtime.NewPoller(rawResp, c.genClient.Pipeline(), &runtime.NewPollerOptions[CancelCertificateOperationResponse]{
+		Handler: &handler,
+	})
 }
 
 // DeleteCertificateOperationOptions contains optional parameters for Client.DeleteCertificateOperation
@@ -1337,13 +1868,17 @@ func (d *DeleteCertificateOperationOptions) toGenerated() *generated.KeyVaultCli
 // DeleteCertificateOperationResponse contains response fields for Client.DeleteCertificateOperation
 type DeleteCertificateOperationResponse struct {
 	Operation
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
 }
 
 // DeleteCertificateOperation deletes the creation operation for a specified certificate that is in the process of being created. The certificate is no
 // longer created. This operation requires the certificates/update permission.
 func (c *Client) DeleteCertificateOperation(ctx context.Context, certificateName string, options *DeleteCertificateOperationOptions) (DeleteCertificateOperationResponse, error) {
+	var rawResp *http.Response
 	resp, err := c.genClient.DeleteCertificateOperation(
-		ctx,
+		withRawResponse(ctx, &rawResp),
 		c.vaultURL,
 		certificateName,
 		options.toGenerated(),
@@ -1354,6 +1889,43 @@ func (c *Client) DeleteCertificateOperation(ctx context.Context, certificateName
 	}
 
 	return DeleteCertificateOperationResponse{
-		Operation: certificateOperationFromGenerated(resp.CertificateOperation),
+		Operation:   certificateOperationFromGenerated(resp.CertificateOperation),
+		RawResponse: rawResp,
 	}, nil
 }
+
+// GetVaultCapabilitiesOptions contains optional parameters for Client.GetVaultCapabilities
+type GetVaultCapabilitiesOptions struct {
+	// placeholder for future optional parameters.
+}
+
+// GetVaultCapabilitiesResponse contains response fields for Client.GetVaultCapabilities
+type GetVaultCapabilitiesResponse struct {
+	VaultCapabilities
+}
+
+// GetVaultCapabilities determines whether the vault has soft delete and purge protection enabled, and how long it
+// retains deleted certificates. Use it to choose a delete workflow, for example to skip purging deleted certificates
+// when the vault has purge protection. Key Vault reports these capabilities only in the properties of certificates, so
+// the response's Known field is false when the vault has no certificates. This operation requires the certificates/list
+// permission.
+func (c *Client) GetVaultCapabilities(ctx context.Context, options *GetVaultCapabilitiesOptions) (GetVaultCapabilitiesResponse, error) {
+	pager := c.genClient.NewGetCertificatesPager(c.vaultURL, &generated.KeyVaultClientGetCertificatesOptions{
+		IncludePending: to.Ptr(true),
+		Maxresults:     to.Ptr(int32(1)),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return GetVaultCapabilitiesResponse{}, err
+		}
+		for _, cert := range page.Value {
+			if cert != nil && cert.Attributes != nil && cert.Attributes.RecoveryLevel != nil {
+				return GetVaultCapabilitiesResponse{
+					VaultCapabilities: vaultCapabilitiesFromRecoveryLevel((*string)(cert.Attributes.RecoveryLevel), cert.Attributes.RecoverableDays),
+				}, nil
+			}
+		}
+	}
+	return GetVaultCapabilitiesResponse{}, nil
+}
@@ -0,0 +1,203 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"context"
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"golang.org/x/crypto/pkcs12"
+)
+
+const defaultClientCertificateRefreshInterval = time.Hour
+
+// ClientCertificateProviderOptions contains optional parameters for NewClientCertificateProvider.
+type ClientCertificateProviderOptions struct {
+	// Version of the certificate. By default, the provider uses the latest version and gets it again after
+	// RefreshInterval, so it picks up renewed certificates.
+	Version string
+
+	// Signer, when set, signs TLS handshakes in place of the certificate's private key, for example a signer
+	// backed by a Key Vault key. The provider then doesn't read the certificate's secret, so it works with
+	// certificates whose keys aren't exportable. Signer's public key must match the certificate's.
+	Signer crypto.Signer
+
+	// RefreshInterval is how long the provider caches the certificate. The default is one hour.
+	RefreshInterval time.Duration
+}
+
+// ClientCertificateProvider serves a Key Vault certificate to TLS clients, so mutual TLS clients can authenticate
+// with a certificate whose key is never written to disk. Don't use this type directly, use
+// NewClientCertificateProvider() instead.
+type ClientCertificateProvider struct {
+	client  *Client
+	name    string
+	options ClientCertificateProviderOptions
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	fetchedAt time.Time
+}
+
+// NewClientCertificateProvider creates a ClientCertificateProvider for the named certificate. Unless options.Signer
+// is set, the certificate's private key is read from its backing secret, so the client's credential needs permission
+// to get secrets.
+func NewClientCertificateProvider(client *Client, name string, options *ClientCertificateProviderOptions) *ClientCertificateProvider {
+	if options == nil {
+		options = &ClientCertificateProviderOptions{}
+	}
+	p := &ClientCertificateProvider{client: client, name: name, options: *options}
+	if p.options.RefreshInterval <= 0 {
+		p.options.RefreshInterval = defaultClientCertificateRefreshInterval
+	}
+	return p
+}
+
+// GetClientCertificate returns the certificate, getting it from Key Vault when the cached copy is older than
+// RefreshInterval. When Key Vault can't be reached, it returns the cached copy if there is one. Assign it to
+// tls.Config.GetClientCertificate.
+func (p *ClientCertificateProvider) GetClientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	ctx := context.Background()
+	if info != nil {
+		ctx = info.Context()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cert != nil && time.Since(p.fetchedAt) < p.options.RefreshInterval {
+		return p.cert, nil
+	}
+	cert, err := p.load(ctx)
+	if err != nil {
+		if p.cert != nil {
+			return p.cert, nil
+		}
+		return nil, err
+	}
+	p.cert = cert
+	p.fetchedAt = time.Now()
+	return cert, nil
+}
+
+func (p *ClientCertificateProvider) load(ctx context.Context) (*tls.Certificate, error) {
+	resp, err := p.client.GetCertificate(ctx, p.name, &GetCertificateOptions{Version: p.options.Version})
+	if err != nil {
+		return nil, err
+	}
+	if p.options.Signer != nil {
+		return certificateWithSigner(resp.CER, p.options.Signer)
+	}
+	if resp.SecretID == nil {
+		return nil, errors.New("the certificate has no secret ID")
+	}
+	value, contentType, err := p.client.getSecretValue(ctx, *resp.SecretID)
+	if err != nil {
+		return nil, err
+	}
+	return parseCertificateSecret(value, contentType)
+}
+
+// getSecretValue gets the value of a certificate's backing secret
+func (c *Client) getSecretValue(ctx context.Context, secretID string) (value string, contentType string, err error) {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, secretID)
+	if err != nil {
+		return "", "", err
+	}
+	reqQP := req.Raw().URL.Query()
+	reqQP.Set("api-version", c.genClient.APIVersion())
+	req.Raw().URL.RawQuery = reqQP.Encode()
+	req.Raw().Header.Set("Accept", "application/json")
+	resp, err := c.pl.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return "", "", runtime.NewResponseError(resp)
+	}
+	var secret struct {
+		Value       *string `json:"value"`
+		ContentType *string `json:"contentType"`
+	}
+	if err := runtime.UnmarshalAsJSON(resp, &secret); err != nil {
+		return "", "", err
+	}
+	if secret.Value == nil {
+		return "", "", errors.New("the certificate's secret has no value")
+	}
+	if secret.ContentType != nil {
+		contentType = *secret.ContentType
+	}
+	return *secret.Value, contentType, nil
+}
+
+// parseCertificateSecret parses the value of a certificate's backing secret, which holds the certificate
+// chain and, when the key is exportable, the private key
+func parseCertificateSecret(value string, contentType string) (*tls.Certificate, error) {
+	pemData, err := certificateSecretPEM(value, contentType)
+	if err != nil {
+		return nil, err
+	}
+	cert, _, err := assembleCertificate(pemData)
+	if err != nil {
+		return nil, fmt.Errorf("the certificate's secret doesn't contain a usable certificate and key; set ClientCertificateProviderOptions.Signer for certificates with non-exportable keys: %w", err)
+	}
+	return cert, nil
+}
+
+// certificateSecretPEM returns the value of a certificate's backing secret as PEM. Secrets that aren't
+// PEM hold base64 encoded PKCS#12 data without a password.
+func certificateSecretPEM(value string, contentType string) ([]byte, error) {
+	if contentType == string(CertificateContentTypePEM) {
+		return []byte(value), nil
+	}
+	pfx, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := pkcs12.ToPEM(pfx, "")
+	if err != nil {
+		return nil, err
+	}
+	var pemData []byte
+	for _, b := range blocks {
+		pemData = append(pemData, pem.EncodeToMemory(b)...)
+	}
+	return pemData, nil
+}
+
+// certificateWithSigner pairs a DER certificate with a signer for its key
+func certificateWithSigner(cer []byte, signer crypto.Signer) (*tls.Certificate, error) {
+	leaf, err := x509.ParseCertificate(cer)
+	if err != nil {
+		return nil, err
+	}
+	type publicKey interface {
+		Equal(crypto.PublicKey) bool
+	}
+	if pk, ok := leaf.PublicKey.(publicKey); ok && !pk.Equal(signer.Public()) {
+		return nil, errors.New("the signer's public key doesn't match the certificate's")
+	} else if !ok && !reflect.DeepEqual(leaf.PublicKey, signer.Public()) {
+		return nil, errors.New("the signer's public key doesn't match the certificate's")
+	}
+	return &tls.Certificate{
+		Certificate: [][]byte{cer},
+		PrivateKey:  signer,
+		Leaf:        leaf,
+	}, nil
+}
@@ -7,18 +7,29 @@
 package azcertificates
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -27,6 +38,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/internal/recording"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azcertificates/internal/generated"
 	"github.com/stretchr/testify/require"
 )
 
@@ -210,9 +222,9 @@ func TestClient_CancelCertificateOperation(t *testing.T) {
 	_, err = client.BeginCreateCertificate(ctx, certName, NewDefaultCertificatePolicy(), nil)
 	require.NoError(t, err)
 
-	cancelResp, err := client.CancelCertificateOperation(ctx, certName, nil)
+	cancelPoller, err := client.BeginCancelCertificateOperation(ctx, certName, nil)
 	require.NoError(t, err)
-	require.Contains(t, *cancelResp.ID, certName)
+	require.NotNil(t, cancelPoller)
 
 	getResp, err := client.GetCertificateOperation(ctx, certName, nil)
 	require.NoError(t, err)
@@ -894,3 +906,1536 @@ func TestClient_ListDeletedCertificates(t *testing.T) {
 	}
 	require.Equal(t, createdCount, deletedCount)
 }
+
+func TestVaultCapabilitiesFromRecoveryLevel(t *testing.T) {
+	v := vaultCapabilitiesFromRecoveryLevel(nil, nil)
+	require.False(t, v.Known)
+
+	v = vaultCapabilitiesFromRecoveryLevel(to.Ptr("Purgeable"), to.Ptr(int32(0)))
+	require.True(t, v.Known)
+	require.False(t, v.SoftDelete)
+	require.False(t, v.PurgeProtection)
+	require.Nil(t, v.RecoverableDays)
+
+	v = vaultCapabilitiesFromRecoveryLevel(to.Ptr("CustomizedRecoverable+Purgeable"), to.Ptr(int32(7)))
+	require.True(t, v.SoftDelete)
+	require.False(t, v.PurgeProtection)
+	require.EqualValues(t, 7, *v.RecoverableDays)
+
+	v = vaultCapabilitiesFromRecoveryLevel(to.Ptr("Recoverable+ProtectedSubscription"), to.Ptr(int32(90)))
+	require.True(t, v.SoftDelete)
+	require.True(t, v.PurgeProtection)
+	require.EqualValues(t, 90, *v.RecoverableDays)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	created := time.Unix(1655000000, 0)
+	id := "https://vault.vault.azure.net/certificates/cert/version"
+	policy := &Policy{
+		Properties:       &Properties{Enabled: to.Ptr(true), CreatedOn: &created},
+		IssuerParameters: &IssuerParameters{IssuerName: to.Ptr("Self")},
+		KeyCurveName:     to.Ptr(KeyCurveNameP256),
+		KeyType:          to.Ptr(KeyTypeEC),
+		LifetimeActions:  []*LifetimeAction{{Action: to.Ptr(PolicyActionAutoRenew), DaysBeforeExpiry: to.Ptr(int32(30))}},
+		ContentType:      to.Ptr(CertificateContentTypePEM),
+		X509Properties: &X509CertificateProperties{
+			KeyUsages: []*KeyUsage{to.Ptr(KeyUsageDigitalSignature)},
+			Subject:   to.Ptr("CN=test"),
+		},
+	}
+
+	cert := CertificateWithPolicy{
+		Properties: &Properties{
+			CreatedOn:      &created,
+			Enabled:        to.Ptr(true),
+			ID:             &id,
+			Name:           to.Ptr("cert"),
+			Tags:           map[string]*string{"tag": to.Ptr("value")},
+			VaultURL:       to.Ptr("https://vault.vault.azure.net/"),
+			Version:        to.Ptr("version"),
+			X509Thumbprint: []byte{1, 2, 3},
+		},
+		CER:      []byte{4, 5, 6},
+		ID:       &id,
+		KeyID:    to.Ptr("https://vault.vault.azure.net/keys/cert/version"),
+		Policy:   policy,
+		SecretID: to.Ptr("https://vault.vault.azure.net/secrets/cert/version"),
+	}
+
+	data, err := json.Marshal(cert)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"created":1655000000`)
+
+	var certCopy CertificateWithPolicy
+	require.NoError(t, json.Unmarshal(data, &certCopy))
+	require.Equal(t, cert, certCopy)
+
+	data, err = json.Marshal(policy)
+	require.NoError(t, err)
+	var policyCopy Policy
+	require.NoError(t, json.Unmarshal(data, &policyCopy))
+	require.Equal(t, *policy, policyCopy)
+
+	op := Operation{
+		CSR:              []byte{7, 8, 9},
+		Error:            &CertificateOperationError{Code: to.Ptr("Failed"), Message: to.Ptr("failed"), InnerError: &CertificateOperationError{Code: to.Ptr("IssuerError")}},
+		IssuerParameters: &IssuerParameters{IssuerName: to.Ptr("Self")},
+		Status:           to.Ptr("failed"),
+		ID:               to.Ptr("https://vault.vault.azure.net/certificates/cert/pending"),
+	}
+	data, err = json.Marshal(op)
+	require.NoError(t, err)
+	var opCopy Operation
+	require.NoError(t, json.Unmarshal(data, &opCopy))
+	require.Equal(t, op, opCopy)
+
+	// nil fields don't panic and stay nil
+	data, err = json.Marshal(CertificateWithPolicy{})
+	require.NoError(t, err)
+	require.Equal(t, "{}", string(data))
+	data, err = json.Marshal(Policy{})
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &policyCopy))
+	require.Equal(t, Policy{}, policyCopy)
+}
+
+func TestClientCertificateProviderParsing(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cer, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	// a PEM secret holds the certificate and its key
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	secret := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cer}))
+	cert, err := parseCertificateSecret(secret, string(CertificateContentTypePEM))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{cer}, cert.Certificate)
+
+	// without the key, the secret isn't usable
+	_, err = parseCertificateSecret(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cer})), string(CertificateContentTypePEM))
+	require.Error(t, err)
+
+	cert, err = certificateWithSigner(cer, key)
+	require.NoError(t, err)
+	require.Equal(t, key, cert.PrivateKey)
+	require.NotNil(t, cert.Leaf)
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = certificateWithSigner(cer, other)
+	require.Error(t, err)
+}
+
+func TestParseCER(t *testing.T) {
+	var chain [][]byte
+	var parent *x509.Certificate
+	var parentKey *ecdsa.PrivateKey
+	for i := 0; i < 2; i++ {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(int64(i + 1)),
+			Subject:               pkix.Name{CommonName: fmt.Sprintf("cert-%d", i)},
+			NotBefore:             time.Now(),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  i == 0,
+			BasicConstraintsValid: true,
+		}
+		signer, signerKey := template, key
+		if parent != nil {
+			signer, signerKey = parent, parentKey
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+		require.NoError(t, err)
+		parent, err = x509.ParseCertificate(der)
+		require.NoError(t, err)
+		parentKey = key
+		// leaf first
+		chain = append([][]byte{der}, chain...)
+	}
+
+	var pemChain []byte
+	for _, der := range chain {
+		pemChain = append(pemChain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+	for name, data := range map[string][]byte{
+		"DER":    bytes.Join(chain, nil),
+		"PEM":    append(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("skipped")}), pemChain...),
+		"base64": []byte(base64.StdEncoding.EncodeToString(bytes.Join(chain, nil))),
+	} {
+		certs, err := ParseCER(data)
+		require.NoError(t, err, name)
+		require.Len(t, certs, 2, name)
+		require.Equal(t, "cert-1", certs[0].Subject.CommonName, name)
+		require.Equal(t, "cert-0", certs[1].Subject.CommonName, name)
+	}
+
+	leaf, err := GetCertificateResponse{CertificateWithPolicy: CertificateWithPolicy{CER: chain[0]}}.ParseX509()
+	require.NoError(t, err)
+	require.Equal(t, "cert-1", leaf.Subject.CommonName)
+
+	for _, data := range [][]byte{nil, []byte("not a certificate"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("x")})} {
+		_, err = ParseCER(data)
+		require.Error(t, err)
+	}
+	_, err = Certificate{}.ParseX509()
+	require.Error(t, err)
+}
+
+func TestPolicyFile(t *testing.T) {
+	policy := Policy{
+		Properties:       &Properties{Enabled: to.Ptr(true)},
+		IssuerParameters: &IssuerParameters{IssuerName: to.Ptr("Self")},
+		KeyType:          to.Ptr(KeyTypeEC),
+		KeyCurveName:     to.Ptr(KeyCurveNameP256),
+		Exportable:       to.Ptr(true),
+		ContentType:      to.Ptr(CertificateContentTypePEM),
+		X509Properties: &X509CertificateProperties{
+			Subject:                 to.Ptr("CN=contoso.com"),
+			ValidityInMonths:        to.Ptr(int32(12)),
+			KeyUsages:               []*KeyUsage{to.Ptr(KeyUsageDigitalSignature)},
+			EnhancedKeyUsages:       []*string{to.Ptr("1.3.6.1.5.5.7.3.1")},
+			SubjectAlternativeNames: &SubjectAlternativeNames{DNSNames: []*string{to.Ptr("contoso.com"), to.Ptr("www.contoso.com")}},
+		},
+		LifetimeActions: []*LifetimeAction{{Action: to.Ptr(PolicyActionAutoRenew), DaysBeforeExpiry: to.Ptr(int32(30))}},
+	}
+	for _, format := range []PolicyFileFormat{PolicyFileFormatYAML, PolicyFileFormatJSON} {
+		data, err := policy.MarshalDeclarative(format)
+		require.NoError(t, err)
+		parsed, err := ParsePolicyFile(bytes.NewReader(data))
+		require.NoError(t, err, string(data))
+		require.Equal(t, policy, parsed)
+	}
+	_, err := policy.MarshalDeclarative("xml")
+	require.Error(t, err)
+
+	parsed, err := ParsePolicyFile(strings.NewReader(`
+issuer:
+  name: Self
+x509:
+  subject: CN=fabrikam.com
+`))
+	require.NoError(t, err)
+	require.Equal(t, "Self", *parsed.IssuerParameters.IssuerName)
+	require.Equal(t, "CN=fabrikam.com", *parsed.X509Properties.Subject)
+	require.Nil(t, parsed.X509Properties.SubjectAlternativeNames)
+
+	parsed, err = ParsePolicyFile(strings.NewReader(""))
+	require.NoError(t, err)
+	require.Equal(t, Policy{}, parsed)
+
+	for _, invalid := range []string{
+		"key:\n  type: DSA\n",
+		"x509:\n  subjct: CN=typo\n",
+		`{"key": {"curve": "P-999"}}`,
+		`{"unknown": true}`,
+		"lifetimeActions:\n  - action: AutoRenew\n",
+		"lifetimeActions:\n  - daysBeforeExpiry: 30\n",
+		"x509:\n  keyUsages: [signEverything]\n",
+	} {
+		_, err := ParsePolicyFile(strings.NewReader(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+// challengeTransport answers Key Vault's authentication challenge and responds to authenticated requests with respond
+type challengeTransport struct {
+	respond func(req *http.Request) *http.Response
+}
+
+func (c *challengeTransport) Do(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	if req.Header.Get("Authorization") == "" {
+		resp = &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+			Body:       http.NoBody,
+		}
+	} else {
+		resp = c.respond(req)
+	}
+	resp.Request = req
+	return resp, nil
+}
+
+func jsonResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestPagerMaxResults(t *testing.T) {
+	var maxResults []string
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		maxResults = append(maxResults, req.URL.Query().Get("maxresults"))
+		return jsonResponse(http.StatusOK, `{"value":[]}`)
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	max := to.Ptr(int32(5))
+	pages := []func() error{
+		func() error {
+			_, err := client.NewListPropertiesOfCertificatesPager(&ListPropertiesOfCertificatesOptions{MaxResults: max}).NextPage(context.Background())
+			return err
+		},
+		func() error {
+			_, err := client.NewListPropertiesOfCertificateVersionsPager("cert", &ListPropertiesOfCertificateVersionsOptions{MaxResults: max}).NextPage(context.Background())
+			return err
+		},
+		func() error {
+			_, err := client.NewListPropertiesOfIssuersPager(&ListPropertiesOfIssuersOptions{MaxResults: max}).NextPage(context.Background())
+			return err
+		},
+		func() error {
+			_, err := client.NewListDeletedCertificatesPager(&ListDeletedCertificatesOptions{MaxResults: max}).NextPage(context.Background())
+			return err
+		},
+		func() error {
+			_, err := client.NewListPropertiesOfCertificatesPager(nil).NextPage(context.Background())
+			return err
+		},
+	}
+	for _, page := range pages {
+		require.NoError(t, page())
+	}
+	require.Equal(t, []string{"5", "5", "5", "5", ""}, maxResults)
+}
+
+func TestBeginCreateCertificateReturnCSROnPending(t *testing.T) {
+	csr := []byte("csr")
+	op := fmt.Sprintf(`{"id":"%scertificates/cert/pending","issuer":{"name":"Unknown"},"csr":"%s","status":"inProgress","status_details":"Pending certificate created. Please Perform Merge to complete the request."}`, fakeKvURL, base64.StdEncoding.EncodeToString(csr))
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		if req.Method == http.MethodPost {
+			resp := jsonResponse(http.StatusAccepted, op)
+			resp.Header.Set("Location", fakeKvURL+"certificates/cert/pending?api-version=7.3")
+			return resp
+		}
+		return jsonResponse(http.StatusOK, op)
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	policy := Policy{IssuerParameters: &IssuerParameters{IssuerName: to.Ptr(string(WellKnownIssuerNamesUnknown))}, X509Properties: &X509CertificateProperties{Subject: to.Ptr("CN=contoso.com")}}
+
+	poller, err := client.BeginCreateCertificate(context.Background(), "cert", policy, &BeginCreateCertificateOptions{ReturnCSROnPending: true})
+	require.NoError(t, err)
+	require.True(t, poller.Done())
+	resp, err := poller.Result(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, resp.PendingOperation)
+	require.Equal(t, csr, resp.PendingOperation.CSR)
+	require.Nil(t, resp.ID)
+
+	// by default, the poller waits for the merge
+	poller, err = client.BeginCreateCertificate(context.Background(), "cert", policy, nil)
+	require.NoError(t, err)
+	require.False(t, poller.Done())
+	_, err = poller.Poll(context.Background())
+	require.NoError(t, err)
+	require.False(t, poller.Done())
+}
+
+func TestBackupAndRestoreAllCertificates(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	var restored []string
+	var mu sync.Mutex
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		path := strings.TrimPrefix(req.URL.Path, "/certificates")
+		switch {
+		case req.Method == http.MethodGet && path == "":
+			var items []string
+			for _, n := range names {
+				items = append(items, fmt.Sprintf(`{"id":"%scertificates/%s"}`, fakeKvURL, n))
+			}
+			return jsonResponse(http.StatusOK, `{"value":[`+strings.Join(items, ",")+`]}`)
+		case req.Method == http.MethodPost && path == "/b/backup":
+			return jsonResponse(http.StatusForbidden, `{"error":{"code":"Forbidden","message":"no"}}`)
+		case req.Method == http.MethodPost && strings.HasSuffix(path, "/backup"):
+			name := strings.TrimSuffix(strings.TrimPrefix(path, "/"), "/backup")
+			return jsonResponse(http.StatusOK, fmt.Sprintf(`{"value":"%s"}`, base64.RawURLEncoding.EncodeToString([]byte("backup-"+name))))
+		case req.Method == http.MethodPost && path == "/restore":
+			var body struct {
+				Value string `json:"value"`
+			}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			backup, err := base64.RawURLEncoding.DecodeString(body.Value)
+			require.NoError(t, err)
+			mu.Lock()
+			restored = append(restored, string(backup))
+			mu.Unlock()
+			return jsonResponse(http.StatusOK, `{}`)
+		}
+		return jsonResponse(http.StatusNotFound, `{}`)
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	backupResp, err := client.BackupAllCertificates(context.Background(), &archive, &BackupAllCertificatesOptions{MaxConcurrency: 2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c"}, backupResp.Certificates)
+	require.Len(t, backupResp.Errors, 1)
+	require.Contains(t, backupResp.Errors, "b")
+
+	restoreResp, err := client.RestoreAllCertificates(context.Background(), bytes.NewReader(archive.Bytes()), nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c"}, restoreResp.Certificates)
+	require.Empty(t, restoreResp.Errors)
+	sort.Strings(restored)
+	require.Equal(t, []string{"backup-a", "backup-c"}, restored)
+
+	// truncated archives and other data are rejected
+	for _, data := range [][]byte{archive.Bytes()[:archive.Len()-4], []byte("not an archive")} {
+		_, err = client.RestoreAllCertificates(context.Background(), bytes.NewReader(data), nil)
+		require.ErrorIs(t, err, ErrInvalidBackupArchive)
+	}
+}
+
+func TestImportCertificatePreserveCertOrder(t *testing.T) {
+	var body map[string]interface{}
+	var apiVersion string
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		apiVersion = req.URL.Query().Get("api-version")
+		body = nil
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return jsonResponse(http.StatusOK, `{"id":"`+fakeKvURL+`certificates/cert/1","preserveCertOrder":true}`)
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	_, err = client.ImportCertificate(context.Background(), "cert", []byte("cert"), &ImportCertificateOptions{PreserveCertOrder: to.Ptr(true)})
+	require.NoError(t, err)
+	require.Equal(t, "7.4", apiVersion)
+	require.Equal(t, true, body["preserveCertOrder"])
+
+	_, err = client.ImportCertificate(context.Background(), "cert", []byte("cert"), nil)
+	require.NoError(t, err)
+	require.NotContains(t, body, "preserveCertOrder")
+}
+
+func TestCertificateOperationError(t *testing.T) {
+	g := &generated.Error{
+		Code:    to.Ptr("BadParameter"),
+		Message: to.Ptr("issuer rejected the request"),
+		InnerError: &generated.Error{
+			Code:    to.Ptr("CSRRejected"),
+			Message: to.Ptr("key size too small"),
+		},
+	}
+	opErr := certificateErrorFromGenerated(g)
+	require.Equal(t, "BadParameter: issuer rejected the request; inner error: CSRRejected: key size too small", opErr.Error())
+	require.Equal(t, g, opErr.toGenerated())
+
+	var err error = fmt.Errorf("operation failed: %w", opErr)
+	var outer, inner *CertificateOperationError
+	require.True(t, errors.As(err, &outer))
+	require.True(t, errors.As(outer.Unwrap(), &inner))
+	require.Equal(t, "CSRRejected", *inner.Code)
+	require.Nil(t, inner.Unwrap())
+	require.Equal(t, "UnknownError", (&CertificateOperationError{}).Error())
+}
+
+func TestNewPolicies(t *testing.T) {
+	selfSigned := NewSelfSignedPolicy("CN=contoso.com", 6)
+	require.Equal(t, "Self", *selfSigned.IssuerParameters.IssuerName)
+	require.Equal(t, "CN=contoso.com", *selfSigned.X509Properties.Subject)
+	require.EqualValues(t, 6, *selfSigned.X509Properties.ValidityInMonths)
+	require.Equal(t, KeyTypeRSA, *selfSigned.KeyType)
+	require.EqualValues(t, 2048, *selfSigned.KeySize)
+	require.Equal(t, CertificateContentTypePKCS12, *selfSigned.ContentType)
+	require.Equal(t, PolicyActionAutoRenew, *selfSigned.LifetimeActions[0].Action)
+	require.EqualValues(t, 80, *selfSigned.LifetimeActions[0].LifetimePercentage)
+	require.Nil(t, NewSelfSignedPolicy("CN=contoso.com", 0).X509Properties.ValidityInMonths)
+
+	issuer := NewIssuerPolicy("digicert", "CN=contoso.com")
+	require.Equal(t, "digicert", *issuer.IssuerParameters.IssuerName)
+	require.Nil(t, issuer.X509Properties.ValidityInMonths)
+
+	external := NewExternallySignedPolicy("CN=contoso.com")
+	require.Equal(t, "Unknown", *external.IssuerParameters.IssuerName)
+	require.Equal(t, PolicyActionEmailContacts, *external.LifetimeActions[0].Action)
+	require.EqualValues(t, 30, *external.LifetimeActions[0].DaysBeforeExpiry)
+	require.Nil(t, external.LifetimeActions[0].LifetimePercentage)
+
+	// the policies are valid and independent of each other
+	for _, p := range []Policy{selfSigned, issuer, external} {
+		require.NoError(t, p.toPolicyFile().validate())
+	}
+	*selfSigned.KeySize = 4096
+	require.EqualValues(t, 2048, *issuer.KeySize)
+}
+
+func TestBeginImportCertificate(t *testing.T) {
+	bundle := `{"id":"` + fakeKvURL + `certificates/cert/%s","attributes":{"enabled":true}}`
+	for _, test := range []struct {
+		name        string
+		operations  []string
+		wantVersion string
+		wantErr     string
+	}{
+		{name: "no operation", wantVersion: "imported"},
+		{name: "completed operation", operations: []string{`{"status":"completed"}`}, wantVersion: "imported"},
+		{name: "operation in progress", operations: []string{`{"status":"inProgress"}`, `{"status":"inProgress"}`, `{"status":"completed"}`}, wantVersion: "issued"},
+		{name: "failed operation", operations: []string{`{"status":"inProgress"}`, `{"status":"failed","error":{"code":"IssuerError","message":"rejected"}}`}, wantErr: "certificate operation failed: IssuerError: rejected"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			operations := test.operations
+			var polls int
+			transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+				switch {
+				case req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/certificates/cert/import"):
+					return jsonResponse(http.StatusOK, fmt.Sprintf(bundle, "imported"))
+				case req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/certificates/cert/pending"):
+					polls++
+					if len(operations) == 0 {
+						return jsonResponse(http.StatusNotFound, `{"error":{"code":"NotFound"}}`)
+					}
+					op := operations[0]
+					if len(operations) > 1 {
+						operations = operations[1:]
+					}
+					return jsonResponse(http.StatusOK, op)
+				case req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/certificates/cert/"):
+					return jsonResponse(http.StatusOK, fmt.Sprintf(bundle, "issued"))
+				}
+				t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+				return nil
+			}}
+			client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+			require.NoError(t, err)
+
+			poller, err := client.BeginImportCertificate(context.Background(), "cert", []byte("cert"), nil)
+			require.NoError(t, err)
+			for !poller.Done() {
+				_, err = poller.Poll(context.Background())
+				require.NoError(t, err)
+			}
+			resp, err := poller.Result(context.Background())
+			if test.wantErr != "" {
+				require.EqualError(t, err, test.wantErr)
+				var opErr *CertificateOperationError
+				require.ErrorAs(t, err, &opErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, fakeKvURL+"certificates/cert/"+test.wantVersion, *resp.ID)
+			// the operation is requested once after the import, then polled until it's done
+			wantPolls := len(test.operations)
+			if wantPolls == 0 {
+				wantPolls = 1
+			}
+			require.Equal(t, wantPolls, polls)
+		})
+	}
+}
+
+func TestBeginCancelCertificateOperation(t *testing.T) {
+	operations := []string{`{"status":"inProgress"}`, `{"status":"cancelled","cancellation_requested":true}`}
+	var polls int
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		if strings.HasSuffix(req.URL.Path, "/certificates/cert/pending") {
+			switch req.Method {
+			case http.MethodPatch:
+				return jsonResponse(http.StatusOK, `{"status":"inProgress","cancellation_requested":true}`)
+			case http.MethodGet:
+				op := operations[polls]
+				polls++
+				return jsonResponse(http.StatusOK, op)
+			}
+		}
+		t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		return nil
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	poller, err := client.BeginCancelCertificateOperation(context.Background(), "cert", nil)
+	require.NoError(t, err)
+	require.False(t, poller.Done())
+	token, err := poller.ResumeToken()
+	require.NoError(t, err)
+
+	// a resumed poller picks up where the first left off
+	poller, err = client.BeginCancelCertificateOperation(context.Background(), "cert", &BeginCancelCertificateOperationOptions{ResumeToken: token})
+	require.NoError(t, err)
+	for !poller.Done() {
+		_, err = poller.Poll(context.Background())
+		require.NoError(t, err)
+	}
+	resp, err := poller.Result(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "cancelled", *resp.Status)
+	require.True(t, *resp.CancellationRequested)
+	require.Equal(t, 2, polls)
+
+	// the operation may finish before it can be cancelled
+	transport.respond = func(req *http.Request) *http.Response {
+		return jsonResponse(http.StatusOK, `{"status":"completed"}`)
+	}
+	poller, err = client.BeginCancelCertificateOperation(context.Background(), "cert", nil)
+	require.NoError(t, err)
+	require.True(t, poller.Done())
+	resp, err = poller.Result(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "completed", *resp.Status)
+}
+
+func TestGetAllCertificateVersions(t *testing.T) {
+	versions := []string{"v1", "v2", "v3", "v4", "v5"}
+	failing := ""
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		path := strings.TrimPrefix(req.URL.Path, "/certificates/cert/")
+		switch {
+		case req.Method == http.MethodGet && path == "versions":
+			// the versions are listed in two pages
+			page, next := versions[:3], fmt.Sprintf(`"%scertificates/cert/versions?page=2"`, fakeKvURL)
+			if req.URL.Query().Get("page") == "2" {
+				page, next = versions[3:], "null"
+			}
+			var items []string
+			for _, v := range page {
+				items = append(items, fmt.Sprintf(`{"id":"%scertificates/cert/%s"}`, fakeKvURL, v))
+			}
+			return jsonResponse(http.StatusOK, `{"value":[`+strings.Join(items, ",")+`],"nextLink":`+next+`}`)
+		case req.Method == http.MethodGet:
+			mu.Lock()
+			inFlight++
+			if inFlight > maxInFlight {
+				maxInFlight = inFlight
+			}
+			mu.Unlock()
+			time.Sleep(time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			if path == failing {
+				return jsonResponse(http.StatusForbidden, `{"error":{"code":"Forbidden","message":"no"}}`)
+			}
+			return jsonResponse(http.StatusOK, fmt.Sprintf(`{"id":"%scertificates/cert/%s","cer":"%s"}`, fakeKvURL, path, base64.StdEncoding.EncodeToString([]byte(path))))
+		}
+		t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		return nil
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	resp, err := client.GetAllCertificateVersions(context.Background(), "cert", &GetAllCertificateVersionsOptions{MaxConcurrency: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Certificates, len(versions))
+	for i, v := range versions {
+		require.Equal(t, fakeKvURL+"certificates/cert/"+v, *resp.Certificates[i].ID)
+		require.Equal(t, []byte(v), resp.Certificates[i].CER)
+	}
+	require.LessOrEqual(t, maxInFlight, 2)
+
+	failing = "v4"
+	_, err = client.GetAllCertificateVersions(context.Background(), "cert", nil)
+	var respErr *azcore.ResponseError
+	require.ErrorAs(t, err, &respErr)
+	require.Equal(t, http.StatusForbidden, respErr.StatusCode)
+}
+
+func TestGetCertificateByThumbprint(t *testing.T) {
+	// certificate "b" has an older version, "b0", than its latest, "b1"
+	versions := map[string][]string{"a": {"a1"}, "b": {"b1", "b0"}}
+	thumbprint := func(version string) []byte {
+		sum := sha1.Sum([]byte(version))
+		return sum[:]
+	}
+	var gets []string
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+		item := func(name, version string) string {
+			id := fakeKvURL + "certificates/" + name
+			if version != versions[name][0] {
+				id += "/" + version
+			}
+			return fmt.Sprintf(`{"id":"%s","x5t":"%s","attributes":{"enabled":true}}`, id, base64.RawURLEncoding.EncodeToString(thumbprint(version)))
+		}
+		switch {
+		case len(path) == 1:
+			return jsonResponse(http.StatusOK, `{"value":[`+item("a", "a1")+","+item("b", "b1")+`]}`)
+		case len(path) == 3 && path[2] == "versions":
+			var items []string
+			for _, v := range versions[path[1]] {
+				items = append(items, fmt.Sprintf(`{"id":"%scertificates/%s/%s","x5t":"%s","attributes":{"enabled":true}}`, fakeKvURL, path[1], v, base64.RawURLEncoding.EncodeToString(thumbprint(v))))
+			}
+			return jsonResponse(http.StatusOK, `{"value":[`+strings.Join(items, ",")+`]}`)
+		case len(path) <= 3:
+			// the latest version's URL has no version
+			version := versions[path[1]][0]
+			if len(path) == 3 {
+				version = path[2]
+			}
+			gets = append(gets, version)
+			return jsonResponse(http.StatusOK, fmt.Sprintf(`{"id":"%scertificates/%s/%s","cer":"%s"}`, fakeKvURL, path[1], version, base64.StdEncoding.EncodeToString([]byte(version))))
+		}
+		t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		return nil
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	sha256Hex := func(version string) string {
+		sum := sha256.Sum256([]byte(version))
+		return hex.EncodeToString(sum[:])
+	}
+	for _, test := range []struct {
+		thumbprint string
+		options    *GetCertificateByThumbprintOptions
+		version    string
+		gets       []string
+	}{
+		// a SHA-1 search gets only the matching version
+		{thumbprint: hex.EncodeToString(thumbprint("b1")), version: "b1", gets: []string{"b1"}},
+		{thumbprint: strings.ToUpper(hex.EncodeToString(thumbprint("b0"))), version: "b0", gets: []string{"b0"}},
+		// a SHA-256 search checks the latest versions first and doesn't get them again
+		{thumbprint: sha256Hex("a1"), version: "a1", gets: []string{"a1"}},
+		{thumbprint: sha256Hex("b0"), version: "b0", gets: []string{"a1", "b1", "b0"}},
+		{thumbprint: sha256Hex("b0"), options: &GetCertificateByThumbprintOptions{LatestVersionsOnly: true}, gets: []string{"a1", "b1"}},
+		{thumbprint: sha256Hex("c"), gets: []string{"a1", "b1", "b0"}},
+	} {
+		gets = nil
+		resp, err := client.GetCertificateByThumbprint(context.Background(), test.thumbprint, test.options)
+		if test.version == "" {
+			require.ErrorIs(t, err, ErrThumbprintNotFound)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, []byte(test.version), resp.CER)
+		}
+		require.Equal(t, test.gets, gets, test.thumbprint)
+	}
+
+	_, err = client.GetCertificateByThumbprint(context.Background(), "AB:CD", nil)
+	require.Error(t, err)
+	_, err = client.GetCertificateByThumbprint(context.Background(), "not hex", nil)
+	require.Error(t, err)
+}
+
+func TestRawResponse(t *testing.T) {
+	var requestID int
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		requestID++
+		var resp *http.Response
+		if strings.HasSuffix(req.URL.Path, "/certificates") {
+			resp = jsonResponse(http.StatusOK, `{"value":[]}`)
+		} else {
+			resp = jsonResponse(http.StatusOK, `{"id":"`+fakeKvURL+`certificates/cert/1"}`)
+		}
+		resp.Header.Set("x-ms-request-id", fmt.Sprint(requestID))
+		return resp
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	// the caller's capture still works
+	var captured *http.Response
+	resp, err := client.GetCertificate(runtime.WithCaptureResponse(context.Background(), &captured), "cert", nil)
+	require.NoError(t, err)
+	require.Equal(t, "1", resp.RawResponse.Header.Get("x-ms-request-id"))
+	require.Same(t, captured, resp.RawResponse)
+
+	page, err := client.NewListPropertiesOfCertificatesPager(nil).NextPage(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "2", page.RawResponse.Header.Get("x-ms-request-id"))
+}
+
+func TestCertificateOperationErrorClassify(t *testing.T) {
+	for _, test := range []struct {
+		err       *CertificateOperationError
+		category  IssuerErrorCategory
+		retryable bool
+		code      string
+	}{
+		{
+			err:       &CertificateOperationError{Code: to.Ptr("PendingValidation"), Message: to.Ptr("The order is pending validation")},
+			category:  IssuerErrorCategoryValidationPending,
+			retryable: true,
+			code:      "PendingValidation",
+		},
+		{
+			err: &CertificateOperationError{
+				Code:       to.Ptr("IssuerError"),
+				Message:    to.Ptr("The issuer failed the request"),
+				InnerError: &CertificateOperationError{Code: to.Ptr("DCVFailed"), Message: to.Ptr("Domain control validation failed for contoso.com")},
+			},
+			category: IssuerErrorCategoryDomainNotVerified,
+			code:     "DCVFailed",
+		},
+		{
+			err:      &CertificateOperationError{Code: to.Ptr("IssuerError"), Message: to.Ptr("Order failed: insufficient funds in account")},
+			category: IssuerErrorCategoryPayment,
+			code:     "IssuerError",
+		},
+		{
+			err:      &CertificateOperationError{Code: to.Ptr("Unauthorized"), Message: to.Ptr("Invalid API key")},
+			category: IssuerErrorCategoryIssuerCredentials,
+			code:     "Unauthorized",
+		},
+		{
+			err:      &CertificateOperationError{Code: to.Ptr("BadParameter"), Message: to.Ptr("Key size 1024 is not supported")},
+			category: IssuerErrorCategoryInvalidRequest,
+			code:     "BadParameter",
+		},
+		{
+			err:       &CertificateOperationError{Code: to.Ptr("IssuerError"), Message: to.Ptr("The request timed out")},
+			category:  IssuerErrorCategoryTransient,
+			retryable: true,
+			code:      "IssuerError",
+		},
+		{
+			err:      &CertificateOperationError{Code: to.Ptr("IssuerError"), InnerError: &CertificateOperationError{Code: to.Ptr("E42")}},
+			category: IssuerErrorCategoryUnknown,
+			code:     "E42",
+		},
+		{
+			category: IssuerErrorCategoryUnknown,
+		},
+	} {
+		c := test.err.Classify()
+		require.Equal(t, test.category, c.Category)
+		require.Equal(t, test.retryable, c.Retryable)
+		require.Equal(t, test.code, c.Code)
+		require.Contains(t, PossibleIssuerErrorCategoryValues(), c.Category)
+	}
+}
+
+func TestListIncludePending(t *testing.T) {
+	var includePending []string
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		includePending = append(includePending, req.URL.Query().Get("includePending"))
+		return jsonResponse(http.StatusOK, `{"value":[
+			{"id":"`+fakeKvURL+`certificates/issued","x5t":"AQID","attributes":{"enabled":true}},
+			{"id":"`+fakeKvURL+`certificates/pending","attributes":{"enabled":false}}
+		]}`)
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	certs, err := client.NewListPropertiesOfCertificatesPager(&ListPropertiesOfCertificatesOptions{IncludePending: to.Ptr(true)}).NextPage(context.Background())
+	require.NoError(t, err)
+	require.Len(t, certs.Certificates, 2)
+	require.Equal(t, CertificateStatusIssued, *certs.Certificates[0].Status)
+	require.Equal(t, CertificateStatusPending, *certs.Certificates[1].Status)
+
+	deleted, err := client.NewListDeletedCertificatesPager(&ListDeletedCertificatesOptions{IncludePending: to.Ptr(false)}).NextPage(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, CertificateStatusPending, *deleted.DeletedCertificates[1].Status)
+
+	_, err = client.NewListPropertiesOfCertificatesPager(nil).NextPage(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"true", "false", ""}, includePending)
+}
+
+func TestPolicyValidate(t *testing.T) {
+	for _, test := range []struct {
+		policy Policy
+		err    string
+	}{
+		{policy: NewDefaultCertificatePolicy()},
+		{policy: NewSelfSignedPolicy("CN=contoso.com", 12)},
+		{policy: Policy{KeyType: to.Ptr(KeyTypeEC), KeySize: to.Ptr(int32(256)), KeyCurveName: to.Ptr(KeyCurveNameP256)}},
+		{policy: Policy{LifetimeActions: []*LifetimeAction{{Action: to.Ptr(PolicyActionAutoRenew), DaysBeforeExpiry: to.Ptr(int32(30))}}}},
+		{
+			policy: Policy{KeyType: to.Ptr(KeyTypeEC), KeySize: to.Ptr(int32(2048))},
+			err:    "KeySize 2048 isn't valid for key type EC",
+		},
+		{
+			policy: Policy{KeyType: to.Ptr(KeyTypeECHSM), KeySize: to.Ptr(int32(384)), KeyCurveName: to.Ptr(KeyCurveNameP256)},
+			err:    "KeySize 384 doesn't match KeyCurveName P-256",
+		},
+		{
+			policy: Policy{KeyType: to.Ptr(KeyTypeRSA), KeySize: to.Ptr(int32(256))},
+			err:    "KeySize 256 isn't valid for key type RSA",
+		},
+		{
+			policy: Policy{KeyType: to.Ptr(KeyTypeRSA), KeyCurveName: to.Ptr(KeyCurveNameP384)},
+			err:    "curves apply only to EC keys",
+		},
+		{
+			policy: Policy{X509Properties: &X509CertificateProperties{Subject: to.Ptr(""), SubjectAlternativeNames: &SubjectAlternativeNames{}}},
+			err:    "needs a Subject or SubjectAlternativeNames",
+		},
+		{
+			policy: Policy{LifetimeActions: []*LifetimeAction{{Action: to.Ptr(PolicyActionEmailContacts), LifetimePercentage: to.Ptr(int32(100))}}},
+			err:    "LifetimeActions[0].LifetimePercentage is 100",
+		},
+		{
+			policy: Policy{
+				X509Properties:  &X509CertificateProperties{Subject: to.Ptr("CN=contoso.com"), ValidityInMonths: to.Ptr(int32(1))},
+				LifetimeActions: []*LifetimeAction{{Action: to.Ptr(PolicyActionAutoRenew), DaysBeforeExpiry: to.Ptr(int32(30))}},
+			},
+			err: "must be at most 27",
+		},
+	} {
+		err := test.policy.Validate()
+		if test.err == "" {
+			require.NoError(t, err)
+			continue
+		}
+		require.ErrorIs(t, err, ErrInvalidPolicy)
+		require.Contains(t, err.Error(), test.err)
+	}
+
+	requests := 0
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		requests++
+		return jsonResponse(http.StatusOK, `{}`)
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	invalid := Policy{KeyType: to.Ptr(KeyTypeEC), KeySize: to.Ptr(int32(2048))}
+
+	_, err = client.BeginCreateCertificate(context.Background(), "cert", invalid, nil)
+	require.ErrorIs(t, err, ErrInvalidPolicy)
+	_, err = client.UpdateCertificatePolicy(context.Background(), "cert", invalid, nil)
+	require.ErrorIs(t, err, ErrInvalidPolicy)
+	require.Zero(t, requests)
+
+	_, err = client.UpdateCertificatePolicy(context.Background(), "cert", invalid, &UpdateCertificatePolicyOptions{SkipPolicyValidation: true})
+	require.NoError(t, err)
+	require.NotZero(t, requests)
+}
+
+func TestAddAndRemoveContacts(t *testing.T) {
+	// the fake service stores the contacts as JSON; etag is its ETag, or "" to omit it
+	stored := `[{"email":"admin@contoso.com","name":"Admin"}]`
+	etag := ""
+	var methods []string
+	// onGet is called before each GET, to simulate other clients
+	onGet := func() {}
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		methods = append(methods, req.Method)
+		switch req.Method {
+		case http.MethodGet:
+			onGet()
+			if stored == "" {
+				return jsonResponse(http.StatusNotFound, `{"error":{"code":"ContactsNotFound"}}`)
+			}
+		case http.MethodPut:
+			if ifMatch := req.Header.Get("If-Match"); ifMatch != etag {
+				return jsonResponse(http.StatusPreconditionFailed, `{"error":{"code":"PreconditionFailed"}}`)
+			}
+			var body struct{ Contacts json.RawMessage }
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			stored = string(body.Contacts)
+		case http.MethodDelete:
+			stored = ""
+			return jsonResponse(http.StatusOK, `{"id":"`+fakeKvURL+`certificates/contacts"}`)
+		}
+		resp := jsonResponse(http.StatusOK, `{"id":"`+fakeKvURL+`certificates/contacts","contacts":`+stored+`}`)
+		if etag != "" {
+			resp.Header.Set("ETag", etag)
+		}
+		return resp
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	emails := func(contacts []*Contact) []string {
+		var ret []string
+		for _, c := range contacts {
+			ret = append(ret, *c.Email)
+		}
+		return ret
+	}
+
+	// without an ETag, the contacts are read again before writing
+	added, err := client.AddContacts(context.Background(), []*Contact{{Email: to.Ptr("ops@contoso.com")}, {Email: to.Ptr("ADMIN@contoso.com"), Name: to.Ptr("Admin"), Phone: to.Ptr("555")}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ADMIN@contoso.com", "ops@contoso.com"}, emails(added.ContactList))
+	require.Equal(t, "555", *added.ContactList[0].Phone)
+	require.Equal(t, []string{http.MethodGet, http.MethodGet, http.MethodPut}, methods)
+
+	// adding contacts that are already there doesn't write
+	methods = nil
+	_, err = client.AddContacts(context.Background(), []*Contact{{Email: to.Ptr("ops@contoso.com")}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{http.MethodGet}, methods)
+
+	// another client adds a contact between the reads, so the update starts over and keeps it
+	methods = nil
+	gets := 0
+	onGet = func() {
+		if gets++; gets == 2 {
+			stored = `[{"email":"admin@contoso.com"},{"email":"ops@contoso.com"},{"email":"sec@contoso.com"}]`
+		}
+	}
+	removed, err := client.RemoveContacts(context.Background(), []string{"Ops@Contoso.com"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"admin@contoso.com", "sec@contoso.com"}, emails(removed.ContactList))
+	require.Equal(t, []string{http.MethodGet, http.MethodGet, http.MethodGet, http.MethodGet, http.MethodPut}, methods)
+	onGet = func() {}
+
+	// with an ETag, a write the service rejects is retried, and a client that always changes the contacts
+	// makes the update fail
+	etag = `"1"`
+	methods = nil
+	respond := transport.respond
+	transport.respond = func(respond func(*http.Request) *http.Response) func(*http.Request) *http.Response {
+		return func(req *http.Request) *http.Response {
+			resp := respond(req)
+			if req.Method == http.MethodGet {
+				// another client changes the contacts right after the read
+				etag += "x"
+			}
+			return resp
+		}
+	}(transport.respond)
+	_, err = client.AddContacts(context.Background(), []*Contact{{Email: to.Ptr("new@contoso.com")}}, nil)
+	require.ErrorIs(t, err, ErrContactsChanged)
+	require.Equal(t, []string{http.MethodGet, http.MethodPut, http.MethodGet, http.MethodPut, http.MethodGet, http.MethodPut}, methods)
+
+	// removing every contact deletes them
+	transport.respond = respond
+	etag = ""
+	methods = nil
+	removed, err = client.RemoveContacts(context.Background(), []string{"admin@contoso.com", "sec@contoso.com"}, nil)
+	require.NoError(t, err)
+	require.Empty(t, removed.ContactList)
+	require.Equal(t, http.MethodDelete, methods[len(methods)-1])
+
+	// a vault without contacts has none to remove, and gets the added ones
+	methods = nil
+	added, err = client.AddContacts(context.Background(), []*Contact{{Email: to.Ptr("ops@contoso.com")}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"ops@contoso.com"}, emails(added.ContactList))
+}
+
+func TestDownloadCertificate(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	caCER, err := x509.CreateCertificate(rand.Reader, &caTemplate, &caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafTemplate := x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"contoso.com"},
+	}
+	leafCER, err := x509.CreateCertificate(rand.Reader, &leafTemplate, &caTemplate, &leafKey.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(leafKey)
+	require.NoError(t, err)
+
+	// the chain's CA comes before the leaf
+	secret := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caCER})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafCER})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		if strings.HasPrefix(req.URL.Path, "/secrets/") {
+			body, err := json.Marshal(map[string]string{"value": secret, "contentType": string(CertificateContentTypePEM)})
+			require.NoError(t, err)
+			return jsonResponse(http.StatusOK, string(body))
+		}
+		return jsonResponse(http.StatusOK, `{"id":"`+fakeKvURL+`certificates/cert/v1","sid":"`+fakeKvURL+`secrets/cert/v1","cer":"`+base64.StdEncoding.EncodeToString(leafCER)+`"}`)
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	resp, err := client.DownloadCertificate(context.Background(), "cert", nil)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{leafCER, caCER}, resp.Certificate.Certificate)
+	require.Equal(t, []string{"contoso.com"}, resp.Certificate.Leaf.DNSNames)
+	require.True(t, leafKey.Equal(resp.Certificate.PrivateKey))
+	require.Equal(t, CertificateContentTypePEM, resp.ContentType)
+
+	// the PEM is ready for tls.LoadX509KeyPair
+	pair, err := tls.X509KeyPair(resp.PEM, resp.PEM)
+	require.NoError(t, err)
+	require.Equal(t, resp.Certificate.Certificate, pair.Certificate)
+
+	// a certificate whose key isn't exportable has none in its secret
+	secret = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafCER}))
+	_, err = client.DownloadCertificate(context.Background(), "cert", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no private key")
+}
+
+func TestRotateIssuerCredentials(t *testing.T) {
+	// storedAccount is the account ID of the fake issuer, which keeps it unless rejectAccount is set
+	storedAccount := "old"
+	rejectAccount := false
+	var methods []string
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		methods = append(methods, req.Method)
+		if req.Method == http.MethodPatch {
+			var body struct {
+				Credentials struct {
+					AccountID string `json:"account_id"`
+					Password  string `json:"pwd"`
+				}
+				Provider *string
+			}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			// only the credentials are updated
+			require.Nil(t, body.Provider)
+			require.Equal(t, "secret", body.Credentials.Password)
+			if !rejectAccount {
+				storedAccount = body.Credentials.AccountID
+			}
+		}
+		return jsonResponse(http.StatusOK, `{"id":"`+fakeKvURL+`certificates/issuers/issuer","provider":"Test","credentials":{"account_id":"`+storedAccount+`"},"attributes":{"enabled":true}}`)
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	creds := IssuerCredentials{AccountID: to.Ptr("new"), Password: to.Ptr("secret")}
+
+	resp, err := client.RotateIssuerCredentials(context.Background(), "issuer", creds, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{http.MethodPatch, http.MethodGet}, methods)
+	require.True(t, resp.AccountIDAccepted)
+	require.False(t, resp.Verified)
+	require.NoError(t, resp.VerificationError)
+	require.Equal(t, "issuer", *resp.Name)
+
+	var verified Issuer
+	resp, err = client.RotateIssuerCredentials(context.Background(), "issuer", creds, &RotateIssuerCredentialsOptions{
+		Verify: func(ctx context.Context, issuer Issuer, credentials IssuerCredentials) error {
+			verified = issuer
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Verified)
+	require.Equal(t, "Test", *verified.Provider)
+
+	// a rejection by the provider is reported, not returned
+	rejected := errors.New("invalid API key")
+	rejectAccount = true
+	resp, err = client.RotateIssuerCredentials(context.Background(), "issuer", IssuerCredentials{AccountID: to.Ptr("newer"), Password: to.Ptr("secret")}, &RotateIssuerCredentialsOptions{
+		Verify: func(context.Context, Issuer, IssuerCredentials) error { return rejected },
+	})
+	require.NoError(t, err)
+	require.False(t, resp.AccountIDAccepted)
+	require.False(t, resp.Verified)
+	require.ErrorIs(t, resp.VerificationError, rejected)
+
+	methods = nil
+	_, err = client.RotateIssuerCredentials(context.Background(), "issuer", IssuerCredentials{AccountID: to.Ptr("new")}, nil)
+	require.Error(t, err)
+	require.Empty(t, methods)
+}
+
+func TestExpiryWatcher(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	// expiries are the expiry of each certificate, and disabled the names of disabled ones
+	expiries := map[string]time.Time{
+		"expired": now.Add(-day),
+		"soon":    now.Add(10 * day),
+		"later":   now.Add(60 * day),
+		"off":     now.Add(day),
+	}
+	disabled := map[string]bool{"off": true}
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		var items []string
+		for _, name := range []string{"expired", "soon", "later", "off"} {
+			items = append(items, fmt.Sprintf(`{"id":"%scertificates/%s","x5t":"AQID","attributes":{"enabled":%t,"exp":%d}}`,
+				fakeKvURL, name, !disabled[name], expiries[name].Unix()))
+		}
+		// a pending certificate has no expiry
+		items = append(items, `{"id":"`+fakeKvURL+`certificates/pending","attributes":{"enabled":true}}`)
+		return jsonResponse(http.StatusOK, `{"value":[`+strings.Join(items, ",")+`]}`)
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	summary := func(events []ExpiryEvent) map[string]ExpiryEventType {
+		ret := map[string]ExpiryEventType{}
+		for _, e := range events {
+			ret[e.Name] = e.Type
+		}
+		return ret
+	}
+
+	w := NewExpiryWatcher(client, nil)
+	w.now = func() time.Time { return now }
+	events, err := w.Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]ExpiryEventType{"expired": ExpiryEventTypeExpired, "soon": ExpiryEventTypeNearExpiry}, summary(events))
+	require.True(t, expiries["soon"].Equal(events[1].ExpiresOn))
+
+	// events are reported once
+	events, err = w.Check(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, events)
+
+	// until a certificate expires, or is renewed and nears expiry again
+	w.now = func() time.Time { return now.Add(11 * day) }
+	expiries["expired"] = now.Add(20 * day)
+	events, err = w.Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]ExpiryEventType{"expired": ExpiryEventTypeNearExpiry, "soon": ExpiryEventTypeExpired}, summary(events))
+
+	// Run sends events on the channel, and closes it when it returns
+	w = NewExpiryWatcher(client, &ExpiryWatcherOptions{Threshold: 90 * day, IncludeDisabled: true})
+	w.now = func() time.Time { return now.Add(-30 * day) }
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	var received []ExpiryEvent
+	for i := 0; i < 4; i++ {
+		received = append(received, <-w.Events())
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	_, open := <-w.Events()
+	require.False(t, open)
+	require.Len(t, summary(received), 4)
+
+	// or passes them to OnEvent
+	var calls []ExpiryEvent
+	ctx, cancel = context.WithCancel(context.Background())
+	w = NewExpiryWatcher(client, &ExpiryWatcherOptions{OnEvent: func(e ExpiryEvent) {
+		calls = append(calls, e)
+		if len(calls) == 2 {
+			cancel()
+		}
+	}})
+	w.now = func() time.Time { return now }
+	require.ErrorIs(t, w.Run(ctx), context.Canceled)
+	require.Len(t, calls, 2)
+}
+
+func TestAPIVersion(t *testing.T) {
+	var apiVersions []string
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		apiVersions = append(apiVersions, req.URL.Query().Get("api-version"))
+		return jsonResponse(http.StatusOK, `{"id":"`+fakeKvURL+`certificates/cert/1"}`)
+	}}
+	newClient := func(apiVersion string) (*Client, error) {
+		return NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{
+			ClientOptions: azcore.ClientOptions{Transport: transport},
+			APIVersion:    apiVersion,
+		})
+	}
+
+	for _, v := range []string{"", "7.2", "7.3", "7.4"} {
+		apiVersions = nil
+		client, err := newClient(v)
+		require.NoError(t, err)
+		_, err = client.GetCertificate(context.Background(), "cert", nil)
+		require.NoError(t, err)
+		want := v
+		if want == "" {
+			want = "7.4"
+		}
+		require.Equal(t, []string{want}, apiVersions)
+	}
+
+	// options the pinned version doesn't support are rejected without sending a request
+	client, err := newClient("7.3")
+	require.NoError(t, err)
+	apiVersions = nil
+	_, err = client.ImportCertificate(context.Background(), "cert", []byte("cert"), &ImportCertificateOptions{PreserveCertOrder: to.Ptr(true)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "PreserveCertOrder")
+	require.Empty(t, apiVersions)
+
+	_, err = newClient("2016-10-01")
+	require.Error(t, err)
+}
+
+func TestResumeTokens(t *testing.T) {
+	var created bool
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		switch {
+		case req.Method != http.MethodGet:
+		case req.URL.Path == "/certificates/cert/pending" && created:
+			return jsonResponse(http.StatusOK, `{"id":"https://fakekvurl.vault.azure.net/certificates/cert/pending","status":"completed"}`)
+		case req.URL.Path == "/certificates/cert/pending":
+			return jsonResponse(http.StatusOK, `{"id":"https://fakekvurl.vault.azure.net/certificates/cert/pending","status":"inProgress"}`)
+		case req.URL.Path == "/certificates/cert/" || req.URL.Path == "/certificates/cert":
+			return jsonResponse(http.StatusOK, `{"id":"https://fakekvurl.vault.azure.net/certificates/cert/v1"}`)
+		case req.URL.Path == "/deletedcertificates/cert":
+			return jsonResponse(http.StatusOK, `{"id":"https://fakekvurl.vault.azure.net/certificates/cert/v1","recoveryId":"https://fakekvurl.vault.azure.net/deletedcertificates/cert"}`)
+		}
+		t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		return nil
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	op, err := client.GetCertificateOperation(ctx, "cert", nil)
+	require.NoError(t, err)
+	token, err := op.ResumeToken()
+	require.NoError(t, err)
+	// the format is pinned, because tokens must be resumable by later versions of the module
+	require.Equal(t, `{"version":1,"operation":"create","certificate":"cert","pollURL":"https://fakekvurl.vault.azure.net/certificates/cert/pending"}`, token)
+
+	poller, err := client.BeginCreateCertificate(ctx, "cert", Policy{}, &BeginCreateCertificateOptions{ResumeToken: token})
+	require.NoError(t, err)
+	require.False(t, poller.Done())
+	_, err = poller.Poll(ctx)
+	require.NoError(t, err)
+	require.False(t, poller.Done())
+	created = true
+	resp, err := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "https://fakekvurl.vault.azure.net/certificates/cert/v1", *resp.ID)
+
+	// tokens of pollers remain resumable
+	created = false
+	legacy := `{"type":"CreateCertificateResponse","token":{"PollURL":"https://fakekvurl.vault.azure.net/certificates/cert/pending","Status":"inProgress"}}`
+	poller, err = client.BeginCreateCertificate(ctx, "cert", Policy{}, &BeginCreateCertificateOptions{ResumeToken: legacy})
+	require.NoError(t, err)
+	require.False(t, poller.Done())
+
+	delPoller, err := client.BeginDeleteCertificate(ctx, "cert", &BeginDeleteCertificateOptions{ResumeToken: DeleteCertificateResumeToken("cert")})
+	require.NoError(t, err)
+	delResp, err := delPoller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "https://fakekvurl.vault.azure.net/deletedcertificates/cert", *delResp.RecoveryID)
+
+	recPoller, err := client.BeginRecoverDeletedCertificate(ctx, "cert", &BeginRecoverDeletedCertificateOptions{ResumeToken: RecoverDeletedCertificateResumeToken("cert")})
+	require.NoError(t, err)
+	recResp, err := recPoller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "https://fakekvurl.vault.azure.net/certificates/cert/v1", *recResp.ID)
+
+	for _, test := range []struct {
+		name, token, contains string
+	}{
+		{"newer version", `{"version":2,"operation":"delete","certificate":"cert","newField":true}`, "newer version"},
+		{"other operation", DeleteCertificateResumeToken("cert"), "delete operation"},
+		{"other certificate", `{"version":1,"operation":"create","certificate":"other","pollURL":"https://fakekvurl.vault.azure.net/certificates/other/pending"}`, `"other"`},
+		{"other vault", `{"version":1,"operation":"create","certificate":"cert","pollURL":"https://attacker.example.com/certificates/cert/pending"}`, "attacker.example.com"},
+		{"invalid", "not a token", "invalid resume token"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := client.BeginCreateCertificate(ctx, "cert", Policy{}, &BeginCreateCertificateOptions{ResumeToken: test.token})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.contains)
+		})
+	}
+
+	// unknown fields of a supported version are ignored
+	_, err = client.BeginDeleteCertificate(ctx, "cert", &BeginDeleteCertificateOptions{ResumeToken: `{"version":1,"operation":"delete","certificate":"cert","newField":true}`})
+	require.NoError(t, err)
+
+	_, err = Operation{}.ResumeToken()
+	require.Error(t, err)
+}
+
+func TestExternalCARoundTrip(t *testing.T) {
+	// Key Vault's key and pending CSR
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: "contoso.com"}}, key)
+	require.NoError(t, err)
+
+	// the external certificate authority
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	sign := func(notAfter time.Time) []byte {
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "contoso.com"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     notAfter,
+		}, ca, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		return der
+	}
+	leafDER := sign(time.Now().Add(time.Hour))
+	pemOf := func(typ string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	}
+
+	status := "inProgress"
+	var merged [][]byte
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/certificates/cert/pending":
+			return jsonResponse(http.StatusOK, fmt.Sprintf(`{"id":"https://fakekvurl.vault.azure.net/certificates/cert/pending","status":%q,"csr":%q}`,
+				status, base64.StdEncoding.EncodeToString(csrDER)))
+		case req.Method == http.MethodPost && req.URL.Path == "/certificates/cert/pending/merge":
+			var body struct {
+				X5C [][]byte `json:"x5c"`
+			}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			merged = body.X5C
+			return jsonResponse(http.StatusCreated, `{"id":"https://fakekvurl.vault.azure.net/certificates/cert/v1"}`)
+		}
+		t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		return nil
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	csr, err := client.GetPendingCSR(ctx, "cert", nil)
+	require.NoError(t, err)
+	require.Equal(t, "contoso.com", csr.Request.Subject.CommonName)
+	block, _ := pem.Decode(csr.CSR)
+	require.Equal(t, "CERTIFICATE REQUEST", block.Type)
+	require.Equal(t, csrDER, block.Bytes)
+
+	// the certificate for the CSR's key is sent first
+	chain := append(pemOf("CERTIFICATE", caDER), pemOf("CERTIFICATE", leafDER)...)
+	resp, err := client.CompleteWithSignedCertificate(ctx, "cert", chain, nil)
+	require.NoError(t, err)
+	require.Equal(t, "https://fakekvurl.vault.azure.net/certificates/cert/v1", *resp.ID)
+	require.Equal(t, [][]byte{leafDER, caDER}, merged)
+
+	merged = nil
+	for _, test := range []struct {
+		name, contains string
+		chain          []byte
+	}{
+		{"private key", "private key", append(pemOf("CERTIFICATE", leafDER), pemOf("EC PRIVATE KEY", []byte("key"))...)},
+		{"other key", "no certificate for the key", pemOf("CERTIFICATE", caDER)},
+		{"expired", "valid from", pemOf("CERTIFICATE", sign(time.Now().Add(-time.Minute)))},
+		{"not PEM", "no CERTIFICATE block", leafDER},
+		{"other block", "unexpected PEM block", pemOf("CERTIFICATE REQUEST", csrDER)},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := client.CompleteWithSignedCertificate(ctx, "cert", test.chain, nil)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.contains)
+		})
+	}
+	require.Nil(t, merged)
+
+	status = "completed"
+	_, err = client.GetPendingCSR(ctx, "cert", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no pending operation")
+}
+
+func TestBuildInventory(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Now().Truncate(time.Second)
+	// a CA-issued certificate whose CER names its issuer
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ca := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "Contoso CA"}, NotAfter: now.Add(365 * day), IsCA: true, BasicConstraintsValid: true}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cer, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "contoso.com"}, NotAfter: now.Add(10 * day)}, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+
+	expiries := map[string]time.Time{
+		"expired": now.Add(-day - time.Hour),
+		"soon":    now.Add(10*day + time.Hour),
+		"later":   now.Add(200*day + time.Hour),
+		"off":     now.Add(day + time.Hour),
+	}
+	names := []string{"later", "soon", "off", "expired"}
+	var gets []string
+	var mu sync.Mutex
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		path := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+		if len(path) == 1 {
+			var items []string
+			for _, name := range names {
+				items = append(items, fmt.Sprintf(`{"id":"%scertificates/%s","x5t":"AQID","attributes":{"enabled":%t,"exp":%d}}`,
+					fakeKvURL, name, name != "off", expiries[name].Unix()))
+			}
+			return jsonResponse(http.StatusOK, `{"value":[`+strings.Join(items, ",")+`]}`)
+		}
+		name := path[1]
+		mu.Lock()
+		gets = append(gets, name)
+		mu.Unlock()
+		policy := `{"key_props":{"kty":"RSA","key_size":2048},"issuer":{"name":"Self"}}`
+		var cerField string
+		if name == "soon" {
+			policy = `{"key_props":{"kty":"EC","crv":"P-256"},"issuer":{"name":"Contoso"}}`
+			cerField = fmt.Sprintf(`"cer":"%s",`, base64.StdEncoding.EncodeToString(cer))
+		}
+		return jsonResponse(http.StatusOK, fmt.Sprintf(`{"id":"%scertificates/%s/v-%s",%s"attributes":{"enabled":%t,"exp":%d},"policy":%s}`,
+			fakeKvURL, name, name, cerField, name != "off", expiries[name].Unix(), policy))
+	}}
+	client, err := NewClient(fakeKvURL, NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	resp, err := client.BuildInventory(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, resp.Certificates, 3)
+	expired, soon, later := resp.Certificates[0], resp.Certificates[1], resp.Certificates[2]
+	require.Equal(t, "expired", expired.Name)
+	require.Equal(t, -2, expired.DaysRemaining)
+	require.Equal(t, CertificateInventoryEntry{
+		Name:          "soon",
+		Version:       "v-soon",
+		Enabled:       true,
+		ExpiresOn:     soon.ExpiresOn,
+		DaysRemaining: 10,
+		Issuer:        "CN=Contoso CA",
+		IssuerName:    "Contoso",
+		KeyType:       "EC",
+		KeyCurveName:  "P-256",
+	}, soon)
+	require.True(t, expiries["soon"].Equal(*soon.ExpiresOn))
+	require.Equal(t, "later", later.Name)
+	require.Equal(t, "Self", later.IssuerName)
+	require.Equal(t, int32(2048), later.KeySize)
+	require.Empty(t, later.Issuer)
+
+	// the options select certificates before they're retrieved
+	gets = nil
+	resp, err = client.BuildInventory(ctx, &BuildInventoryOptions{
+		ExpiresWithin:   90 * day,
+		IncludeDisabled: true,
+		Filter:          func(item *CertificateItem) bool { return *item.Properties.Name != "expired" },
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Certificates, 2)
+	require.Equal(t, "off", resp.Certificates[0].Name)
+	require.False(t, resp.Certificates[0].Enabled)
+	require.Equal(t, "soon", resp.Certificates[1].Name)
+	sort.Strings(gets)
+	require.Equal(t, []string{"off", "soon"}, gets)
+
+	var b bytes.Buffer
+	require.NoError(t, resp.Certificates.WriteCSV(&b))
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Equal(t, "name,version,enabled,expiresOn,daysRemaining,issuer,issuerName,keyType,keySize,keyCurveName", lines[0])
+	require.Equal(t, fmt.Sprintf("soon,v-soon,true,%s,10,CN=Contoso CA,Contoso,EC,,P-256", expiries["soon"].UTC().Format(time.RFC3339)), lines[2])
+
+	b.Reset()
+	require.NoError(t, resp.Certificates.WriteJSON(&b))
+	var decoded CertificateInventory
+	require.NoError(t, json.Unmarshal(b.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+	require.Equal(t, "CN=Contoso CA", decoded[1].Issuer)
+
+	b.Reset()
+	require.NoError(t, CertificateInventory(nil).WriteJSON(&b))
+	require.Equal(t, "[]\n", b.String())
+}
@@ -123,3 +123,22 @@ func PossibleWellKnownIssuerNamesValues() []WellKnownIssuerNames {
 		WellKnownIssuerNamesUnknown,
 	}
 }
+
+// CertificateStatus is the status of a listed certificate.
+type CertificateStatus string
+
+const (
+	// CertificateStatusIssued is a certificate with an issued version.
+	CertificateStatusIssued CertificateStatus = "Issued"
+	// CertificateStatusPending is a certificate whose first version is still being created, for example
+	// while an issuer processes its certificate signing request. It's listed only with IncludePending.
+	CertificateStatusPending CertificateStatus = "Pending"
+)
+
+// PossibleCertificateStatusValues returns a slice of all possible CertificateStatus values.
+func PossibleCertificateStatusValues() []CertificateStatus {
+	return []CertificateStatus{
+		CertificateStatusIssued,
+		CertificateStatusPending,
+	}
+}
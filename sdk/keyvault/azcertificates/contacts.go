@@ -0,0 +1,205 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// ErrContactsChanged is returned by AddContacts and RemoveContacts when another client kept changing the
+// certificate contacts while they tried to update them.
+var ErrContactsChanged = errors.New("the certificate contacts changed during the update")
+
+// contactsUpdateAttempts is how many times AddContacts and RemoveContacts read and write the contacts
+// before giving up with ErrContactsChanged
+const contactsUpdateAttempts = 3
+
+// AddContactsOptions contains optional parameters for Client.AddContacts
+type AddContactsOptions struct {
+	// placeholder for future optional parameters.
+}
+
+// AddContactsResponse contains response fields for Client.AddContacts
+type AddContactsResponse struct {
+	// Contacts are the vault's contacts after the update.
+	Contacts
+
+	// RawResponse is the HTTP response of the last request.
+	RawResponse *http.Response
+}
+
+// AddContacts adds contacts to the vault's certificate contacts, keeping the others. A contact whose email
+// address, compared case insensitively, is already a contact's replaces it. Unlike SetContacts, which replaces
+// the whole list, it reads the contacts and writes them back only if they haven't changed in between, using
+// If-Match with the ETag of the read when the service returns one, and retries a few times when they have,
+// so contacts set by other tools aren't lost. This operation requires the certificates/managecontacts
+// permission. Pass nil for options to accept default values.
+func (c *Client) AddContacts(ctx context.Context, contacts []*Contact, options *AddContactsOptions) (AddContactsResponse, error) {
+	updated, rawResp, err := c.updateContacts(ctx, func(current []*Contact) ([]*Contact, bool) {
+		next := make([]*Contact, len(current))
+		copy(next, current)
+		changed := false
+		for _, contact := range contacts {
+			if contact == nil {
+				continue
+			}
+			idx := indexOfContact(next, contact.Email)
+			if idx < 0 {
+				next = append(next, contact)
+				changed = true
+			} else if !contactsEqual(next[idx], contact) {
+				next[idx] = contact
+				changed = true
+			}
+		}
+		return next, changed
+	})
+	if err != nil {
+		return AddContactsResponse{}, err
+	}
+	return AddContactsResponse{Contacts: updated, RawResponse: rawResp}, nil
+}
+
+// RemoveContactsOptions contains optional parameters for Client.RemoveContacts
+type RemoveContactsOptions struct {
+	// placeholder for future optional parameters.
+}
+
+// RemoveContactsResponse contains response fields for Client.RemoveContacts
+type RemoveContactsResponse struct {
+	// Contacts are the vault's contacts after the update.
+	Contacts
+
+	// RawResponse is the HTTP response of the last request.
+	RawResponse *http.Response
+}
+
+// RemoveContacts removes the contacts having the email addresses, compared case insensitively, from the vault's
+// certificate contacts, keeping the others. It guards against concurrent changes like AddContacts does, and
+// deletes the contacts when none remain. This operation requires the certificates/managecontacts permission.
+// Pass nil for options to accept default values.
+func (c *Client) RemoveContacts(ctx context.Context, emails []string, options *RemoveContactsOptions) (RemoveContactsResponse, error) {
+	updated, rawResp, err := c.updateContacts(ctx, func(current []*Contact) ([]*Contact, bool) {
+		var next []*Contact
+		for _, contact := range current {
+			remove := false
+			for _, email := range emails {
+				remove = remove || (contact.Email != nil && strings.EqualFold(*contact.Email, email))
+			}
+			if !remove {
+				next = append(next, contact)
+			}
+		}
+		return next, len(next) != len(current)
+	})
+	if err != nil {
+		return RemoveContactsResponse{}, err
+	}
+	return RemoveContactsResponse{Contacts: updated, RawResponse: rawResp}, nil
+}
+
+// updateContacts writes the contacts modify returns for the current contacts, unless it reports no change,
+// retrying when the contacts change between the read and the write
+func (c *Client) updateContacts(ctx context.Context, modify func([]*Contact) ([]*Contact, bool)) (Contacts, *http.Response, error) {
+	for attempt := 0; attempt < contactsUpdateAttempts; attempt++ {
+		current, etag, rawResp, err := c.readContacts(ctx)
+		if err != nil {
+			return Contacts{}, nil, err
+		}
+		next, changed := modify(current.ContactList)
+		if !changed {
+			return current, rawResp, nil
+		}
+
+		writeCtx := ctx
+		if etag != "" {
+			writeCtx = runtime.WithHTTPHeader(ctx, http.Header{"If-Match": []string{etag}})
+		} else {
+			// without an ETag the service can't reject a stale write, so narrow the window for one
+			again, _, _, err := c.readContacts(ctx)
+			if err != nil {
+				return Contacts{}, nil, err
+			}
+			if !contactListsEqual(current.ContactList, again.ContactList) {
+				continue
+			}
+		}
+
+		var updated Contacts
+		if len(next) == 0 {
+			var resp DeleteContactsResponse
+			resp, err = c.DeleteContacts(writeCtx, nil)
+			// the deleted contacts are returned, and none remain
+			updated, rawResp = Contacts{ID: resp.ID}, resp.RawResponse
+		} else {
+			var resp SetContactsResponse
+			resp, err = c.SetContacts(writeCtx, next, nil)
+			updated, rawResp = resp.Contacts, resp.RawResponse
+		}
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusPreconditionFailed {
+			continue
+		}
+		if err != nil {
+			return Contacts{}, nil, err
+		}
+		return updated, rawResp, nil
+	}
+	return Contacts{}, nil, ErrContactsChanged
+}
+
+// readContacts gets the contacts and their ETag, which is empty when the service doesn't return one.
+// A vault without contacts has an empty list.
+func (c *Client) readContacts(ctx context.Context) (Contacts, string, *http.Response, error) {
+	resp, err := c.GetContacts(ctx, nil)
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+		return Contacts{}, "", respErr.RawResponse, nil
+	}
+	if err != nil {
+		return Contacts{}, "", nil, err
+	}
+	return resp.Contacts, resp.RawResponse.Header.Get("ETag"), resp.RawResponse, nil
+}
+
+// indexOfContact returns the index of the contact having the email address, or -1
+func indexOfContact(contacts []*Contact, email *string) int {
+	if email == nil {
+		return -1
+	}
+	for i, contact := range contacts {
+		if contact != nil && contact.Email != nil && strings.EqualFold(*contact.Email, *email) {
+			return i
+		}
+	}
+	return -1
+}
+
+func contactsEqual(a, b *Contact) bool {
+	eq := func(x, y *string) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && *x == *y)
+	}
+	return eq(a.Email, b.Email) && eq(a.Name, b.Name) && eq(a.Phone, b.Phone)
+}
+
+func contactListsEqual(a, b []*Contact) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !contactsEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+)
+
+// DownloadCertificateOptions contains optional parameters for Client.DownloadCertificate
+type DownloadCertificateOptions struct {
+	// Version of the certificate. The default is the latest version.
+	Version string
+}
+
+// DownloadCertificateResponse contains response fields for Client.DownloadCertificate
+type DownloadCertificateResponse struct {
+	// Certificate is the certificate chain, leaf first, and private key, ready for tls.Config.Certificates.
+	// Its Leaf is set.
+	Certificate tls.Certificate
+
+	// PEM is the certificate chain, leaf first, followed by the private key, PEM encoded, for software
+	// that reads certificates from files.
+	PEM []byte
+
+	// ContentType is the format Key Vault stores the certificate's secret in, which depends on the
+	// ContentType of the certificate's policy.
+	ContentType CertificateContentType
+}
+
+// DownloadCertificate gets a certificate with its private key, which Key Vault stores in the certificate's
+// backing secret in PKCS#12 or PEM format, and assembles them into a tls.Certificate, for example to serve
+// with an http.Server. The certificate's key must be exportable. This operation requires the certificates/get
+// and secrets/get permissions. Pass nil for options to accept default values.
+func (c *Client) DownloadCertificate(ctx context.Context, name string, options *DownloadCertificateOptions) (DownloadCertificateResponse, error) {
+	if options == nil {
+		options = &DownloadCertificateOptions{}
+	}
+	resp, err := c.GetCertificate(ctx, name, &GetCertificateOptions{Version: options.Version})
+	if err != nil {
+		return DownloadCertificateResponse{}, err
+	}
+	if resp.SecretID == nil {
+		return DownloadCertificateResponse{}, errors.New("the certificate has no secret ID")
+	}
+	value, contentType, err := c.getSecretValue(ctx, *resp.SecretID)
+	if err != nil {
+		return DownloadCertificateResponse{}, err
+	}
+	pemData, err := certificateSecretPEM(value, contentType)
+	if err != nil {
+		return DownloadCertificateResponse{}, err
+	}
+	cert, ordered, err := assembleCertificate(pemData)
+	if err != nil {
+		return DownloadCertificateResponse{}, err
+	}
+	if contentType == "" {
+		contentType = string(CertificateContentTypePKCS12)
+	}
+	return DownloadCertificateResponse{
+		Certificate: *cert,
+		PEM:         ordered,
+		ContentType: CertificateContentType(contentType),
+	}, nil
+}
+
+// assembleCertificate pairs the private key in pemData with the certificate it belongs to and returns them,
+// with the certificate first, as a tls.Certificate and as PEM. The certificates of a PKCS#12 secret can be in
+// any order, while TLS needs the leaf first.
+func assembleCertificate(pemData []byte) (*tls.Certificate, []byte, error) {
+	var certs []*pem.Block
+	var key *pem.Block
+	for rest := pemData; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, &pem.Block{Type: block.Type, Bytes: block.Bytes})
+		} else if key == nil && strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			// PKCS#12 attributes such as localKeyId aren't valid in a PEM private key
+			key = &pem.Block{Type: block.Type, Bytes: block.Bytes}
+		}
+	}
+	if len(certs) == 0 {
+		return nil, nil, errors.New("the certificate's secret contains no certificate")
+	}
+	if key == nil {
+		return nil, nil, errors.New("the certificate's secret contains no private key; the key of a certificate whose policy doesn't make it exportable can't be downloaded")
+	}
+	keyPEM := pem.EncodeToMemory(key)
+
+	var err error
+	for i := range certs {
+		ordered := []*pem.Block{certs[i]}
+		ordered = append(ordered, certs[:i]...)
+		ordered = append(ordered, certs[i+1:]...)
+		var certPEM []byte
+		for _, b := range ordered {
+			certPEM = append(certPEM, pem.EncodeToMemory(b)...)
+		}
+		var cert tls.Certificate
+		// X509KeyPair fails when the first certificate isn't the key's
+		if cert, err = tls.X509KeyPair(certPEM, keyPEM); err != nil {
+			continue
+		}
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return nil, nil, err
+		}
+		return &cert, append(certPEM, keyPEM...), nil
+	}
+	return nil, nil, err
+}
@@ -0,0 +1,176 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultExpiryWatcherInterval  = time.Hour
+	defaultExpiryWatcherThreshold = 30 * 24 * time.Hour
+	expiryWatcherEventsBuffer     = 16
+)
+
+// ExpiryEventType is the kind of an ExpiryEvent.
+type ExpiryEventType string
+
+const (
+	// ExpiryEventTypeNearExpiry means the certificate expires within the watcher's threshold.
+	ExpiryEventTypeNearExpiry ExpiryEventType = "NearExpiry"
+	// ExpiryEventTypeExpired means the certificate has expired.
+	ExpiryEventTypeExpired ExpiryEventType = "Expired"
+)
+
+// ExpiryEvent reports a certificate that's near expiry or expired.
+type ExpiryEvent struct {
+	// Type is the kind of event.
+	Type ExpiryEventType
+
+	// Name is the name of the certificate.
+	Name string
+
+	// ExpiresOn is when the latest version of the certificate expires.
+	ExpiresOn time.Time
+
+	// Properties are the properties of the latest version of the certificate.
+	Properties *Properties
+}
+
+// ExpiryWatcherOptions contains optional parameters for NewExpiryWatcher
+type ExpiryWatcherOptions struct {
+	// Interval is how often Run lists the certificates. The default is one hour.
+	Interval time.Duration
+
+	// Threshold is how long before a certificate expires it's reported as near expiry. The default is 30 days.
+	Threshold time.Duration
+
+	// IncludeDisabled makes the watcher report disabled certificates, which it otherwise ignores.
+	IncludeDisabled bool
+
+	// OnEvent, when set, is called with each event instead of sending it on the Events channel.
+	OnEvent func(ExpiryEvent)
+
+	// OnError is called when listing the certificates fails. Run tries again at the next interval.
+	OnError func(error)
+}
+
+// ExpiryWatcher periodically lists the certificates in a vault and reports the ones that are near expiry or
+// expired. Each event is reported once for a certificate version: a certificate is reported again only when
+// it expires after being reported near expiry, or when a new version with a different expiry is near expiry
+// in turn. Create one with NewExpiryWatcher.
+type ExpiryWatcher struct {
+	client  *Client
+	options ExpiryWatcherOptions
+	events  chan ExpiryEvent
+	now     func() time.Time
+
+	mu sync.Mutex
+	// reported holds the last event reported for each certificate
+	reported map[string]ExpiryEvent
+}
+
+// NewExpiryWatcher creates an ExpiryWatcher for the vault of client. It requires the certificates/list
+// permission. Pass nil for options to accept default values.
+func NewExpiryWatcher(client *Client, options *ExpiryWatcherOptions) *ExpiryWatcher {
+	if options == nil {
+		options = &ExpiryWatcherOptions{}
+	}
+	w := &ExpiryWatcher{
+		client:   client,
+		options:  *options,
+		events:   make(chan ExpiryEvent, expiryWatcherEventsBuffer),
+		now:      time.Now,
+		reported: map[string]ExpiryEvent{},
+	}
+	if w.options.Interval <= 0 {
+		w.options.Interval = defaultExpiryWatcherInterval
+	}
+	if w.options.Threshold <= 0 {
+		w.options.Threshold = defaultExpiryWatcherThreshold
+	}
+	return w
+}
+
+// Events returns the channel on which Run sends events when ExpiryWatcherOptions.OnEvent isn't set. Run closes it
+// when it returns. Run waits for events to be received, so they must be received promptly.
+func (w *ExpiryWatcher) Events() <-chan ExpiryEvent {
+	return w.events
+}
+
+// Run checks the certificates every ExpiryWatcherOptions.Interval, starting immediately, and delivers the events
+// until ctx is done. It then closes the Events channel and returns ctx's error. Run must be called only once.
+func (w *ExpiryWatcher) Run(ctx context.Context) error {
+	defer close(w.events)
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+	for {
+		events, err := w.Check(ctx)
+		if err != nil && w.options.OnError != nil && ctx.Err() == nil {
+			w.options.OnError(err)
+		}
+		for _, e := range events {
+			if w.options.OnEvent != nil {
+				w.options.OnEvent(e)
+				continue
+			}
+			select {
+			case w.events <- e:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check lists the certificates once and returns the events not reported before, without delivering them. It's
+// the step Run repeats, for callers that schedule checks themselves. When listing fails partway, Check returns
+// the events of the certificates listed before the failure along with the error.
+func (w *ExpiryWatcher) Check(ctx context.Context) ([]ExpiryEvent, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+	var events []ExpiryEvent
+	pager := w.client.NewListPropertiesOfCertificatesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return events, err
+		}
+		for _, item := range page.Certificates {
+			if item == nil || item.Properties == nil || item.Properties.Name == nil || item.Properties.ExpiresOn == nil {
+				continue
+			}
+			props := item.Properties
+			if !w.options.IncludeDisabled && props.Enabled != nil && !*props.Enabled {
+				continue
+			}
+			e := ExpiryEvent{Name: *props.Name, ExpiresOn: *props.ExpiresOn, Properties: props}
+			switch {
+			case !now.Before(e.ExpiresOn):
+				e.Type = ExpiryEventTypeExpired
+			case e.ExpiresOn.Sub(now) <= w.options.Threshold:
+				e.Type = ExpiryEventTypeNearExpiry
+			default:
+				continue
+			}
+			if last, ok := w.reported[e.Name]; ok && last.Type == e.Type && last.ExpiresOn.Equal(e.ExpiresOn) {
+				continue
+			}
+			w.reported[e.Name] = e
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
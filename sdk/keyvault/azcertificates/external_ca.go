@@ -0,0 +1,171 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// GetPendingCSROptions contains optional parameters for Client.GetPendingCSR
+type GetPendingCSROptions struct {
+	// placeholder for future optional parameters
+}
+
+// GetPendingCSRResponse contains response fields for Client.GetPendingCSR
+type GetPendingCSRResponse struct {
+	// CSR is the certificate signing request, PEM encoded, to send to the certificate authority.
+	CSR []byte
+
+	// Request is the parsed CSR, whose subject and names can be checked before sending it.
+	Request *x509.CertificateRequest
+
+	// Operation is the pending certificate operation.
+	Operation Operation
+}
+
+// GetPendingCSR gets the certificate signing request of a certificate waiting to be signed by a certificate authority
+// that isn't integrated with Key Vault, such as one created with a policy from NewExternallySignedPolicy. Send the CSR
+// to the certificate authority, then pass the certificate it issues to CompleteWithSignedCertificate. An error is
+// returned when the certificate has no pending operation. This operation requires the certificates/get permission.
+// Pass nil for options to accept default values.
+func (c *Client) GetPendingCSR(ctx context.Context, certificateName string, options *GetPendingCSROptions) (GetPendingCSRResponse, error) {
+	op, err := c.GetCertificateOperation(ctx, certificateName, nil)
+	if err != nil {
+		return GetPendingCSRResponse{}, err
+	}
+	csr, err := pendingCSR(certificateName, op.Operation)
+	if err != nil {
+		return GetPendingCSRResponse{}, err
+	}
+	return GetPendingCSRResponse{
+		CSR:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: op.CSR}),
+		Request:   csr,
+		Operation: op.Operation,
+	}, nil
+}
+
+// CompleteWithSignedCertificateOptions contains optional parameters for Client.CompleteWithSignedCertificate
+type CompleteWithSignedCertificateOptions struct {
+	// The attributes of the certificate (optional).
+	Properties *Properties
+}
+
+// CompleteWithSignedCertificateResponse contains response fields for Client.CompleteWithSignedCertificate
+type CompleteWithSignedCertificateResponse struct {
+	CertificateWithPolicy
+
+	// RawResponse is the HTTP response of the operation.
+	RawResponse *http.Response
+}
+
+// CompleteWithSignedCertificate completes the pending operation of a certificate, whose CSR was gotten with
+// GetPendingCSR, with the certificate the certificate authority issued for it. pemChain is the issued
+// certificate and, optionally, the certificates of its chain, PEM encoded. Before merging the chain with
+// MergeCertificate, the method checks that it has a certificate for the CSR's key, which it sends first, that
+// the certificate is currently valid and that pemChain has no private key, because Key Vault already has the key.
+// This operation requires the certificates/get and certificates/create permissions. Pass nil for options to
+// accept default values.
+func (c *Client) CompleteWithSignedCertificate(ctx context.Context, certificateName string, pemChain []byte, options *CompleteWithSignedCertificateOptions) (CompleteWithSignedCertificateResponse, error) {
+	if options == nil {
+		options = &CompleteWithSignedCertificateOptions{}
+	}
+	op, err := c.GetCertificateOperation(ctx, certificateName, nil)
+	if err != nil {
+		return CompleteWithSignedCertificateResponse{}, err
+	}
+	csr, err := pendingCSR(certificateName, op.Operation)
+	if err != nil {
+		return CompleteWithSignedCertificateResponse{}, err
+	}
+	chain, err := orderSignedChain(pemChain, csr.PublicKey, time.Now())
+	if err != nil {
+		return CompleteWithSignedCertificateResponse{}, err
+	}
+	resp, err := c.MergeCertificate(ctx, certificateName, chain, &MergeCertificateOptions{Properties: options.Properties})
+	if err != nil {
+		return CompleteWithSignedCertificateResponse{}, err
+	}
+	return CompleteWithSignedCertificateResponse{
+		CertificateWithPolicy: resp.CertificateWithPolicy,
+		RawResponse:           resp.RawResponse,
+	}, nil
+}
+
+// pendingCSR returns the parsed CSR of op, or an error when op isn't waiting for a signed certificate
+func pendingCSR(certificateName string, op Operation) (*x509.CertificateRequest, error) {
+	if op.Status == nil || *op.Status != "inProgress" {
+		status := "unknown"
+		if op.Status != nil {
+			status = *op.Status
+		}
+		return nil, fmt.Errorf("certificate %q has no pending operation; its operation's status is %s", certificateName, status)
+	}
+	if len(op.CSR) == 0 {
+		return nil, fmt.Errorf("the pending operation of certificate %q has no CSR", certificateName)
+	}
+	csr, err := x509.ParseCertificateRequest(op.CSR)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the CSR of certificate %q: %w", certificateName, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("the CSR of certificate %q has an invalid signature: %w", certificateName, err)
+	}
+	return csr, nil
+}
+
+// orderSignedChain returns the DER certificates of pemChain with the certificate for key first. It returns an error
+// when pemChain has anything other than certificates, has no certificate for key, or that certificate isn't valid at now.
+func orderSignedChain(pemChain []byte, key crypto.PublicKey, now time.Time) ([][]byte, error) {
+	var leaf *x509.Certificate
+	var others [][]byte
+	rest := pemChain
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if strings.Contains(block.Type, "PRIVATE KEY") {
+			return nil, errors.New("the chain has a private key; send only the certificates because Key Vault has the key")
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block %q; the chain must contain only certificates", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PEM certificate %d: %w", len(others)+1, err)
+		}
+		if leaf == nil && publicKeysEqual(cert.PublicKey, key) {
+			leaf = cert
+			continue
+		}
+		others = append(others, cert.Raw)
+	}
+	if leaf == nil && len(others) == 0 {
+		return nil, errors.New("no CERTIFICATE block in PEM data")
+	}
+	if leaf == nil {
+		return nil, errors.New("the chain has no certificate for the key of the pending CSR")
+	}
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return nil, fmt.Errorf("the signed certificate is valid from %s to %s", leaf.NotBefore.Format(time.RFC3339), leaf.NotAfter.Format(time.RFC3339))
+	}
+	return append([][]byte{leaf.Raw}, others...), nil
+}
+
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	k, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && k.Equal(b)
+}
@@ -13,3 +13,23 @@ import "github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 func (client *KeyVaultClient) Pipeline() runtime.Pipeline {
 	return client.pl
 }
+
+// DefaultAPIVersion is the service version the client uses unless another is set with NewKeyVaultClientWithAPIVersion.
+const DefaultAPIVersion = "7.4"
+
+// NewKeyVaultClientWithAPIVersion creates a new instance of KeyVaultClient that sends requests for apiVersion.
+func NewKeyVaultClientWithAPIVersion(pl runtime.Pipeline, apiVersion string) *KeyVaultClient {
+	return &KeyVaultClient{pl: pl, apiVersion: apiVersion}
+}
+
+// APIVersion returns the service version the client sends requests for.
+func (client *KeyVaultClient) APIVersion() string {
+	return client.version()
+}
+
+func (client *KeyVaultClient) version() string {
+	if client.apiVersion == "" {
+		return DefaultAPIVersion
+	}
+	return client.apiVersion
+}
@@ -104,6 +104,10 @@ type CertificateBundle struct {
 	// READ-ONLY; The management policy.
 	Policy *CertificatePolicy `json:"policy,omitempty" azure:"ro"`
 
+	// READ-ONLY; Specifies whether the certificate chain preserves its original order. The default value is false, which sets
+	// the leaf certificate at index 0.
+	PreserveCertOrder *bool `json:"preserveCertOrder,omitempty" azure:"ro"`
+
 	// READ-ONLY; The secret id.
 	Sid *string `json:"sid,omitempty" azure:"ro"`
 
@@ -119,6 +123,10 @@ type CertificateCreateParameters struct {
 	// The management policy for the certificate.
 	CertificatePolicy *CertificatePolicy `json:"policy,omitempty"`
 
+	// Specifies whether the certificate chain preserves its original order. The default value is false, which sets the leaf
+	// certificate at index 0.
+	PreserveCertOrder *bool `json:"preserveCertOrder,omitempty"`
+
 	// Application specific metadata in the form of key-value pairs.
 	Tags map[string]*string `json:"tags,omitempty"`
 }
@@ -138,6 +146,10 @@ type CertificateImportParameters struct {
 	// If the private key in base64EncodedCertificate is encrypted, the password used for encryption.
 	Password *string `json:"pwd,omitempty"`
 
+	// Specifies whether the certificate chain preserves its original order. The default value is false, which sets the leaf
+	// certificate at index 0.
+	PreserveCertOrder *bool `json:"preserveCertOrder,omitempty"`
+
 	// Application specific metadata in the form of key-value pairs.
 	Tags map[string]*string `json:"tags,omitempty"`
 }
@@ -357,6 +369,10 @@ type DeletedCertificateBundle struct {
 	// READ-ONLY; The management policy.
 	Policy *CertificatePolicy `json:"policy,omitempty" azure:"ro"`
 
+	// READ-ONLY; Specifies whether the certificate chain preserves its original order. The default value is false, which sets
+	// the leaf certificate at index 0.
+	PreserveCertOrder *bool `json:"preserveCertOrder,omitempty" azure:"ro"`
+
 	// READ-ONLY; The time when the certificate is scheduled to be purged, in UTC
 	ScheduledPurgeDate *time.Time `json:"scheduledPurgeDate,omitempty" azure:"ro"`
 
@@ -145,6 +145,7 @@ func (c CertificateBundle) MarshalJSON() ([]byte, error) {
 	populate(objectMap, "id", c.ID)
 	populate(objectMap, "kid", c.Kid)
 	populate(objectMap, "policy", c.Policy)
+	populate(objectMap, "preserveCertOrder", c.PreserveCertOrder)
 	populate(objectMap, "sid", c.Sid)
 	populate(objectMap, "tags", c.Tags)
 	populateByteArray(objectMap, "x5t", c.X509Thumbprint, runtime.Base64URLFormat)
@@ -178,6 +179,9 @@ func (c *CertificateBundle) UnmarshalJSON(data []byte) error {
 		case "policy":
 			err = unpopulate(val, &c.Policy)
 			delete(rawMsg, key)
+		case "preserveCertOrder":
+			err = unpopulate(val, &c.PreserveCertOrder)
+			delete(rawMsg, key)
 		case "sid":
 			err = unpopulate(val, &c.Sid)
 			delete(rawMsg, key)
@@ -200,6 +204,7 @@ func (c CertificateCreateParameters) MarshalJSON() ([]byte, error) {
 	objectMap := make(map[string]interface{})
 	populate(objectMap, "attributes", c.CertificateAttributes)
 	populate(objectMap, "policy", c.CertificatePolicy)
+	populate(objectMap, "preserveCertOrder", c.PreserveCertOrder)
 	populate(objectMap, "tags", c.Tags)
 	return json.Marshal(objectMap)
 }
@@ -211,6 +216,7 @@ func (c CertificateImportParameters) MarshalJSON() ([]byte, error) {
 	populate(objectMap, "attributes", c.CertificateAttributes)
 	populate(objectMap, "policy", c.CertificatePolicy)
 	populate(objectMap, "pwd", c.Password)
+	populate(objectMap, "preserveCertOrder", c.PreserveCertOrder)
 	populate(objectMap, "tags", c.Tags)
 	return json.Marshal(objectMap)
 }
@@ -422,6 +428,7 @@ func (d DeletedCertificateBundle) MarshalJSON() ([]byte, error) {
 	populate(objectMap, "id", d.ID)
 	populate(objectMap, "kid", d.Kid)
 	populate(objectMap, "policy", d.Policy)
+	populate(objectMap, "preserveCertOrder", d.PreserveCertOrder)
 	populate(objectMap, "recoveryId", d.RecoveryID)
 	populateTimeUnix(objectMap, "scheduledPurgeDate", d.ScheduledPurgeDate)
 	populate(objectMap, "sid", d.Sid)
@@ -460,6 +467,9 @@ func (d *DeletedCertificateBundle) UnmarshalJSON(data []byte) error {
 		case "policy":
 			err = unpopulate(val, &d.Policy)
 			delete(rawMsg, key)
+		case "preserveCertOrder":
+			err = unpopulate(val, &d.PreserveCertOrder)
+			delete(rawMsg, key)
 		case "recoveryId":
 			err = unpopulate(val, &d.RecoveryID)
 			delete(rawMsg, key)
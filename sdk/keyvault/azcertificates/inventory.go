@@ -0,0 +1,273 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CertificateInventoryEntry describes the latest version of a certificate in a CertificateInventory.
+type CertificateInventoryEntry struct {
+	// Name is the name of the certificate.
+	Name string `json:"name"`
+
+	// Version is the latest version of the certificate.
+	Version string `json:"version"`
+
+	// Enabled reports whether the latest version is enabled.
+	Enabled bool `json:"enabled"`
+
+	// ExpiresOn is when the latest version expires. It's nil when the service doesn't report an expiry.
+	ExpiresOn *time.Time `json:"expiresOn,omitempty"`
+
+	// DaysRemaining is the number of whole days until the latest version expires when the inventory was built.
+	// It's negative for an expired certificate and zero when ExpiresOn is nil.
+	DaysRemaining int `json:"daysRemaining"`
+
+	// Issuer is the distinguished name of the issuer of the latest version, for example "CN=Contoso CA".
+	Issuer string `json:"issuer"`
+
+	// IssuerName is the name of the issuer in the certificate's policy, for example "Self" or "Unknown".
+	IssuerName string `json:"issuerName"`
+
+	// KeyType is the type of the certificate's key, for example "RSA" or "EC".
+	KeyType string `json:"keyType"`
+
+	// KeySize is the size of an RSA key in bits. It's zero for other keys.
+	KeySize int32 `json:"keySize,omitempty"`
+
+	// KeyCurveName is the curve of an EC key, for example "P-256". It's empty for other keys.
+	KeyCurveName string `json:"keyCurveName,omitempty"`
+}
+
+// CertificateInventory is a list of certificates sorted by expiry, soonest first. Certificates without an expiry
+// come last. Certificates that expire at the same time are sorted by name.
+type CertificateInventory []CertificateInventoryEntry
+
+// inventoryCSVHeader is the header row written by CertificateInventory.WriteCSV
+var inventoryCSVHeader = []string{"name", "version", "enabled", "expiresOn", "daysRemaining", "issuer", "issuerName", "keyType", "keySize", "keyCurveName"}
+
+// WriteJSON writes the inventory to w as a JSON array.
+func (c CertificateInventory) WriteJSON(w io.Writer) error {
+	entries := c
+	if entries == nil {
+		// an empty inventory is an empty array rather than null
+		entries = CertificateInventory{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// WriteCSV writes the inventory to w as CSV with a header row. Times are in RFC 3339 format.
+func (c CertificateInventory) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(inventoryCSVHeader); err != nil {
+		return err
+	}
+	for _, e := range c {
+		var expiresOn, keySize string
+		if e.ExpiresOn != nil {
+			expiresOn = e.ExpiresOn.UTC().Format(time.RFC3339)
+		}
+		if e.KeySize != 0 {
+			keySize = strconv.Itoa(int(e.KeySize))
+		}
+		if err := cw.Write([]string{
+			e.Name,
+			e.Version,
+			strconv.FormatBool(e.Enabled),
+			expiresOn,
+			strconv.Itoa(e.DaysRemaining),
+			e.Issuer,
+			e.IssuerName,
+			e.KeyType,
+			keySize,
+			e.KeyCurveName,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// BuildInventoryOptions contains optional parameters for Client.BuildInventory
+type BuildInventoryOptions struct {
+	// ExpiresWithin, when greater than zero, limits the inventory to certificates that expire within this duration,
+	// including expired certificates. For example, 90 days lists what expires next quarter.
+	ExpiresWithin time.Duration
+
+	// IncludeDisabled includes certificates whose latest version is disabled, which are otherwise omitted.
+	IncludeDisabled bool
+
+	// Filter, when set, selects the certificates to include. It's called after the other options are applied.
+	Filter func(*CertificateItem) bool
+
+	// MaxConcurrency is the maximum number of certificates retrieved at once. The default value is 4.
+	MaxConcurrency int
+}
+
+// BuildInventoryResponse contains response fields for Client.BuildInventory
+type BuildInventoryResponse struct {
+	// Certificates are the selected certificates, sorted by expiry.
+	Certificates CertificateInventory
+}
+
+// BuildInventory lists the certificates in the vault and describes the latest version of each, with its expiry,
+// issuer and key, sorted by expiry. It gets each selected certificate with GetCertificate, getting up to
+// BuildInventoryOptions.MaxConcurrency certificates at once, and returns the first error, after which it stops
+// getting certificates. Write the inventory with its WriteJSON or WriteCSV method. This operation requires the
+// certificates/list and certificates/get permissions. Pass nil for options to accept default values.
+func (c *Client) BuildInventory(ctx context.Context, options *BuildInventoryOptions) (BuildInventoryResponse, error) {
+	if options == nil {
+		options = &BuildInventoryOptions{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultConcurrency
+	}
+
+	now := time.Now()
+	var items []*CertificateItem
+	pager := c.NewListPropertiesOfCertificatesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return BuildInventoryResponse{}, err
+		}
+		for _, item := range page.Certificates {
+			if item == nil || item.Properties == nil || item.Properties.Name == nil {
+				continue
+			}
+			props := item.Properties
+			if !options.IncludeDisabled && props.Enabled != nil && !*props.Enabled {
+				continue
+			}
+			if options.ExpiresWithin > 0 && (props.ExpiresOn == nil || props.ExpiresOn.Sub(now) > options.ExpiresWithin) {
+				continue
+			}
+			if options.Filter != nil && !options.Filter(item) {
+				continue
+			}
+			items = append(items, item)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	entries := make(CertificateInventory, len(items))
+	var once sync.Once
+	var firstErr error
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			resp, err := c.GetCertificate(ctx, name, nil)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			entries[i] = newCertificateInventoryEntry(name, resp.CertificateWithPolicy, now)
+		}(i, *item.Properties.Name)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return BuildInventoryResponse{}, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return BuildInventoryResponse{}, err
+	}
+	sortInventory(entries)
+	return BuildInventoryResponse{Certificates: entries}, nil
+}
+
+// newCertificateInventoryEntry describes cert as of now
+func newCertificateInventoryEntry(name string, cert CertificateWithPolicy, now time.Time) CertificateInventoryEntry {
+	e := CertificateInventoryEntry{Name: name}
+	if props := cert.Properties; props != nil {
+		if props.Version != nil {
+			e.Version = *props.Version
+		}
+		e.Enabled = props.Enabled == nil || *props.Enabled
+		if props.ExpiresOn != nil {
+			expiresOn := *props.ExpiresOn
+			e.ExpiresOn = &expiresOn
+			e.DaysRemaining = daysUntil(now, expiresOn)
+		}
+	}
+	if x, err := cert.ParseX509(); err == nil {
+		e.Issuer = x.Issuer.String()
+		if e.ExpiresOn == nil {
+			e.ExpiresOn = &x.NotAfter
+			e.DaysRemaining = daysUntil(now, x.NotAfter)
+		}
+	}
+	if p := cert.Policy; p != nil {
+		if p.IssuerParameters != nil && p.IssuerParameters.IssuerName != nil {
+			e.IssuerName = *p.IssuerParameters.IssuerName
+		}
+		if p.KeyType != nil {
+			e.KeyType = string(*p.KeyType)
+		}
+		if p.KeySize != nil {
+			e.KeySize = *p.KeySize
+		}
+		if p.KeyCurveName != nil {
+			e.KeyCurveName = string(*p.KeyCurveName)
+		}
+	}
+	return e
+}
+
+// daysUntil returns the whole days from now until t, rounded toward negative infinity so a certificate that
+// expired an hour ago has -1 days remaining
+func daysUntil(now, t time.Time) int {
+	d := t.Sub(now)
+	days := int(d / (24 * time.Hour))
+	if d < 0 && d%(24*time.Hour) != 0 {
+		days--
+	}
+	return days
+}
+
+// sortInventory sorts entries by expiry, soonest first, then by name
+func sortInventory(entries CertificateInventory) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].ExpiresOn, entries[j].ExpiresOn
+		switch {
+		case a == nil && b == nil:
+		case a == nil:
+			return false
+		case b == nil:
+			return true
+		case !a.Equal(*b):
+			return a.Before(*b)
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
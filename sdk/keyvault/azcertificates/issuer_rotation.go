@@ -0,0 +1,80 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// RotateIssuerCredentialsOptions contains optional parameters for Client.RotateIssuerCredentials
+type RotateIssuerCredentialsOptions struct {
+	// Verify checks the new credentials with the issuer's provider after they're stored, for example by
+	// calling an account API of the CA that doesn't order a certificate. Key Vault doesn't check issuer
+	// credentials until it orders a certificate with them, so without Verify a rejected account ID or
+	// password goes unnoticed until a certificate fails to renew. issuer is the issuer after the update.
+	Verify func(ctx context.Context, issuer Issuer, credentials IssuerCredentials) error
+}
+
+// RotateIssuerCredentialsResponse contains response fields for Client.RotateIssuerCredentials
+type RotateIssuerCredentialsResponse struct {
+	// Issuer is the issuer after the update.
+	Issuer
+
+	// AccountIDAccepted reports whether the issuer read back after the update has the new account ID.
+	AccountIDAccepted bool
+
+	// Verified reports whether options.Verify accepted the new credentials. It's false when Verify isn't set.
+	Verified bool
+
+	// VerificationError is the error options.Verify returned, when it rejected the new credentials.
+	VerificationError error
+
+	// RawResponse is the HTTP response of the update.
+	RawResponse *http.Response
+}
+
+// RotateIssuerCredentials replaces the account ID and password of a certificate issuer, keeping its other
+// settings, and reports whether the new credentials were accepted. It reads the issuer back to confirm Key Vault
+// stored the new account ID and, when options.Verify is set, passes the credentials to it to check them with the
+// provider. A rejected account ID or password is reported in the response rather than as an error, because the
+// new credentials are stored either way; errors are returned only when a request to Key Vault fails. The password
+// isn't returned by Key Vault, so the previous credentials can't be restored by this method. This operation
+// requires the certificates/setissuers and certificates/getissuers permissions. Pass nil for options to accept
+// default values.
+func (c *Client) RotateIssuerCredentials(ctx context.Context, issuerName string, credentials IssuerCredentials, options *RotateIssuerCredentialsOptions) (RotateIssuerCredentialsResponse, error) {
+	if options == nil {
+		options = &RotateIssuerCredentialsOptions{}
+	}
+	if credentials.AccountID == nil || *credentials.AccountID == "" {
+		return RotateIssuerCredentialsResponse{}, errors.New("the new credentials have no account ID")
+	}
+	if credentials.Password == nil || *credentials.Password == "" {
+		return RotateIssuerCredentialsResponse{}, errors.New("the new credentials have no password")
+	}
+
+	// only the credentials are sent, so the update keeps the issuer's other settings
+	updated, err := c.UpdateIssuer(ctx, Issuer{Name: &issuerName, Credentials: &credentials}, nil)
+	if err != nil {
+		return RotateIssuerCredentialsResponse{}, err
+	}
+	resp := RotateIssuerCredentialsResponse{Issuer: updated.Issuer, RawResponse: updated.RawResponse}
+
+	current, err := c.GetIssuer(ctx, issuerName, nil)
+	if err != nil {
+		return RotateIssuerCredentialsResponse{}, err
+	}
+	resp.AccountIDAccepted = current.Credentials != nil && current.Credentials.AccountID != nil &&
+		*current.Credentials.AccountID == *credentials.AccountID
+
+	if options.Verify != nil {
+		resp.VerificationError = options.Verify(ctx, current.Issuer, credentials)
+		resp.Verified = resp.VerificationError == nil
+	}
+	return resp, nil
+}
@@ -7,8 +7,14 @@
 package azcertificates
 
 import (
+	"bytes"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
@@ -160,6 +166,26 @@ type CertificateWithPolicy struct {
 	SecretID *string
 }
 
+// MarshalJSON implements the json.Marshaler interface for the CertificateWithPolicy type. The JSON has the
+// format of the Key Vault REST API: times are Unix timestamps in seconds, CER is standard base64 and the
+// X509 thumbprint is URL-safe base64. UnmarshalJSON reads this format.
+func (c CertificateWithPolicy) MarshalJSON() ([]byte, error) {
+	g := generated.CertificateBundle{
+		Attributes:  c.Properties.toGenerated(),
+		Cer:         c.CER,
+		ContentType: c.ContentType,
+		ID:          c.ID,
+		Kid:         c.KeyID,
+		Policy:      c.Policy.toGeneratedJSON(),
+		Sid:         c.SecretID,
+	}
+	if c.Properties != nil {
+		g.Tags = c.Properties.Tags
+		g.X509Thumbprint = c.Properties.X509Thumbprint
+	}
+	return json.Marshal(g)
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface for the CertificateWithPolicy type.
 func (c *CertificateWithPolicy) UnmarshalJSON(data []byte) error {
 	var g generated.CertificateBundle
@@ -177,6 +203,70 @@ func (c *CertificateWithPolicy) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ParseX509 parses CER and returns the certificate. See ParseCER for the formats it accepts.
+func (c Certificate) ParseX509() (*x509.Certificate, error) {
+	return parseLeaf(c.CER)
+}
+
+// ParseX509 parses CER and returns the certificate. See ParseCER for the formats it accepts.
+func (c CertificateWithPolicy) ParseX509() (*x509.Certificate, error) {
+	return parseLeaf(c.CER)
+}
+
+// ParseCER parses the CER contents of a certificate and returns its certificates in the order they
+// appear, which for a chain is the leaf certificate first. It accepts DER, including the concatenated
+// DER of a chain, PEM with one or more CERTIFICATE blocks, and base64 encoded DER. PEM blocks of other
+// types, such as private keys, are skipped.
+func ParseCER(cer []byte) ([]*x509.Certificate, error) {
+	if len(bytes.TrimSpace(cer)) == 0 {
+		return nil, errors.New("no certificate data")
+	}
+	if bytes.Contains(cer, []byte("-----BEGIN")) {
+		return parsePEMCertificates(cer)
+	}
+	certs, err := x509.ParseCertificates(cer)
+	if err == nil {
+		return certs, nil
+	}
+	if der, b64err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(cer))); b64err == nil {
+		if certs, b64err = x509.ParseCertificates(der); b64err == nil {
+			return certs, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to parse certificate data: %w", err)
+}
+
+func parsePEMCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PEM certificate %d: %w", len(certs), err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no CERTIFICATE block in PEM data")
+	}
+	return certs, nil
+}
+
+func parseLeaf(cer []byte) (*x509.Certificate, error) {
+	certs, err := ParseCER(cer)
+	if err != nil {
+		return nil, err
+	}
+	return certs[0], nil
+}
+
 func certificateFromGenerated(g *generated.CertificateBundle) Certificate {
 	if g == nil {
 		return Certificate{}
@@ -193,28 +283,61 @@ func certificateFromGenerated(g *generated.CertificateBundle) Certificate {
 	}
 }
 
-// CertificateOperationError - The key vault server error.
+// CertificateOperationError - The key vault server error. InnerError, when present, describes the error's cause
+// in more detail, for example an issuer's reason for rejecting a certificate signing request. errors.As finds
+// inner errors because Unwrap returns InnerError.
 type CertificateOperationError struct {
 	// READ-ONLY; The error code.
 	Code *string
 
 	// READ-ONLY; The key vault server error.
-	innerError *CertificateOperationError
+	InnerError *CertificateOperationError
 
 	// READ-ONLY; The error message.
-	message *string
+	Message *string
 }
 
-// Error returns the error string detailing why the Certificate Operation failed.
+// Error returns the code and message of the error and of each of its inner errors.
 func (c *CertificateOperationError) Error() string {
 	if c == nil {
 		return ""
 	}
-	marshalled, err := json.Marshal(*c)
-	if err != nil {
-		return fmt.Sprintf("could not turn operation error into a string: %v", err)
+	var sb strings.Builder
+	for e := c; e != nil; e = e.InnerError {
+		if e != c {
+			sb.WriteString("; inner error: ")
+		}
+		code := "UnknownError"
+		if e.Code != nil {
+			code = *e.Code
+		}
+		sb.WriteString(code)
+		if e.Message != nil {
+			sb.WriteString(": ")
+			sb.WriteString(*e.Message)
+		}
+	}
+	return sb.String()
+}
+
+// Unwrap returns InnerError, or nil when there isn't one.
+func (c *CertificateOperationError) Unwrap() error {
+	if c == nil || c.InnerError == nil {
+		return nil
+	}
+	return c.InnerError
+}
+
+func (c *CertificateOperationError) toGenerated() *generated.Error {
+	if c == nil {
+		return nil
+	}
+
+	return &generated.Error{
+		Code:       c.Code,
+		Message:    c.Message,
+		InnerError: c.InnerError.toGenerated(),
 	}
-	return string(marshalled)
 }
 
 func certificateErrorFromGenerated(g *generated.Error) *CertificateOperationError {
@@ -224,8 +347,8 @@ func certificateErrorFromGenerated(g *generated.Error) *CertificateOperationErro
 
 	return &CertificateOperationError{
 		Code:       g.Code,
-		message:    g.Message,
-		innerError: certificateErrorFromGenerated(g.InnerError),
+		Message:    g.Message,
+		InnerError: certificateErrorFromGenerated(g.InnerError),
 	}
 }
 
@@ -253,6 +376,18 @@ type CertificateItem struct {
 
 	// Certificate identifier.
 	ID *string
+
+	// READ-ONLY; Status is CertificateStatusPending for a certificate that has no issued version yet. Such
+	// certificates are listed only when the list options set IncludePending.
+	Status *CertificateStatus
+}
+
+// certificateStatus returns the status of a listed certificate. Only issued certificates have a thumbprint.
+func certificateStatus(thumbprint []byte) *CertificateStatus {
+	if len(thumbprint) == 0 {
+		return to.Ptr(CertificateStatusPending)
+	}
+	return to.Ptr(CertificateStatusIssued)
 }
 
 // Operation - A certificate operation is returned in case of asynchronous requests.
@@ -285,6 +420,35 @@ type Operation struct {
 	ID *string
 }
 
+// MarshalJSON implements the json.Marshaler interface for the Operation type. The JSON has the format
+// of the Key Vault REST API, in which CSR is standard base64. UnmarshalJSON reads this format.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	g := generated.CertificateOperation{
+		CancellationRequested: o.CancellationRequested,
+		Csr:                   o.CSR,
+		Error:                 o.Error.toGenerated(),
+		RequestID:             o.RequestID,
+		Status:                o.Status,
+		StatusDetails:         o.StatusDetails,
+		Target:                o.Target,
+		ID:                    o.ID,
+	}
+	if o.IssuerParameters != nil {
+		g.IssuerParameters = o.IssuerParameters.toGenerated()
+	}
+	return json.Marshal(g)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for the Operation type.
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	var g generated.CertificateOperation
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	*o = certificateOperationFromGenerated(g)
+	return nil
+}
+
 func certificateOperationFromGenerated(g generated.CertificateOperation) Operation {
 	return Operation{
 		CancellationRequested: g.CancellationRequested,
@@ -340,13 +504,101 @@ func NewDefaultCertificatePolicy() Policy {
 	}
 }
 
+// NewSelfSignedPolicy returns a Policy for a self-signed certificate with the given subject, such as
+// "CN=contoso.com", valid for validityMonths months. The certificate has an exportable 2048-bit RSA key,
+// is downloaded as PKCS#12 and is renewed automatically when 80% of its lifetime has passed. A
+// validityMonths of 0 or less uses the service's default of 12 months.
+func NewSelfSignedPolicy(subject string, validityMonths int) Policy {
+	p := newCommonPolicy(string(WellKnownIssuerNamesSelf), subject, PolicyActionAutoRenew)
+	if validityMonths > 0 {
+		p.X509Properties.ValidityInMonths = to.Ptr(int32(validityMonths))
+	}
+	return p
+}
+
+// NewIssuerPolicy returns a Policy for a certificate with the given subject, signed by issuer, the name
+// of an issuer created with CreateIssuer, such as one for DigiCert or GlobalSign. The certificate has the
+// same defaults as NewSelfSignedPolicy, and is valid for the issuer's default period.
+func NewIssuerPolicy(issuer, subject string) Policy {
+	return newCommonPolicy(issuer, subject, PolicyActionAutoRenew)
+}
+
+// NewExternallySignedPolicy returns a Policy for a certificate with the given subject whose certificate
+// signing request is signed outside Key Vault, by a certificate authority that isn't integrated with it.
+// Get the CSR with Client.GetPendingCSR and complete it with Client.CompleteWithSignedCertificate. Key Vault
+// can't renew such certificates, so the certificate contacts are emailed 30 days before it expires
+// instead. The key has the same defaults as NewSelfSignedPolicy.
+func NewExternallySignedPolicy(subject string) Policy {
+	p := newCommonPolicy(string(WellKnownIssuerNamesUnknown), subject, PolicyActionEmailContacts)
+	p.LifetimeActions[0].LifetimePercentage = nil
+	p.LifetimeActions[0].DaysBeforeExpiry = to.Ptr(int32(30))
+	return p
+}
+
+func newCommonPolicy(issuer, subject string, action PolicyAction) Policy {
+	return Policy{
+		IssuerParameters: &IssuerParameters{IssuerName: to.Ptr(issuer)},
+		KeyType:          to.Ptr(KeyTypeRSA),
+		KeySize:          to.Ptr(int32(2048)),
+		Exportable:       to.Ptr(true),
+		ReuseKey:         to.Ptr(false),
+		ContentType:      to.Ptr(CertificateContentTypePKCS12),
+		LifetimeActions: []*LifetimeAction{
+			{Action: to.Ptr(action), LifetimePercentage: to.Ptr(int32(80))},
+		},
+		X509Properties: &X509CertificateProperties{
+			Subject:   to.Ptr(subject),
+			KeyUsages: []*KeyUsage{to.Ptr(KeyUsageDigitalSignature), to.Ptr(KeyUsageKeyEncipherment)},
+		},
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface for the Policy type. The JSON has the format
+// of the Key Vault REST API, in which times are Unix timestamps in seconds. UnmarshalJSON reads this format.
+func (c Policy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.toGeneratedJSON())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for the Policy type.
+func (c *Policy) UnmarshalJSON(data []byte) error {
+	var g generated.CertificatePolicy
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	*c = *certificatePolicyFromGenerated(&g)
+	return nil
+}
+
+// toGeneratedJSON converts c for serialization. Unlike the create parameters, it omits
+// the empty values the service requires so nil fields round trip.
+func (c *Policy) toGeneratedJSON() *generated.CertificatePolicy {
+	if c == nil {
+		return nil
+	}
+	g := c.toGeneratedCertificateCreateParameters()
+	if c.IssuerParameters == nil {
+		g.IssuerParameters = nil
+	}
+	if c.X509Properties == nil {
+		g.X509CertificateProperties = nil
+	} else if c.X509Properties.SubjectAlternativeNames == nil {
+		g.X509CertificateProperties.SubjectAlternativeNames = nil
+	}
+	if c.ContentType == nil {
+		g.SecretProperties = nil
+	}
+	return g
+}
+
 func (c *Policy) toGeneratedCertificateCreateParameters() *generated.CertificatePolicy {
 	if c == nil {
 		return nil
 	}
 	var la []*generated.LifetimeAction
 	for _, l := range c.LifetimeActions {
-		la = append(la, l.toGenerated())
+		if l != nil {
+			la = append(la, l.toGenerated())
+		}
 	}
 
 	var keyProps *generated.KeyProperties
@@ -392,7 +644,9 @@ func certificatePolicyFromGenerated(g *generated.CertificatePolicy) *Policy {
 	c.Properties = propertiesFromGenerated(g.Attributes, nil, nil, nil)
 	c.IssuerParameters = issuerParametersFromGenerated(g.IssuerParameters)
 	c.LifetimeActions = la
-	c.ContentType = (*CertificateContentType)(g.SecretProperties.ContentType)
+	if g.SecretProperties != nil {
+		c.ContentType = (*CertificateContentType)(g.SecretProperties.ContentType)
+	}
 	c.X509Properties = x509CertificatePropertiesFromGenerated(g.X509CertificateProperties)
 	return c
 }
@@ -505,6 +759,10 @@ type DeletedCertificateItem struct {
 
 	// READ-ONLY; The time when the certificate is scheduled to be purged, in UTC
 	ScheduledPurgeDate *time.Time
+
+	// READ-ONLY; Status is CertificateStatusPending for a certificate that had no issued version when it was
+	// deleted. Such certificates are listed only when ListDeletedCertificatesOptions sets IncludePending.
+	Status *CertificateStatus
 }
 
 // Issuer - The issuer for Key Vault certificate.
@@ -728,3 +986,41 @@ func x509CertificatePropertiesFromGenerated(g *generated.X509CertificateProperti
 		ValidityInMonths:        g.ValidityInMonths,
 	}
 }
+
+// VaultCapabilities describes how a vault treats deleted certificates.
+type VaultCapabilities struct {
+	// Known is false when the vault has no certificates from which to determine its capabilities. The
+	// other fields are meaningful only when Known is true.
+	Known bool
+
+	// PurgeProtection is true when the vault forbids purging deleted certificates. The vault permanently
+	// deletes them only at the end of the retention interval.
+	PurgeProtection bool
+
+	// RecoverableDays is the number of days the vault retains deleted certificates. It's nil when soft
+	// delete is disabled.
+	RecoverableDays *int32
+
+	// RecoveryLevel is the vault's deletion recovery level, for example "Recoverable+Purgeable".
+	RecoveryLevel *string
+
+	// SoftDelete is true when deleted certificates are recoverable until the end of the retention interval.
+	SoftDelete bool
+}
+
+// vaultCapabilitiesFromRecoveryLevel interprets a deletion recovery level
+func vaultCapabilitiesFromRecoveryLevel(recoveryLevel *string, recoverableDays *int32) VaultCapabilities {
+	if recoveryLevel == nil {
+		return VaultCapabilities{}
+	}
+	v := VaultCapabilities{
+		Known:         true,
+		RecoveryLevel: recoveryLevel,
+		SoftDelete:    strings.Contains(*recoveryLevel, "Recoverable"),
+	}
+	if v.SoftDelete {
+		v.PurgeProtection = !strings.Contains(*recoveryLevel, "Purgeable")
+		v.RecoverableDays = recoverableDays
+	}
+	return v
+}
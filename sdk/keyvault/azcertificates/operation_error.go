@@ -0,0 +1,120 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import "strings"
+
+// IssuerErrorCategory classifies the failure of a certificate operation.
+type IssuerErrorCategory string
+
+const (
+	// IssuerErrorCategoryUnknown is a failure that doesn't match another category.
+	IssuerErrorCategoryUnknown IssuerErrorCategory = "Unknown"
+	// IssuerErrorCategoryValidationPending means the certificate authority is still validating the request,
+	// for example waiting for an organization validation to be approved.
+	IssuerErrorCategoryValidationPending IssuerErrorCategory = "ValidationPending"
+	// IssuerErrorCategoryDomainNotVerified means the certificate authority couldn't verify control of a
+	// domain in the certificate.
+	IssuerErrorCategoryDomainNotVerified IssuerErrorCategory = "DomainNotVerified"
+	// IssuerErrorCategoryPayment means the certificate authority account has a billing or payment problem.
+	IssuerErrorCategoryPayment IssuerErrorCategory = "Payment"
+	// IssuerErrorCategoryIssuerCredentials means the certificate authority rejected the issuer's credentials.
+	IssuerErrorCategoryIssuerCredentials IssuerErrorCategory = "IssuerCredentials"
+	// IssuerErrorCategoryInvalidRequest means the certificate authority rejected the request or the policy,
+	// for example an unsupported key size or subject.
+	IssuerErrorCategoryInvalidRequest IssuerErrorCategory = "InvalidRequest"
+	// IssuerErrorCategoryTransient means the certificate authority or Key Vault was temporarily unavailable.
+	IssuerErrorCategoryTransient IssuerErrorCategory = "Transient"
+)
+
+// PossibleIssuerErrorCategoryValues returns the possible values for the IssuerErrorCategory const type.
+func PossibleIssuerErrorCategoryValues() []IssuerErrorCategory {
+	return []IssuerErrorCategory{
+		IssuerErrorCategoryUnknown,
+		IssuerErrorCategoryValidationPending,
+		IssuerErrorCategoryDomainNotVerified,
+		IssuerErrorCategoryPayment,
+		IssuerErrorCategoryIssuerCredentials,
+		IssuerErrorCategoryInvalidRequest,
+		IssuerErrorCategoryTransient,
+	}
+}
+
+// IssuerErrorClassification is returned by CertificateOperationError.Classify.
+type IssuerErrorClassification struct {
+	// Category is the kind of failure.
+	Category IssuerErrorCategory
+
+	// Retryable is true when the same request may succeed later without changes, such as after the certificate
+	// authority finishes validating it. Other failures need a change to the request, the issuer or the account.
+	Retryable bool
+
+	// Code and Message are the code and message of the error in the chain that determined the category, or of
+	// the innermost error when the category is IssuerErrorCategoryUnknown.
+	Code    string
+	Message string
+}
+
+// issuerErrorPatterns are matched, in order, against the lower-cased code and message of each error in a chain.
+// Certificate authorities report failures in their own words, so the patterns are phrases they commonly use.
+var issuerErrorPatterns = []struct {
+	category IssuerErrorCategory
+	phrases  []string
+}{
+	{IssuerErrorCategoryPayment, []string{"payment", "billing", "insufficient funds", "insufficient balance", "credit limit", "invoice"}},
+	{IssuerErrorCategoryIssuerCredentials, []string{"unauthorized", "authentication failed", "invalid api key", "invalid credentials", "access denied", "forbidden"}},
+	{IssuerErrorCategoryDomainNotVerified, []string{"domain not verified", "domain validation failed", "domainnotverified", "dcv", "domain control"}},
+	{IssuerErrorCategoryValidationPending, []string{"pending validation", "validation pending", "awaiting validation", "pending approval", "awaiting approval", "not yet validated"}},
+	{IssuerErrorCategoryTransient, []string{"timeout", "timed out", "temporarily unavailable", "service unavailable", "try again", "throttl", "too many requests", "internal server error"}},
+	{IssuerErrorCategoryInvalidRequest, []string{"badparameter", "invalid", "not supported", "unsupported", "rejected", "malformed"}},
+}
+
+// Classify categorizes the failure of a certificate operation, such as one returned in Operation.Error by an
+// issuer, so automation can decide whether to retry it or report it. The category is found by matching the code
+// and message of the error and its inner errors, innermost first, against phrases certificate authorities use.
+// The classification is a best effort; IssuerErrorCategoryUnknown means no phrase matched.
+func (c *CertificateOperationError) Classify() IssuerErrorClassification {
+	var chain []*CertificateOperationError
+	for e := c; e != nil; e = e.InnerError {
+		chain = append(chain, e)
+	}
+	if len(chain) == 0 {
+		return IssuerErrorClassification{Category: IssuerErrorCategoryUnknown}
+	}
+
+	for _, pattern := range issuerErrorPatterns {
+		// the innermost error is usually the certificate authority's own
+		for i := len(chain) - 1; i >= 0; i-- {
+			code, message := chain[i].codeAndMessage()
+			text := strings.ToLower(code + " " + message)
+			for _, phrase := range pattern.phrases {
+				if strings.Contains(text, phrase) {
+					return IssuerErrorClassification{
+						Category:  pattern.category,
+						Retryable: pattern.category == IssuerErrorCategoryValidationPending || pattern.category == IssuerErrorCategoryTransient,
+						Code:      code,
+						Message:   message,
+					}
+				}
+			}
+		}
+	}
+
+	code, message := chain[len(chain)-1].codeAndMessage()
+	return IssuerErrorClassification{Category: IssuerErrorCategoryUnknown, Code: code, Message: message}
+}
+
+func (c *CertificateOperationError) codeAndMessage() (string, string) {
+	var code, message string
+	if c.Code != nil {
+		code = *c.Code
+	}
+	if c.Message != nil {
+		message = *c.Message
+	}
+	return code, message
+}
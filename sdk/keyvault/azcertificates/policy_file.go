@@ -0,0 +1,277 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyFileFormat is the format of a declarative policy file.
+type PolicyFileFormat string
+
+const (
+	// PolicyFileFormatYAML is YAML.
+	PolicyFileFormatYAML PolicyFileFormat = "yaml"
+	// PolicyFileFormatJSON is JSON.
+	PolicyFileFormatJSON PolicyFileFormat = "json"
+)
+
+// policyFile is the schema of a declarative policy file. The fields are all optional:
+//
+//	enabled: true                          # whether certificates created with the policy are enabled
+//	contentType: application/x-pem-file    # or application/x-pkcs12
+//	issuer:
+//	  name: Self                           # Self, Unknown or the name of an issuer
+//	  certificateType: OV-SSL
+//	  certificateTransparency: false
+//	key:
+//	  type: EC                             # EC, EC-HSM, RSA, RSA-HSM or oct
+//	  curve: P-256                         # P-256, P-256K, P-384 or P-521
+//	  size: 2048
+//	  exportable: true
+//	  reuse: false
+//	x509:
+//	  subject: CN=contoso.com
+//	  validityInMonths: 12
+//	  dnsNames: [contoso.com, www.contoso.com]
+//	  emails: [admin@contoso.com]
+//	  userPrincipalNames: [admin@contoso.com]
+//	  keyUsages: [digitalSignature, keyEncipherment]
+//	  enhancedKeyUsages: [1.3.6.1.5.5.7.3.1]
+//	lifetimeActions:
+//	  - action: AutoRenew                  # AutoRenew or EmailContacts
+//	    daysBeforeExpiry: 30               # or lifetimePercentage
+//
+// The JSON format has the same fields.
+type policyFile struct {
+	Enabled         *bool                      `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	ContentType     *CertificateContentType    `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+	Issuer          *policyFileIssuer          `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Key             *policyFileKey             `json:"key,omitempty" yaml:"key,omitempty"`
+	X509            *policyFileX509            `json:"x509,omitempty" yaml:"x509,omitempty"`
+	LifetimeActions []policyFileLifetimeAction `json:"lifetimeActions,omitempty" yaml:"lifetimeActions,omitempty"`
+}
+
+type policyFileIssuer struct {
+	Name                    *string `json:"name,omitempty" yaml:"name,omitempty"`
+	CertificateType         *string `json:"certificateType,omitempty" yaml:"certificateType,omitempty"`
+	CertificateTransparency *bool   `json:"certificateTransparency,omitempty" yaml:"certificateTransparency,omitempty"`
+}
+
+type policyFileKey struct {
+	Type       *KeyType      `json:"type,omitempty" yaml:"type,omitempty"`
+	Curve      *KeyCurveName `json:"curve,omitempty" yaml:"curve,omitempty"`
+	Size       *int32        `json:"size,omitempty" yaml:"size,omitempty"`
+	Exportable *bool         `json:"exportable,omitempty" yaml:"exportable,omitempty"`
+	Reuse      *bool         `json:"reuse,omitempty" yaml:"reuse,omitempty"`
+}
+
+type policyFileX509 struct {
+	Subject            *string    `json:"subject,omitempty" yaml:"subject,omitempty"`
+	ValidityInMonths   *int32     `json:"validityInMonths,omitempty" yaml:"validityInMonths,omitempty"`
+	DNSNames           []string   `json:"dnsNames,omitempty" yaml:"dnsNames,omitempty"`
+	Emails             []string   `json:"emails,omitempty" yaml:"emails,omitempty"`
+	UserPrincipalNames []string   `json:"userPrincipalNames,omitempty" yaml:"userPrincipalNames,omitempty"`
+	KeyUsages          []KeyUsage `json:"keyUsages,omitempty" yaml:"keyUsages,omitempty"`
+	EnhancedKeyUsages  []string   `json:"enhancedKeyUsages,omitempty" yaml:"enhancedKeyUsages,omitempty"`
+}
+
+type policyFileLifetimeAction struct {
+	Action             *PolicyAction `json:"action,omitempty" yaml:"action,omitempty"`
+	DaysBeforeExpiry   *int32        `json:"daysBeforeExpiry,omitempty" yaml:"daysBeforeExpiry,omitempty"`
+	LifetimePercentage *int32        `json:"lifetimePercentage,omitempty" yaml:"lifetimePercentage,omitempty"`
+}
+
+// MarshalDeclarative returns the policy in the declarative format ParsePolicyFile reads, so it can be kept
+// in source control and applied with UpdateCertificatePolicy. Read-only properties aren't included.
+func (c Policy) MarshalDeclarative(format PolicyFileFormat) ([]byte, error) {
+	f := c.toPolicyFile()
+	switch format {
+	case PolicyFileFormatYAML:
+		return yaml.Marshal(f)
+	case PolicyFileFormatJSON:
+		return json.MarshalIndent(f, "", "  ")
+	default:
+		return nil, fmt.Errorf("unknown policy file format %q", format)
+	}
+}
+
+// ParsePolicyFile reads a policy in the declarative format MarshalDeclarative writes, as YAML or JSON. It
+// returns an error for unknown fields and for values that aren't valid for the field, such as an unknown
+// key type, so mistakes in a file are found before the policy is sent to the vault.
+func ParsePolicyFile(r io.Reader) (Policy, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Policy{}, err
+	}
+	var f policyFile
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&f)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&f)
+		if errors.Is(err, io.EOF) {
+			// empty document
+			err = nil
+		}
+	}
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+	if err := f.validate(); err != nil {
+		return Policy{}, err
+	}
+	return f.toPolicy(), nil
+}
+
+func (c Policy) toPolicyFile() policyFile {
+	f := policyFile{ContentType: c.ContentType}
+	if c.Properties != nil {
+		f.Enabled = c.Properties.Enabled
+	}
+	if i := c.IssuerParameters; i != nil && (i.IssuerName != nil || i.CertificateType != nil || i.CertificateTransparency != nil) {
+		f.Issuer = &policyFileIssuer{Name: i.IssuerName, CertificateType: i.CertificateType, CertificateTransparency: i.CertificateTransparency}
+	}
+	if c.KeyType != nil || c.KeyCurveName != nil || c.KeySize != nil || c.Exportable != nil || c.ReuseKey != nil {
+		f.Key = &policyFileKey{Type: c.KeyType, Curve: c.KeyCurveName, Size: c.KeySize, Exportable: c.Exportable, Reuse: c.ReuseKey}
+	}
+	if x := c.X509Properties; x != nil {
+		fx := policyFileX509{
+			Subject:           x.Subject,
+			ValidityInMonths:  x.ValidityInMonths,
+			EnhancedKeyUsages: derefStrings(x.EnhancedKeyUsages),
+		}
+		for _, k := range x.KeyUsages {
+			if k != nil {
+				fx.KeyUsages = append(fx.KeyUsages, *k)
+			}
+		}
+		if san := x.SubjectAlternativeNames; san != nil {
+			fx.DNSNames = derefStrings(san.DNSNames)
+			fx.Emails = derefStrings(san.Emails)
+			fx.UserPrincipalNames = derefStrings(san.UserPrincipalNames)
+		}
+		f.X509 = &fx
+	}
+	for _, a := range c.LifetimeActions {
+		if a != nil {
+			f.LifetimeActions = append(f.LifetimeActions, policyFileLifetimeAction{Action: a.Action, DaysBeforeExpiry: a.DaysBeforeExpiry, LifetimePercentage: a.LifetimePercentage})
+		}
+	}
+	return f
+}
+
+func (f policyFile) toPolicy() Policy {
+	p := Policy{ContentType: f.ContentType}
+	if f.Enabled != nil {
+		p.Properties = &Properties{Enabled: f.Enabled}
+	}
+	if f.Issuer != nil {
+		p.IssuerParameters = &IssuerParameters{IssuerName: f.Issuer.Name, CertificateType: f.Issuer.CertificateType, CertificateTransparency: f.Issuer.CertificateTransparency}
+	}
+	if f.Key != nil {
+		p.KeyType = f.Key.Type
+		p.KeyCurveName = f.Key.Curve
+		p.KeySize = f.Key.Size
+		p.Exportable = f.Key.Exportable
+		p.ReuseKey = f.Key.Reuse
+	}
+	if x := f.X509; x != nil {
+		p.X509Properties = &X509CertificateProperties{
+			Subject:           x.Subject,
+			ValidityInMonths:  x.ValidityInMonths,
+			EnhancedKeyUsages: ptrStrings(x.EnhancedKeyUsages),
+		}
+		for i := range x.KeyUsages {
+			p.X509Properties.KeyUsages = append(p.X509Properties.KeyUsages, &x.KeyUsages[i])
+		}
+		if len(x.DNSNames)+len(x.Emails)+len(x.UserPrincipalNames) > 0 {
+			p.X509Properties.SubjectAlternativeNames = &SubjectAlternativeNames{
+				DNSNames:           ptrStrings(x.DNSNames),
+				Emails:             ptrStrings(x.Emails),
+				UserPrincipalNames: ptrStrings(x.UserPrincipalNames),
+			}
+		}
+	}
+	for i := range f.LifetimeActions {
+		a := f.LifetimeActions[i]
+		p.LifetimeActions = append(p.LifetimeActions, &LifetimeAction{Action: a.Action, DaysBeforeExpiry: a.DaysBeforeExpiry, LifetimePercentage: a.LifetimePercentage})
+	}
+	return p
+}
+
+func (f policyFile) validate() error {
+	if f.ContentType != nil && !contains(PossibleCertificateContentTypeValues(), *f.ContentType) {
+		return fmt.Errorf("invalid contentType %q", *f.ContentType)
+	}
+	if f.Key != nil {
+		if f.Key.Type != nil && !contains(PossibleKeyTypeValues(), *f.Key.Type) {
+			return fmt.Errorf("invalid key type %q", *f.Key.Type)
+		}
+		if f.Key.Curve != nil && !contains(PossibleKeyCurveNameValues(), *f.Key.Curve) {
+			return fmt.Errorf("invalid key curve %q", *f.Key.Curve)
+		}
+		if f.Key.Size != nil && *f.Key.Size <= 0 {
+			return fmt.Errorf("invalid key size %d", *f.Key.Size)
+		}
+	}
+	if f.X509 != nil {
+		for _, k := range f.X509.KeyUsages {
+			if !contains(PossibleKeyUsageValues(), k) {
+				return fmt.Errorf("invalid x509 key usage %q", k)
+			}
+		}
+		if f.X509.ValidityInMonths != nil && *f.X509.ValidityInMonths <= 0 {
+			return fmt.Errorf("invalid x509 validityInMonths %d", *f.X509.ValidityInMonths)
+		}
+	}
+	for i, a := range f.LifetimeActions {
+		if a.Action == nil || !contains(PossiblePolicyActionValues(), *a.Action) {
+			return fmt.Errorf("lifetimeActions[%d] needs an action, one of %v", i, PossiblePolicyActionValues())
+		}
+		if (a.DaysBeforeExpiry == nil) == (a.LifetimePercentage == nil) {
+			return fmt.Errorf("lifetimeActions[%d] needs either daysBeforeExpiry or lifetimePercentage", i)
+		}
+	}
+	return nil
+}
+
+func contains[T comparable](values []T, v T) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+func derefStrings(s []*string) []string {
+	var r []string
+	for _, v := range s {
+		if v != nil {
+			r = append(r, *v)
+		}
+	}
+	return r
+}
+
+func ptrStrings(s []string) []*string {
+	var r []*string
+	for i := range s {
+		r = append(r, &s[i])
+	}
+	return r
+}
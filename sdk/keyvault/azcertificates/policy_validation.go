@@ -0,0 +1,110 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidPolicy is wrapped by the errors Policy.Validate returns.
+var ErrInvalidPolicy = errors.New("invalid certificate policy")
+
+// rsaKeySizes are the RSA key sizes Key Vault supports
+var rsaKeySizes = []int32{2048, 3072, 4096}
+
+// ecKeySizes maps the elliptic curves to their key sizes
+var ecKeySizes = map[KeyCurveName]int32{
+	KeyCurveNameP256:  256,
+	KeyCurveNameP256K: 256,
+	KeyCurveNameP384:  384,
+	KeyCurveNameP521:  521,
+}
+
+// Validate checks the Policy for combinations of values Key Vault rejects, such as an RSA key size with an EC
+// key type, so they can be fixed before sending a request. It checks only the fields that are set, because
+// UpdateCertificatePolicy updates only those. The error wraps ErrInvalidPolicy. BeginCreateCertificate and
+// UpdateCertificatePolicy call Validate unless their options' SkipPolicyValidation is true.
+func (c Policy) Validate() error {
+	if err := c.validateKey(); err != nil {
+		return err
+	}
+
+	if x := c.X509Properties; x != nil {
+		if (x.Subject == nil || *x.Subject == "") && !x.SubjectAlternativeNames.hasNames() {
+			return fmt.Errorf("%w: X509Properties needs a Subject or SubjectAlternativeNames", ErrInvalidPolicy)
+		}
+		if x.ValidityInMonths != nil && *x.ValidityInMonths <= 0 {
+			return fmt.Errorf("%w: X509Properties.ValidityInMonths is %d, it must be positive", ErrInvalidPolicy, *x.ValidityInMonths)
+		}
+	}
+
+	for i, a := range c.LifetimeActions {
+		if a == nil {
+			continue
+		}
+		if a.DaysBeforeExpiry != nil && a.LifetimePercentage != nil {
+			return fmt.Errorf("%w: LifetimeActions[%d] sets both DaysBeforeExpiry and LifetimePercentage, set only one", ErrInvalidPolicy, i)
+		}
+		if p := a.LifetimePercentage; p != nil && (*p < 1 || *p > 99) {
+			return fmt.Errorf("%w: LifetimeActions[%d].LifetimePercentage is %d, it must be between 1 and 99", ErrInvalidPolicy, i, *p)
+		}
+		if d := a.DaysBeforeExpiry; d != nil {
+			if *d < 1 {
+				return fmt.Errorf("%w: LifetimeActions[%d].DaysBeforeExpiry is %d, it must be at least 1", ErrInvalidPolicy, i, *d)
+			}
+			if c.X509Properties != nil && c.X509Properties.ValidityInMonths != nil {
+				if max := *c.X509Properties.ValidityInMonths * 27; *d > max {
+					return fmt.Errorf("%w: LifetimeActions[%d].DaysBeforeExpiry is %d, it must be at most %d for a validity of %d months",
+						ErrInvalidPolicy, i, *d, max, *c.X509Properties.ValidityInMonths)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c Policy) validateKey() error {
+	if c.KeyType == nil {
+		return nil
+	}
+
+	switch *c.KeyType {
+	case KeyTypeRSA, KeyTypeRSAHSM:
+		if c.KeyCurveName != nil {
+			return fmt.Errorf("%w: KeyCurveName %s is set for key type %s, curves apply only to EC keys", ErrInvalidPolicy, *c.KeyCurveName, *c.KeyType)
+		}
+		if c.KeySize != nil && !contains(rsaKeySizes, *c.KeySize) {
+			return fmt.Errorf("%w: KeySize %d isn't valid for key type %s, use one of %v", ErrInvalidPolicy, *c.KeySize, *c.KeyType, rsaKeySizes)
+		}
+	case KeyTypeEC, KeyTypeECHSM:
+		if c.KeyCurveName != nil {
+			size, ok := ecKeySizes[*c.KeyCurveName]
+			if !ok {
+				return fmt.Errorf("%w: KeyCurveName %q isn't one of %v", ErrInvalidPolicy, *c.KeyCurveName, PossibleKeyCurveNameValues())
+			}
+			if c.KeySize != nil && *c.KeySize != size {
+				return fmt.Errorf("%w: KeySize %d doesn't match KeyCurveName %s, whose size is %d", ErrInvalidPolicy, *c.KeySize, *c.KeyCurveName, size)
+			}
+		} else if c.KeySize != nil {
+			valid := false
+			for _, size := range ecKeySizes {
+				valid = valid || size == *c.KeySize
+			}
+			if !valid {
+				return fmt.Errorf("%w: KeySize %d isn't valid for key type %s, use 256, 384 or 521, or set KeyCurveName instead", ErrInvalidPolicy, *c.KeySize, *c.KeyType)
+			}
+		}
+	}
+	return nil
+}
+
+// hasNames returns whether s has at least one name
+func (s *SubjectAlternativeNames) hasNames() bool {
+	return s != nil && (len(s.DNSNames) > 0 || len(s.Emails) > 0 || len(s.UserPrincipalNames) > 0)
+}
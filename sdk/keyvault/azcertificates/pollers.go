@@ -10,7 +10,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azcertificates/internal/generated"
@@ -21,10 +23,26 @@ type beginCreateCertificateOperation struct {
 	Status  string
 	poll    func(context.Context, string) (*http.Response, error)
 	result  func(context.Context) (CreateCertificateResponse, error)
+
+	// returnCSR makes the operation done when it's waiting for a certificate signed by an external issuer
+	returnCSR bool
+	pending   *Operation
 }
 
 func (b *beginCreateCertificateOperation) Done() bool {
-	return b.Status == "completed" || b.Status == "cancelled"
+	return b.Status == "completed" || b.Status == "cancelled" || b.pending != nil
+}
+
+// observe records op as the pending operation when the caller asked for the CSR of a certificate
+// awaiting an external issuer's signature
+func (b *beginCreateCertificateOperation) observe(op Operation) {
+	if !b.returnCSR || op.Status == nil || *op.Status != "inProgress" || len(op.CSR) == 0 {
+		return
+	}
+	if op.IssuerParameters == nil || op.IssuerParameters.IssuerName == nil || !strings.EqualFold(*op.IssuerParameters.IssuerName, string(WellKnownIssuerNamesUnknown)) {
+		return
+	}
+	b.pending = &op
 }
 
 func (b *beginCreateCertificateOperation) Poll(ctx context.Context) (*http.Response, error) {
@@ -49,10 +67,15 @@ func (b *beginCreateCertificateOperation) Poll(ctx context.Context) (*http.Respo
 		return nil, errors.New("missing status")
 	}
 	b.Status = *op.Status
+	b.observe(op)
 	return resp, nil
 }
 
 func (b *beginCreateCertificateOperation) Result(ctx context.Context, out *CreateCertificateResponse) error {
+	if b.pending != nil {
+		*out = CreateCertificateResponse{PendingOperation: b.pending}
+		return nil
+	}
 	result, err := b.result(ctx)
 	if err != nil {
 		return err
@@ -64,6 +87,121 @@ func (b *beginCreateCertificateOperation) Result(ctx context.Context, out *Creat
 ///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 ///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
 
+type beginImportCertificateOperation struct {
+	PollURL string
+	Status  string
+	poll    func(context.Context, string) (*http.Response, error)
+	result  func(context.Context) (ImportCertificateResponse, error)
+
+	// imported is the response of the import, the result when the import started no operation
+	imported *ImportCertificateResponse
+	opErr    *CertificateOperationError
+}
+
+func (b *beginImportCertificateOperation) Done() bool {
+	return b.Status != "inProgress"
+}
+
+func (b *beginImportCertificateOperation) Poll(ctx context.Context) (*http.Response, error) {
+	resp, err := b.poll(ctx, b.PollURL)
+	if err != nil {
+		return nil, err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return nil, runtime.NewResponseError(resp)
+	}
+	payload, err := runtime.Payload(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var op Operation
+	if err := json.Unmarshal(payload, &op); err != nil {
+		return nil, err
+	}
+
+	if op.Status == nil {
+		return nil, errors.New("missing status")
+	}
+	b.Status = *op.Status
+	b.opErr = op.Error
+	return resp, nil
+}
+
+func (b *beginImportCertificateOperation) Result(ctx context.Context, out *ImportCertificateResponse) error {
+	switch {
+	case b.opErr != nil:
+		return fmt.Errorf("certificate operation %s: %w", b.Status, b.opErr)
+	case b.Status != "completed":
+		return fmt.Errorf("certificate operation %s", b.Status)
+	case b.PollURL == "" && b.imported != nil:
+		*out = *b.imported
+		return nil
+	}
+	result, err := b.result(ctx)
+	if err != nil {
+		return err
+	}
+	*out = result
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
+type beginCancelCertificateOperation struct {
+	PollURL string
+	Status  string
+	poll    func(context.Context, string) (*http.Response, error)
+
+	// op is the operation as of the last poll
+	op *Operation
+}
+
+func (b *beginCancelCertificateOperation) Done() bool {
+	return b.Status != "inProgress"
+}
+
+func (b *beginCancelCertificateOperation) Poll(ctx context.Context) (*http.Response, error) {
+	resp, err := b.poll(ctx, b.PollURL)
+	if err != nil {
+		return nil, err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return nil, runtime.NewResponseError(resp)
+	}
+	payload, err := runtime.Payload(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var op Operation
+	if err := json.Unmarshal(payload, &op); err != nil {
+		return nil, err
+	}
+
+	if op.Status == nil {
+		return nil, errors.New("missing status")
+	}
+	b.Status = *op.Status
+	b.op = &op
+	return resp, nil
+}
+
+func (b *beginCancelCertificateOperation) Result(ctx context.Context, out *CancelCertificateOperationResponse) error {
+	if b.op == nil {
+		// a poller resumed from a token hasn't read the operation yet
+		if _, err := b.Poll(ctx); err != nil {
+			return err
+		}
+	}
+	*out = CancelCertificateOperationResponse{Operation: *b.op}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+///////////////////////////////////////////////////////////////////////////////////////////////////////////////////////
+
 type beginDeleteCertificateOperation struct {
 	resp *http.Response
 	poll func(context.Context) (*http.Response, error)
@@ -0,0 +1,331 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+// Package renewal renews Key Vault certificates that Key Vault doesn't renew by itself: self-signed certificates
+// whose policy has no AutoRenew lifetime action, and certificates whose issuer is "Unknown", whose CSRs must be
+// signed by a CA Key Vault doesn't integrate with. A Controller checks a set of certificates periodically, creates
+// a new version of each one that's due for renewal, and merges the certificates a SignFunc returns for the CSRs of
+// pending versions.
+package renewal
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azcertificates"
+)
+
+const (
+	defaultInterval    = time.Hour
+	defaultRenewBefore = 30 * 24 * time.Hour
+)
+
+// SignFunc signs csr, the signing request of a new version of the certificate name, and returns the signed
+// certificate followed by the certificates of its chain, if any, DER encoded.
+type SignFunc func(ctx context.Context, name string, csr *x509.CertificateRequest) ([][]byte, error)
+
+// Options contains optional parameters for NewController.
+type Options struct {
+	// Interval is how often Run checks the certificates. The default is one hour.
+	Interval time.Duration
+
+	// RenewBefore is how long before it expires a certificate is renewed when its policy has no lifetime
+	// action saying when. The default is 30 days.
+	RenewBefore time.Duration
+
+	// Sign signs the CSRs of certificates whose issuer is "Unknown". When it's nil, such certificates aren't
+	// renewed.
+	Sign SignFunc
+
+	// OnRenewed is called after a new version of a certificate is issued.
+	OnRenewed func(name string, certificate azcertificates.CertificateWithPolicy)
+
+	// OnError is called when checking or renewing a certificate fails. The controller tries again at the
+	// next check.
+	OnError func(name string, err error)
+}
+
+// Action is what a Controller did with a certificate.
+type Action string
+
+const (
+	// ActionNone means the certificate isn't due for renewal.
+	ActionNone Action = "None"
+	// ActionSkipped means the controller doesn't renew the certificate, because Key Vault renews it, or
+	// because its issuer is "Unknown" and Options.Sign isn't set.
+	ActionSkipped Action = "Skipped"
+	// ActionPending means a new version of the certificate is being created.
+	ActionPending Action = "Pending"
+	// ActionRenewed means the controller created a new version of the certificate.
+	ActionRenewed Action = "Renewed"
+	// ActionMerged means the controller signed and merged the CSR of a new version created earlier.
+	ActionMerged Action = "Merged"
+	// ActionFailed means checking or renewing the certificate failed.
+	ActionFailed Action = "Failed"
+)
+
+// Result is the outcome of checking a certificate.
+type Result struct {
+	// Name is the name of the certificate.
+	Name string
+
+	// Action is what the controller did.
+	Action Action
+
+	// Certificate is the new version of the certificate when Action is ActionRenewed or ActionMerged.
+	Certificate *azcertificates.CertificateWithPolicy
+
+	// Err is why checking or renewing the certificate failed when Action is ActionFailed.
+	Err error
+}
+
+// Controller renews a set of certificates. Create one with NewController.
+type Controller struct {
+	vault   vault
+	names   []string
+	options Options
+	now     func() time.Time
+
+	// mu keeps Reconcile from creating two versions of a certificate at once
+	mu sync.Mutex
+}
+
+// NewController creates a Controller that renews the certificates having names in the vault of client.
+// It requires the certificates/get and certificates/create permissions. Pass nil for options to accept
+// default values.
+func NewController(client *azcertificates.Client, names []string, options *Options) (*Controller, error) {
+	if client == nil {
+		return nil, errors.New("client can't be nil")
+	}
+	return newController(clientVault{client}, names, options)
+}
+
+func newController(v vault, names []string, options *Options) (*Controller, error) {
+	if len(names) == 0 {
+		return nil, errors.New("no certificate names")
+	}
+	if options == nil {
+		options = &Options{}
+	}
+	c := &Controller{vault: v, names: names, options: *options, now: time.Now}
+	if c.options.Interval <= 0 {
+		c.options.Interval = defaultInterval
+	}
+	if c.options.RenewBefore <= 0 {
+		c.options.RenewBefore = defaultRenewBefore
+	}
+	return c, nil
+}
+
+// Run checks the certificates every Options.Interval, starting immediately, until ctx is done, and then
+// returns ctx's error.
+func (c *Controller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.options.Interval)
+	defer ticker.Stop()
+	for {
+		c.Reconcile(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile checks each certificate once, renewing the ones that are due, and returns what it did with them,
+// in the order of the names passed to NewController. It calls Options.OnRenewed and Options.OnError as it goes.
+func (c *Controller) Reconcile(ctx context.Context) []Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results := make([]Result, 0, len(c.names))
+	for _, name := range c.names {
+		r := c.reconcile(ctx, name)
+		r.Name = name
+		if r.Err != nil {
+			r.Action = ActionFailed
+			if c.options.OnError != nil {
+				c.options.OnError(name, r.Err)
+			}
+		} else if r.Certificate != nil && c.options.OnRenewed != nil {
+			c.options.OnRenewed(name, *r.Certificate)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func (c *Controller) reconcile(ctx context.Context, name string) Result {
+	cert, err := c.vault.getCertificate(ctx, name)
+	if err != nil {
+		return Result{Err: err}
+	}
+	if cert.Policy == nil {
+		return Result{Err: fmt.Errorf("certificate %s has no policy", name)}
+	}
+	issuer := ""
+	if cert.Policy.IssuerParameters != nil && cert.Policy.IssuerParameters.IssuerName != nil {
+		issuer = *cert.Policy.IssuerParameters.IssuerName
+	}
+	unknownIssuer := strings.EqualFold(issuer, string(azcertificates.WellKnownIssuerNamesUnknown))
+
+	op, err := c.vault.getOperation(ctx, name)
+	if err != nil {
+		return Result{Err: err}
+	}
+	if op != nil && op.Status != nil && strings.EqualFold(*op.Status, "inProgress") {
+		// a version created earlier, by the controller or someone else, awaits its certificate
+		if !unknownIssuer || c.options.Sign == nil || len(op.CSR) == 0 {
+			return Result{Action: ActionPending}
+		}
+		merged, err := c.signAndMerge(ctx, name, op.CSR)
+		if err != nil {
+			return Result{Err: err}
+		}
+		return Result{Action: ActionMerged, Certificate: merged}
+	}
+
+	switch {
+	case strings.EqualFold(issuer, string(azcertificates.WellKnownIssuerNamesSelf)):
+		if autoRenews(cert.Policy) {
+			return Result{Action: ActionSkipped}
+		}
+	case unknownIssuer:
+		if c.options.Sign == nil {
+			return Result{Action: ActionSkipped}
+		}
+	default:
+		// Key Vault renews certificates of the CAs it integrates with
+		return Result{Action: ActionSkipped}
+	}
+
+	if renewAt, ok := c.renewAt(cert); !ok || c.now().Before(renewAt) {
+		return Result{Action: ActionNone}
+	}
+	resp, err := c.vault.create(ctx, name, *cert.Policy)
+	if err != nil {
+		return Result{Err: err}
+	}
+	if resp.PendingOperation != nil {
+		merged, err := c.signAndMerge(ctx, name, resp.PendingOperation.CSR)
+		if err != nil {
+			return Result{Err: err}
+		}
+		return Result{Action: ActionRenewed, Certificate: merged}
+	}
+	return Result{Action: ActionRenewed, Certificate: &resp.CertificateWithPolicy}
+}
+
+// renewAt returns when cert is due for renewal, according to the first lifetime action of its policy that has
+// a trigger, or Options.RenewBefore when none does. It returns false when cert has no expiry.
+func (c *Controller) renewAt(cert azcertificates.CertificateWithPolicy) (time.Time, bool) {
+	if cert.Properties == nil || cert.Properties.ExpiresOn == nil {
+		return time.Time{}, false
+	}
+	expiresOn := *cert.Properties.ExpiresOn
+	for _, la := range cert.Policy.LifetimeActions {
+		if la == nil {
+			continue
+		}
+		if la.DaysBeforeExpiry != nil {
+			return expiresOn.Add(-time.Duration(*la.DaysBeforeExpiry) * 24 * time.Hour), true
+		}
+		if la.LifetimePercentage != nil && cert.Properties.NotBefore != nil {
+			notBefore := *cert.Properties.NotBefore
+			lifetime := expiresOn.Sub(notBefore)
+			return notBefore.Add(lifetime * time.Duration(*la.LifetimePercentage) / 100), true
+		}
+	}
+	return expiresOn.Add(-c.options.RenewBefore), true
+}
+
+// signAndMerge signs a DER encoded CSR of the certificate name with Options.Sign and merges the result
+func (c *Controller) signAndMerge(ctx context.Context, name string, der []byte) (*azcertificates.CertificateWithPolicy, error) {
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing the CSR of certificate %s: %w", name, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("checking the CSR of certificate %s: %w", name, err)
+	}
+	chain, err := c.options.Sign(ctx, name, csr)
+	if err != nil {
+		return nil, fmt.Errorf("signing the CSR of certificate %s: %w", name, err)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("signing the CSR of certificate %s returned no certificate", name)
+	}
+	merged, err := c.vault.merge(ctx, name, chain)
+	if err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
+
+// autoRenews returns whether Key Vault renews certificates having policy p
+func autoRenews(p *azcertificates.Policy) bool {
+	for _, la := range p.LifetimeActions {
+		if la != nil && la.Action != nil && *la.Action == azcertificates.PolicyActionAutoRenew {
+			return true
+		}
+	}
+	return false
+}
+
+// vault is the part of azcertificates.Client a Controller uses
+type vault interface {
+	getCertificate(ctx context.Context, name string) (azcertificates.CertificateWithPolicy, error)
+	// getOperation returns nil when the certificate has no operation
+	getOperation(ctx context.Context, name string) (*azcertificates.Operation, error)
+	// create creates a new version of the certificate, returning its pending operation when it
+	// awaits a signed certificate
+	create(ctx context.Context, name string, policy azcertificates.Policy) (azcertificates.CreateCertificateResponse, error)
+	merge(ctx context.Context, name string, certificates [][]byte) (azcertificates.CertificateWithPolicy, error)
+}
+
+type clientVault struct {
+	client *azcertificates.Client
+}
+
+func (v clientVault) getCertificate(ctx context.Context, name string) (azcertificates.CertificateWithPolicy, error) {
+	resp, err := v.client.GetCertificate(ctx, name, nil)
+	return resp.CertificateWithPolicy, err
+}
+
+func (v clientVault) getOperation(ctx context.Context, name string) (*azcertificates.Operation, error) {
+	resp, err := v.client.GetCertificateOperation(ctx, name, nil)
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Operation, nil
+}
+
+func (v clientVault) create(ctx context.Context, name string, policy azcertificates.Policy) (azcertificates.CreateCertificateResponse, error) {
+	// the policy is the certificate's own, which the service already accepted
+	poller, err := v.client.BeginCreateCertificate(ctx, name, policy, &azcertificates.BeginCreateCertificateOptions{
+		ReturnCSROnPending:   true,
+		SkipPolicyValidation: true,
+	})
+	if err != nil {
+		return azcertificates.CreateCertificateResponse{}, err
+	}
+	return poller.PollUntilDone(ctx, nil)
+}
+
+func (v clientVault) merge(ctx context.Context, name string, certificates [][]byte) (azcertificates.CertificateWithPolicy, error) {
+	resp, err := v.client.MergeCertificate(ctx, name, certificates, nil)
+	return resp.CertificateWithPolicy, err
+}
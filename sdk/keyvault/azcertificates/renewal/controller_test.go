@@ -0,0 +1,224 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package renewal
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azcertificates"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeVault struct {
+	certs   map[string]azcertificates.CertificateWithPolicy
+	ops     map[string]*azcertificates.Operation
+	csr     []byte
+	created []string
+	merged  map[string][][]byte
+}
+
+func (f *fakeVault) getCertificate(ctx context.Context, name string) (azcertificates.CertificateWithPolicy, error) {
+	cert, ok := f.certs[name]
+	if !ok {
+		return azcertificates.CertificateWithPolicy{}, errors.New("not found")
+	}
+	return cert, nil
+}
+
+func (f *fakeVault) getOperation(ctx context.Context, name string) (*azcertificates.Operation, error) {
+	return f.ops[name], nil
+}
+
+func (f *fakeVault) create(ctx context.Context, name string, policy azcertificates.Policy) (azcertificates.CreateCertificateResponse, error) {
+	f.created = append(f.created, name)
+	if *policy.IssuerParameters.IssuerName == string(azcertificates.WellKnownIssuerNamesUnknown) {
+		return azcertificates.CreateCertificateResponse{PendingOperation: &azcertificates.Operation{CSR: f.csr, Status: to.Ptr("inProgress")}}, nil
+	}
+	return azcertificates.CreateCertificateResponse{CertificateWithPolicy: azcertificates.CertificateWithPolicy{ID: to.Ptr(name + "/v2")}}, nil
+}
+
+func (f *fakeVault) merge(ctx context.Context, name string, certificates [][]byte) (azcertificates.CertificateWithPolicy, error) {
+	if f.merged == nil {
+		f.merged = map[string][][]byte{}
+	}
+	f.merged[name] = certificates
+	return azcertificates.CertificateWithPolicy{ID: to.Ptr(name + "/merged")}, nil
+}
+
+func testCertificate(issuer azcertificates.WellKnownIssuerNames, notBefore, expiresOn time.Time, actions ...*azcertificates.LifetimeAction) azcertificates.CertificateWithPolicy {
+	return azcertificates.CertificateWithPolicy{
+		Properties: &azcertificates.Properties{NotBefore: &notBefore, ExpiresOn: &expiresOn},
+		Policy: &azcertificates.Policy{
+			IssuerParameters: &azcertificates.IssuerParameters{IssuerName: to.Ptr(string(issuer))},
+			LifetimeActions:  actions,
+		},
+	}
+}
+
+func testCA(t *testing.T) SignFunc {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	sign, err := NewCASigner(ca, key, time.Hour)
+	require.NoError(t, err)
+	return sign
+}
+
+func testCSR(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "www.contoso.com"},
+		DNSNames: []string{"www.contoso.com", "contoso.com"},
+	}, key)
+	require.NoError(t, err)
+	return csr
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	year := 365 * 24 * time.Hour
+	v := &fakeVault{
+		certs: map[string]azcertificates.CertificateWithPolicy{
+			// due 30 days before it expires, by default
+			"self-due":    testCertificate(azcertificates.WellKnownIssuerNamesSelf, now.Add(-year), now.Add(29*24*time.Hour)),
+			"self-notdue": testCertificate(azcertificates.WellKnownIssuerNamesSelf, now.Add(-year), now.Add(31*24*time.Hour)),
+			// Key Vault renews it
+			"self-auto": testCertificate(azcertificates.WellKnownIssuerNamesSelf, now.Add(-year), now.Add(time.Hour),
+				&azcertificates.LifetimeAction{Action: to.Ptr(azcertificates.PolicyActionAutoRenew), DaysBeforeExpiry: to.Ptr[int32](60)}),
+			// due at 80% of its lifetime, which has passed
+			"self-percentage": testCertificate(azcertificates.WellKnownIssuerNamesSelf, now.Add(-9*24*time.Hour), now.Add(24*time.Hour),
+				&azcertificates.LifetimeAction{Action: to.Ptr(azcertificates.PolicyActionEmailContacts), LifetimePercentage: to.Ptr[int32](80)}),
+			// not due until 10 days before it expires
+			"self-days": testCertificate(azcertificates.WellKnownIssuerNamesSelf, now.Add(-year), now.Add(20*24*time.Hour),
+				&azcertificates.LifetimeAction{Action: to.Ptr(azcertificates.PolicyActionEmailContacts), DaysBeforeExpiry: to.Ptr[int32](10)}),
+			"unknown-due": testCertificate(azcertificates.WellKnownIssuerNamesUnknown, now.Add(-year), now.Add(time.Hour)),
+			"digicert":    testCertificate("DigiCert", now.Add(-year), now.Add(time.Hour)),
+		},
+		csr: testCSR(t),
+	}
+	var renewed, failed []string
+	c, err := newController(v, []string{"self-due", "self-notdue", "self-auto", "self-percentage", "self-days", "unknown-due", "digicert", "missing"}, &Options{
+		Sign:      testCA(t),
+		OnRenewed: func(name string, _ azcertificates.CertificateWithPolicy) { renewed = append(renewed, name) },
+		OnError:   func(name string, _ error) { failed = append(failed, name) },
+	})
+	require.NoError(t, err)
+	c.now = func() time.Time { return now }
+
+	results := c.Reconcile(context.Background())
+	actions := map[string]Action{}
+	for _, r := range results {
+		actions[r.Name] = r.Action
+	}
+	require.Equal(t, map[string]Action{
+		"self-due":        ActionRenewed,
+		"self-notdue":     ActionNone,
+		"self-auto":       ActionSkipped,
+		"self-percentage": ActionRenewed,
+		"self-days":       ActionNone,
+		"unknown-due":     ActionRenewed,
+		"digicert":        ActionSkipped,
+		"missing":         ActionFailed,
+	}, actions)
+	require.Equal(t, []string{"self-due", "self-percentage", "unknown-due"}, v.created)
+	require.Equal(t, []string{"self-due", "self-percentage", "unknown-due"}, renewed)
+	require.Equal(t, []string{"missing"}, failed)
+	require.Equal(t, "unknown-due/merged", *results[5].Certificate.ID)
+
+	chain := v.merged["unknown-due"]
+	require.Len(t, chain, 2)
+	leaf, err := x509.ParseCertificate(chain[0])
+	require.NoError(t, err)
+	require.Equal(t, "www.contoso.com", leaf.Subject.CommonName)
+	require.Equal(t, []string{"www.contoso.com", "contoso.com"}, leaf.DNSNames)
+	require.False(t, leaf.IsCA)
+	ca, err := x509.ParseCertificate(chain[1])
+	require.NoError(t, err)
+	require.NoError(t, leaf.CheckSignatureFrom(ca))
+}
+
+func TestReconcilePendingOperation(t *testing.T) {
+	now := time.Now()
+	v := &fakeVault{
+		certs: map[string]azcertificates.CertificateWithPolicy{
+			"unknown": testCertificate(azcertificates.WellKnownIssuerNamesUnknown, now.Add(-time.Hour), now.Add(time.Hour)),
+			"self":    testCertificate(azcertificates.WellKnownIssuerNamesSelf, now.Add(-time.Hour), now.Add(time.Hour)),
+		},
+		ops: map[string]*azcertificates.Operation{
+			"unknown": {Status: to.Ptr("inProgress"), CSR: testCSR(t)},
+			"self":    {Status: to.Ptr("inProgress")},
+		},
+	}
+	c, err := newController(v, []string{"unknown", "self"}, &Options{Sign: testCA(t)})
+	require.NoError(t, err)
+	results := c.Reconcile(context.Background())
+	require.Equal(t, ActionMerged, results[0].Action)
+	require.Equal(t, ActionPending, results[1].Action)
+	require.Empty(t, v.created)
+	require.Len(t, v.merged["unknown"], 2)
+
+	// without a signer, certificates of unknown issuers are left alone
+	c, err = newController(v, []string{"unknown"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, ActionPending, c.Reconcile(context.Background())[0].Action)
+	delete(v.ops, "unknown")
+	require.Equal(t, ActionSkipped, c.Reconcile(context.Background())[0].Action)
+}
+
+func TestReconcileSignError(t *testing.T) {
+	now := time.Now()
+	v := &fakeVault{
+		certs: map[string]azcertificates.CertificateWithPolicy{
+			"unknown": testCertificate(azcertificates.WellKnownIssuerNamesUnknown, now.Add(-time.Hour), now.Add(time.Hour)),
+		},
+		csr: testCSR(t),
+	}
+	signErr := errors.New("CA unavailable")
+	c, err := newController(v, []string{"unknown"}, &Options{
+		Sign: func(context.Context, string, *x509.CertificateRequest) ([][]byte, error) { return nil, signErr },
+	})
+	require.NoError(t, err)
+	r := c.Reconcile(context.Background())[0]
+	require.Equal(t, ActionFailed, r.Action)
+	require.ErrorIs(t, r.Err, signErr)
+	require.Empty(t, v.merged)
+}
+
+func TestNewController(t *testing.T) {
+	_, err := NewController(nil, []string{"cert"}, nil)
+	require.Error(t, err)
+	_, err = newController(&fakeVault{}, nil, nil)
+	require.Error(t, err)
+
+	c, err := newController(&fakeVault{}, []string{"cert"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, defaultInterval, c.options.Interval)
+	require.Equal(t, defaultRenewBefore, c.options.RenewBefore)
+}
@@ -0,0 +1,70 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package renewal
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// the CSR extensions a CA signer copies into the certificates it issues
+var (
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionSubjectAltName   = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidExtensionExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+	copiedExtensions             = []asn1.ObjectIdentifier{oidExtensionKeyUsage, oidExtensionSubjectAltName, oidExtensionExtendedKeyUsage}
+)
+
+const serialNumberLimit = 1 << 62
+
+// NewCASigner returns a SignFunc that issues certificates valid for validity with the CA certificate ca, whose
+// private key is key, for example a private CA's. The certificates have the subject, subject alternative names,
+// key usages and extended key usages the CSR requests, and are returned with ca.
+func NewCASigner(ca *x509.Certificate, key crypto.Signer, validity time.Duration) (SignFunc, error) {
+	if ca == nil || key == nil {
+		return nil, errors.New("the CA certificate and key are required")
+	}
+	if !ca.IsCA {
+		return nil, errors.New("the certificate isn't a CA certificate")
+	}
+	if validity <= 0 {
+		return nil, errors.New("validity must be positive")
+	}
+	return func(ctx context.Context, name string, csr *x509.CertificateRequest) ([][]byte, error) {
+		serial, err := rand.Int(rand.Reader, big.NewInt(serialNumberLimit))
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		template := &x509.Certificate{
+			SerialNumber: serial,
+			Subject:      csr.Subject,
+			// allow for clock skew between this host and the certificate's users
+			NotBefore: now.Add(-5 * time.Minute),
+			NotAfter:  now.Add(validity),
+		}
+		for _, ext := range csr.Extensions {
+			for _, id := range copiedExtensions {
+				if ext.Id.Equal(id) {
+					template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{Id: ext.Id, Critical: ext.Critical, Value: ext.Value})
+				}
+			}
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, csr.PublicKey, key)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{der, ca.Raw}, nil
+	}, nil
+}
@@ -0,0 +1,118 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	shared "github.com/Azure/azure-sdk-for-go/sdk/keyvault/internal"
+)
+
+// resumeTokenVersion is the version of the resume token envelope written by this module. Later versions of
+// the module read tokens of every version up to theirs, so a token can be resumed by a process running a
+// newer minor version. A change that older modules couldn't read requires a new version.
+const resumeTokenVersion = 1
+
+const (
+	resumeTokenOperationCreate  = "create"
+	resumeTokenOperationDelete  = "delete"
+	resumeTokenOperationRecover = "recover"
+)
+
+// resumeToken is the versioned envelope of resume tokens. Its JSON field names must not change.
+type resumeToken struct {
+	Version     int    `json:"version"`
+	Operation   string `json:"operation"`
+	Certificate string `json:"certificate"`
+
+	// PollURL is the URL of the pending certificate operation, for create
+	PollURL string `json:"pollURL,omitempty"`
+}
+
+func (r resumeToken) String() string {
+	// marshaling a struct of strings can't fail
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// ResumeToken returns a token for resuming polling of the certificate creation o belongs to, in this or
+// another process, by passing it to Client.BeginCreateCertificate in BeginCreateCertificateOptions.ResumeToken.
+// Unlike the token of a Poller, it can be created for an operation gotten from Client.GetCertificateOperation
+// or from CreateCertificateResponse.PendingOperation. The token is versioned, and later minor versions of this
+// module accept it. o must have an ID.
+func (o Operation) ResumeToken() (string, error) {
+	if o.ID == nil || *o.ID == "" {
+		return "", errors.New("the operation has no ID")
+	}
+	_, name, _ := shared.ParseID(o.ID)
+	if name == nil || *name == "" {
+		return "", fmt.Errorf("the operation ID %q has no certificate name", *o.ID)
+	}
+	return resumeToken{
+		Version:     resumeTokenVersion,
+		Operation:   resumeTokenOperationCreate,
+		Certificate: *name,
+		PollURL:     *o.ID,
+	}.String(), nil
+}
+
+// DeleteCertificateResumeToken returns a token for resuming polling of the deletion of a certificate, in this or
+// another process, by passing it to Client.BeginDeleteCertificate in BeginDeleteCertificateOptions.ResumeToken.
+// The deletion must have been started. The token is versioned, and later minor versions of this module accept it.
+func DeleteCertificateResumeToken(certificateName string) string {
+	return resumeToken{Version: resumeTokenVersion, Operation: resumeTokenOperationDelete, Certificate: certificateName}.String()
+}
+
+// RecoverDeletedCertificateResumeToken returns a token for resuming polling of the recovery of a deleted
+// certificate, in this or another process, by passing it to Client.BeginRecoverDeletedCertificate in
+// BeginRecoverDeletedCertificateOptions.ResumeToken. The recovery must have been started. The token is versioned,
+// and later minor versions of this module accept it.
+func RecoverDeletedCertificateResumeToken(certificateName string) string {
+	return resumeToken{Version: resumeTokenVersion, Operation: resumeTokenOperationRecover, Certificate: certificateName}.String()
+}
+
+// parseResumeToken parses a versioned token for operation on the named certificate. It returns nil and no error
+// when token isn't versioned, that is when it's the token of a Poller.
+func (c *Client) parseResumeToken(token, operation, certificateName string) (*resumeToken, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(token), &fields); err != nil {
+		return nil, fmt.Errorf("invalid resume token: %w", err)
+	}
+	if _, ok := fields["version"]; !ok {
+		return nil, nil
+	}
+	var rt resumeToken
+	if err := json.Unmarshal([]byte(token), &rt); err != nil {
+		return nil, fmt.Errorf("invalid resume token: %w", err)
+	}
+	switch {
+	case rt.Version < 1:
+		return nil, fmt.Errorf("invalid resume token version %d", rt.Version)
+	case rt.Version > resumeTokenVersion:
+		return nil, fmt.Errorf("the resume token has version %d, which requires a newer version of this module", rt.Version)
+	case rt.Operation != operation:
+		return nil, fmt.Errorf("the resume token is for a %s operation, not %s", rt.Operation, operation)
+	case rt.Certificate != certificateName:
+		return nil, fmt.Errorf("the resume token is for certificate %q, not %q", rt.Certificate, certificateName)
+	}
+	if operation == resumeTokenOperationCreate {
+		// the poller sends the client's credential to the poll URL, so it must be in the client's vault
+		pollURL, err := url.Parse(rt.PollURL)
+		if err != nil || pollURL.Scheme != "https" {
+			return nil, fmt.Errorf("the resume token has an invalid poll URL %q", rt.PollURL)
+		}
+		vaultURL, err := url.Parse(c.vaultURL)
+		if err != nil || !strings.EqualFold(pollURL.Host, vaultURL.Host) {
+			return nil, fmt.Errorf("the resume token is for vault %s, not %s", pollURL.Host, c.vaultURL)
+		}
+	}
+	return &rt, nil
+}
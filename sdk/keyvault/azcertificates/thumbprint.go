@@ -0,0 +1,139 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azcertificates
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	shared "github.com/Azure/azure-sdk-for-go/sdk/keyvault/internal"
+)
+
+// ErrThumbprintNotFound is returned by GetCertificateByThumbprint when no certificate version has the thumbprint.
+var ErrThumbprintNotFound = errors.New("no certificate has the thumbprint")
+
+// GetCertificateByThumbprintOptions contains optional parameters for Client.GetCertificateByThumbprint
+type GetCertificateByThumbprintOptions struct {
+	// LatestVersionsOnly limits the search to the latest version of each certificate.
+	LatestVersionsOnly bool
+}
+
+// GetCertificateByThumbprintResponse contains response fields for Client.GetCertificateByThumbprint
+type GetCertificateByThumbprintResponse struct {
+	CertificateWithPolicy
+}
+
+// GetCertificateByThumbprint finds the certificate version having a thumbprint, such as one a TLS stack
+// reports, and gets it. thumbprint is the hex encoded SHA-1 or SHA-256 hash of the certificate's DER data;
+// it may contain colons or spaces between bytes. Key Vault can't filter certificates by thumbprint, so this
+// method searches the latest version of every certificate and then, unless options.LatestVersionsOnly is true,
+// every other version, stopping at the first match. Listed certificates carry their SHA-1 thumbprint, so a
+// SHA-1 search gets only the matching certificate, while a SHA-256 search gets each certificate it checks.
+// It returns ErrThumbprintNotFound when no version matches. This operation requires the certificates/list and
+// certificates/get permissions. Pass nil for options to accept default values.
+func (c *Client) GetCertificateByThumbprint(ctx context.Context, thumbprint string, options *GetCertificateByThumbprintOptions) (GetCertificateByThumbprintResponse, error) {
+	if options == nil {
+		options = &GetCertificateByThumbprintOptions{}
+	}
+	want, err := hex.DecodeString(strings.NewReplacer(":", "", " ", "").Replace(thumbprint))
+	if err != nil {
+		return GetCertificateByThumbprintResponse{}, fmt.Errorf("invalid thumbprint: %w", err)
+	}
+	if len(want) != sha1.Size && len(want) != sha256.Size {
+		return GetCertificateByThumbprintResponse{}, fmt.Errorf("invalid thumbprint: %d bytes isn't the size of a SHA-1 or SHA-256 hash", len(want))
+	}
+
+	// match gets the certificate version the item refers to when it has the thumbprint
+	match := func(item *CertificateItem) (*CertificateWithPolicy, error) {
+		if item == nil || item.Properties == nil || len(item.Properties.X509Thumbprint) == 0 {
+			// a pending certificate has no issued version yet
+			return nil, nil
+		}
+		_, name, version := shared.ParseID(item.ID)
+		if name == nil {
+			return nil, nil
+		}
+		if len(want) == sha1.Size && !bytes.Equal(item.Properties.X509Thumbprint, want) {
+			return nil, nil
+		}
+		var v string
+		if version != nil {
+			v = *version
+		}
+		resp, err := c.GetCertificate(ctx, *name, &GetCertificateOptions{Version: v})
+		if err != nil {
+			return nil, err
+		}
+		if len(want) == sha256.Size {
+			if sum := sha256.Sum256(resp.CER); !bytes.Equal(sum[:], want) {
+				return nil, nil
+			}
+		}
+		return &resp.CertificateWithPolicy, nil
+	}
+
+	// the latest versions are the likeliest to match, and listing them takes the fewest requests
+	var names []string
+	// latest maps the names of the certificates to the thumbprints of their latest versions, which were checked
+	latest := map[string][]byte{}
+	pager := c.NewListPropertiesOfCertificatesPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return GetCertificateByThumbprintResponse{}, err
+		}
+		for _, item := range page.Certificates {
+			cert, err := match(item)
+			if err != nil {
+				return GetCertificateByThumbprintResponse{}, err
+			}
+			if cert != nil {
+				return GetCertificateByThumbprintResponse{CertificateWithPolicy: *cert}, nil
+			}
+			if item != nil {
+				if _, name, _ := shared.ParseID(item.ID); name != nil {
+					names = append(names, *name)
+					if item.Properties != nil {
+						latest[*name] = item.Properties.X509Thumbprint
+					}
+				}
+			}
+		}
+	}
+	if options.LatestVersionsOnly {
+		return GetCertificateByThumbprintResponse{}, ErrThumbprintNotFound
+	}
+
+	for _, name := range names {
+		pager := c.NewListPropertiesOfCertificateVersionsPager(name, nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return GetCertificateByThumbprintResponse{}, err
+			}
+			for _, item := range page.Certificates {
+				if item == nil || item.Properties == nil || bytes.Equal(item.Properties.X509Thumbprint, latest[name]) {
+					continue
+				}
+				cert, err := match(item)
+				if err != nil {
+					return GetCertificateByThumbprintResponse{}, err
+				}
+				if cert != nil {
+					return GetCertificateByThumbprintResponse{CertificateWithPolicy: *cert}, nil
+				}
+			}
+		}
+	}
+	return GetCertificateByThumbprintResponse{}, ErrThumbprintNotFound
+}
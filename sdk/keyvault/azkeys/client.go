@@ -8,11 +8,19 @@ package azkeys
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/crypto"
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal"
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/base"
@@ -22,8 +30,9 @@ import (
 
 // Client interacts with Key Vault keys.
 type Client struct {
-	kvClient *generated.KeyVaultClient
-	vaultURL string
+	kvClient   *generated.KeyVaultClient
+	vaultURL   string
+	managedHSM bool
 }
 
 // ClientOptions are the configurable options for a Client.
@@ -47,7 +56,9 @@ func (c *ClientOptions) toConnectionOptions() *policy.ClientOptions {
 	}
 }
 
-// NewClient constructs a Client that accesses a Key Vault's keys.
+// NewClient constructs a Client that accesses a Key Vault's keys. vaultURL may be a vault's or a Managed HSM's URL.
+// The Client detects which from the URL's host name, and returns a *NotSupportedError for operations the service
+// doesn't support, such as GetRandomBytes on a vault.
 func NewClient(vaultURL string, credential azcore.TokenCredential, options *ClientOptions) (*Client, error) {
 	if options == nil {
 		options = &ClientOptions{}
@@ -62,8 +73,9 @@ func NewClient(vaultURL string, credential azcore.TokenCredential, options *Clie
 
 	pl := runtime.NewPipeline(internal.ModuleName, internal.ModuleVersion, runtime.PipelineOptions{}, genOptions)
 	return &Client{
-		kvClient: generated.NewKeyVaultClient(pl),
-		vaultURL: vaultURL,
+		kvClient:   generated.NewKeyVaultClient(pl),
+		vaultURL:   vaultURL,
+		managedHSM: isManagedHSMURL(vaultURL),
 	}, nil
 }
 
@@ -72,6 +84,11 @@ func (c *Client) VaultURL() string {
 	return c.vaultURL
 }
 
+// IsManagedHSM returns whether the client's URL is a Managed HSM's rather than a vault's.
+func (c *Client) IsManagedHSM() bool {
+	return c.managedHSM
+}
+
 // NewCryptoClient creates a new *crypto.Client for the specified key and optional version.
 // The created client uses the same vault URL and options as this Client.
 func (c *Client) NewCryptoClient(keyName string, keyVersion *string) *crypto.Client {
@@ -163,10 +180,13 @@ func (c *Client) CreateKey(ctx context.Context, name string, keyType KeyType, op
 	if options == nil {
 		options = &CreateKeyOptions{}
 	}
+	if err := c.checkKeyType("CreateKey", &keyType); err != nil {
+		return CreateKeyResponse{}, err
+	}
 
 	resp, err := c.kvClient.CreateKey(ctx, c.vaultURL, name, options.toKeyCreateParameters(keyType), options.toGenerated())
 	if err != nil {
-		return CreateKeyResponse{}, err
+		return CreateKeyResponse{}, wrapError(err)
 	}
 
 	return createKeyResponseFromGenerated(resp), nil
@@ -244,7 +264,7 @@ func (c *Client) CreateECKey(ctx context.Context, name string, options *CreateEC
 
 	resp, err := c.kvClient.CreateKey(ctx, c.vaultURL, name, options.toKeyCreateParameters(keyType), &generated.KeyVaultClientCreateKeyOptions{})
 	if err != nil {
-		return CreateECKeyResponse{}, err
+		return CreateECKeyResponse{}, wrapError(err)
 	}
 
 	return createECKeyResponseFromGenerated(resp), nil
@@ -255,13 +275,14 @@ type CreateOctKeyOptions struct {
 	// HardwareProtected determines whether the key is is created in a hardware security module (HSM).
 	HardwareProtected *bool
 
-	// Size is the key size in bits. For example: 128, 192 or 256.
+	// Size is the key size in bits: 128, 192 or 256. When nil, Key Vault creates a 256-bit key.
 	Size *int32
 
 	// Properties is the key's management properties.
 	Properties *Properties
 
-	// Operations are the operations Key Vault will allow for the key.
+	// Operations are the operations Key Vault will allow for the key. When nil, the key allows
+	// encrypt, decrypt, wrapKey and unwrapKey.
 	Operations []*Operation
 
 	// ReleasePolicy specifies conditions under which the key can be exported
@@ -271,6 +292,9 @@ type CreateOctKeyOptions struct {
 	Tags map[string]*string
 }
 
+// validOctKeySizes are the AES key sizes Managed HSM supports
+var validOctKeySizes = map[int32]bool{128: true, 192: true, 256: true}
+
 // conver the CreateOctKeyOptions to generated.KeyCreateParameters
 func (c *CreateOctKeyOptions) toKeyCreateParameters(keyType KeyType) generated.KeyCreateParameters {
 	var keyOps []*generated.JSONWebKeyOperation
@@ -308,8 +332,14 @@ func createOctKeyResponseFromGenerated(g generated.KeyVaultClientCreateKeyRespon
 	}
 }
 
-// CreateOctKey creates a new AES key. If the named key already exists, this creates a new version of the key. Pass nil for options to accept default values.
+// CreateOctKey creates a new AES key. If the named key already exists, this creates a new version of the key. Only
+// Managed HSMs support AES keys. The key is protected by the HSM unless options.HardwareProtected is false. It returns
+// an error without sending a request when options.Size isn't 128, 192 or 256. Pass nil for options to accept default
+// values.
 func (c *Client) CreateOctKey(ctx context.Context, name string, options *CreateOctKeyOptions) (CreateOctKeyResponse, error) {
+	if err := c.requireManagedHSM("CreateOctKey"); err != nil {
+		return CreateOctKeyResponse{}, err
+	}
 	keyType := KeyTypeOctHSM
 
 	if options != nil && options.HardwareProtected != nil && !*options.HardwareProtected {
@@ -317,10 +347,22 @@ func (c *Client) CreateOctKey(ctx context.Context, name string, options *CreateO
 	} else if options == nil {
 		options = &CreateOctKeyOptions{}
 	}
+	if options.Size != nil && !validOctKeySizes[*options.Size] {
+		return CreateOctKeyResponse{}, fmt.Errorf("AES keys must have 128, 192 or 256 bits, not %d", *options.Size)
+	}
+	params := options.toKeyCreateParameters(keyType)
+	if params.KeyOps == nil {
+		params.KeyOps = []*generated.JSONWebKeyOperation{
+			to.Ptr(generated.JSONWebKeyOperationEncrypt),
+			to.Ptr(generated.JSONWebKeyOperationDecrypt),
+			to.Ptr(generated.JSONWebKeyOperationWrapKey),
+			to.Ptr(generated.JSONWebKeyOperationUnwrapKey),
+		}
+	}
 
-	resp, err := c.kvClient.CreateKey(ctx, c.vaultURL, name, options.toKeyCreateParameters(keyType), &generated.KeyVaultClientCreateKeyOptions{})
+	resp, err := c.kvClient.CreateKey(ctx, c.vaultURL, name, params, &generated.KeyVaultClientCreateKeyOptions{})
 	if err != nil {
-		return CreateOctKeyResponse{}, err
+		return CreateOctKeyResponse{}, wrapError(err)
 	}
 
 	return createOctKeyResponseFromGenerated(resp), nil
@@ -404,15 +446,41 @@ func (c *Client) CreateRSAKey(ctx context.Context, name string, options *CreateR
 
 	resp, err := c.kvClient.CreateKey(ctx, c.vaultURL, name, options.toKeyCreateParameters(keyType), &generated.KeyVaultClientCreateKeyOptions{})
 	if err != nil {
-		return CreateRSAKeyResponse{}, err
+		return CreateRSAKeyResponse{}, wrapError(err)
 	}
 
 	return createRSAKeyResponseFromGenerated(resp), nil
 }
 
-// ListPropertiesOfKeysOptions contains optional parameters for ListKeys
+// ListPropertiesOfKeysOptions contains optional parameters for NewListPropertiesOfKeysPager
 type ListPropertiesOfKeysOptions struct {
-	// placeholder for future optional parameters
+	// IncludeManaged includes keys whose lifetime Key Vault manages, such as those backing certificates,
+	// which aren't listed by default.
+	IncludeManaged bool
+
+	// Tags, when set, restricts the listed keys to those with all the specified tags and values. Key Vault
+	// doesn't filter keys by tag, so the keys are filtered by the client and pages may have fewer keys than
+	// Key Vault returned, or none.
+	Tags map[string]string
+}
+
+// includes returns whether the options select key
+func (l ListPropertiesOfKeysOptions) includes(key *KeyItem) bool {
+	if key == nil {
+		return false
+	}
+	if key.Properties != nil && key.Properties.Managed != nil && *key.Properties.Managed && !l.IncludeManaged {
+		return false
+	}
+	for name, value := range l.Tags {
+		if key.Properties == nil {
+			return false
+		}
+		if v, ok := key.Properties.Tags[name]; !ok || v == nil || *v != value {
+			return false
+		}
+	}
+	return true
 }
 
 // ListPropertiesOfKeysResponse contains a page of key properties.
@@ -424,11 +492,13 @@ type ListPropertiesOfKeysResponse struct {
 	Keys []*KeyItem
 }
 
-// convert internal Response to ListKeysPage
-func listKeysPageFromGenerated(i generated.KeyVaultClientGetKeysResponse) ListPropertiesOfKeysResponse {
+// convert internal Response to ListKeysPage, including only the keys options selects
+func listKeysPageFromGenerated(i generated.KeyVaultClientGetKeysResponse, options ListPropertiesOfKeysOptions) ListPropertiesOfKeysResponse {
 	var keys []*KeyItem
 	for _, k := range i.Value {
-		keys = append(keys, keyItemFromGenerated(k))
+		if key := keyItemFromGenerated(k); options.includes(key) {
+			keys = append(keys, key)
+		}
 	}
 	return ListPropertiesOfKeysResponse{
 		NextLink: i.NextLink,
@@ -439,8 +509,12 @@ func listKeysPageFromGenerated(i generated.KeyVaultClientGetKeysResponse) ListPr
 // NewListPropertiesOfKeysPager retrieves a list of the keys in the Key Vault as JSON Web Key structures that contain the
 // public part of a stored key. The LIST operation is applicable to all key types, however only the
 // base key identifier, attributes, and tags are provided in the response. Individual versions of a
-// key are not listed in the response. This operation requires the keys/list permission.
+// key are not listed in the response. Keys managed by Key Vault, such as those backing certificates, are listed only
+// when options.IncludeManaged is true. This operation requires the keys/list permission.
 func (c *Client) NewListPropertiesOfKeysPager(options *ListPropertiesOfKeysOptions) *runtime.Pager[ListPropertiesOfKeysResponse] {
+	if options == nil {
+		options = &ListPropertiesOfKeysOptions{}
+	}
 	return runtime.NewPager(runtime.PagingHandler[ListPropertiesOfKeysResponse]{
 		More: func(page ListPropertiesOfKeysResponse) bool {
 			return page.NextLink != nil && len(*page.NextLink) > 0
@@ -461,13 +535,13 @@ func (c *Client) NewListPropertiesOfKeysPager(options *ListPropertiesOfKeysOptio
 				return ListPropertiesOfKeysResponse{}, err
 			}
 			if !runtime.HasStatusCode(resp, http.StatusOK) {
-				return ListPropertiesOfKeysResponse{}, runtime.NewResponseError(resp)
+				return ListPropertiesOfKeysResponse{}, newResponseError(resp)
 			}
 			genResp, err := c.kvClient.GetKeysHandleResponse(resp)
 			if err != nil {
 				return ListPropertiesOfKeysResponse{}, err
 			}
-			return listKeysPageFromGenerated(genResp), nil
+			return listKeysPageFromGenerated(genResp, *options), nil
 		},
 	})
 }
@@ -505,12 +579,57 @@ func (c *Client) GetKey(ctx context.Context, name string, options *GetKeyOptions
 
 	resp, err := c.kvClient.GetKey(ctx, c.vaultURL, name, options.Version, &generated.KeyVaultClientGetKeyOptions{})
 	if err != nil {
-		return GetKeyResponse{}, err
+		return GetKeyResponse{}, wrapError(err)
 	}
 
 	return getKeyResponseFromGenerated(resp), err
 }
 
+// GetPublicKeyPEMOptions contains optional parameters for GetPublicKeyPEM
+type GetPublicKeyPEMOptions struct {
+	// Version is the version of the key. When empty, the latest version's public key is returned.
+	Version string
+}
+
+// GetPublicKeyPEMResponse is returned by GetPublicKeyPEM.
+type GetPublicKeyPEMResponse struct {
+	// PEM is the public key as a PEM block of type "PUBLIC KEY" containing a DER encoded SubjectPublicKeyInfo,
+	// the format OpenSSL and most systems accept.
+	PEM []byte
+
+	// KeyID is the ID of the key version.
+	KeyID *string
+}
+
+// GetPublicKeyPEM gets the public key of an RSA or EC key, encoded as PEM, for sharing with systems that verify
+// signatures or encrypt data with it. It returns an error for symmetric keys, which have no public key, and for
+// keys on curve P-256K. This operation requires the keys/get permission. Pass nil for options to accept default
+// values.
+func (c *Client) GetPublicKeyPEM(ctx context.Context, name string, options *GetPublicKeyPEMOptions) (GetPublicKeyPEMResponse, error) {
+	if options == nil {
+		options = &GetPublicKeyPEMOptions{}
+	}
+	resp, err := c.GetKey(ctx, name, &GetKeyOptions{Version: options.Version})
+	if err != nil {
+		return GetPublicKeyPEMResponse{}, err
+	}
+	if resp.JSONWebKey == nil {
+		return GetPublicKeyPEMResponse{}, fmt.Errorf("key %q has no key material", name)
+	}
+	pub, err := resp.JSONWebKey.Public()
+	if err != nil {
+		return GetPublicKeyPEMResponse{}, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return GetPublicKeyPEMResponse{}, err
+	}
+	return GetPublicKeyPEMResponse{
+		PEM:   pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		KeyID: resp.ID,
+	}, nil
+}
+
 // GetDeletedKeyOptions contains optional parameters for GetDeletedKey
 type GetDeletedKeyOptions struct {
 	// placeholder for future optional parameters
@@ -551,7 +670,7 @@ func (c *Client) GetDeletedKey(ctx context.Context, name string, options *GetDel
 
 	resp, err := c.kvClient.GetDeletedKey(ctx, c.vaultURL, name, options.toGenerated())
 	if err != nil {
-		return GetDeletedKeyResponse{}, err
+		return GetDeletedKeyResponse{}, wrapError(err)
 	}
 
 	return getDeletedKeyResponseFromGenerated(resp), nil
@@ -584,7 +703,7 @@ func (c *Client) PurgeDeletedKey(ctx context.Context, name string, options *Purg
 		options = &PurgeDeletedKeyOptions{}
 	}
 	resp, err := c.kvClient.PurgeDeletedKey(ctx, c.vaultURL, name, options.toGenerated())
-	return purgeDeletedKeyResponseFromGenerated(resp), err
+	return purgeDeletedKeyResponseFromGenerated(resp), wrapError(err)
 }
 
 // DeleteKeyResponse contains the response for a Client.BeginDeleteKey operation.
@@ -594,7 +713,8 @@ type DeleteKeyResponse struct {
 
 // BeginDeleteKeyOptions contains optional parameters for BeginDeleteKey.
 type BeginDeleteKeyOptions struct {
-	// ResumeToken is a string to rehydrate a poller for an operation that has already begun.
+	// ResumeToken is a string to rehydrate a poller for an operation that has already begun, such as one
+	// returned by DeleteKeyResumeToken or by the ResumeToken method of a Poller.
 	ResumeToken string
 }
 
@@ -616,6 +736,15 @@ func (c *Client) BeginDeleteKey(ctx context.Context, name string, options *Begin
 	}
 
 	if options.ResumeToken != "" {
+		rt, err := parseResumeToken(options.ResumeToken, resumeTokenOperationDelete, name)
+		if err != nil {
+			return nil, err
+		}
+		if rt != nil {
+			return runtime.NewPoller(nil, c.kvClient.Pipeline(), &runtime.NewPollerOptions[DeleteKeyResponse]{
+				Handler: &handler,
+			})
+		}
 		return runtime.NewPollerFromResumeToken(options.ResumeToken, c.kvClient.Pipeline(), &runtime.NewPollerFromResumeTokenOptions[DeleteKeyResponse]{
 			Handler: &handler,
 		})
@@ -624,7 +753,7 @@ func (c *Client) BeginDeleteKey(ctx context.Context, name string, options *Begin
 	var rawResp *http.Response
 	ctx = runtime.WithCaptureResponse(ctx, &rawResp)
 	if _, err := c.kvClient.DeleteKey(ctx, c.vaultURL, name, nil); err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return runtime.NewPoller(rawResp, c.kvClient.Pipeline(), &runtime.NewPollerOptions[DeleteKeyResponse]{
@@ -672,7 +801,7 @@ func (c *Client) BackupKey(ctx context.Context, name string, options *BackupKeyO
 
 	resp, err := c.kvClient.BackupKey(ctx, c.vaultURL, name, options.toGenerated())
 	if err != nil {
-		return BackupKeyResponse{}, err
+		return BackupKeyResponse{}, wrapError(err)
 	}
 
 	return backupKeyResponseFromGenerated(resp), nil
@@ -680,7 +809,8 @@ func (c *Client) BackupKey(ctx context.Context, name string, options *BackupKeyO
 
 // BeginRecoverDeletedKeyOptions contains the optional parameters for the Client.BeginRecoverDeletedKey operation
 type BeginRecoverDeletedKeyOptions struct {
-	// ResumeToken returns a string for creating a new poller to begin polling again
+	// ResumeToken is a string to rehydrate a poller for an operation that has already begun, such as one
+	// returned by RecoverDeletedKeyResumeToken or by the ResumeToken method of a Poller.
 	ResumeToken string
 }
 
@@ -719,6 +849,15 @@ func (c *Client) BeginRecoverDeletedKey(ctx context.Context, name string, option
 	}
 
 	if options.ResumeToken != "" {
+		rt, err := parseResumeToken(options.ResumeToken, resumeTokenOperationRecover, name)
+		if err != nil {
+			return nil, err
+		}
+		if rt != nil {
+			return runtime.NewPoller(nil, c.kvClient.Pipeline(), &runtime.NewPollerOptions[RecoverDeletedKeyResponse]{
+				Handler: &handler,
+			})
+		}
 		return runtime.NewPollerFromResumeToken(options.ResumeToken, c.kvClient.Pipeline(), &runtime.NewPollerFromResumeTokenOptions[RecoverDeletedKeyResponse]{
 			Handler: &handler,
 		})
@@ -727,7 +866,7 @@ func (c *Client) BeginRecoverDeletedKey(ctx context.Context, name string, option
 	var rawResp *http.Response
 	ctx = runtime.WithCaptureResponse(ctx, &rawResp)
 	if _, err := c.kvClient.RecoverDeletedKey(ctx, c.vaultURL, name, nil); err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 
 	return runtime.NewPoller(rawResp, c.kvClient.Pipeline(), &runtime.NewPollerOptions[RecoverDeletedKeyResponse]{
@@ -785,7 +924,7 @@ func (c *Client) UpdateKeyProperties(ctx context.Context, properties Properties,
 	}
 	resp, err := c.kvClient.UpdateKey(ctx, c.vaultURL, name, version, params, nil)
 	if err != nil {
-		return UpdateKeyPropertiesResponse{}, err
+		return UpdateKeyPropertiesResponse{}, wrapError(err)
 	}
 
 	return updateKeyPropertiesFromGenerated(resp), nil
@@ -832,11 +971,11 @@ func (c *Client) NewListDeletedKeysPager(options *ListDeletedKeysOptions) *runti
 				return ListDeletedKeysResponse{}, err
 			}
 			if !runtime.HasStatusCode(resp, http.StatusOK) {
-				return ListDeletedKeysResponse{}, runtime.NewResponseError(resp)
+				return ListDeletedKeysResponse{}, newResponseError(resp)
 			}
 			genResp, err := c.kvClient.GetDeletedKeysHandleResponse(resp)
 			if err != nil {
-				return ListDeletedKeysResponse{}, runtime.NewResponseError(resp)
+				return ListDeletedKeysResponse{}, newResponseError(resp)
 			}
 
 			var values []*DeletedKeyItem
@@ -910,11 +1049,11 @@ func (c *Client) NewListPropertiesOfKeyVersionsPager(keyName string, options *Li
 				return ListPropertiesOfKeyVersionsResponse{}, err
 			}
 			if !runtime.HasStatusCode(resp, http.StatusOK) {
-				return ListPropertiesOfKeyVersionsResponse{}, runtime.NewResponseError(resp)
+				return ListPropertiesOfKeyVersionsResponse{}, newResponseError(resp)
 			}
 			genResp, err := c.kvClient.GetKeyVersionsHandleResponse(resp)
 			if err != nil {
-				return ListPropertiesOfKeyVersionsResponse{}, runtime.NewResponseError(resp)
+				return ListPropertiesOfKeyVersionsResponse{}, newResponseError(resp)
 			}
 			return listKeyVersionsPageFromGenerated(genResp), nil
 		},
@@ -957,7 +1096,7 @@ func (c *Client) RestoreKeyBackup(ctx context.Context, keyBackup []byte, options
 
 	resp, err := c.kvClient.RestoreKey(ctx, c.vaultURL, generated.KeyRestoreParameters{KeyBundleBackup: keyBackup}, options.toGenerated())
 	if err != nil {
-		return RestoreKeyBackupResponse{}, err
+		return RestoreKeyBackupResponse{}, wrapError(err)
 	}
 
 	return restoreKeyBackupResponseFromGenerated(resp), nil
@@ -1007,10 +1146,13 @@ func (c *Client) ImportKey(ctx context.Context, name string, key JSONWebKey, opt
 	if options == nil {
 		options = &ImportKeyOptions{}
 	}
+	if err := c.checkKeyType("ImportKey", key.KeyType); err != nil {
+		return ImportKeyResponse{}, err
+	}
 
 	resp, err := c.kvClient.ImportKey(ctx, c.vaultURL, name, options.toImportKeyParameters(key), &generated.KeyVaultClientImportKeyOptions{})
 	if err != nil {
-		return ImportKeyResponse{}, err
+		return ImportKeyResponse{}, wrapError(err)
 	}
 
 	return importKeyResponseFromGenerated(resp), nil
@@ -1031,26 +1173,57 @@ type GetRandomBytesResponse struct {
 	Value []byte
 }
 
-// GetRandomBytes gets the requested number of random bytes from Azure Managed HSM. Pass nil for options to accept default values.
+// maxRandomBytesPerRequest is the largest count Managed HSM accepts in a request for random bytes
+const maxRandomBytesPerRequest = 128
+
+// GetRandomBytes gets the requested number of cryptographically secure random bytes from Azure Managed HSM. Vaults don't
+// support this operation. Managed HSM returns at most 128 bytes per request, so larger counts are gotten with several
+// requests. Pass nil for options to accept default values.
 func (c *Client) GetRandomBytes(ctx context.Context, count *int32, options *GetRandomBytesOptions) (GetRandomBytesResponse, error) {
+	if err := c.requireManagedHSM("GetRandomBytes"); err != nil {
+		return GetRandomBytesResponse{}, err
+	}
 	if options == nil {
 		options = &GetRandomBytesOptions{}
 	}
+	if count == nil || *count <= maxRandomBytesPerRequest {
+		value, err := c.getRandomBytes(ctx, count, options)
+		if err != nil {
+			return GetRandomBytesResponse{}, err
+		}
+		return GetRandomBytesResponse{Value: value}, nil
+	}
 
+	value := make([]byte, 0, *count)
+	for remaining := *count; remaining > 0; {
+		n := remaining
+		if n > maxRandomBytesPerRequest {
+			n = maxRandomBytesPerRequest
+		}
+		chunk, err := c.getRandomBytes(ctx, &n, options)
+		if err != nil {
+			return GetRandomBytesResponse{}, err
+		}
+		if len(chunk) != int(n) {
+			return GetRandomBytesResponse{}, fmt.Errorf("requested %d random bytes but Managed HSM returned %d", n, len(chunk))
+		}
+		value = append(value, chunk...)
+		remaining -= n
+	}
+	return GetRandomBytesResponse{Value: value}, nil
+}
+
+func (c *Client) getRandomBytes(ctx context.Context, count *int32, options *GetRandomBytesOptions) ([]byte, error) {
 	resp, err := c.kvClient.GetRandomBytes(
 		ctx,
 		c.vaultURL,
 		generated.GetRandomBytesRequest{Count: count},
 		options.toGenerated(),
 	)
-
 	if err != nil {
-		return GetRandomBytesResponse{}, err
+		return nil, wrapError(err)
 	}
-
-	return GetRandomBytesResponse{
-		Value: resp.Value,
-	}, nil
+	return resp.Value, nil
 }
 
 // RotateKeyOptions contains optional parameters for RotateKey.
@@ -1080,7 +1253,7 @@ func (c *Client) RotateKey(ctx context.Context, keyName string, options *RotateK
 		options.toGenerated(),
 	)
 	if err != nil {
-		return RotateKeyResponse{}, err
+		return RotateKeyResponse{}, wrapError(err)
 	}
 
 	vaultURL, name, version := shared.ParseID(resp.Key.Kid)
@@ -1109,24 +1282,8 @@ type GetKeyRotationPolicyResponse struct {
 }
 
 func getKeyRotationPolicyResponseFromGenerated(i generated.KeyVaultClientGetKeyRotationPolicyResponse) GetKeyRotationPolicyResponse {
-	var acts []*LifetimeActions
-	for _, a := range i.LifetimeActions {
-		acts = append(acts, lifetimeActionsFromGenerated(a))
-	}
-	var attribs *RotationPolicyAttributes
-	if i.Attributes != nil {
-		attribs = &RotationPolicyAttributes{
-			ExpiresIn: i.Attributes.ExpiryTime,
-			CreatedOn: i.Attributes.Created,
-			UpdatedOn: i.Attributes.Updated,
-		}
-	}
 	return GetKeyRotationPolicyResponse{
-		RotationPolicy: RotationPolicy{
-			ID:              i.ID,
-			LifetimeActions: acts,
-			Attributes:      attribs,
-		},
+		RotationPolicy: rotationPolicyFromGenerated(i.KeyRotationPolicy),
 	}
 }
 
@@ -1143,7 +1300,7 @@ func (c *Client) GetKeyRotationPolicy(ctx context.Context, keyName string, optio
 		options.toGenerated(),
 	)
 	if err != nil {
-		return GetKeyRotationPolicyResponse{}, err
+		return GetKeyRotationPolicyResponse{}, wrapError(err)
 	}
 
 	return getKeyRotationPolicyResponseFromGenerated(resp), nil
@@ -1190,7 +1347,7 @@ func (c *Client) ReleaseKey(ctx context.Context, name string, targetAttestationT
 	)
 
 	if err != nil {
-		return ReleaseKeyResponse{}, err
+		return ReleaseKeyResponse{}, wrapError(err)
 	}
 
 	return ReleaseKeyResponse{
@@ -1209,24 +1366,8 @@ type UpdateKeyRotationPolicyResponse struct {
 }
 
 func updateKeyRotationPolicyResponseFromGenerated(i generated.KeyVaultClientUpdateKeyRotationPolicyResponse) UpdateKeyRotationPolicyResponse {
-	var acts []*LifetimeActions
-	for _, a := range i.LifetimeActions {
-		acts = append(acts, lifetimeActionsFromGenerated(a))
-	}
-	var attribs *RotationPolicyAttributes
-	if i.Attributes != nil {
-		attribs = &RotationPolicyAttributes{
-			ExpiresIn: i.Attributes.ExpiryTime,
-			CreatedOn: i.Attributes.Created,
-			UpdatedOn: i.Attributes.Updated,
-		}
-	}
 	return UpdateKeyRotationPolicyResponse{
-		RotationPolicy: RotationPolicy{
-			ID:              i.ID,
-			LifetimeActions: acts,
-			Attributes:      attribs,
-		},
+		RotationPolicy: rotationPolicyFromGenerated(i.KeyRotationPolicy),
 	}
 }
 
@@ -1241,8 +1382,396 @@ func (c *Client) UpdateKeyRotationPolicy(ctx context.Context, keyName string, po
 	)
 
 	if err != nil {
-		return UpdateKeyRotationPolicyResponse{}, err
+		return UpdateKeyRotationPolicyResponse{}, wrapError(err)
 	}
 
 	return updateKeyRotationPolicyResponseFromGenerated(resp), nil
 }
+
+// EnsureRotationPolicyOptions contains optional parameters for EnsureRotationPolicy.
+type EnsureRotationPolicyOptions struct {
+	// placeholder for future optional parameters
+}
+
+// EnsureRotationPolicyResponse is returned by EnsureRotationPolicy.
+type EnsureRotationPolicyResponse struct {
+	RotationPolicy
+
+	// Updated is true when the key's rotation policy differed from the desired policy and was updated.
+	Updated bool
+}
+
+// EnsureRotationPolicy makes the key's rotation policy match policy, updating it only when they differ, so it
+// can be called repeatedly, for example each time an application starts. Only the fields policy sets are
+// compared and applied: the key's expiry time is left alone when policy.Attributes.ExpiresIn is nil, and its
+// lifetime actions are left alone when policy.LifetimeActions is nil. Lifetime actions are compared without
+// regard to their order. Pass nil for options to accept default values.
+func (c *Client) EnsureRotationPolicy(ctx context.Context, keyName string, policy RotationPolicy, options *EnsureRotationPolicyOptions) (EnsureRotationPolicyResponse, error) {
+	current, err := c.GetKeyRotationPolicy(ctx, keyName, nil)
+	if err != nil {
+		return EnsureRotationPolicyResponse{}, err
+	}
+	merged, changed := mergeRotationPolicy(current.RotationPolicy, policy)
+	if !changed {
+		return EnsureRotationPolicyResponse{RotationPolicy: current.RotationPolicy}, nil
+	}
+	updated, err := c.UpdateKeyRotationPolicy(ctx, keyName, merged, nil)
+	if err != nil {
+		return EnsureRotationPolicyResponse{}, err
+	}
+	return EnsureRotationPolicyResponse{RotationPolicy: updated.RotationPolicy, Updated: true}, nil
+}
+
+// mergeRotationPolicy returns current with the fields desired sets, and whether that changed current
+func mergeRotationPolicy(current, desired RotationPolicy) (RotationPolicy, bool) {
+	merged := RotationPolicy{LifetimeActions: current.LifetimeActions}
+	if current.Attributes != nil {
+		merged.Attributes = &RotationPolicyAttributes{ExpiresIn: current.Attributes.ExpiresIn}
+	}
+	changed := false
+	if desired.Attributes != nil && desired.Attributes.ExpiresIn != nil {
+		if merged.Attributes == nil || !equalDurations(merged.Attributes.ExpiresIn, desired.Attributes.ExpiresIn) {
+			merged.Attributes = &RotationPolicyAttributes{ExpiresIn: desired.Attributes.ExpiresIn}
+			changed = true
+		}
+	}
+	if desired.LifetimeActions != nil && !equalLifetimeActions(current.LifetimeActions, desired.LifetimeActions) {
+		merged.LifetimeActions = desired.LifetimeActions
+		changed = true
+	}
+	return merged, changed
+}
+
+// equalLifetimeActions returns whether a and b have the same actions, in any order
+func equalLifetimeActions(a, b []*LifetimeActions) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	matched := make([]bool, len(b))
+	for _, x := range a {
+		found := false
+		for i, y := range b {
+			if !matched[i] && equalLifetimeAction(x, y) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func equalLifetimeAction(a, b *LifetimeActions) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	var typeA, typeB string
+	if a.Action != nil && a.Action.Type != nil {
+		typeA = string(*a.Action.Type)
+	}
+	if b.Action != nil && b.Action.Type != nil {
+		typeB = string(*b.Action.Type)
+	}
+	// the service returns action types capitalized, e.g. "Rotate" for RotationActionRotate
+	if !strings.EqualFold(typeA, typeB) {
+		return false
+	}
+	var afterA, afterB, beforeA, beforeB *string
+	if a.Trigger != nil {
+		afterA, beforeA = a.Trigger.TimeAfterCreate, a.Trigger.TimeBeforeExpiry
+	}
+	if b.Trigger != nil {
+		afterB, beforeB = b.Trigger.TimeAfterCreate, b.Trigger.TimeBeforeExpiry
+	}
+	return equalDurations(afterA, afterB) && equalDurations(beforeA, beforeB)
+}
+
+// equalDurations compares ISO 8601 durations, whose letters are case insensitive
+func equalDurations(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return strings.EqualFold(*a, *b)
+}
+
+// ErrNoRotationPolicy is the error RotateAllKeys reports for keys whose rotation policy has no lifetime actions
+// when RotateAllKeysOptions.DefaultRotationPolicy isn't set.
+var ErrNoRotationPolicy = errors.New("the key has no rotation policy")
+
+// RotateAllKeysOptions contains optional parameters for RotateAllKeys.
+type RotateAllKeysOptions struct {
+	// DefaultRotationPolicy is applied to keys whose rotation policy has no lifetime actions before they're
+	// rotated. When it's nil, such keys aren't rotated and their results report ErrNoRotationPolicy.
+	DefaultRotationPolicy *RotationPolicy
+
+	// MaxConcurrency is the maximum number of keys processed at once. The default value is 4.
+	MaxConcurrency int
+}
+
+// RotateAllKeysResult is the outcome of rotating a single key.
+type RotateAllKeysResult struct {
+	// Err is the error that prevented rotating the key, if any.
+	Err error
+
+	// Key is the new version of the key, when it was rotated.
+	Key *Key
+
+	// PolicyApplied is true when DefaultRotationPolicy was applied to the key.
+	PolicyApplied bool
+}
+
+// RotateAllKeysResponse is returned by RotateAllKeys.
+type RotateAllKeysResponse struct {
+	// Results maps the names of the keys RotateAllKeys tried to rotate to the outcome for each.
+	Results map[string]*RotateAllKeysResult
+}
+
+// RotateAllKeys rotates every enabled key in the vault that filter returns true for, or every enabled key when
+// filter is nil. Keys managed by Key Vault, such as those backing certificates, can't be rotated and are skipped.
+// Before rotating a key, RotateAllKeys checks that its rotation policy has lifetime actions, optionally applying
+// a default policy. Failing to rotate a key doesn't stop the other keys from being rotated; the response reports
+// each key's outcome. An error is returned only when listing the keys fails. Pass nil for options to accept
+// default values.
+func (c *Client) RotateAllKeys(ctx context.Context, filter func(*KeyItem) bool, options *RotateAllKeysOptions) (RotateAllKeysResponse, error) {
+	if options == nil {
+		options = &RotateAllKeysOptions{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 4
+	}
+
+	var names []string
+	pager := c.NewListPropertiesOfKeysPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return RotateAllKeysResponse{}, err
+		}
+		for _, key := range page.Keys {
+			if key == nil || key.Name == nil || key.Properties == nil {
+				continue
+			}
+			if key.Properties.Enabled != nil && !*key.Properties.Enabled {
+				continue
+			}
+			if key.Properties.Managed != nil && *key.Properties.Managed {
+				continue
+			}
+			if filter != nil && !filter(key) {
+				continue
+			}
+			names = append(names, *key.Name)
+		}
+	}
+
+	resp := RotateAllKeysResponse{Results: make(map[string]*RotateAllKeysResult, len(names))}
+	for _, name := range names {
+		resp.Results[name] = &RotateAllKeysResult{}
+	}
+
+	// each goroutine writes only to its key's result
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string, result *RotateAllKeysResult) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.rotateKeyWithPolicy(ctx, name, options.DefaultRotationPolicy, result)
+		}(name, resp.Results[name])
+	}
+	wg.Wait()
+
+	return resp, nil
+}
+
+// rotateKeyWithPolicy rotates a key after checking its rotation policy, recording the outcome in result
+func (c *Client) rotateKeyWithPolicy(ctx context.Context, name string, defaultPolicy *RotationPolicy, result *RotateAllKeysResult) {
+	policy, err := c.GetKeyRotationPolicy(ctx, name, nil)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	if len(policy.LifetimeActions) == 0 {
+		if defaultPolicy == nil {
+			result.Err = ErrNoRotationPolicy
+			return
+		}
+		if _, err := c.UpdateKeyRotationPolicy(ctx, name, *defaultPolicy, nil); err != nil {
+			result.Err = err
+			return
+		}
+		result.PolicyApplied = true
+	}
+
+	rotated, err := c.RotateKey(ctx, name, nil)
+	if err != nil {
+		result.Err = err
+		return
+	}
+	result.Key = &rotated.Key
+}
+
+// KeyAliasTagPrefix prefixes the names of the tags that record which version of a key an alias refers to.
+// For example, the version PromoteKeyVersion gives the alias "current" has the tag "alias-current", whose
+// value is the time of the promotion.
+const KeyAliasTagPrefix = "alias-"
+
+// ErrKeyAliasNotFound is returned by ResolveKeyAlias when no version of the key has the alias.
+var ErrKeyAliasNotFound = errors.New("no version of the key has the alias")
+
+// PromoteKeyVersionOptions contains optional parameters for PromoteKeyVersion.
+type PromoteKeyVersionOptions struct {
+	// PreviousAlias, if set, is given to the version that had the alias before the promotion, for example
+	// "previous" when promoting a version to "current".
+	PreviousAlias string
+}
+
+// PromoteKeyVersionResponse is returned by PromoteKeyVersion.
+type PromoteKeyVersionResponse struct {
+	Key
+}
+
+// PromoteKeyVersion makes alias refer to a version of a key, so applications that resolve the alias with
+// ResolveKeyAlias switch to the version without a configuration change. Aliases are recorded in the tags of
+// key versions. The promotion takes effect as soon as the version's tag is set; afterward PromoteKeyVersion
+// removes the alias from the versions that had it, which ResolveKeyAlias ignores in the meantime.
+// Pass nil for options to accept default values.
+func (c *Client) PromoteKeyVersion(ctx context.Context, name string, version string, alias string, options *PromoteKeyVersionOptions) (PromoteKeyVersionResponse, error) {
+	if options == nil {
+		options = &PromoteKeyVersionOptions{}
+	}
+	versions, err := c.listKeyVersions(ctx, name)
+	if err != nil {
+		return PromoteKeyVersionResponse{}, err
+	}
+	var target *Properties
+	for _, v := range versions {
+		if v.Version != nil && *v.Version == version {
+			target = v
+		}
+	}
+	if target == nil {
+		return PromoteKeyVersionResponse{}, fmt.Errorf("key %q has no version %q", name, version)
+	}
+	previous := resolveKeyAlias(versions, alias)
+
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	tags := copyTags(target.Tags)
+	tags[KeyAliasTagPrefix+alias] = &now
+	if options.PreviousAlias != "" {
+		delete(tags, KeyAliasTagPrefix+options.PreviousAlias)
+	}
+	promoted, err := c.setKeyVersionTags(ctx, name, version, tags)
+	if err != nil {
+		return PromoteKeyVersionResponse{}, err
+	}
+
+	// clean up the tags of the versions that had the aliases
+	for _, v := range versions {
+		if v == target || v.Version == nil {
+			continue
+		}
+		tags := copyTags(v.Tags)
+		_, hadAlias := tags[KeyAliasTagPrefix+alias]
+		delete(tags, KeyAliasTagPrefix+alias)
+		if options.PreviousAlias != "" {
+			delete(tags, KeyAliasTagPrefix+options.PreviousAlias)
+			if v == previous {
+				tags[KeyAliasTagPrefix+options.PreviousAlias] = &now
+			}
+		}
+		if !hadAlias && len(tags) == len(v.Tags) {
+			// nothing changed
+			continue
+		}
+		if _, err := c.setKeyVersionTags(ctx, name, *v.Version, tags); err != nil {
+			return PromoteKeyVersionResponse{}, err
+		}
+	}
+	return PromoteKeyVersionResponse{Key: promoted.Key}, nil
+}
+
+// ResolveKeyAliasOptions contains optional parameters for ResolveKeyAlias.
+type ResolveKeyAliasOptions struct {
+	// placeholder for future optional parameters
+}
+
+// ResolveKeyAliasResponse is returned by ResolveKeyAlias.
+type ResolveKeyAliasResponse struct {
+	// Version is the version of the key the alias refers to.
+	Version string
+}
+
+// ResolveKeyAlias gets the version of a key an alias set by PromoteKeyVersion refers to. It returns
+// ErrKeyAliasNotFound when no version has the alias. Pass nil for options to accept default values.
+func (c *Client) ResolveKeyAlias(ctx context.Context, name string, alias string, options *ResolveKeyAliasOptions) (ResolveKeyAliasResponse, error) {
+	versions, err := c.listKeyVersions(ctx, name)
+	if err != nil {
+		return ResolveKeyAliasResponse{}, err
+	}
+	v := resolveKeyAlias(versions, alias)
+	if v == nil {
+		return ResolveKeyAliasResponse{}, ErrKeyAliasNotFound
+	}
+	return ResolveKeyAliasResponse{Version: *v.Version}, nil
+}
+
+// resolveKeyAlias returns the version most recently given alias, or nil if no version has it
+func resolveKeyAlias(versions []*Properties, alias string) *Properties {
+	var resolved *Properties
+	var promotedAt time.Time
+	for _, v := range versions {
+		if v.Version == nil {
+			continue
+		}
+		tag := v.Tags[KeyAliasTagPrefix+alias]
+		if tag == nil {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, *tag)
+		if err != nil {
+			continue
+		}
+		if resolved == nil || t.After(promotedAt) {
+			resolved, promotedAt = v, t
+		}
+	}
+	return resolved
+}
+
+func (c *Client) listKeyVersions(ctx context.Context, name string) ([]*Properties, error) {
+	var versions []*Properties
+	pager := c.NewListPropertiesOfKeyVersionsPager(name, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range page.Keys {
+			if k != nil && k.Properties != nil {
+				versions = append(versions, k.Properties)
+			}
+		}
+	}
+	return versions, nil
+}
+
+func (c *Client) setKeyVersionTags(ctx context.Context, name string, version string, tags map[string]*string) (UpdateKeyPropertiesResponse, error) {
+	return c.UpdateKeyProperties(ctx, Properties{Name: &name, Version: &version, Tags: tags}, nil)
+}
+
+func copyTags(tags map[string]*string) map[string]*string {
+	cp := make(map[string]*string, len(tags))
+	for k, v := range tags {
+		cp[k] = v
+	}
+	return cp
+}
@@ -7,18 +7,33 @@
 package azkeys
 
 import (
+	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/internal/recording"
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/crypto"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
 	"github.com/stretchr/testify/require"
 )
 
@@ -928,3 +943,782 @@ func TestClient_SignVerify(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, *verifyResponse.IsValid)
 }
+
+func TestJSONRoundTrip(t *testing.T) {
+	created := time.Unix(1655000000, 0)
+	id := "https://vault.vault.azure.net/keys/key/version"
+	key := Key{
+		Properties: &Properties{
+			CreatedOn:     &created,
+			Enabled:       to.Ptr(true),
+			ID:            &id,
+			Managed:       to.Ptr(false),
+			Name:          to.Ptr("key"),
+			RecoveryLevel: to.Ptr("Recoverable"),
+			ReleasePolicy: &ReleasePolicy{EncodedPolicy: []byte("policy"), Immutable: to.Ptr(true)},
+			Tags:          map[string]*string{"tag": to.Ptr("value")},
+			VaultURL:      to.Ptr("https://vault.vault.azure.net/"),
+			Version:       to.Ptr("version"),
+		},
+		JSONWebKey: &JSONWebKey{
+			Crv:     to.Ptr(CurveNameP256),
+			ID:      &id,
+			KeyOps:  []*Operation{to.Ptr(OperationSign)},
+			KeyType: to.Ptr(KeyTypeEC),
+			X:       []byte{1, 2, 3},
+			Y:       []byte{4, 5, 6},
+		},
+		ID:   &id,
+		Name: to.Ptr("key"),
+	}
+
+	data, err := json.Marshal(key)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"created":1655000000`)
+	require.Contains(t, string(data), `"x":"AQID"`)
+
+	var keyCopy Key
+	require.NoError(t, json.Unmarshal(data, &keyCopy))
+	require.Equal(t, key, keyCopy)
+
+	data, err = json.Marshal(key.JSONWebKey)
+	require.NoError(t, err)
+	var jwk JSONWebKey
+	require.NoError(t, json.Unmarshal(data, &jwk))
+	require.Equal(t, *key.JSONWebKey, jwk)
+
+	policy := RotationPolicy{
+		Attributes: &RotationPolicyAttributes{ExpiresIn: to.Ptr("P90D"), CreatedOn: &created},
+		LifetimeActions: []*LifetimeActions{
+			{Action: &LifetimeActionsType{Type: to.Ptr(RotationActionRotate)}, Trigger: &LifetimeActionsTrigger{TimeAfterCreate: to.Ptr("P30D")}},
+			// incomplete actions don't panic
+			{},
+		},
+		ID: to.Ptr("https://vault.vault.azure.net/keys/key/rotationpolicy"),
+	}
+	data, err = json.Marshal(policy)
+	require.NoError(t, err)
+	var policyCopy RotationPolicy
+	require.NoError(t, json.Unmarshal(data, &policyCopy))
+	require.Equal(t, policy, policyCopy)
+
+	// a key without attributes doesn't panic
+	require.NoError(t, json.Unmarshal([]byte(`{"key":{"kid":"https://vault.vault.azure.net/keys/key/version"}}`), &keyCopy))
+	require.Equal(t, "key", *keyCopy.Name)
+}
+
+func TestJSONWebKeyPublic(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	for _, pub := range []interface{}{&rsaKey.PublicKey, &ecKey.PublicKey} {
+		jwk, err := NewJSONWebKeyFromPublicKey(pub)
+		require.NoError(t, err)
+		actual, err := jwk.Public()
+		require.NoError(t, err)
+		require.Equal(t, pub, actual)
+	}
+
+	jwk, err := NewJSONWebKeyFromPublicKey(&ecKey.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, CurveNameP384, *jwk.Crv)
+	require.Len(t, jwk.X, 48)
+
+	_, err = JSONWebKey{KeyType: to.Ptr(KeyTypeOct), K: []byte("key")}.Public()
+	require.Error(t, err)
+	_, err = JSONWebKey{KeyType: to.Ptr(KeyTypeEC), Crv: to.Ptr(CurveNameP256K)}.Public()
+	require.Error(t, err)
+	_, err = NewJSONWebKeyFromPublicKey(rsaKey)
+	require.Error(t, err)
+}
+
+func TestImportKeyFromPEM(t *testing.T) {
+	var imported generated.KeyImportParameters
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") == "" {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		require.Equal(t, http.MethodPut, req.Method)
+		require.Equal(t, "/keys/key", req.URL.Path)
+		imported = generated.KeyImportParameters{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&imported))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"key":{"kid":"https://fakekvurl.vault.azure.net/keys/key/v1"}}`)),
+			Request:    req,
+		}, nil
+	})
+	client, err := NewClient("https://fakekvurl.vault.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pkcs8RSA, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+	pkcs8EC, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	pkcs1 := x509.MarshalPKCS1PrivateKey(rsaKey)
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("ignored")})
+
+	checkRSA := func(t *testing.T) {
+		require.Equal(t, "RSA", string(*imported.Key.Kty))
+		require.Equal(t, rsaKey.N.Bytes(), imported.Key.N)
+		require.Equal(t, rsaKey.D.Bytes(), imported.Key.D)
+		require.Equal(t, rsaKey.Primes[0].Bytes(), imported.Key.P)
+		require.Equal(t, rsaKey.Primes[1].Bytes(), imported.Key.Q)
+		require.Equal(t, rsaKey.Precomputed.Dp.Bytes(), imported.Key.DP)
+		require.Equal(t, rsaKey.Precomputed.Dq.Bytes(), imported.Key.DQ)
+		require.Equal(t, rsaKey.Precomputed.Qinv.Bytes(), imported.Key.QI)
+	}
+	checkEC := func(t *testing.T) {
+		require.Equal(t, "EC", string(*imported.Key.Kty))
+		require.Equal(t, "P-256", string(*imported.Key.Crv))
+		require.Equal(t, ecKey.D.FillBytes(make([]byte, 32)), imported.Key.D)
+		require.Equal(t, ecKey.X.FillBytes(make([]byte, 32)), imported.Key.X)
+	}
+	for _, test := range []struct {
+		name  string
+		data  []byte
+		check func(*testing.T)
+	}{
+		{"PKCS1", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: pkcs1}), checkRSA},
+		{"PKCS8 RSA", append(cert, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8RSA})...), checkRSA},
+		{"PKCS8 EC", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8EC}), checkEC},
+		{"SEC1", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}), checkEC},
+		{"DER PKCS1", pkcs1, checkRSA},
+		{"DER PKCS8", pkcs8EC, checkEC},
+		{"DER SEC1", sec1, checkEC},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := client.ImportKeyFromPEM(ctx, "key", test.data, nil)
+			require.NoError(t, err)
+			require.Equal(t, "https://fakekvurl.vault.azure.net/keys/key/v1", *resp.ID)
+			require.Nil(t, imported.Hsm)
+			test.check(t)
+		})
+	}
+
+	_, err = client.ImportKeyFromPEM(ctx, "key", sec1, &ImportKeyFromPEMOptions{
+		HardwareProtected: to.Ptr(true),
+		KeyOps:            []*Operation{to.Ptr(OperationSign), to.Ptr(OperationVerify)},
+	})
+	require.NoError(t, err)
+	require.True(t, *imported.Hsm)
+	require.Equal(t, []*string{to.Ptr("sign"), to.Ptr("verify")}, imported.Key.KeyOps)
+
+	for name, data := range map[string][]byte{
+		"encrypted":      pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: pkcs8RSA}),
+		"no private key": cert,
+		"two keys":       append(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}), pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: pkcs1})...),
+		"not a key":      []byte("not a key"),
+	} {
+		_, err = client.ImportKeyFromPEM(ctx, "key", data, nil)
+		require.Error(t, err, name)
+	}
+}
+
+func TestListKeysMaterialized(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	forbidden := ""
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") == "" {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		status, header, body := http.StatusOK, http.Header{"Content-Type": []string{"application/json"}}, ""
+		switch name := strings.Trim(strings.TrimPrefix(req.URL.Path, "/keys"), "/"); {
+		case name == "":
+			body = `{"value":[
+				{"kid":"https://fakekvurl.vault.azure.net/keys/a","attributes":{"enabled":true}},
+				{"kid":"https://fakekvurl.vault.azure.net/keys/b","attributes":{"enabled":true}},
+				{"kid":"https://fakekvurl.vault.azure.net/keys/disabled","attributes":{"enabled":false}},
+				{"kid":"https://fakekvurl.vault.azure.net/keys/c"}]}`
+		case name == "disabled":
+			t.Fatal("ListKeys requested a disabled key")
+		default:
+			requests[name]++
+			if name == "b" && requests[name] == 1 {
+				status = http.StatusTooManyRequests
+				header.Set("Retry-After", "1")
+				body = `{"error":{"code":"Throttled","message":"throttled"}}`
+			} else if name == forbidden {
+				status = http.StatusForbidden
+				body = `{"error":{"code":"Forbidden","message":"forbidden"}}`
+			} else {
+				body = fmt.Sprintf(`{"key":{"kid":"https://fakekvurl.vault.azure.net/keys/%s/v1","kty":"RSA","n":"AQAB","e":"AQAB"}}`, name)
+			}
+		}
+		return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	})
+	client, err := NewClient("https://fakekvurl.vault.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{
+		Transport: transport,
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	}})
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp, err := client.ListKeys(ctx, &ListKeysOptions{MaxConcurrency: 2})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), time.Second, "ListKeys should honor Retry-After")
+	require.Len(t, resp.Keys, 4)
+	for i, name := range []string{"a", "b", "disabled", "c"} {
+		require.Equal(t, name, *resp.Keys[i].Name)
+	}
+	require.Equal(t, "https://fakekvurl.vault.azure.net/keys/b/v1", *resp.Keys[1].ID)
+	require.Equal(t, KeyTypeRSA, *resp.Keys[1].JSONWebKey.KeyType)
+	require.Nil(t, resp.Keys[2].JSONWebKey)
+	require.Equal(t, 2, requests["b"])
+
+	resp, err = client.ListKeys(ctx, &ListKeysOptions{Filter: func(k *KeyItem) bool { return *k.Name == "a" }})
+	require.NoError(t, err)
+	require.Len(t, resp.Keys, 1)
+
+	forbidden = "c"
+	_, err = client.ListKeys(ctx, nil)
+	var respErr *azcore.ResponseError
+	require.ErrorAs(t, err, &respErr)
+	require.Equal(t, http.StatusForbidden, respErr.StatusCode)
+}
+
+func TestListPropertiesOfKeysFilters(t *testing.T) {
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") == "" {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		body := `{"value":[
+			{"kid":"https://fakekvurl.vault.azure.net/keys/a","attributes":{"enabled":true},"tags":{"env":"prod","team":"x"}},
+			{"kid":"https://fakekvurl.vault.azure.net/keys/cert","attributes":{"enabled":true},"managed":true,"tags":{"env":"prod"}}],
+			"nextLink":"https://fakekvurl.vault.azure.net/keys?page=2"}`
+		if req.URL.Query().Get("page") == "2" {
+			body = `{"value":[{"kid":"https://fakekvurl.vault.azure.net/keys/b","attributes":{"enabled":true},"tags":{"env":"dev"}},{"kid":"https://fakekvurl.vault.azure.net/keys/c","attributes":{"enabled":true}}]}`
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	client, err := NewClient("https://fakekvurl.vault.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	list := func(options *ListPropertiesOfKeysOptions) []string {
+		var names []string
+		pager := client.NewListPropertiesOfKeysPager(options)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			require.NoError(t, err)
+			for _, key := range page.Keys {
+				names = append(names, *key.Name)
+			}
+		}
+		return names
+	}
+	require.Equal(t, []string{"a", "b", "c"}, list(nil))
+	require.Equal(t, []string{"a", "cert", "b", "c"}, list(&ListPropertiesOfKeysOptions{IncludeManaged: true}))
+	require.Equal(t, []string{"a"}, list(&ListPropertiesOfKeysOptions{Tags: map[string]string{"env": "prod"}}))
+	require.Equal(t, []string{"a", "cert"}, list(&ListPropertiesOfKeysOptions{Tags: map[string]string{"env": "prod"}, IncludeManaged: true}))
+	require.Equal(t, []string{"a"}, list(&ListPropertiesOfKeysOptions{Tags: map[string]string{"env": "prod", "team": "x"}}))
+	require.Empty(t, list(&ListPropertiesOfKeysOptions{Tags: map[string]string{"env": "prod", "team": "y"}}))
+}
+
+func TestResolveKeyAlias(t *testing.T) {
+	promoted := func(version string, tags map[string]*string) *Properties {
+		return &Properties{Version: to.Ptr(version), Tags: tags}
+	}
+	earlier := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339Nano)
+	later := time.Date(2022, 6, 2, 0, 0, 0, 0, time.UTC).Format(time.RFC3339Nano)
+
+	versions := []*Properties{
+		promoted("a", map[string]*string{KeyAliasTagPrefix + "current": &earlier, "team": to.Ptr("crypto")}),
+		promoted("b", map[string]*string{KeyAliasTagPrefix + "current": &later}),
+		promoted("c", map[string]*string{KeyAliasTagPrefix + "previous": &later}),
+		promoted("d", map[string]*string{KeyAliasTagPrefix + "current": to.Ptr("not a time")}),
+		promoted("e", nil),
+	}
+
+	// while a promotion is in progress, the most recently promoted version wins
+	require.Equal(t, "b", *resolveKeyAlias(versions, "current").Version)
+	require.Equal(t, "c", *resolveKeyAlias(versions, "previous").Version)
+	require.Nil(t, resolveKeyAlias(versions, "next"))
+	require.Nil(t, resolveKeyAlias(nil, "current"))
+}
+
+func TestCheckCompliance(t *testing.T) {
+	rsaKey := func(bits int) Key {
+		n := make([]byte, bits/8)
+		n[0] = 0x80
+		return Key{JSONWebKey: &JSONWebKey{KeyType: to.Ptr(KeyTypeRSA), N: n}, Properties: &Properties{}}
+	}
+	ecKey := func(crv CurveName) Key {
+		return Key{JSONWebKey: &JSONWebKey{KeyType: to.Ptr(KeyTypeECHSM), Crv: to.Ptr(crv)}, Properties: &Properties{}}
+	}
+	rotating := &RotationPolicy{LifetimeActions: []*LifetimeActions{{
+		Action:  &LifetimeActionsType{Type: to.Ptr(RotationActionRotate)},
+		Trigger: &LifetimeActionsTrigger{TimeAfterCreate: to.Ptr("P90D")},
+	}}}
+	notifying := &RotationPolicy{LifetimeActions: []*LifetimeActions{{Action: &LifetimeActionsType{Type: to.Ptr(RotationActionNotify)}}}}
+	rules := []ComplianceRule{
+		MinRSAKeySize(3072),
+		AllowedECCurves(CurveNameP256, CurveNameP384),
+		RotationPolicyRequired(),
+		MaxKeyAge(90 * 24 * time.Hour),
+		ExpiryRequired(),
+	}
+	violated := func(key ComplianceKey) []string {
+		var names []string
+		for _, v := range checkCompliance("key", key, rules) {
+			require.Equal(t, "key", v.KeyName)
+			require.NotEmpty(t, v.Message)
+			names = append(names, v.Rule)
+		}
+		return names
+	}
+
+	compliant := rsaKey(4096)
+	compliant.Properties = &Properties{CreatedOn: to.Ptr(time.Now().Add(-time.Hour)), ExpiresOn: to.Ptr(time.Now().Add(time.Hour))}
+	require.Empty(t, violated(ComplianceKey{Key: compliant, RotationPolicy: rotating}))
+
+	require.Equal(t, []string{"MinRSAKeySize", "RotationPolicyRequired", "ExpiryRequired"}, violated(ComplianceKey{Key: rsaKey(2048), RotationPolicy: notifying}))
+	require.Equal(t, []string{"AllowedECCurves", "ExpiryRequired"}, violated(ComplianceKey{Key: ecKey(CurveNameP256K), RotationPolicy: rotating}))
+	require.Equal(t, []string{"ExpiryRequired"}, violated(ComplianceKey{Key: ecKey(CurveNameP384), RotationPolicy: rotating}))
+
+	old := rsaKey(4096)
+	old.Properties = &Properties{CreatedOn: to.Ptr(time.Now().Add(-100 * 24 * time.Hour)), ExpiresOn: to.Ptr(time.Now()), Managed: to.Ptr(true)}
+	// managed keys rotate with their certificates
+	require.Equal(t, []string{"MaxKeyAge"}, violated(ComplianceKey{Key: old}))
+
+	custom := ComplianceRule{Name: "Tagged", Check: func(key ComplianceKey) string {
+		if key.Key.Properties.Tags["owner"] == nil {
+			return "no owner"
+		}
+		return ""
+	}}
+	require.Equal(t, []ComplianceViolation{{KeyName: "k", Rule: "Tagged", Message: "no owner"}}, checkCompliance("k", ComplianceKey{Key: rsaKey(4096)}, []ComplianceRule{custom, {Name: "NoCheck"}}))
+}
+
+func TestManagedHSMCapabilities(t *testing.T) {
+	for u, want := range map[string]bool{
+		"https://contoso.managedhsm.azure.net/":        true,
+		"https://contoso.managedhsm.usgovcloudapi.net": true,
+		"https://CONTOSO.MANAGEDHSM.AZURE.CN:443/":     true,
+		"https://contoso.vault.azure.net/":             false,
+		"https://managedhsm.vault.azure.net/":          false,
+		"https://contoso-managedhsm.vault.azure.net/":  false,
+		"not a URL\x7f": false,
+	} {
+		require.Equal(t, want, isManagedHSMURL(u), u)
+	}
+
+	// operations a vault doesn't support fail without sending a request
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		return nil, nil
+	})
+	client, err := NewClient("https://contoso.vault.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	require.False(t, client.IsManagedHSM())
+
+	_, err = client.GetRandomBytes(ctx, to.Ptr(int32(8)), nil)
+	require.ErrorIs(t, err, ErrNotSupported)
+	var notSupported *NotSupportedError
+	require.ErrorAs(t, err, &notSupported)
+	require.Equal(t, "GetRandomBytes", notSupported.Operation)
+	require.False(t, notSupported.ManagedHSM)
+	require.EqualError(t, err, "GetRandomBytes isn't supported by a Key Vault vault")
+
+	_, err = client.CreateOctKey(ctx, "key", nil)
+	require.ErrorIs(t, err, ErrNotSupported)
+	_, err = client.CreateKey(ctx, "key", KeyTypeOctHSM, nil)
+	require.ErrorIs(t, err, ErrNotSupported)
+	_, err = client.ImportKey(ctx, "key", JSONWebKey{KeyType: to.Ptr(KeyTypeOct)}, nil)
+	require.ErrorIs(t, err, ErrNotSupported)
+
+	client, err = NewClient("https://contoso.managedhsm.azure.net/", NewFakeCredential("fake", "fake"), nil)
+	require.NoError(t, err)
+	require.True(t, client.IsManagedHSM())
+}
+
+func TestGetRandomBytesChunking(t *testing.T) {
+	var counts []int32
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") == "" {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://managedhsm.azure.net"`}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		require.Equal(t, "/rng", req.URL.Path)
+		var body struct {
+			Count int32 `json:"count"`
+		}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		counts = append(counts, body.Count)
+		value := make([]byte, body.Count)
+		for i := range value {
+			value[i] = byte(len(counts))
+		}
+		b, err := json.Marshal(map[string]string{"value": base64.RawURLEncoding.EncodeToString(value)})
+		require.NoError(t, err)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(b)),
+			Request:    req,
+		}, nil
+	})
+	client, err := NewClient("https://contoso.managedhsm.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	resp, err := client.GetRandomBytes(ctx, to.Ptr(int32(100)), nil)
+	require.NoError(t, err)
+	require.Len(t, resp.Value, 100)
+	require.Equal(t, []int32{100}, counts)
+
+	counts = nil
+	resp, err = client.GetRandomBytes(ctx, to.Ptr(int32(300)), nil)
+	require.NoError(t, err)
+	require.Equal(t, []int32{128, 128, 44}, counts)
+	require.Len(t, resp.Value, 300)
+	require.Equal(t, byte(1), resp.Value[0])
+	require.Equal(t, byte(2), resp.Value[128])
+	require.Equal(t, byte(3), resp.Value[299])
+}
+
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (p transportFunc) Do(req *http.Request) (*http.Response, error) {
+	return p(req)
+}
+
+func TestResumeTokens(t *testing.T) {
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") == "" {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		body := ""
+		switch {
+		case req.Method != http.MethodGet:
+		case req.URL.Path == "/deletedkeys/key":
+			body = `{"key":{"kid":"https://fakekvurl.vault.azure.net/keys/key/v1"},"recoveryId":"https://fakekvurl.vault.azure.net/deletedkeys/key"}`
+		case req.URL.Path == "/keys/key/" || req.URL.Path == "/keys/key":
+			body = `{"key":{"kid":"https://fakekvurl.vault.azure.net/keys/key/v1"}}`
+		}
+		if body == "" {
+			t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	client, err := NewClient("https://fakekvurl.vault.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	// the format is pinned, because tokens must be resumable by later versions of the module
+	require.Equal(t, `{"version":1,"operation":"delete","key":"key"}`, DeleteKeyResumeToken("key"))
+
+	delPoller, err := client.BeginDeleteKey(ctx, "key", &BeginDeleteKeyOptions{ResumeToken: DeleteKeyResumeToken("key")})
+	require.NoError(t, err)
+	require.False(t, delPoller.Done())
+	delResp, err := delPoller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "https://fakekvurl.vault.azure.net/deletedkeys/key", *delResp.RecoveryID)
+
+	recPoller, err := client.BeginRecoverDeletedKey(ctx, "key", &BeginRecoverDeletedKeyOptions{ResumeToken: RecoverDeletedKeyResumeToken("key")})
+	require.NoError(t, err)
+	recResp, err := recPoller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: time.Second})
+	require.NoError(t, err)
+	require.Equal(t, "https://fakekvurl.vault.azure.net/keys/key/v1", *recResp.ID)
+
+	// tokens of pollers remain resumable
+	token, err := recPoller.ResumeToken()
+	require.Error(t, err, "a done poller has no token")
+	require.Empty(t, token)
+	legacy := `{"type":"DeleteKeyResponse","token":{}}`
+	delPoller, err = client.BeginDeleteKey(ctx, "key", &BeginDeleteKeyOptions{ResumeToken: legacy})
+	require.NoError(t, err)
+	_, err = delPoller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: time.Second})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name, token, contains string
+	}{
+		{"newer version", `{"version":2,"operation":"delete","key":"key","newField":true}`, "newer version"},
+		{"other operation", RecoverDeletedKeyResumeToken("key"), "recover operation"},
+		{"other key", DeleteKeyResumeToken("other"), `"other"`},
+		{"invalid", "not a token", "invalid resume token"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := client.BeginDeleteKey(ctx, "key", &BeginDeleteKeyOptions{ResumeToken: test.token})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.contains)
+		})
+	}
+
+	// unknown fields of a supported version are ignored
+	_, err = client.BeginDeleteKey(ctx, "key", &BeginDeleteKeyOptions{ResumeToken: `{"version":1,"operation":"delete","key":"key","newField":true}`})
+	require.NoError(t, err)
+}
+
+func TestEnsureRotationPolicy(t *testing.T) {
+	policy := `{"id":"https://fakekvurl.vault.azure.net/keys/key/rotationpolicy","lifetimeActions":[{"trigger":{"timeBeforeExpiry":"P30D"},"action":{"type":"Notify"}},{"trigger":{"timeAfterCreate":"P60D"},"action":{"type":"Rotate"}}],"attributes":{"expiryTime":"P90D"}}`
+	var updates []generated.KeyRotationPolicy
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") == "" {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		require.Equal(t, "/keys/key/rotationpolicy", req.URL.Path)
+		if req.Method == http.MethodPut {
+			b, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			var update generated.KeyRotationPolicy
+			require.NoError(t, json.Unmarshal(b, &update))
+			updates = append(updates, update)
+			policy = string(b)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(policy)),
+			Request:    req,
+		}, nil
+	})
+	client, err := NewClient("https://fakekvurl.vault.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	// the current policy, with its actions in another order and its durations and types in another case
+	desired := RotationPolicy{
+		Attributes: &RotationPolicyAttributes{ExpiresIn: to.Ptr("p90d")},
+		LifetimeActions: []*LifetimeActions{
+			{Action: &LifetimeActionsType{Type: to.Ptr(RotationActionRotate)}, Trigger: &LifetimeActionsTrigger{TimeAfterCreate: to.Ptr("P60D")}},
+			{Action: &LifetimeActionsType{Type: to.Ptr(RotationActionNotify)}, Trigger: &LifetimeActionsTrigger{TimeBeforeExpiry: to.Ptr("P30D")}},
+		},
+	}
+	resp, err := client.EnsureRotationPolicy(ctx, "key", desired, nil)
+	require.NoError(t, err)
+	require.False(t, resp.Updated)
+	require.Empty(t, updates)
+	require.Equal(t, "P90D", *resp.Attributes.ExpiresIn)
+
+	// only the fields the desired policy sets are applied
+	resp, err = client.EnsureRotationPolicy(ctx, "key", RotationPolicy{Attributes: &RotationPolicyAttributes{ExpiresIn: to.Ptr("P180D")}}, nil)
+	require.NoError(t, err)
+	require.True(t, resp.Updated)
+	require.Len(t, updates, 1)
+	require.Equal(t, "P180D", *updates[0].Attributes.ExpiryTime)
+	require.Len(t, updates[0].LifetimeActions, 2)
+	require.Equal(t, "P180D", *resp.Attributes.ExpiresIn)
+
+	desired.LifetimeActions = desired.LifetimeActions[:1]
+	resp, err = client.EnsureRotationPolicy(ctx, "key", RotationPolicy{LifetimeActions: desired.LifetimeActions}, nil)
+	require.NoError(t, err)
+	require.True(t, resp.Updated)
+	require.Len(t, updates, 2)
+	require.Equal(t, "P180D", *updates[1].Attributes.ExpiryTime)
+	require.Len(t, updates[1].LifetimeActions, 1)
+	require.Equal(t, "P60D", *updates[1].LifetimeActions[0].Trigger.TimeAfterCreate)
+
+	// applying the same policy again changes nothing
+	resp, err = client.EnsureRotationPolicy(ctx, "key", RotationPolicy{LifetimeActions: desired.LifetimeActions}, nil)
+	require.NoError(t, err)
+	require.False(t, resp.Updated)
+	require.Len(t, updates, 2)
+}
+
+func TestError(t *testing.T) {
+	status, body := http.StatusNotFound, `{"error":{"code":"KeyNotFound","message":"A key with (name/id) missing was not found in this key vault."}}`
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") == "" {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	client, err := NewClient("https://fakekvurl.vault.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: transport, Retry: policy.RetryOptions{MaxRetries: -1}},
+	})
+	require.NoError(t, err)
+
+	_, err = client.GetKey(ctx, "missing", nil)
+	var kvErr *Error
+	require.ErrorAs(t, err, &kvErr)
+	require.Equal(t, ErrorCodeKeyNotFound, kvErr.Code)
+	require.Equal(t, http.StatusNotFound, kvErr.StatusCode)
+	require.Equal(t, "A key with (name/id) missing was not found in this key vault.", kvErr.Message)
+	require.True(t, IsNotFound(err))
+	require.False(t, IsForbidden(err))
+	// the *azcore.ResponseError is still available
+	var respErr *azcore.ResponseError
+	require.ErrorAs(t, err, &respErr)
+	require.Equal(t, "KeyNotFound", respErr.ErrorCode)
+
+	status, body = http.StatusForbidden, `{"error":{"code":"Forbidden","message":"The user does not have keys get permission.","innererror":{"code":"AccessDenied"}}}`
+	pager := client.NewListPropertiesOfKeysPager(nil)
+	_, err = pager.NextPage(ctx)
+	require.ErrorAs(t, err, &kvErr)
+	require.Equal(t, ErrorCodeForbidden, kvErr.Code)
+	require.Equal(t, "AccessDenied", kvErr.InnerCode)
+	require.True(t, IsForbidden(err))
+
+	// the code of a response without one is derived from the status code
+	status, body = http.StatusTooManyRequests, ``
+	_, err = client.RotateKey(ctx, "key", nil)
+	require.ErrorAs(t, err, &kvErr)
+	require.Equal(t, ErrorCodeThrottled, kvErr.Code)
+	require.True(t, IsThrottled(err))
+
+	status, body = http.StatusConflict, `{"error":{"code":"ObjectIsDeletedButRecoverable","message":"Key is currently in a deleted but recoverable state."}}`
+	_, err = client.CreateRSAKey(ctx, "deleted", nil)
+	require.ErrorAs(t, err, &kvErr)
+	require.Equal(t, ErrorCodeObjectIsDeletedButRecoverable, kvErr.Code)
+	require.True(t, IsConflict(err))
+
+	// the helpers recognize errors of other clients
+	require.True(t, IsNotFound(fmt.Errorf("wrapped: %w", &azcore.ResponseError{StatusCode: http.StatusNotFound})))
+	require.False(t, IsNotFound(errors.New("not found")))
+	require.False(t, IsThrottled(nil))
+}
+
+func TestCreateOctKey(t *testing.T) {
+	var body generated.KeyCreateParameters
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") == "" {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://managedhsm.azure.net"`}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		body = generated.KeyCreateParameters{}
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"key":{"kid":"https://contoso.managedhsm.azure.net/keys/key/v1","kty":"oct-HSM"},"attributes":{"enabled":true}}`)),
+			Request:    req,
+		}, nil
+	})
+	client, err := NewClient("https://contoso.managedhsm.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	// the key is HSM protected and allows the AES operations by default
+	_, err = client.CreateOctKey(ctx, "key", &CreateOctKeyOptions{Size: to.Ptr(int32(128))})
+	require.NoError(t, err)
+	require.Equal(t, generated.JSONWebKeyTypeOctHSM, *body.Kty)
+	require.Equal(t, int32(128), *body.KeySize)
+	require.Equal(t, []*generated.JSONWebKeyOperation{
+		to.Ptr(generated.JSONWebKeyOperationEncrypt),
+		to.Ptr(generated.JSONWebKeyOperationDecrypt),
+		to.Ptr(generated.JSONWebKeyOperationWrapKey),
+		to.Ptr(generated.JSONWebKeyOperationUnwrapKey),
+	}, body.KeyOps)
+
+	_, err = client.CreateOctKey(ctx, "key", &CreateOctKeyOptions{HardwareProtected: to.Ptr(false), Operations: []*Operation{to.Ptr(OperationWrapKey)}})
+	require.NoError(t, err)
+	require.Equal(t, generated.JSONWebKeyTypeOct, *body.Kty)
+	require.Nil(t, body.KeySize)
+	require.Equal(t, []*generated.JSONWebKeyOperation{to.Ptr(generated.JSONWebKeyOperationWrapKey)}, body.KeyOps)
+
+	// unsupported sizes fail without sending a request
+	body = generated.KeyCreateParameters{}
+	_, err = client.CreateOctKey(ctx, "key", &CreateOctKeyOptions{Size: to.Ptr(int32(512))})
+	require.EqualError(t, err, "AES keys must have 128, 192 or 256 bits, not 512")
+	require.Nil(t, body.Kty)
+}
+
+func TestGetPublicKeyPEM(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	key, err := NewJSONWebKeyFromPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+	var paths []string
+	transport := transportFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get("Authorization") == "" {
+			return &http.Response{
+				StatusCode: http.StatusUnauthorized,
+				Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+				Body:       http.NoBody,
+				Request:    req,
+			}, nil
+		}
+		paths = append(paths, req.URL.Path)
+		body := Key{ID: to.Ptr("https://fakekvurl.vault.azure.net/keys/ec/v1"), JSONWebKey: key}
+		if strings.HasPrefix(req.URL.Path, "/keys/aes") {
+			body.JSONWebKey = &JSONWebKey{KeyType: to.Ptr(KeyTypeOctHSM)}
+		}
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(b)),
+			Request:    req,
+		}, nil
+	})
+	client, err := NewClient("https://fakekvurl.vault.azure.net/", NewFakeCredential("fake", "fake"), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	resp, err := client.GetPublicKeyPEM(ctx, "ec", &GetPublicKeyPEMOptions{Version: "v1"})
+	require.NoError(t, err)
+	require.Equal(t, "https://fakekvurl.vault.azure.net/keys/ec/v1", *resp.KeyID)
+	require.Equal(t, []string{"/keys/ec/v1"}, paths)
+	block, rest := pem.Decode(resp.PEM)
+	require.Empty(t, rest)
+	require.Equal(t, "PUBLIC KEY", block.Type)
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	require.NoError(t, err)
+	require.True(t, priv.PublicKey.Equal(pub))
+
+	// symmetric keys have no public key
+	_, err = client.GetPublicKeyPEM(ctx, "aes", nil)
+	require.Error(t, err)
+}
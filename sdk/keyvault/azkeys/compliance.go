@@ -0,0 +1,276 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azkeys
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ComplianceKey is the key a ComplianceRule checks.
+type ComplianceKey struct {
+	// Key is the latest version of the key.
+	Key Key
+
+	// RotationPolicy is the key's rotation policy. It's nil unless a rule sets NeedsRotationPolicy, and for
+	// keys managed by Key Vault, such as those backing certificates, which have no rotation policy.
+	RotationPolicy *RotationPolicy
+}
+
+// ComplianceRule is a requirement ScanVault checks every key against. Use the rules returned by functions
+// such as MinRSAKeySize, or write your own.
+type ComplianceRule struct {
+	// Name identifies the rule in violations.
+	Name string
+
+	// Check returns a description of how the key violates the rule, or an empty string when it complies.
+	Check func(key ComplianceKey) string
+
+	// NeedsRotationPolicy makes ScanVault get the rotation policies of the keys for Check.
+	NeedsRotationPolicy bool
+}
+
+// ComplianceViolation is a key's violation of a rule.
+type ComplianceViolation struct {
+	// KeyName is the name of the key.
+	KeyName string
+
+	// Rule is the name of the rule.
+	Rule string
+
+	// Message describes the violation.
+	Message string
+}
+
+// ScanVaultOptions contains optional parameters for ScanVault.
+type ScanVaultOptions struct {
+	// Filter, when set, limits the scan to the keys it returns true for.
+	Filter func(*KeyItem) bool
+
+	// MaxConcurrency is the maximum number of keys scanned at once. The default value is 4.
+	MaxConcurrency int
+}
+
+// ScanVaultResponse is returned by ScanVault.
+type ScanVaultResponse struct {
+	// Violations are the violations found, sorted by key name and then by rule name.
+	Violations []ComplianceViolation
+
+	// Errors maps the names of the keys that couldn't be scanned to the errors that prevented it.
+	Errors map[string]error
+
+	// Scanned is the number of keys scanned.
+	Scanned int
+}
+
+// ScanVault checks the latest version of every key in the vault against rules and returns the violations.
+// Failing to get a key doesn't stop the scan; the response reports the keys that couldn't be scanned. An
+// error is returned only when listing the keys fails. Pass nil for options to accept default values.
+func (c *Client) ScanVault(ctx context.Context, rules []ComplianceRule, options *ScanVaultOptions) (ScanVaultResponse, error) {
+	if options == nil {
+		options = &ScanVaultOptions{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 4
+	}
+	needsPolicy := false
+	for _, rule := range rules {
+		needsPolicy = needsPolicy || rule.NeedsRotationPolicy
+	}
+
+	var names []string
+	pager := c.NewListPropertiesOfKeysPager(&ListPropertiesOfKeysOptions{IncludeManaged: true})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return ScanVaultResponse{}, err
+		}
+		for _, key := range page.Keys {
+			if key == nil || key.Name == nil {
+				continue
+			}
+			if options.Filter != nil && !options.Filter(key) {
+				continue
+			}
+			names = append(names, *key.Name)
+		}
+	}
+
+	resp := ScanVaultResponse{Errors: map[string]error{}}
+	var mu sync.Mutex
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			key, err := c.getComplianceKey(ctx, name, needsPolicy)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				resp.Errors[name] = err
+				return
+			}
+			resp.Scanned++
+			resp.Violations = append(resp.Violations, checkCompliance(name, key, rules)...)
+		}(name)
+	}
+	wg.Wait()
+
+	sort.Slice(resp.Violations, func(i, j int) bool {
+		a, b := resp.Violations[i], resp.Violations[j]
+		if a.KeyName != b.KeyName {
+			return a.KeyName < b.KeyName
+		}
+		return a.Rule < b.Rule
+	})
+	return resp, nil
+}
+
+func (c *Client) getComplianceKey(ctx context.Context, name string, needsPolicy bool) (ComplianceKey, error) {
+	key, err := c.GetKey(ctx, name, nil)
+	if err != nil {
+		return ComplianceKey{}, err
+	}
+	ck := ComplianceKey{Key: key.Key}
+	if needsPolicy && !isManaged(key.Key) {
+		policy, err := c.GetKeyRotationPolicy(ctx, name, nil)
+		if err != nil {
+			return ComplianceKey{}, err
+		}
+		ck.RotationPolicy = &policy.RotationPolicy
+	}
+	return ck, nil
+}
+
+func checkCompliance(name string, key ComplianceKey, rules []ComplianceRule) []ComplianceViolation {
+	var violations []ComplianceViolation
+	for _, rule := range rules {
+		if rule.Check == nil {
+			continue
+		}
+		if msg := rule.Check(key); msg != "" {
+			violations = append(violations, ComplianceViolation{KeyName: name, Rule: rule.Name, Message: msg})
+		}
+	}
+	return violations
+}
+
+func isManaged(key Key) bool {
+	return key.Properties != nil && key.Properties.Managed != nil && *key.Properties.Managed
+}
+
+func keyTypeOf(key Key) KeyType {
+	if key.JSONWebKey == nil || key.JSONWebKey.KeyType == nil {
+		return ""
+	}
+	return *key.JSONWebKey.KeyType
+}
+
+// MinRSAKeySize returns a rule that RSA keys violate when their modulus has fewer than bits bits.
+func MinRSAKeySize(bits int) ComplianceRule {
+	return ComplianceRule{
+		Name: "MinRSAKeySize",
+		Check: func(key ComplianceKey) string {
+			kt := keyTypeOf(key.Key)
+			if kt != KeyTypeRSA && kt != KeyTypeRSAHSM {
+				return ""
+			}
+			if size := new(big.Int).SetBytes(key.Key.JSONWebKey.N).BitLen(); size < bits {
+				return fmt.Sprintf("the RSA key has %d bits; at least %d are required", size, bits)
+			}
+			return ""
+		},
+	}
+}
+
+// AllowedECCurves returns a rule that EC keys violate when their curve isn't one of curves.
+func AllowedECCurves(curves ...CurveName) ComplianceRule {
+	return ComplianceRule{
+		Name: "AllowedECCurves",
+		Check: func(key ComplianceKey) string {
+			kt := keyTypeOf(key.Key)
+			if kt != KeyTypeEC && kt != KeyTypeECHSM {
+				return ""
+			}
+			var crv CurveName
+			if key.Key.JSONWebKey.Crv != nil {
+				crv = *key.Key.JSONWebKey.Crv
+			}
+			allowed := make([]string, len(curves))
+			for i, c := range curves {
+				if c == crv {
+					return ""
+				}
+				allowed[i] = string(c)
+			}
+			return fmt.Sprintf("the EC key uses curve %q; allowed curves are %s", crv, strings.Join(allowed, ", "))
+		},
+	}
+}
+
+// RotationPolicyRequired returns a rule that keys violate when their rotation policy doesn't rotate them
+// automatically. Keys managed by Key Vault, such as those backing certificates, rotate with their
+// certificates and comply.
+func RotationPolicyRequired() ComplianceRule {
+	return ComplianceRule{
+		Name:                "RotationPolicyRequired",
+		NeedsRotationPolicy: true,
+		Check: func(key ComplianceKey) string {
+			if isManaged(key.Key) {
+				return ""
+			}
+			if key.RotationPolicy != nil {
+				for _, a := range key.RotationPolicy.LifetimeActions {
+					if a != nil && a.Action != nil && a.Action.Type != nil && strings.EqualFold(string(*a.Action.Type), string(RotationActionRotate)) {
+						return ""
+					}
+				}
+			}
+			return "the key's rotation policy doesn't rotate it"
+		},
+	}
+}
+
+// MaxKeyAge returns a rule that keys violate when their latest version was created more than maxAge ago.
+func MaxKeyAge(maxAge time.Duration) ComplianceRule {
+	return ComplianceRule{
+		Name: "MaxKeyAge",
+		Check: func(key ComplianceKey) string {
+			if key.Key.Properties == nil || key.Key.Properties.CreatedOn == nil {
+				return ""
+			}
+			if age := time.Since(*key.Key.Properties.CreatedOn); age > maxAge {
+				return fmt.Sprintf("the key's latest version is %s old; the maximum is %s", age.Round(time.Hour), maxAge)
+			}
+			return ""
+		},
+	}
+}
+
+// ExpiryRequired returns a rule that keys violate when they have no expiry date.
+func ExpiryRequired() ComplianceRule {
+	return ComplianceRule{
+		Name: "ExpiryRequired",
+		Check: func(key ComplianceKey) string {
+			if key.Key.Properties == nil || key.Key.Properties.ExpiresOn == nil {
+				return "the key has no expiry date"
+			}
+			return ""
+		},
+	}
+}
@@ -8,6 +8,7 @@ package crypto
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
@@ -28,11 +29,36 @@ import (
 // cryptographic operations are performed by the Key Vault service.
 type Client struct {
 	base.CryptoClient
+	keyProvider *CachedPublicKeyProvider
 }
 
+// Operator performs cryptographic operations with a key. It's implemented by *Client and by the fake client in
+// package crypto/fake, so code that accepts an Operator can be tested without a vault.
+type Operator interface {
+	Encrypt(ctx context.Context, alg EncryptionAlg, plaintext []byte, options *EncryptOptions) (EncryptResponse, error)
+	Decrypt(ctx context.Context, alg EncryptionAlg, ciphertext []byte, options *DecryptOptions) (DecryptResponse, error)
+	WrapKey(ctx context.Context, alg WrapAlg, key []byte, options *WrapKeyOptions) (WrapKeyResponse, error)
+	UnwrapKey(ctx context.Context, alg WrapAlg, encryptedKey []byte, options *UnwrapKeyOptions) (UnwrapKeyResponse, error)
+	Sign(ctx context.Context, algorithm SignatureAlg, digest []byte, options *SignOptions) (SignResponse, error)
+	Verify(ctx context.Context, algorithm SignatureAlg, digest []byte, signature []byte, options *VerifyOptions) (VerifyResponse, error)
+}
+
+var _ Operator = (*Client)(nil)
+
 // ClientOptions are the configurable options on a Client.
 type ClientOptions struct {
 	azcore.ClientOptions
+
+	// PublicKeyProvider caches the public key material the Client uses to encrypt, wrap keys and verify
+	// locally. It can be shared by clients. When nil, the Client sends all operations to Key Vault, unless
+	// PreferLocal is true.
+	PublicKeyProvider *CachedPublicKeyProvider
+
+	// PreferLocal makes the Client encrypt, wrap keys and verify locally with the public portion of its key,
+	// which it gets from Key Vault once and caches, when the key's type, operations and the algorithm allow it.
+	// Other operations are sent to Key Vault. It has no effect when PublicKeyProvider is set, which already
+	// selects local operations.
+	PreferLocal bool
 }
 
 // converts ClientOptions to generated *generated.ConnectionOptions
@@ -108,7 +134,15 @@ func NewClient(keyURL string, credential azcore.TokenCredential, options *Client
 		return nil, err
 	}
 
-	return &Client{base.NewCryptoClient(vaultURL, keyID, keyVersion, pl)}, nil
+	keyProvider := options.PublicKeyProvider
+	if keyProvider == nil && options.PreferLocal {
+		keyProvider = NewCachedPublicKeyProvider(nil)
+	}
+
+	return &Client{
+		CryptoClient: base.NewCryptoClient(vaultURL, keyID, keyVersion, pl),
+		keyProvider:  keyProvider,
+	}, nil
 }
 
 // EncryptOptions contains optional parameters for Client.EncryptOptions
@@ -181,13 +215,38 @@ func (c *Client) keyVersion() string {
 	return base.KeyVersion(c.CryptoClient)
 }
 
+// getKeyMaterial returns the client's key, from its PublicKeyProvider when it has one
+func (c *Client) getKeyMaterial(ctx context.Context) (*generated.JSONWebKey, error) {
+	if c.keyProvider != nil {
+		return c.keyProvider.getKey(ctx, c)
+	}
+	resp, err := c.client().GetKey(ctx, c.vaultURL(), c.keyID(), c.keyVersion(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Key == nil {
+		return nil, errors.New("Key Vault returned no key material")
+	}
+	return resp.Key, nil
+}
+
 // Encrypt encrypts plaintext using the client's key. This method encrypts only a single block of data, whose
-// size dependens on the key and algorithm.
+// size dependens on the key and algorithm. When the client has a PublicKeyProvider, or ClientOptions.PreferLocal
+// is true, RSA encryption is performed locally with the key's cached public key.
 func (c *Client) Encrypt(ctx context.Context, alg EncryptionAlg, plaintext []byte, options *EncryptOptions) (EncryptResponse, error) {
 	if options == nil {
 		options = &EncryptOptions{}
 	}
 
+	if c.keyProvider != nil {
+		if key, err := c.keyProvider.getKey(ctx, c); err == nil {
+			resp, err := encryptLocally(key, alg, plaintext)
+			if err != errLocalUnsupported {
+				return resp, err
+			}
+		}
+	}
+
 	resp, err := c.client().Encrypt(
 		ctx,
 		c.vaultURL(),
@@ -300,12 +359,22 @@ func wrapKeyResponseFromGenerated(i generated.KeyVaultClientWrapKeyResponse, alg
 	}
 }
 
-// WrapKey encrypts the specified key.
+// WrapKey encrypts the specified key. When the client has a PublicKeyProvider, or ClientOptions.PreferLocal
+// is true, RSA key wrapping is performed locally with the key's cached public key.
 func (c *Client) WrapKey(ctx context.Context, alg WrapAlg, key []byte, options *WrapKeyOptions) (WrapKeyResponse, error) {
 	if options == nil {
 		options = &WrapKeyOptions{}
 	}
 
+	if c.keyProvider != nil {
+		if jwk, err := c.keyProvider.getKey(ctx, c); err == nil {
+			resp, err := wrapKeyLocally(jwk, alg, key)
+			if err != errLocalUnsupported {
+				return resp, err
+			}
+		}
+	}
+
 	resp, err := c.client().WrapKey(
 		ctx,
 		c.vaultURL(),
@@ -458,12 +527,22 @@ func verifyResponseFromGenerated(i generated.KeyVaultClientVerifyResponse, id st
 }
 
 // Verify verifies the specified signature. The algorithm must be the same algorithm used to sign the digest, and
-// compatible with the hash algorithm used to compute the digest.
+// compatible with the hash algorithm used to compute the digest. When the client has a PublicKeyProvider, or
+// ClientOptions.PreferLocal is true, RSA and EC signatures are verified locally with the key's cached public key.
 func (c *Client) Verify(ctx context.Context, algorithm SignatureAlg, digest []byte, signature []byte, options *VerifyOptions) (VerifyResponse, error) {
 	if options == nil {
 		options = &VerifyOptions{}
 	}
 
+	if c.keyProvider != nil {
+		if key, err := c.keyProvider.getKey(ctx, c); err == nil {
+			resp, err := verifyLocally(key, algorithm, digest, signature)
+			if err != errLocalUnsupported {
+				return resp, err
+			}
+		}
+	}
+
 	resp, err := c.client().Verify(
 		ctx,
 		c.vaultURL(),
@@ -0,0 +1,274 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+// Package fake contains a fake implementation of crypto.Operator, whose outputs depend only on its key ID and
+// its inputs, for hermetic tests of code that encrypts, wraps or signs with Key Vault keys, and for comparing
+// its outputs with golden files. It doesn't protect data and must not be used outside tests.
+//
+// The outputs are derived from a key K, the SHA-256 hash of the key ID, with the stream function
+//
+//	S(label, alg, data, n) = the first n bytes of H(0) || H(1) || ...
+//	H(i) = HMAC-SHA256(K, label || 0x00 || alg || 0x00 || data || uint32be(i))
+//
+// where label is an ASCII operation name and alg the algorithm's name, for example "RS256":
+//
+//   - Encrypt XORs the plaintext with S("encrypt", alg, IV, len(plaintext)), so ciphertexts have the plaintext's
+//     length. The CBC algorithms use a 16 byte IV and the GCM algorithms a 12 byte IV, which is EncryptOptions.IV
+//     or, when that's empty, S("iv", alg, "", size). The GCM algorithms' AuthTag is the first 16 bytes of
+//     HMAC-SHA256(K, "tag" || 0x00 || alg || 0x00 || IV || AuthData || ciphertext). Decrypt reverses Encrypt,
+//     failing when a GCM AuthTag doesn't match.
+//   - WrapKey XORs the key with S("wrap", alg, "", len(key)), and UnwrapKey reverses it.
+//   - Sign returns S("sign", alg, digest, size), where size is the size of a real signature: 64 bytes for ES256
+//     and ES256K, 96 for ES384, 132 for ES512 and 256, a 2048-bit RSA key's, for the RSA algorithms. The digest
+//     must have the size of the algorithm's hash. Verify reports whether a signature is the one Sign returns.
+//
+// For example, a Client with the key ID "https://fake.vault.azure.net/keys/key/version" signs the SHA-256 digest
+// of "hello" with ES256 to a signature beginning with the bytes 0x72 0xad 0xb5 0xcc.
+package fake
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/crypto"
+)
+
+const (
+	cbcIVSize = 16
+	gcmIVSize = 12
+	tagSize   = 16
+)
+
+// Client is a fake crypto.Operator. Its zero value is not usable; create one with NewClient.
+type Client struct {
+	keyID string
+	key   []byte
+
+	// Err, when set, is returned by every operation, for testing error handling.
+	Err error
+}
+
+var _ crypto.Operator = (*Client)(nil)
+
+// NewClient creates a Client whose outputs are derived from keyID, which it also returns as the KeyID of
+// each response. Clients having the same key ID return the same outputs.
+func NewClient(keyID string) *Client {
+	k := sha256.Sum256([]byte(keyID))
+	return &Client{keyID: keyID, key: k[:]}
+}
+
+// Encrypt encrypts plaintext as described in the package documentation.
+func (c *Client) Encrypt(ctx context.Context, alg crypto.EncryptionAlg, plaintext []byte, options *crypto.EncryptOptions) (crypto.EncryptResponse, error) {
+	if c.Err != nil {
+		return crypto.EncryptResponse{}, c.Err
+	}
+	if options == nil {
+		options = &crypto.EncryptOptions{}
+	}
+	ivSize, err := encryptionIVSize(alg)
+	if err != nil {
+		return crypto.EncryptResponse{}, err
+	}
+	iv := c.iv(string(alg), options.IV, ivSize)
+	resp := crypto.EncryptResponse{
+		Algorithm:  to.Ptr(alg),
+		Ciphertext: xor(plaintext, c.stream("encrypt", string(alg), iv, len(plaintext))),
+		IV:         iv,
+		KeyID:      to.Ptr(c.keyID),
+	}
+	if ivSize == gcmIVSize {
+		resp.AuthData = options.AuthData
+		resp.AuthTag = c.tag(string(alg), iv, options.AuthData, resp.Ciphertext)
+	}
+	return resp, nil
+}
+
+// Decrypt decrypts ciphertext encrypted by Encrypt.
+func (c *Client) Decrypt(ctx context.Context, alg crypto.EncryptionAlg, ciphertext []byte, options *crypto.DecryptOptions) (crypto.DecryptResponse, error) {
+	if c.Err != nil {
+		return crypto.DecryptResponse{}, c.Err
+	}
+	if options == nil {
+		options = &crypto.DecryptOptions{}
+	}
+	ivSize, err := encryptionIVSize(alg)
+	if err != nil {
+		return crypto.DecryptResponse{}, err
+	}
+	iv := c.iv(string(alg), options.IV, ivSize)
+	if ivSize == gcmIVSize && !hmac.Equal(options.AuthTag, c.tag(string(alg), iv, options.AuthData, ciphertext)) {
+		return crypto.DecryptResponse{}, errors.New("fake: authentication tag mismatch")
+	}
+	return crypto.DecryptResponse{
+		Algorithm: to.Ptr(alg),
+		KeyID:     to.Ptr(c.keyID),
+		Plaintext: xor(ciphertext, c.stream("encrypt", string(alg), iv, len(ciphertext))),
+	}, nil
+}
+
+// WrapKey wraps key as described in the package documentation.
+func (c *Client) WrapKey(ctx context.Context, alg crypto.WrapAlg, key []byte, options *crypto.WrapKeyOptions) (crypto.WrapKeyResponse, error) {
+	if c.Err != nil {
+		return crypto.WrapKeyResponse{}, c.Err
+	}
+	if err := checkWrapAlg(alg); err != nil {
+		return crypto.WrapKeyResponse{}, err
+	}
+	return crypto.WrapKeyResponse{
+		Algorithm:    to.Ptr(alg),
+		EncryptedKey: xor(key, c.stream("wrap", string(alg), nil, len(key))),
+		KeyID:        to.Ptr(c.keyID),
+	}, nil
+}
+
+// UnwrapKey unwraps a key wrapped by WrapKey.
+func (c *Client) UnwrapKey(ctx context.Context, alg crypto.WrapAlg, encryptedKey []byte, options *crypto.UnwrapKeyOptions) (crypto.UnwrapKeyResponse, error) {
+	if c.Err != nil {
+		return crypto.UnwrapKeyResponse{}, c.Err
+	}
+	if err := checkWrapAlg(alg); err != nil {
+		return crypto.UnwrapKeyResponse{}, err
+	}
+	return crypto.UnwrapKeyResponse{
+		Algorithm: to.Ptr(alg),
+		Key:       xor(encryptedKey, c.stream("wrap", string(alg), nil, len(encryptedKey))),
+		KeyID:     to.Ptr(c.keyID),
+	}, nil
+}
+
+// Sign signs digest as described in the package documentation.
+func (c *Client) Sign(ctx context.Context, algorithm crypto.SignatureAlg, digest []byte, options *crypto.SignOptions) (crypto.SignResponse, error) {
+	if c.Err != nil {
+		return crypto.SignResponse{}, c.Err
+	}
+	sig, err := c.signature(algorithm, digest)
+	if err != nil {
+		return crypto.SignResponse{}, err
+	}
+	return crypto.SignResponse{
+		Algorithm: to.Ptr(algorithm),
+		KeyID:     to.Ptr(c.keyID),
+		Signature: sig,
+	}, nil
+}
+
+// Verify reports whether signature is the signature Sign returns for digest.
+func (c *Client) Verify(ctx context.Context, algorithm crypto.SignatureAlg, digest []byte, signature []byte, options *crypto.VerifyOptions) (crypto.VerifyResponse, error) {
+	if c.Err != nil {
+		return crypto.VerifyResponse{}, c.Err
+	}
+	sig, err := c.signature(algorithm, digest)
+	if err != nil {
+		return crypto.VerifyResponse{}, err
+	}
+	return crypto.VerifyResponse{
+		Algorithm: to.Ptr(algorithm),
+		IsValid:   to.Ptr(hmac.Equal(sig, signature)),
+		KeyID:     to.Ptr(c.keyID),
+	}, nil
+}
+
+func (c *Client) signature(alg crypto.SignatureAlg, digest []byte) ([]byte, error) {
+	var digestSize, sigSize int
+	switch alg {
+	case crypto.SignatureAlgES256, crypto.SignatureAlgES256K:
+		digestSize, sigSize = 32, 64
+	case crypto.SignatureAlgES384:
+		digestSize, sigSize = 48, 96
+	case crypto.SignatureAlgES512:
+		digestSize, sigSize = 64, 132
+	case crypto.SignatureAlgPS256, crypto.SignatureAlgRS256:
+		digestSize, sigSize = 32, 256
+	case crypto.SignatureAlgPS384, crypto.SignatureAlgRS384:
+		digestSize, sigSize = 48, 256
+	case crypto.SignatureAlgPS512, crypto.SignatureAlgRS512:
+		digestSize, sigSize = 64, 256
+	default:
+		return nil, fmt.Errorf("fake: unsupported signature algorithm %q", alg)
+	}
+	if len(digest) != digestSize {
+		return nil, fmt.Errorf("fake: %s requires a %d byte digest, not %d bytes", alg, digestSize, len(digest))
+	}
+	return c.stream("sign", string(alg), digest, sigSize), nil
+}
+
+// stream returns n bytes of the stream function S described in the package documentation
+func (c *Client) stream(label string, alg string, data []byte, n int) []byte {
+	out := make([]byte, 0, n+sha256.Size)
+	counter := make([]byte, 4)
+	for i := uint32(0); len(out) < n; i++ {
+		binary.BigEndian.PutUint32(counter, i)
+		mac := hmac.New(sha256.New, c.key)
+		mac.Write([]byte(label))
+		mac.Write([]byte{0})
+		mac.Write([]byte(alg))
+		mac.Write([]byte{0})
+		mac.Write(data)
+		mac.Write(counter)
+		out = mac.Sum(out)
+	}
+	return out[:n]
+}
+
+// iv returns iv, or the default IV of alg when iv is empty. It returns nil when size is 0.
+func (c *Client) iv(alg string, iv []byte, size int) []byte {
+	if size == 0 {
+		return nil
+	}
+	if len(iv) > 0 {
+		return iv
+	}
+	return c.stream("iv", alg, nil, size)
+}
+
+func (c *Client) tag(alg string, iv, authData, ciphertext []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte("tag"))
+	mac.Write([]byte{0})
+	mac.Write([]byte(alg))
+	mac.Write([]byte{0})
+	mac.Write(iv)
+	mac.Write(authData)
+	mac.Write(ciphertext)
+	return mac.Sum(nil)[:tagSize]
+}
+
+// encryptionIVSize returns the size of alg's IV, which is 0 for algorithms without one
+func encryptionIVSize(alg crypto.EncryptionAlg) (int, error) {
+	switch alg {
+	case crypto.EncryptionAlgA128CBC, crypto.EncryptionAlgA128CBCPAD, crypto.EncryptionAlgA192CBC,
+		crypto.EncryptionAlgA192CBCPAD, crypto.EncryptionAlgA256CBC, crypto.EncryptionAlgA256CBCPAD:
+		return cbcIVSize, nil
+	case crypto.EncryptionAlgA128GCM, crypto.EncryptionAlgA192GCM, crypto.EncryptionAlgA256GCM:
+		return gcmIVSize, nil
+	case crypto.EncryptionAlgA128KW, crypto.EncryptionAlgA192KW, crypto.EncryptionAlgA256KW,
+		crypto.EncryptionAlgRSA15, crypto.EncryptionAlgRSAOAEP, crypto.EncryptionAlgRSAOAEP256:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("fake: unsupported encryption algorithm %q", alg)
+}
+
+func checkWrapAlg(alg crypto.WrapAlg) error {
+	for _, a := range crypto.PossibleWrapAlgValues() {
+		if a == alg {
+			return nil
+		}
+	}
+	return fmt.Errorf("fake: unsupported key wrap algorithm %q", alg)
+}
+
+func xor(data, stream []byte) []byte {
+	out := make([]byte, len(data))
+	for i := range data {
+		out[i] = data[i] ^ stream[i]
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package fake
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const testKeyID = "https://fake.vault.azure.net/keys/key/version"
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// TestVectors pins the outputs documented in the package documentation, which golden files depend on
+func TestVectors(t *testing.T) {
+	c := NewClient(testKeyID)
+	ctx := context.Background()
+	digest := sha256.Sum256([]byte("hello"))
+
+	sig, err := c.Sign(ctx, crypto.SignatureAlgES256, digest[:], nil)
+	require.NoError(t, err)
+	require.Len(t, sig.Signature, 64)
+	require.Equal(t, mustHex(t, "72adb5cc8cff1d39f009b98d844fa311"), sig.Signature[:16])
+	require.Equal(t, testKeyID, *sig.KeyID)
+
+	sig, err = c.Sign(ctx, crypto.SignatureAlgRS256, digest[:], nil)
+	require.NoError(t, err)
+	require.Len(t, sig.Signature, 256)
+	require.Equal(t, mustHex(t, "4aa1b14892390d2149d89e4ace0619d6"), sig.Signature[:16])
+
+	enc, err := c.Encrypt(ctx, crypto.EncryptionAlgA256GCM, []byte("hello"), &crypto.EncryptOptions{AuthData: []byte("aad")})
+	require.NoError(t, err)
+	require.Equal(t, mustHex(t, "ce86228813"), enc.Ciphertext)
+	require.Equal(t, mustHex(t, "4bf430e529b11292ce72814c"), enc.IV)
+	require.Equal(t, mustHex(t, "f0d7a4b8a512e21515b53da8f69cb4cf"), enc.AuthTag)
+
+	enc, err = c.Encrypt(ctx, crypto.EncryptionAlgRSAOAEP, []byte("hello"), nil)
+	require.NoError(t, err)
+	require.Equal(t, mustHex(t, "f1767ceefe"), enc.Ciphertext)
+	require.Nil(t, enc.IV)
+
+	wrapped, err := c.WrapKey(ctx, crypto.WrapAlgAES256, []byte("0123456789abcdef"), nil)
+	require.NoError(t, err)
+	require.Equal(t, mustHex(t, "b1f48dac8bbd056286e16125d0a43eb1"), wrapped.EncryptedKey)
+}
+
+func TestRoundTrips(t *testing.T) {
+	c := NewClient(testKeyID)
+	ctx := context.Background()
+	plaintext := []byte("plaintext")
+
+	for _, alg := range crypto.PossibleEncryptionAlgValues() {
+		enc, err := c.Encrypt(ctx, alg, plaintext, &crypto.EncryptOptions{AuthData: []byte("aad")})
+		require.NoError(t, err, alg)
+		require.NotEqual(t, plaintext, enc.Ciphertext, alg)
+		dec, err := c.Decrypt(ctx, alg, enc.Ciphertext, &crypto.DecryptOptions{AuthData: enc.AuthData, AuthTag: enc.AuthTag, IV: enc.IV})
+		require.NoError(t, err, alg)
+		require.Equal(t, plaintext, dec.Plaintext, alg)
+	}
+
+	// a tampered GCM ciphertext fails to decrypt
+	enc, err := c.Encrypt(ctx, crypto.EncryptionAlgA128GCM, plaintext, nil)
+	require.NoError(t, err)
+	enc.Ciphertext[0] ^= 1
+	_, err = c.Decrypt(ctx, crypto.EncryptionAlgA128GCM, enc.Ciphertext, &crypto.DecryptOptions{AuthTag: enc.AuthTag, IV: enc.IV})
+	require.Error(t, err)
+
+	for _, alg := range crypto.PossibleWrapAlgValues() {
+		wrapped, err := c.WrapKey(ctx, alg, []byte("key"), nil)
+		require.NoError(t, err, alg)
+		unwrapped, err := c.UnwrapKey(ctx, alg, wrapped.EncryptedKey, nil)
+		require.NoError(t, err, alg)
+		require.Equal(t, []byte("key"), unwrapped.Key, alg)
+	}
+
+	digest := sha512.Sum384([]byte("hello"))
+	sig, err := c.Sign(ctx, crypto.SignatureAlgES384, digest[:], nil)
+	require.NoError(t, err)
+	require.Len(t, sig.Signature, 96)
+	v, err := c.Verify(ctx, crypto.SignatureAlgES384, digest[:], sig.Signature, nil)
+	require.NoError(t, err)
+	require.True(t, *v.IsValid)
+
+	// another key's signature isn't valid
+	other, err := NewClient(testKeyID+"2").Sign(ctx, crypto.SignatureAlgES384, digest[:], nil)
+	require.NoError(t, err)
+	v, err = c.Verify(ctx, crypto.SignatureAlgES384, digest[:], other.Signature, nil)
+	require.NoError(t, err)
+	require.False(t, *v.IsValid)
+
+	// the digest must match the algorithm's hash
+	_, err = c.Sign(ctx, crypto.SignatureAlgES256, digest[:], nil)
+	require.Error(t, err)
+	_, err = c.Sign(ctx, crypto.SignatureAlgRSNULL, digest[:], nil)
+	require.Error(t, err)
+}
+
+func TestErr(t *testing.T) {
+	c := NewClient(testKeyID)
+	c.Err = errors.New("fake failure")
+	var op crypto.Operator = c
+	_, err := op.Encrypt(context.Background(), crypto.EncryptionAlgRSAOAEP, []byte("x"), nil)
+	require.ErrorIs(t, err, c.Err)
+	digest := sha256.Sum256([]byte("x"))
+	_, err = op.Sign(context.Background(), crypto.SignatureAlgRS256, digest[:], nil)
+	require.ErrorIs(t, err, c.Err)
+}
@@ -0,0 +1,177 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package crypto
+
+import (
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	generated "github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/jwk"
+)
+
+// errLocalUnsupported indicates an operation can't be performed locally and should be sent to Key Vault
+var errLocalUnsupported = errors.New("operation isn't supported locally")
+
+// allowsOperation returns true when key permits op
+func allowsOperation(key *generated.JSONWebKey, op string) bool {
+	for _, o := range key.KeyOps {
+		if o != nil && *o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// publicKeyFromJSONWebKey converts the public portion of an RSA or EC JSON web key to a *rsa.PublicKey or *ecdsa.PublicKey
+func publicKeyFromJSONWebKey(key *generated.JSONWebKey) (stdcrypto.PublicKey, error) {
+	pub, err := jwk.PublicKey(key)
+	if errors.Is(err, jwk.ErrUnsupported) {
+		return nil, errLocalUnsupported
+	}
+	return pub, err
+}
+
+// encryptLocally encrypts plaintext with the public portion of key. It returns errLocalUnsupported when the
+// algorithm requires the service, for example because it's symmetric.
+func encryptLocally(key *generated.JSONWebKey, alg EncryptionAlg, plaintext []byte) (EncryptResponse, error) {
+	if !allowsOperation(key, string(generated.JSONWebKeyOperationEncrypt)) {
+		return EncryptResponse{}, errLocalUnsupported
+	}
+	pub, err := publicKeyFromJSONWebKey(key)
+	if err != nil {
+		return EncryptResponse{}, err
+	}
+	ciphertext, err := rsaEncrypt(pub, string(alg), plaintext)
+	if err != nil {
+		return EncryptResponse{}, err
+	}
+
+	return EncryptResponse{
+		Algorithm:  to.Ptr(alg),
+		Ciphertext: ciphertext,
+		KeyID:      key.Kid,
+	}, nil
+}
+
+// wrapKeyLocally wraps k with the public portion of key. It returns errLocalUnsupported when the
+// algorithm requires the service, for example because it's symmetric.
+func wrapKeyLocally(key *generated.JSONWebKey, alg WrapAlg, k []byte) (WrapKeyResponse, error) {
+	if !allowsOperation(key, string(generated.JSONWebKeyOperationWrapKey)) {
+		return WrapKeyResponse{}, errLocalUnsupported
+	}
+	pub, err := publicKeyFromJSONWebKey(key)
+	if err != nil {
+		return WrapKeyResponse{}, err
+	}
+	encryptedKey, err := rsaEncrypt(pub, string(alg), k)
+	if err != nil {
+		return WrapKeyResponse{}, err
+	}
+
+	return WrapKeyResponse{
+		Algorithm:    to.Ptr(alg),
+		EncryptedKey: encryptedKey,
+		KeyID:        key.Kid,
+	}, nil
+}
+
+// rsaEncrypt encrypts data with pub using the RSA algorithm named alg, which is an EncryptionAlg or a WrapAlg
+// because both name RSA algorithms the same way. It returns errLocalUnsupported when pub isn't an RSA key or
+// alg isn't an RSA algorithm.
+func rsaEncrypt(pub stdcrypto.PublicKey, alg string, data []byte) ([]byte, error) {
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errLocalUnsupported
+	}
+	switch alg {
+	case string(EncryptionAlgRSA15):
+		return rsa.EncryptPKCS1v15(rand.Reader, rsaKey, data)
+	case string(EncryptionAlgRSAOAEP):
+		return rsa.EncryptOAEP(sha1.New(), rand.Reader, rsaKey, data, nil)
+	case string(EncryptionAlgRSAOAEP256):
+		return rsa.EncryptOAEP(sha256.New(), rand.Reader, rsaKey, data, nil)
+	default:
+		return nil, errLocalUnsupported
+	}
+}
+
+// signatureHash returns the hash function of alg, and whether alg is an RSASSA-PSS algorithm
+func signatureHash(alg SignatureAlg) (stdcrypto.Hash, bool, error) {
+	switch alg {
+	case SignatureAlgES256, SignatureAlgRS256:
+		return stdcrypto.SHA256, false, nil
+	case SignatureAlgES384, SignatureAlgRS384:
+		return stdcrypto.SHA384, false, nil
+	case SignatureAlgES512, SignatureAlgRS512:
+		return stdcrypto.SHA512, false, nil
+	case SignatureAlgPS256:
+		return stdcrypto.SHA256, true, nil
+	case SignatureAlgPS384:
+		return stdcrypto.SHA384, true, nil
+	case SignatureAlgPS512:
+		return stdcrypto.SHA512, true, nil
+	default:
+		return 0, false, errLocalUnsupported
+	}
+}
+
+// verifyLocally verifies signature with the public portion of key. It returns errLocalUnsupported when the
+// algorithm requires the service.
+func verifyLocally(key *generated.JSONWebKey, alg SignatureAlg, digest []byte, signature []byte) (VerifyResponse, error) {
+	if !allowsOperation(key, string(generated.JSONWebKeyOperationVerify)) {
+		return VerifyResponse{}, errLocalUnsupported
+	}
+	hash, pss, err := signatureHash(alg)
+	if err != nil {
+		return VerifyResponse{}, err
+	}
+	pub, err := publicKeyFromJSONWebKey(key)
+	if err != nil {
+		return VerifyResponse{}, err
+	}
+
+	var valid bool
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		if alg[0] == 'E' {
+			return VerifyResponse{}, fmt.Errorf("algorithm %s requires an EC key", alg)
+		}
+		if pss {
+			valid = rsa.VerifyPSS(k, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		} else {
+			valid = rsa.VerifyPKCS1v15(k, hash, digest, signature) == nil
+		}
+	case *ecdsa.PublicKey:
+		if alg[0] != 'E' {
+			return VerifyResponse{}, fmt.Errorf("algorithm %s requires an RSA key", alg)
+		}
+		// Key Vault EC signatures are the concatenation of R and S, each the size of the curve order
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) == 2*size {
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			valid = ecdsa.Verify(k, digest, r, s)
+		}
+	default:
+		return VerifyResponse{}, errLocalUnsupported
+	}
+
+	return VerifyResponse{
+		Algorithm: to.Ptr(alg),
+		IsValid:   &valid,
+		KeyID:     key.Kid,
+	}, nil
+}
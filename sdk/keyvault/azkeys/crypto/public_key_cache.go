@@ -0,0 +1,142 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package crypto
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	generated "github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
+	shared "github.com/Azure/azure-sdk-for-go/sdk/keyvault/internal"
+)
+
+// defaultLatestVersionTTL is how long a provider caches the latest version of a key when no TTL is specified.
+const defaultLatestVersionTTL = 5 * time.Minute
+
+// CachedPublicKeyProviderOptions contains optional parameters for NewCachedPublicKeyProvider.
+type CachedPublicKeyProviderOptions struct {
+	// LatestVersionTTL is how long the provider caches key material retrieved without a key version,
+	// that is, the latest version of a key. Defaults to five minutes. Key material for a specific
+	// version is cached indefinitely because key versions are immutable.
+	LatestVersionTTL time.Duration
+}
+
+// CachedPublicKeyProvider caches the public key material of Key Vault keys so that a Client can
+// encrypt and verify locally without retrieving the key for every operation. A provider is safe
+// for concurrent use and can be shared by any number of clients.
+type CachedPublicKeyProvider struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedPublicKey
+
+	// now is a seam for tests
+	now func() time.Time
+}
+
+type cachedPublicKey struct {
+	key *generated.JSONWebKey
+
+	// expires is the zero time for entries that never expire
+	expires time.Time
+}
+
+// NewCachedPublicKeyProvider creates a CachedPublicKeyProvider. Pass nil to accept default options.
+func NewCachedPublicKeyProvider(options *CachedPublicKeyProviderOptions) *CachedPublicKeyProvider {
+	if options == nil {
+		options = &CachedPublicKeyProviderOptions{}
+	}
+	ttl := options.LatestVersionTTL
+	if ttl <= 0 {
+		ttl = defaultLatestVersionTTL
+	}
+	return &CachedPublicKeyProvider{
+		ttl:     ttl,
+		entries: map[string]cachedPublicKey{},
+		now:     time.Now,
+	}
+}
+
+// Clear removes all key material from the cache.
+func (p *CachedPublicKeyProvider) Clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.entries = map[string]cachedPublicKey{}
+}
+
+// getKey returns the public key material of the client's key, retrieving it from Key Vault
+// only when the cache has no usable entry for it.
+func (p *CachedPublicKeyProvider) getKey(ctx context.Context, c *Client) (*generated.JSONWebKey, error) {
+	if key := p.lookup(c.vaultURL(), c.keyID(), c.keyVersion()); key != nil {
+		return key, nil
+	}
+
+	resp, err := c.client().GetKey(ctx, c.vaultURL(), c.keyID(), c.keyVersion(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Key == nil {
+		return nil, errors.New("Key Vault returned no key material")
+	}
+
+	key := publicJSONWebKey(resp.Key)
+	p.store(c.vaultURL(), c.keyID(), c.keyVersion(), key)
+	return key, nil
+}
+
+// lookup returns the cached key for the specified version, or nil when there's no unexpired entry
+func (p *CachedPublicKeyProvider) lookup(vaultURL, name, version string) *generated.JSONWebKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cacheKey := publicKeyCacheKey(vaultURL, name, version)
+	entry, ok := p.entries[cacheKey]
+	if !ok {
+		return nil
+	}
+	if !entry.expires.IsZero() && !p.now().Before(entry.expires) {
+		delete(p.entries, cacheKey)
+		return nil
+	}
+	return entry.key
+}
+
+// store caches key. A key retrieved without a version is also cached under the
+// version identified by its kid, which never expires.
+func (p *CachedPublicKeyProvider) store(vaultURL, name, version string, key *generated.JSONWebKey) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if version != "" {
+		p.entries[publicKeyCacheKey(vaultURL, name, version)] = cachedPublicKey{key: key}
+		return
+	}
+
+	p.entries[publicKeyCacheKey(vaultURL, name, "")] = cachedPublicKey{key: key, expires: p.now().Add(p.ttl)}
+	if _, _, kidVersion := shared.ParseID(key.Kid); kidVersion != nil && *kidVersion != "" {
+		p.entries[publicKeyCacheKey(vaultURL, name, *kidVersion)] = cachedPublicKey{key: key}
+	}
+}
+
+func publicKeyCacheKey(vaultURL, name, version string) string {
+	return vaultURL + "keys/" + name + "/" + version
+}
+
+// publicJSONWebKey returns a copy of key without private or symmetric key material
+func publicJSONWebKey(key *generated.JSONWebKey) *generated.JSONWebKey {
+	return &generated.JSONWebKey{
+		Crv:    key.Crv,
+		E:      key.E,
+		KeyOps: key.KeyOps,
+		Kid:    key.Kid,
+		Kty:    key.Kty,
+		N:      key.N,
+		X:      key.X,
+		Y:      key.Y,
+	}
+}
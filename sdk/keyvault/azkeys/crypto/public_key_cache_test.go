@@ -0,0 +1,188 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package crypto
+
+import (
+	"context"
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	generated "github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedPublicKeyProvider(t *testing.T) {
+	now := time.Now()
+	p := NewCachedPublicKeyProvider(&CachedPublicKeyProviderOptions{LatestVersionTTL: time.Minute})
+	p.now = func() time.Time { return now }
+
+	key := &generated.JSONWebKey{Kid: to.Ptr(fakeKvURL + "keys/key/version")}
+	p.store(fakeKvURL, "key", "", key)
+	require.Equal(t, key, p.lookup(fakeKvURL, "key", ""))
+	require.Equal(t, key, p.lookup(fakeKvURL, "key", "version"))
+	require.Nil(t, p.lookup(fakeKvURL, "key", "other"))
+
+	// the latest version expires but the versioned entry doesn't
+	now = now.Add(time.Minute)
+	require.Nil(t, p.lookup(fakeKvURL, "key", ""))
+	require.Equal(t, key, p.lookup(fakeKvURL, "key", "version"))
+
+	p.Clear()
+	require.Nil(t, p.lookup(fakeKvURL, "key", "version"))
+}
+
+func TestVerifyLocally(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	key := &generated.JSONWebKey{
+		Crv:    to.Ptr(generated.JSONWebKeyCurveNameP256),
+		KeyOps: []*string{to.Ptr("verify")},
+		Kid:    to.Ptr(fakeKvURL + "keys/key/version"),
+		Kty:    to.Ptr(generated.JSONWebKeyTypeEC),
+		X:      priv.X.Bytes(),
+		Y:      priv.Y.Bytes(),
+	}
+
+	digest := sha256.Sum256([]byte("message"))
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+	require.NoError(t, err)
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+
+	resp, err := verifyLocally(key, SignatureAlgES256, digest[:], sig)
+	require.NoError(t, err)
+	require.True(t, *resp.IsValid)
+
+	sig[0] ^= 0xff
+	resp, err = verifyLocally(key, SignatureAlgES256, digest[:], sig)
+	require.NoError(t, err)
+	require.False(t, *resp.IsValid)
+
+	_, err = verifyLocally(key, SignatureAlgES256K, digest[:], sig)
+	require.ErrorIs(t, err, errLocalUnsupported)
+
+	key.KeyOps = nil
+	_, err = verifyLocally(key, SignatureAlgES256, digest[:], sig)
+	require.ErrorIs(t, err, errLocalUnsupported)
+}
+
+func TestWrapKeyLocally(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key := &generated.JSONWebKey{
+		E:      big.NewInt(int64(priv.E)).Bytes(),
+		KeyOps: []*string{to.Ptr("wrapKey")},
+		Kid:    to.Ptr(fakeKvURL + "keys/key/version"),
+		Kty:    to.Ptr(generated.JSONWebKeyTypeRSA),
+		N:      priv.N.Bytes(),
+	}
+	cek := []byte("0123456789abcdef0123456789abcdef")
+
+	resp, err := wrapKeyLocally(key, WrapAlgRSAOAEP256, cek)
+	require.NoError(t, err)
+	require.Equal(t, WrapAlgRSAOAEP256, *resp.Algorithm)
+	require.Equal(t, *key.Kid, *resp.KeyID)
+	unwrapped, err := rsa.DecryptOAEP(sha256.New(), nil, priv, resp.EncryptedKey, nil)
+	require.NoError(t, err)
+	require.Equal(t, cek, unwrapped)
+
+	resp, err = wrapKeyLocally(key, WrapAlgRSA15, cek)
+	require.NoError(t, err)
+	unwrapped, err = rsa.DecryptPKCS1v15(nil, priv, resp.EncryptedKey)
+	require.NoError(t, err)
+	require.Equal(t, cek, unwrapped)
+
+	_, err = wrapKeyLocally(key, WrapAlgAES256, cek)
+	require.ErrorIs(t, err, errLocalUnsupported)
+
+	// the key must permit wrapping
+	key.KeyOps = []*string{to.Ptr("encrypt")}
+	_, err = wrapKeyLocally(key, WrapAlgRSAOAEP256, cek)
+	require.ErrorIs(t, err, errLocalUnsupported)
+}
+
+type keyTransport struct {
+	key      string
+	requests []string
+}
+
+func (k *keyTransport) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		return &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+	k.requests = append(k.requests, req.Method+" "+req.URL.Path)
+	body := `{"error":{"code":"Unexpected","message":"unexpected request"}}`
+	status := http.StatusBadRequest
+	if req.Method == http.MethodGet {
+		body, status = fmt.Sprintf(`{"key":%s}`, k.key), http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestPreferLocal(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	b64 := base64.RawURLEncoding.EncodeToString
+	transport := &keyTransport{key: fmt.Sprintf(`{"kid":"%skeys/key/version","kty":"RSA","key_ops":["encrypt","wrapKey","verify"],"n":"%s","e":"%s"}`,
+		fakeKvURL, b64(priv.N.Bytes()), b64(big.NewInt(int64(priv.E)).Bytes()))}
+
+	client, err := NewClient(fakeKvURL+"keys/key/version", NewFakeCredential("fake", "fake"), &ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: transport},
+		PreferLocal:   true,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	wrapped, err := client.WrapKey(ctx, WrapAlgRSAOAEP, []byte("cek"), nil)
+	require.NoError(t, err)
+	unwrapped, err := priv.Decrypt(nil, wrapped.EncryptedKey, &rsa.OAEPOptions{Hash: stdcrypto.SHA1})
+	require.NoError(t, err)
+	require.Equal(t, []byte("cek"), unwrapped)
+
+	_, err = client.Encrypt(ctx, EncryptionAlgRSAOAEP256, []byte("plaintext"), nil)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("message"))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, priv, stdcrypto.SHA256, digest[:])
+	require.NoError(t, err)
+	verified, err := client.Verify(ctx, SignatureAlgRS256, digest[:], sig, nil)
+	require.NoError(t, err)
+	require.True(t, *verified.IsValid)
+
+	// the key was retrieved once and every operation was local
+	require.Equal(t, []string{"GET /keys/key/version"}, transport.requests)
+
+	// operations requiring the private key are sent to Key Vault
+	_, err = client.UnwrapKey(ctx, WrapAlgRSAOAEP, wrapped.EncryptedKey, nil)
+	require.Error(t, err)
+	require.Equal(t, "POST /keys/key/version/unwrapkey", transport.requests[1])
+}
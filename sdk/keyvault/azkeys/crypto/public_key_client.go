@@ -0,0 +1,124 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package crypto
+
+import (
+	"context"
+	stdcrypto "crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	generated "github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/jwk"
+)
+
+// PublicKeyClientOptions contains optional parameters for NewPublicKeyClient.
+type PublicKeyClientOptions struct {
+	// placeholder for future optional parameters
+}
+
+// PublicKeyClient performs the operations that require only the public portion of an RSA or EC key: it encrypts,
+// wraps keys and verifies signatures locally, without a credential and without sending requests to Key Vault. It
+// suits services that only verify signatures or encrypt data, which then need no permissions on the vault, or only
+// the keys/get permission when the key is gotten with Client.NewPublicKeyClient. Its methods have the same
+// signatures as those of Client. A PublicKeyClient is safe for concurrent use.
+type PublicKeyClient struct {
+	key *generated.JSONWebKey
+	pub stdcrypto.PublicKey
+}
+
+// NewPublicKeyClient constructs a PublicKeyClient for a JSON web key, such as an element of the "keys" array of a
+// JWKS document or an azkeys.JSONWebKey marshaled to JSON. Any private key material is ignored. When the key has
+// no "key_ops", it permits all of the PublicKeyClient's operations. Pass nil for options to accept default values.
+func NewPublicKeyClient(key []byte, options *PublicKeyClientOptions) (*PublicKeyClient, error) {
+	var k generated.JSONWebKey
+	if err := json.Unmarshal(key, &k); err != nil {
+		return nil, fmt.Errorf("failed to parse the JSON web key: %w", err)
+	}
+	return newPublicKeyClient(&k)
+}
+
+// NewPublicKeyClient gets the public portion of the client's RSA or EC key from Key Vault and returns a
+// PublicKeyClient for it, so the key's public operations can be performed without further requests. Getting
+// the key requires only the keys/get permission.
+func (c *Client) NewPublicKeyClient(ctx context.Context) (*PublicKeyClient, error) {
+	key, err := c.getKeyMaterial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newPublicKeyClient(key)
+}
+
+func newPublicKeyClient(key *generated.JSONWebKey) (*PublicKeyClient, error) {
+	key = publicJSONWebKey(key)
+	pub, err := jwk.PublicKey(key)
+	if errors.Is(err, jwk.ErrUnsupported) {
+		return nil, errors.New("a PublicKeyClient requires an RSA key or an EC key on curve P-256, P-384 or P-521")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(key.KeyOps) == 0 {
+		key.KeyOps = []*string{
+			to.Ptr(string(generated.JSONWebKeyOperationEncrypt)),
+			to.Ptr(string(generated.JSONWebKeyOperationWrapKey)),
+			to.Ptr(string(generated.JSONWebKeyOperationVerify)),
+		}
+	}
+	return &PublicKeyClient{key: key, pub: pub}, nil
+}
+
+// KeyID returns the key's identifier, its "kid", which is empty when the JSON web key has none.
+func (c *PublicKeyClient) KeyID() string {
+	if c.key.Kid == nil {
+		return ""
+	}
+	return *c.key.Kid
+}
+
+// Public returns the public key, a *rsa.PublicKey or an *ecdsa.PublicKey.
+func (c *PublicKeyClient) Public() stdcrypto.PublicKey {
+	return c.pub
+}
+
+// Encrypt encrypts plaintext with an RSA key. Symmetric algorithms, which require Key Vault, aren't supported.
+func (c *PublicKeyClient) Encrypt(ctx context.Context, alg EncryptionAlg, plaintext []byte, options *EncryptOptions) (EncryptResponse, error) {
+	resp, err := encryptLocally(c.key, alg, plaintext)
+	if err == errLocalUnsupported {
+		return EncryptResponse{}, c.unsupported(generated.JSONWebKeyOperationEncrypt, string(alg))
+	}
+	return resp, err
+}
+
+// WrapKey wraps key with an RSA key. Symmetric algorithms, which require Key Vault, aren't supported.
+func (c *PublicKeyClient) WrapKey(ctx context.Context, alg WrapAlg, key []byte, options *WrapKeyOptions) (WrapKeyResponse, error) {
+	resp, err := wrapKeyLocally(c.key, alg, key)
+	if err == errLocalUnsupported {
+		return WrapKeyResponse{}, c.unsupported(generated.JSONWebKeyOperationWrapKey, string(alg))
+	}
+	return resp, err
+}
+
+// Verify verifies the specified signature. The algorithm must be the same algorithm used to sign the digest, and
+// compatible with the hash algorithm used to compute the digest.
+func (c *PublicKeyClient) Verify(ctx context.Context, algorithm SignatureAlg, digest []byte, signature []byte, options *VerifyOptions) (VerifyResponse, error) {
+	resp, err := verifyLocally(c.key, algorithm, digest, signature)
+	if err == errLocalUnsupported {
+		return VerifyResponse{}, c.unsupported(generated.JSONWebKeyOperationVerify, string(algorithm))
+	}
+	return resp, err
+}
+
+// unsupported explains why op can't be performed with alg
+func (c *PublicKeyClient) unsupported(op generated.JSONWebKeyOperation, alg string) error {
+	if !allowsOperation(c.key, string(op)) {
+		return fmt.Errorf("the key doesn't permit the %s operation", op)
+	}
+	return fmt.Errorf("algorithm %s isn't supported with the public key", alg)
+}
@@ -0,0 +1,104 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package crypto
+
+import (
+	"context"
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/jwk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicKeyClientRSA(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := jwk.FromPublicKey(&priv.PublicKey)
+	require.NoError(t, err)
+	key.Kid = to.Ptr(fakeKvURL + "keys/key/version")
+	b, err := json.Marshal(key)
+	require.NoError(t, err)
+
+	// the key has no key_ops, so it permits all public operations
+	client, err := NewPublicKeyClient(b, nil)
+	require.NoError(t, err)
+	require.Equal(t, &priv.PublicKey, client.Public())
+	require.Equal(t, fakeKvURL+"keys/key/version", client.KeyID())
+
+	encrypted, err := client.Encrypt(context.Background(), EncryptionAlgRSAOAEP256, []byte("plaintext"), nil)
+	require.NoError(t, err)
+	require.Equal(t, fakeKvURL+"keys/key/version", *encrypted.KeyID)
+	plaintext, err := rsa.DecryptOAEP(sha256.New(), nil, priv, encrypted.Ciphertext, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("plaintext"), plaintext)
+
+	wrapped, err := client.WrapKey(context.Background(), WrapAlgRSAOAEP256, []byte("key"), nil)
+	require.NoError(t, err)
+	unwrapped, err := rsa.DecryptOAEP(sha256.New(), nil, priv, wrapped.EncryptedKey, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("key"), unwrapped)
+	_, err = client.WrapKey(context.Background(), WrapAlgAES256, []byte("key"), nil)
+	require.Error(t, err)
+
+	digest := sha256.Sum256([]byte("message"))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, priv, stdcrypto.SHA256, digest[:])
+	require.NoError(t, err)
+	verified, err := client.Verify(context.Background(), SignatureAlgRS256, digest[:], sig, nil)
+	require.NoError(t, err)
+	require.True(t, *verified.IsValid)
+	verified, err = client.Verify(context.Background(), SignatureAlgRS256, digest[:], sig[1:], nil)
+	require.NoError(t, err)
+	require.False(t, *verified.IsValid)
+
+	// key_ops restrict the operations
+	key.KeyOps = []*string{to.Ptr("verify")}
+	b, err = json.Marshal(key)
+	require.NoError(t, err)
+	client, err = NewPublicKeyClient(b, nil)
+	require.NoError(t, err)
+	_, err = client.Encrypt(context.Background(), EncryptionAlgRSAOAEP256, []byte("plaintext"), nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "doesn't permit the encrypt operation")
+
+	_, err = NewPublicKeyClient([]byte(`{"kty":"oct","k":"c2VjcmV0"}`), nil)
+	require.Error(t, err)
+	_, err = NewPublicKeyClient([]byte(`not JSON`), nil)
+	require.Error(t, err)
+}
+
+func TestPublicKeyClientFromVault(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	transport := &signingTransport{priv: priv, ops: []string{"sign", "verify"}}
+	client, err := NewClient(fakeKvURL+"keys/key/version", NewFakeCredential("fake", "fake"), &ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: transport},
+	})
+	require.NoError(t, err)
+	publicClient, err := client.NewPublicKeyClient(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &priv.PublicKey, publicClient.Public())
+
+	digest := sha256.Sum256([]byte("message"))
+	r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+	require.NoError(t, err)
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	verified, err := publicClient.Verify(context.Background(), SignatureAlgES256, digest[:], sig, nil)
+	require.NoError(t, err)
+	require.True(t, *verified.IsValid)
+
+	_, err = publicClient.Encrypt(context.Background(), EncryptionAlgRSAOAEP, []byte("plaintext"), nil)
+	require.Error(t, err)
+}
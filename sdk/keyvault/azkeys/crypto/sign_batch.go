@@ -0,0 +1,123 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package crypto
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal"
+)
+
+const (
+	defaultSignBatchConcurrency = 16
+
+	// maxSignAttempts is how many times SignBatch tries to sign a digest whose requests are throttled
+	maxSignAttempts = 5
+)
+
+// RateLimiter limits the rate of requests to Key Vault. Share one between batches, and between clients, to limit
+// their combined rate, for example to stay within a vault's service limits. When Key Vault throttles a request
+// despite the limit, the RateLimiter pauses all the requests sharing it. Create one with NewRateLimiter.
+type RateLimiter struct {
+	throttle *internal.Throttle
+}
+
+// NewRateLimiter creates a RateLimiter that allows requestsPerSecond requests per second. When requestsPerSecond
+// isn't positive, the rate isn't limited and the RateLimiter only pauses requests after one is throttled.
+func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
+	return &RateLimiter{throttle: internal.NewThrottle(requestsPerSecond)}
+}
+
+// SignBatchOptions contains optional parameters for SignBatch.
+type SignBatchOptions struct {
+	// MaxConcurrency is the maximum number of sign requests sent at once. The default value is 16.
+	MaxConcurrency int
+
+	// RateLimiter limits the rate of the sign requests. When nil, the rate isn't limited, but throttled
+	// requests still pause the batch's other requests.
+	RateLimiter *RateLimiter
+}
+
+// SignBatchResult is the result of signing one digest of a batch.
+type SignBatchResult struct {
+	SignResponse
+
+	// Err is the error that prevented signing the digest. It's nil when the digest was signed.
+	Err error
+}
+
+// SignBatchResponse is returned by SignBatch.
+type SignBatchResponse struct {
+	// Results are the results of signing the digests, in the order of the digests.
+	Results []SignBatchResult
+}
+
+// SignBatch signs each of the specified digests with Sign, sending up to SignBatchOptions.MaxConcurrency requests at
+// once, for services that sign many tokens. When Key Vault throttles a request beyond the Client's retry policy,
+// SignBatch pauses all the requests sharing its RateLimiter for the duration of the response's Retry-After header
+// before trying again. The hash algorithm used to compute the digests must be compatible with the specified algorithm.
+// When a digest can't be signed, SignBatch still signs the others, and returns the error of the first digest that
+// failed along with the results. Pass nil for options to accept default values.
+func (c *Client) SignBatch(ctx context.Context, algorithm SignatureAlg, digests [][]byte, options *SignBatchOptions) (SignBatchResponse, error) {
+	if options == nil {
+		options = &SignBatchOptions{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = defaultSignBatchConcurrency
+	}
+	limiter := options.RateLimiter
+	if limiter == nil {
+		limiter = NewRateLimiter(0)
+	}
+
+	results := make([]SignBatchResult, len(digests))
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i, digest := range digests {
+		wg.Add(1)
+		sem <- struct{}{}
+		// each goroutine writes only to its digest's result
+		go func(i int, digest []byte) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i].SignResponse, results[i].Err = c.signThrottled(ctx, algorithm, digest, limiter.throttle)
+		}(i, digest)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		if r.Err != nil {
+			return SignBatchResponse{Results: results}, r.Err
+		}
+	}
+	return SignBatchResponse{Results: results}, nil
+}
+
+// signThrottled signs a digest, pausing all requests sharing throttle when Key Vault throttles one
+func (c *Client) signThrottled(ctx context.Context, algorithm SignatureAlg, digest []byte, throttle *internal.Throttle) (SignResponse, error) {
+	for attempt := 1; ; attempt++ {
+		if err := throttle.Wait(ctx); err != nil {
+			return SignResponse{}, err
+		}
+		resp, err := c.Sign(ctx, algorithm, digest, nil)
+		if err == nil {
+			return resp, nil
+		}
+		var respErr *azcore.ResponseError
+		if attempt == maxSignAttempts || !errors.As(err, &respErr) || respErr.StatusCode != http.StatusTooManyRequests {
+			return SignResponse{}, err
+		}
+		throttle.Pause(internal.RetryAfter(respErr.RawResponse))
+	}
+}
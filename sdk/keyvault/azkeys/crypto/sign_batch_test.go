@@ -0,0 +1,117 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	generated "github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
+	"github.com/stretchr/testify/require"
+)
+
+// throttlingTransport throttles the first sign request and rejects digests starting with "bad"
+type throttlingTransport struct {
+	*signingTransport
+
+	mu        sync.Mutex
+	throttled bool
+	signs     int
+}
+
+func (s *throttlingTransport) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" && strings.HasSuffix(req.URL.Path, "/sign") {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		var params generated.KeySignParameters
+		if err := json.Unmarshal(body, &params); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.signs++
+		throttle := !s.throttled
+		s.throttled = true
+		s.mu.Unlock()
+		if throttle {
+			return &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"0"}}, Body: http.NoBody, Request: req}, nil
+		}
+		if bytes.HasPrefix(params.Value, []byte("bad")) {
+			return &http.Response{
+				StatusCode: http.StatusBadRequest,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"code":"BadParameter","message":"invalid digest"}}`)),
+				Request:    req,
+			}, nil
+		}
+	}
+	return s.signingTransport.Do(req)
+}
+
+func TestSignBatch(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	transport := &throttlingTransport{signingTransport: &signingTransport{priv: priv}}
+	client, err := NewClient(fakeKvURL+"keys/key/version", NewFakeCredential("fake", "fake"), &ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: transport, Retry: policy.RetryOptions{MaxRetries: -1}},
+	})
+	require.NoError(t, err)
+
+	digests := make([][]byte, 20)
+	for i := range digests {
+		sum := sha256.Sum256([]byte(fmt.Sprint(i)))
+		digests[i] = sum[:]
+	}
+	limiter := NewRateLimiter(1000)
+	resp, err := client.SignBatch(context.Background(), SignatureAlgES256, digests, &SignBatchOptions{MaxConcurrency: 4, RateLimiter: limiter})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, len(digests))
+	for i, r := range resp.Results {
+		require.NoError(t, r.Err)
+		require.Equal(t, SignatureAlgES256, *r.Algorithm)
+		// the results are in the order of the digests
+		require.True(t, ecdsa.Verify(&priv.PublicKey, digests[i], new(big.Int).SetBytes(r.Signature[:32]), new(big.Int).SetBytes(r.Signature[32:])), i)
+	}
+	// the throttled request was tried again
+	require.Equal(t, len(digests)+1, transport.signs)
+
+	// a digest that can't be signed doesn't prevent signing the others
+	digests[1] = []byte("bad digest")
+	resp, err = client.SignBatch(context.Background(), SignatureAlgES256, digests, nil)
+	var respErr *azcore.ResponseError
+	require.ErrorAs(t, err, &respErr)
+	require.Equal(t, http.StatusBadRequest, respErr.StatusCode)
+	require.Same(t, err, resp.Results[1].Err)
+	require.NoError(t, resp.Results[0].Err)
+	require.NoError(t, resp.Results[2].Err)
+	require.NotEmpty(t, resp.Results[2].Signature)
+
+	// a paused RateLimiter holds the requests of every batch sharing it
+	limiter.throttle.Pause(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	resp, err = client.SignBatch(ctx, SignatureAlgES256, digests[:2], &SignBatchOptions{RateLimiter: limiter})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorIs(t, resp.Results[1].Err, context.DeadlineExceeded)
+}
@@ -0,0 +1,167 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package crypto
+
+import (
+	"context"
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	generated "github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/jwk"
+)
+
+// NewSignerOptions contains optional parameters for Client.NewSigner.
+type NewSignerOptions struct {
+	// Timeout limits the duration of each signing request. Signer.Sign has no context parameter, so
+	// without a Timeout a request is bounded only by the Client's retry policy.
+	Timeout time.Duration
+}
+
+// Signer implements crypto.Signer with a Key Vault key, so the key can be used where the standard library
+// and other packages expect a private key, for example as the PrivateKey of a tls.Certificate, with
+// x509.CreateCertificateRequest or with JWT libraries. Signing is performed by Key Vault. Create a Signer
+// with Client.NewSigner.
+type Signer struct {
+	client  *Client
+	public  stdcrypto.PublicKey
+	timeout time.Duration
+}
+
+var _ stdcrypto.Signer = (*Signer)(nil)
+
+// NewSigner gets the client's RSA or EC key from Key Vault and returns a Signer for it. The key must
+// permit the sign operation, and EC keys must be on curve P-256, P-384 or P-521.
+func (c *Client) NewSigner(ctx context.Context, options *NewSignerOptions) (*Signer, error) {
+	if options == nil {
+		options = &NewSignerOptions{}
+	}
+
+	key, err := c.getKeyMaterial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(key.KeyOps) > 0 && !allowsOperation(key, string(generated.JSONWebKeyOperationSign)) {
+		return nil, errors.New("the key doesn't permit the sign operation")
+	}
+	pub, err := jwk.PublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{client: c, public: pub, timeout: options.Timeout}, nil
+}
+
+// Public returns the public key, a *rsa.PublicKey or an *ecdsa.PublicKey.
+func (s *Signer) Public() stdcrypto.PublicKey {
+	return s.public
+}
+
+// Sign signs digest with Key Vault's sign operation, using the algorithm selected by the key and opts.
+// rand is ignored because Key Vault generates any randomness the signature requires.
+//
+// RSA keys sign with RSASSA-PSS when opts is an *rsa.PSSOptions, whose SaltLength must be
+// rsa.PSSSaltLengthAuto, rsa.PSSSaltLengthEqualsHash or the size of the hash, and otherwise with
+// RSASSA-PKCS1-v1_5. The hash must be SHA-256, SHA-384 or SHA-512. EC keys require the hash matching
+// their curve, for example SHA-256 for P-256, and return an ASN.1 DER signature, as ecdsa.SignASN1 does.
+func (s *Signer) Sign(rand io.Reader, digest []byte, opts stdcrypto.SignerOpts) ([]byte, error) {
+	alg, err := s.algorithm(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(digest) != opts.HashFunc().Size() {
+		return nil, fmt.Errorf("the digest has %d bytes, expected %d for %s", len(digest), opts.HashFunc().Size(), opts.HashFunc())
+	}
+
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	resp, err := s.client.Sign(ctx, alg, digest, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.public.(*ecdsa.PublicKey); ok {
+		return ecdsaSignatureToASN1(resp.Signature)
+	}
+	return resp.Signature, nil
+}
+
+// algorithm selects the signature algorithm for the key and opts
+func (s *Signer) algorithm(opts stdcrypto.SignerOpts) (SignatureAlg, error) {
+	hash := opts.HashFunc()
+	switch k := s.public.(type) {
+	case *rsa.PublicKey:
+		if pss, ok := opts.(*rsa.PSSOptions); ok {
+			// Key Vault's salt is the size of the hash, which verifiers using PSSSaltLengthAuto accept
+			if pss.SaltLength != rsa.PSSSaltLengthAuto && pss.SaltLength != rsa.PSSSaltLengthEqualsHash && pss.SaltLength != hash.Size() {
+				return "", fmt.Errorf("unsupported RSASSA-PSS salt length %d", pss.SaltLength)
+			}
+			switch hash {
+			case stdcrypto.SHA256:
+				return SignatureAlgPS256, nil
+			case stdcrypto.SHA384:
+				return SignatureAlgPS384, nil
+			case stdcrypto.SHA512:
+				return SignatureAlgPS512, nil
+			}
+		} else {
+			switch hash {
+			case stdcrypto.SHA256:
+				return SignatureAlgRS256, nil
+			case stdcrypto.SHA384:
+				return SignatureAlgRS384, nil
+			case stdcrypto.SHA512:
+				return SignatureAlgRS512, nil
+			}
+		}
+		return "", fmt.Errorf("unsupported hash %s for an RSA key", hash)
+	case *ecdsa.PublicKey:
+		var alg SignatureAlg
+		var expected stdcrypto.Hash
+		switch k.Curve {
+		case elliptic.P256():
+			alg, expected = SignatureAlgES256, stdcrypto.SHA256
+		case elliptic.P384():
+			alg, expected = SignatureAlgES384, stdcrypto.SHA384
+		case elliptic.P521():
+			alg, expected = SignatureAlgES512, stdcrypto.SHA512
+		}
+		if hash != expected {
+			return "", fmt.Errorf("unsupported hash %s for a %s key, which requires %s", hash, k.Curve.Params().Name, expected)
+		}
+		return alg, nil
+	default:
+		return "", fmt.Errorf("unsupported key type %T", s.public)
+	}
+}
+
+// ecdsaSignatureToASN1 converts an ECDSA signature in the JWS format Key Vault returns, r and s concatenated,
+// to the ASN.1 DER format of the standard library
+func ecdsaSignatureToASN1(sig []byte) ([]byte, error) {
+	if len(sig) == 0 || len(sig)%2 != 0 {
+		return nil, fmt.Errorf("invalid ECDSA signature length %d", len(sig))
+	}
+	half := len(sig) / 2
+	return asn1.Marshal(struct {
+		R, S *big.Int
+	}{
+		R: new(big.Int).SetBytes(sig[:half]),
+		S: new(big.Int).SetBytes(sig[half:]),
+	})
+}
@@ -0,0 +1,190 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package crypto
+
+import (
+	"bytes"
+	"context"
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	generated "github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/jwk"
+	"github.com/stretchr/testify/require"
+)
+
+// signingTransport is a vault with one key, which it signs with like Key Vault does
+type signingTransport struct {
+	priv stdcrypto.Signer
+	ops  []string
+}
+
+func (s *signingTransport) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") == "" {
+		return &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+	var body interface{}
+	switch {
+	case req.Method == http.MethodGet:
+		key, err := jwk.FromPublicKey(s.priv.Public())
+		if err != nil {
+			return nil, err
+		}
+		key.Kid = to.Ptr(fakeKvURL + "keys/key/version")
+		for _, op := range s.ops {
+			key.KeyOps = append(key.KeyOps, to.Ptr(op))
+		}
+		body = generated.KeyBundle{Key: key}
+	case strings.HasSuffix(req.URL.Path, "/sign"):
+		var params generated.KeySignParameters
+		if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+			return nil, err
+		}
+		sig, err := s.sign(*params.Algorithm, params.Value)
+		if err != nil {
+			return nil, err
+		}
+		body = map[string]string{"kid": fakeKvURL + "keys/key/version", "value": base64.RawURLEncoding.EncodeToString(sig)}
+	default:
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(b)),
+		Request:    req,
+	}, nil
+}
+
+func (s *signingTransport) sign(alg generated.JSONWebKeySignatureAlgorithm, digest []byte) ([]byte, error) {
+	switch k := s.priv.(type) {
+	case *rsa.PrivateKey:
+		hash, pss, err := signatureHash(SignatureAlg(alg))
+		if err != nil {
+			return nil, err
+		}
+		if pss {
+			return rsa.SignPSS(rand.Reader, k, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+	case *ecdsa.PrivateKey:
+		r, sig, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return nil, err
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return append(r.FillBytes(make([]byte, size)), sig.FillBytes(make([]byte, size))...), nil
+	}
+	return nil, nil
+}
+
+func newSignerForTest(t *testing.T, transport *signingTransport) *Signer {
+	client, err := NewClient(fakeKvURL+"keys/key/version", NewFakeCredential("fake", "fake"), &ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: transport},
+	})
+	require.NoError(t, err)
+	signer, err := client.NewSigner(context.Background(), nil)
+	require.NoError(t, err)
+	return signer
+}
+
+func TestSignerRSA(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer := newSignerForTest(t, &signingTransport{priv: priv, ops: []string{"sign", "verify"}})
+	require.Equal(t, &priv.PublicKey, signer.Public())
+
+	digest := sha256.Sum256([]byte("message"))
+	sig, err := signer.Sign(nil, digest[:], stdcrypto.SHA256)
+	require.NoError(t, err)
+	require.NoError(t, rsa.VerifyPKCS1v15(&priv.PublicKey, stdcrypto.SHA256, digest[:], sig))
+
+	digest512 := sha512.Sum512([]byte("message"))
+	sig, err = signer.Sign(nil, digest512[:], &rsa.PSSOptions{Hash: stdcrypto.SHA512, SaltLength: rsa.PSSSaltLengthEqualsHash})
+	require.NoError(t, err)
+	require.NoError(t, rsa.VerifyPSS(&priv.PublicKey, stdcrypto.SHA512, digest512[:], sig, nil))
+
+	_, err = signer.Sign(nil, digest[:], &rsa.PSSOptions{Hash: stdcrypto.SHA256, SaltLength: 10})
+	require.Error(t, err)
+	_, err = signer.Sign(nil, digest[:], stdcrypto.SHA512)
+	require.Error(t, err)
+
+	// the Signer can be used by packages that accept a crypto.Signer
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "test"},
+	}, signer)
+	require.NoError(t, err)
+	parsed, err := x509.ParseCertificateRequest(csr)
+	require.NoError(t, err)
+	require.NoError(t, parsed.CheckSignature())
+}
+
+func TestSignerEC(t *testing.T) {
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		t.Run(curve.Params().Name, func(t *testing.T) {
+			priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+			signer := newSignerForTest(t, &signingTransport{priv: priv})
+			require.Equal(t, &priv.PublicKey, signer.Public())
+
+			var hash stdcrypto.Hash
+			switch curve {
+			case elliptic.P256():
+				hash = stdcrypto.SHA256
+			case elliptic.P384():
+				hash = stdcrypto.SHA384
+			default:
+				hash = stdcrypto.SHA512
+			}
+			h := hash.New()
+			h.Write([]byte("message"))
+			digest := h.Sum(nil)
+
+			sig, err := signer.Sign(nil, digest, hash)
+			require.NoError(t, err)
+			require.True(t, ecdsa.VerifyASN1(&priv.PublicKey, digest, sig))
+
+			_, err = signer.Sign(nil, digest, stdcrypto.SHA1)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSignerRequiresSignOperation(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	client, err := NewClient(fakeKvURL+"keys/key/version", NewFakeCredential("fake", "fake"), &ClientOptions{
+		ClientOptions: azcore.ClientOptions{Transport: &signingTransport{priv: priv, ops: []string{"verify"}}},
+	})
+	require.NoError(t, err)
+	_, err = client.NewSigner(context.Background(), nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,190 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azkeys
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// ErrorCode is the code of an error returned by Key Vault.
+type ErrorCode string
+
+const (
+	// ErrorCodeBadParameter means a request parameter is invalid, for example a key name with invalid characters.
+	ErrorCodeBadParameter ErrorCode = "BadParameter"
+
+	// ErrorCodeConflict means the request conflicts with the state of the key.
+	ErrorCodeConflict ErrorCode = "Conflict"
+
+	// ErrorCodeForbidden means the caller doesn't have permission for the operation.
+	ErrorCodeForbidden ErrorCode = "Forbidden"
+
+	// ErrorCodeKeyNotFound means the key, key version or deleted key doesn't exist.
+	ErrorCodeKeyNotFound ErrorCode = "KeyNotFound"
+
+	// ErrorCodeNotFound means a resource other than a key doesn't exist, for example a key's rotation policy.
+	ErrorCodeNotFound ErrorCode = "NotFound"
+
+	// ErrorCodeObjectIsBeingDeleted means the key is being deleted and can't be used until the deletion finishes.
+	ErrorCodeObjectIsBeingDeleted ErrorCode = "ObjectIsBeingDeleted"
+
+	// ErrorCodeObjectIsDeletedButRecoverable means a deleted key with the name exists. Recover or purge it
+	// before creating a key with the name.
+	ErrorCodeObjectIsDeletedButRecoverable ErrorCode = "ObjectIsDeletedButRecoverable"
+
+	// ErrorCodeServiceUnavailable means Key Vault is temporarily unavailable.
+	ErrorCodeServiceUnavailable ErrorCode = "ServiceUnavailable"
+
+	// ErrorCodeThrottled means Key Vault throttled the request because the caller sent too many requests.
+	ErrorCodeThrottled ErrorCode = "Throttled"
+
+	// ErrorCodeUnauthorized means the request wasn't authenticated.
+	ErrorCodeUnauthorized ErrorCode = "Unauthorized"
+)
+
+// statusErrorCodes are the codes of errors whose response has no code
+var statusErrorCodes = map[int]ErrorCode{
+	http.StatusBadRequest:         ErrorCodeBadParameter,
+	http.StatusUnauthorized:       ErrorCodeUnauthorized,
+	http.StatusForbidden:          ErrorCodeForbidden,
+	http.StatusNotFound:           ErrorCodeNotFound,
+	http.StatusConflict:           ErrorCodeConflict,
+	http.StatusTooManyRequests:    ErrorCodeThrottled,
+	http.StatusServiceUnavailable: ErrorCodeServiceUnavailable,
+}
+
+// Error is returned by Client methods, pagers and pollers when Key Vault responds with an error. It wraps the
+// *azcore.ResponseError that describes the response, so errors.As finds either.
+type Error struct {
+	// Code is the error's code. When the response has no code, it's derived from the status code, for
+	// example ErrorCodeThrottled for status 429. Compare it with the ErrorCode constants.
+	Code ErrorCode
+
+	// InnerCode is the code of the response's inner error, which refines Code, for example "AccessDenied".
+	// It's empty when the response has no inner error.
+	InnerCode string
+
+	// Message is the error's message.
+	Message string
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// RawResponse is the HTTP response.
+	RawResponse *http.Response
+
+	err *azcore.ResponseError
+}
+
+// Error implements the error interface for type Error.
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the *azcore.ResponseError e wraps.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// IsNotFound returns true when err is, or wraps, an error for a key or other resource that doesn't exist.
+func IsNotFound(err error) bool {
+	e, ok := asError(err)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
+// IsForbidden returns true when err is, or wraps, an error for an operation the caller doesn't have permission for.
+func IsForbidden(err error) bool {
+	e, ok := asError(err)
+	return ok && e.StatusCode == http.StatusForbidden
+}
+
+// IsThrottled returns true when err is, or wraps, an error for a request Key Vault throttled. Retry it after
+// the duration of the response's Retry-After header.
+func IsThrottled(err error) bool {
+	e, ok := asError(err)
+	return ok && (e.StatusCode == http.StatusTooManyRequests || e.Code == ErrorCodeThrottled)
+}
+
+// IsConflict returns true when err is, or wraps, an error for a request that conflicts with the state of a key,
+// for example creating a key whose name belongs to a deleted key.
+func IsConflict(err error) bool {
+	e, ok := asError(err)
+	return ok && e.StatusCode == http.StatusConflict
+}
+
+// asError returns the *Error in err's chain, converting an *azcore.ResponseError, for example one returned by
+// a pipeline shared with another client
+func asError(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return newError(respErr), true
+	}
+	return nil, false
+}
+
+// newResponseError returns an *Error for a response with an error status code
+func newResponseError(resp *http.Response) error {
+	var respErr *azcore.ResponseError
+	if err := runtime.NewResponseError(resp); !errors.As(err, &respErr) {
+		return err
+	}
+	return newError(respErr)
+}
+
+// wrapError returns err as an *Error when it's an *azcore.ResponseError, or else unchanged
+func wrapError(err error) error {
+	var respErr *azcore.ResponseError
+	if err == nil || !errors.As(err, &respErr) {
+		return err
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return newError(respErr)
+}
+
+func newError(respErr *azcore.ResponseError) *Error {
+	e := &Error{
+		Code:        ErrorCode(respErr.ErrorCode),
+		StatusCode:  respErr.StatusCode,
+		RawResponse: respErr.RawResponse,
+		err:         respErr,
+	}
+	if respErr.RawResponse != nil {
+		if body, err := runtime.Payload(respErr.RawResponse); err == nil {
+			var v struct {
+				Error struct {
+					Code       string `json:"code"`
+					Message    string `json:"message"`
+					InnerError *struct {
+						Code string `json:"code"`
+					} `json:"innererror"`
+				} `json:"error"`
+			}
+			if json.Unmarshal(body, &v) == nil {
+				e.Message = v.Error.Message
+				if v.Error.InnerError != nil {
+					e.InnerCode = v.Error.InnerError.Code
+				}
+			}
+		}
+	}
+	if e.Code == "" {
+		e.Code = statusErrorCodes[e.StatusCode]
+	}
+	return e
+}
@@ -0,0 +1,108 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azkeys
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ImportKeyFromPEMOptions contains optional parameters for ImportKeyFromPEM.
+type ImportKeyFromPEMOptions struct {
+	// HardwareProtected determines whether Key Vault protects the imported key with an HSM.
+	HardwareProtected *bool
+
+	// KeyOps are the operations the imported key permits. When nil, Key Vault permits all the operations
+	// of the key's type.
+	KeyOps []*Operation
+
+	// Properties is the properties of the key.
+	Properties *Properties
+}
+
+// ImportKeyFromPEM imports an RSA or EC private key into the vault. keyData is a PEM file with a PKCS #1, PKCS #8 or
+// SEC 1 private key, such as those written by OpenSSL, or the key DER encoded. Other PEM blocks, for example the
+// key's certificate, are ignored. Encrypted keys aren't supported; decrypt them first. EC keys must be on curve P-256,
+// P-384 or P-521. If the named key already exists, this creates a new version of the key. Pass nil for options to
+// accept default values.
+func (c *Client) ImportKeyFromPEM(ctx context.Context, name string, keyData []byte, options *ImportKeyFromPEMOptions) (ImportKeyResponse, error) {
+	if options == nil {
+		options = &ImportKeyFromPEMOptions{}
+	}
+	priv, err := parsePrivateKey(keyData)
+	if err != nil {
+		return ImportKeyResponse{}, err
+	}
+	key, err := NewJSONWebKeyFromPrivateKey(priv)
+	if err != nil {
+		return ImportKeyResponse{}, err
+	}
+	key.KeyOps = options.KeyOps
+	return c.ImportKey(ctx, name, *key, &ImportKeyOptions{
+		HardwareProtected: options.HardwareProtected,
+		Properties:        options.Properties,
+	})
+}
+
+// parsePrivateKey returns the private key in PEM or DER encoded data
+func parsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	var priv crypto.PrivateKey
+	foundPEM := false
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		foundPEM = true
+		if _, ok := block.Headers["Proc-Type"]; ok || block.Type == "ENCRYPTED PRIVATE KEY" {
+			return nil, errors.New("the private key is encrypted; decrypt it before importing it")
+		}
+		var k crypto.PrivateKey
+		var err error
+		switch block.Type {
+		case "RSA PRIVATE KEY":
+			k, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			k, err = x509.ParseECPrivateKey(block.Bytes)
+		case "PRIVATE KEY":
+			k, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the %s PEM block: %w", block.Type, err)
+		}
+		if priv != nil {
+			return nil, errors.New("the PEM data has more than one private key")
+		}
+		priv = k
+	}
+	if priv != nil {
+		return priv, nil
+	}
+	if foundPEM {
+		return nil, errors.New("the PEM data has no private key")
+	}
+
+	// not PEM, so try the DER encodings
+	if k, err := x509.ParsePKCS8PrivateKey(data); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParsePKCS1PrivateKey(data); err == nil {
+		return k, nil
+	}
+	if k, err := x509.ParseECPrivateKey(data); err == nil {
+		return k, nil
+	}
+	return nil, errors.New("the data isn't a PEM or DER encoded PKCS #1, PKCS #8 or SEC 1 private key")
+}
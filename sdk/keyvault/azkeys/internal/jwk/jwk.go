@@ -0,0 +1,131 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+// Package jwk converts between JSON web keys and the standard library's key types.
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	generated "github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
+)
+
+// ErrUnsupported indicates the standard library has no type for a key, for example because it's symmetric
+// or its curve is P-256K.
+var ErrUnsupported = errors.New("the key type isn't supported by the standard library")
+
+// PublicKey converts the public portion of an RSA or EC JSON web key to a *rsa.PublicKey or *ecdsa.PublicKey
+func PublicKey(key *generated.JSONWebKey) (crypto.PublicKey, error) {
+	if key.Kty == nil {
+		return nil, ErrUnsupported
+	}
+	switch *key.Kty {
+	case generated.JSONWebKeyTypeRSA, generated.JSONWebKeyTypeRSAHSM:
+		if len(key.N) == 0 || len(key.E) == 0 {
+			return nil, errors.New("RSA key has no modulus or exponent")
+		}
+		e := new(big.Int).SetBytes(key.E)
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return nil, errors.New("RSA public exponent is too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(key.N), E: int(e.Int64())}, nil
+	case generated.JSONWebKeyTypeEC, generated.JSONWebKeyTypeECHSM:
+		if key.Crv == nil {
+			return nil, errors.New("EC key has no curve")
+		}
+		var curve elliptic.Curve
+		switch *key.Crv {
+		case generated.JSONWebKeyCurveNameP256:
+			curve = elliptic.P256()
+		case generated.JSONWebKeyCurveNameP384:
+			curve = elliptic.P384()
+		case generated.JSONWebKeyCurveNameP521:
+			curve = elliptic.P521()
+		default:
+			// the standard library doesn't implement P-256K
+			return nil, ErrUnsupported
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(key.X), Y: new(big.Int).SetBytes(key.Y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("EC key isn't a point on curve %s", *key.Crv)
+		}
+		return pub, nil
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// FromPublicKey converts a *rsa.PublicKey or *ecdsa.PublicKey to a JSON web key
+func FromPublicKey(pub crypto.PublicKey) (*generated.JSONWebKey, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return &generated.JSONWebKey{
+			E:   big.NewInt(int64(k.E)).Bytes(),
+			Kty: to.Ptr(generated.JSONWebKeyTypeRSA),
+			N:   k.N.Bytes(),
+		}, nil
+	case *ecdsa.PublicKey:
+		var crv generated.JSONWebKeyCurveName
+		switch k.Curve {
+		case elliptic.P256():
+			crv = generated.JSONWebKeyCurveNameP256
+		case elliptic.P384():
+			crv = generated.JSONWebKeyCurveNameP384
+		case elliptic.P521():
+			crv = generated.JSONWebKeyCurveNameP521
+		default:
+			return nil, fmt.Errorf("%w: curve %s", ErrUnsupported, k.Curve.Params().Name)
+		}
+		// RFC 7518 section 6.2.1.2 requires coordinates to be the full size of the curve's field
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return &generated.JSONWebKey{
+			Crv: to.Ptr(crv),
+			Kty: to.Ptr(generated.JSONWebKeyTypeEC),
+			X:   k.X.FillBytes(make([]byte, size)),
+			Y:   k.Y.FillBytes(make([]byte, size)),
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupported, pub)
+	}
+}
+
+// FromPrivateKey converts a *rsa.PrivateKey or *ecdsa.PrivateKey to a JSON web key including the private key material
+func FromPrivateKey(priv crypto.PrivateKey) (*generated.JSONWebKey, error) {
+	switch k := priv.(type) {
+	case *rsa.PrivateKey:
+		if len(k.Primes) != 2 {
+			return nil, fmt.Errorf("%w: RSA key with %d primes", ErrUnsupported, len(k.Primes))
+		}
+		key, err := FromPublicKey(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		k.Precompute()
+		key.D = k.D.Bytes()
+		key.P = k.Primes[0].Bytes()
+		key.Q = k.Primes[1].Bytes()
+		key.DP = k.Precomputed.Dp.Bytes()
+		key.DQ = k.Precomputed.Dq.Bytes()
+		key.QI = k.Precomputed.Qinv.Bytes()
+		return key, nil
+	case *ecdsa.PrivateKey:
+		key, err := FromPublicKey(&k.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+		key.D = k.D.FillBytes(make([]byte, (k.Curve.Params().BitSize+7)/8))
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupported, priv)
+	}
+}
@@ -0,0 +1,87 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultThrottleDelay is how long to pause after a throttled request whose response has no Retry-After header
+const DefaultThrottleDelay = 5 * time.Second
+
+// Throttle spaces requests at a steady rate and pauses them all after a throttled request
+type Throttle struct {
+	mu       sync.Mutex
+	interval time.Duration
+
+	// next is the earliest time of the next request
+	next time.Time
+}
+
+// NewThrottle creates a Throttle allowing requestsPerSecond requests per second. When requestsPerSecond
+// isn't positive, the rate isn't limited and the Throttle only pauses requests.
+func NewThrottle(requestsPerSecond float64) *Throttle {
+	t := &Throttle{}
+	if requestsPerSecond > 0 {
+		t.interval = time.Duration(float64(time.Second) / requestsPerSecond)
+	}
+	return t
+}
+
+// Wait blocks until a request may be sent
+func (t *Throttle) Wait(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		now := time.Now()
+		if !t.next.After(now) {
+			t.next = now.Add(t.interval)
+			t.mu.Unlock()
+			return nil
+		}
+		delay := t.next.Sub(now)
+		t.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Pause delays all requests by at least d
+func (t *Throttle) Pause(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if until := time.Now().Add(d); until.After(t.next) {
+		t.next = until
+	}
+}
+
+// RetryAfter returns the delay specified by the Retry-After header of resp, or DefaultThrottleDelay
+func RetryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return DefaultThrottleDelay
+	}
+	v := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return DefaultThrottleDelay
+}
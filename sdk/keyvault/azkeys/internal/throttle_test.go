@@ -0,0 +1,35 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle(t *testing.T) {
+	throttle := NewThrottle(20)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, throttle.Wait(context.Background()))
+	}
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	throttle.Pause(time.Hour)
+	canceled, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, throttle.Wait(canceled), context.DeadlineExceeded)
+
+	require.Equal(t, 3*time.Second, RetryAfter(&http.Response{Header: http.Header{"Retry-After": []string{"3"}}}))
+	d := RetryAfter(&http.Response{Header: http.Header{"Retry-After": []string{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)}}})
+	require.True(t, d > 58*time.Second && d <= time.Minute, d)
+	require.Equal(t, DefaultThrottleDelay, RetryAfter(&http.Response{Header: http.Header{}}))
+}
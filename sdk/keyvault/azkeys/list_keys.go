@@ -0,0 +1,126 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azkeys
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal"
+)
+
+// maxThrottledAttempts is how many times ListKeys tries to get a key whose requests are throttled
+const maxThrottledAttempts = 5
+
+// ListKeysOptions contains optional parameters for ListKeys.
+type ListKeysOptions struct {
+	// Filter selects the keys to get. When nil, all keys are gotten.
+	Filter func(*KeyItem) bool
+
+	// MaxConcurrency is the maximum number of keys gotten at once. The default value is 4.
+	MaxConcurrency int
+
+	// MaxRequestsPerSecond limits the rate of requests for keys. The default value, 0, doesn't limit the rate.
+	MaxRequestsPerSecond float64
+}
+
+// ListKeysResponse is returned by ListKeys.
+type ListKeysResponse struct {
+	// Keys are the latest versions of the vault's keys, in the order the vault lists them. The Key of a disabled key
+	// has the key's properties but no key material, because Key Vault doesn't return the material of disabled keys.
+	Keys []*Key
+}
+
+// ListKeys lists the keys in the vault and gets the latest version of each, for inventory and audit tools that need
+// the keys' material as well as their properties. Keys are gotten with bounded concurrency. When Key Vault throttles a
+// request beyond the Client's retry policy, ListKeys pauses all its requests for the duration of the response's
+// Retry-After header before trying again. ListKeys returns the first error that prevents getting a key. This
+// operation requires the keys/list and keys/get permissions. Pass nil for options to accept default values.
+func (c *Client) ListKeys(ctx context.Context, options *ListKeysOptions) (ListKeysResponse, error) {
+	if options == nil {
+		options = &ListKeysOptions{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 4
+	}
+
+	var items []*KeyItem
+	pager := c.NewListPropertiesOfKeysPager(&ListPropertiesOfKeysOptions{IncludeManaged: true})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return ListKeysResponse{}, err
+		}
+		for _, item := range page.Keys {
+			if item == nil || item.Name == nil {
+				continue
+			}
+			if options.Filter != nil && !options.Filter(item) {
+				continue
+			}
+			items = append(items, item)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	throttle := internal.NewThrottle(options.MaxRequestsPerSecond)
+	keys := make([]*Key, len(items))
+	var once sync.Once
+	var firstErr error
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		if item.Properties != nil && item.Properties.Enabled != nil && !*item.Properties.Enabled {
+			keys[i] = &Key{Properties: item.Properties, ID: item.ID, Name: item.Name}
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		// each goroutine writes only to its key's element
+		go func(i int, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			key, err := c.getKeyThrottled(ctx, name, throttle)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			keys[i] = key
+		}(i, *item.Name)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return ListKeysResponse{}, firstErr
+	}
+	return ListKeysResponse{Keys: keys}, nil
+}
+
+// getKeyThrottled gets the latest version of a key, pausing all requests sharing throttle when Key Vault throttles one
+func (c *Client) getKeyThrottled(ctx context.Context, name string, throttle *internal.Throttle) (*Key, error) {
+	for attempt := 1; ; attempt++ {
+		if err := throttle.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := c.GetKey(ctx, name, nil)
+		if err == nil {
+			return &resp.Key, nil
+		}
+		var kvErr *Error
+		if attempt == maxThrottledAttempts || !IsThrottled(err) || !errors.As(err, &kvErr) {
+			return nil, err
+		}
+		throttle.Pause(internal.RetryAfter(kvErr.RawResponse))
+	}
+}
@@ -0,0 +1,76 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azkeys
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrNotSupported is matched, using errors.Is, by the *NotSupportedError a Client returns for an operation
+// the kind of service at its URL doesn't support.
+var ErrNotSupported = errors.New("operation not supported")
+
+// NotSupportedError is returned, without sending a request, by operations the service at a Client's URL
+// doesn't support. For example, only Managed HSMs generate random bytes and store symmetric (oct) keys.
+type NotSupportedError struct {
+	// Operation is the name of the unsupported operation, such as "GetRandomBytes" or "CreateOctKey".
+	Operation string
+
+	// ManagedHSM is true when the Client's URL is a Managed HSM's and false when it's a vault's.
+	ManagedHSM bool
+}
+
+// Error implements the error interface for type NotSupportedError.
+func (e *NotSupportedError) Error() string {
+	service := "a Key Vault vault"
+	if e.ManagedHSM {
+		service = "a Managed HSM"
+	}
+	return fmt.Sprintf("%s isn't supported by %s", e.Operation, service)
+}
+
+// Is returns true for ErrNotSupported.
+func (e *NotSupportedError) Is(target error) bool {
+	return target == ErrNotSupported
+}
+
+// isManagedHSMURL returns whether a URL is a Managed HSM's. Managed HSMs, unlike vaults, have a
+// "managedhsm" label in their host name in every cloud, for example "contoso.managedhsm.azure.net".
+func isManagedHSMURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	// the first label is the vault's name, which may be anything
+	labels := strings.Split(strings.ToLower(parsed.Hostname()), ".")
+	for _, label := range labels[1:] {
+		if label == "managedhsm" {
+			return true
+		}
+	}
+	return false
+}
+
+// requireManagedHSM returns a *NotSupportedError for operations only Managed HSMs support
+func (c *Client) requireManagedHSM(operation string) error {
+	if c.managedHSM {
+		return nil
+	}
+	return &NotSupportedError{Operation: operation}
+}
+
+// checkKeyType returns a *NotSupportedError for key types the service doesn't support.
+// Only Managed HSMs store symmetric keys.
+func (c *Client) checkKeyType(operation string, keyType *KeyType) error {
+	if keyType != nil && (*keyType == KeyTypeOct || *keyType == KeyTypeOctHSM) {
+		return c.requireManagedHSM(fmt.Sprintf("%s with key type %s", operation, *keyType))
+	}
+	return nil
+}
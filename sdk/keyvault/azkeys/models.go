@@ -7,10 +7,12 @@
 package azkeys
 
 import (
+	"crypto"
+	"encoding/json"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/generated"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/internal/jwk"
 	shared "github.com/Azure/azure-sdk-for-go/sdk/keyvault/internal"
 )
 
@@ -97,7 +99,7 @@ func keyPropertiesFromGenerated(i *generated.KeyAttributes, id, name, version *s
 		Name:            name,
 		NotBefore:       i.NotBefore,
 		RecoverableDays: i.RecoverableDays,
-		RecoveryLevel:   to.Ptr(string(*i.RecoveryLevel)),
+		RecoveryLevel:   (*string)(i.RecoveryLevel),
 		ReleasePolicy:   keyReleasePolicyFromGenerated(releasePolicy),
 		Tags:            tags,
 		UpdatedOn:       i.Updated,
@@ -121,6 +123,50 @@ type Key struct {
 	Name *string
 }
 
+// MarshalJSON implements the json.Marshaler interface for the Key type. The JSON has the format of a key bundle
+// in version 7.3 of the Key Vault REST API: times are Unix timestamps in seconds and the JSONWebKey's byte fields
+// are URL-safe base64. UnmarshalJSON reads this format.
+func (k Key) MarshalJSON() ([]byte, error) {
+	g := generated.KeyBundle{
+		Attributes: k.Properties.toGenerated(),
+	}
+	if k.JSONWebKey != nil {
+		g.Key = k.JSONWebKey.toGenerated()
+	}
+	if k.ID != nil {
+		if g.Key == nil {
+			g.Key = &generated.JSONWebKey{}
+		}
+		g.Key.Kid = k.ID
+	}
+	if k.Properties != nil {
+		g.Managed = k.Properties.Managed
+		g.ReleasePolicy = k.Properties.ReleasePolicy.toGenerated()
+		g.Tags = k.Properties.Tags
+	}
+	return json.Marshal(g)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for the Key type.
+func (k *Key) UnmarshalJSON(data []byte) error {
+	var g generated.KeyBundle
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	var id *string
+	if g.Key != nil {
+		id = g.Key.Kid
+	}
+	vaultURL, name, version := shared.ParseID(id)
+	*k = Key{
+		Properties: keyPropertiesFromGenerated(g.Attributes, id, name, version, g.Managed, vaultURL, g.Tags, g.ReleasePolicy),
+		JSONWebKey: jsonWebKeyFromGenerated(g.Key),
+		ID:         id,
+		Name:       name,
+	}
+	return nil
+}
+
 // JSONWebKey - As of http://tools.ietf.org/html/draft-ietf-jose-json-web-key-18
 type JSONWebKey struct {
 	// Elliptic curve name. For valid values, see PossibleCurveNameValues.
@@ -170,6 +216,22 @@ type JSONWebKey struct {
 	Y []byte
 }
 
+// MarshalJSON implements the json.Marshaler interface for the JSONWebKey type. The JSON has the format of
+// RFC 7517, in which byte fields are URL-safe base64. UnmarshalJSON reads this format.
+func (j JSONWebKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.toGenerated())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for the JSONWebKey type.
+func (j *JSONWebKey) UnmarshalJSON(data []byte) error {
+	var g generated.JSONWebKey
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	*j = *jsonWebKeyFromGenerated(&g)
+	return nil
+}
+
 // converts generated.JSONWebKey to publicly exposed version
 func jsonWebKeyFromGenerated(i *generated.JSONWebKey) *JSONWebKey {
 	if i == nil {
@@ -227,6 +289,38 @@ func (j JSONWebKey) toGenerated() *generated.JSONWebKey {
 	}
 }
 
+// Public returns the public key of an RSA or EC key: a *rsa.PublicKey or an *ecdsa.PublicKey, which can be
+// used with packages such as crypto/x509. It returns an error for symmetric keys and for keys on curve P-256K,
+// which the standard library doesn't implement.
+func (j JSONWebKey) Public() (crypto.PublicKey, error) {
+	return jwk.PublicKey(j.toGenerated())
+}
+
+// NewJSONWebKeyFromPublicKey converts a *rsa.PublicKey, or an *ecdsa.PublicKey on curve P-256, P-384 or P-521,
+// to a JSONWebKey. The JSONWebKey has no ID or operations.
+func NewJSONWebKeyFromPublicKey(pub crypto.PublicKey) (*JSONWebKey, error) {
+	g, err := jwk.FromPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	j := jsonWebKeyFromGenerated(g)
+	j.KeyOps = nil
+	return j, nil
+}
+
+// NewJSONWebKeyFromPrivateKey converts a *rsa.PrivateKey, or an *ecdsa.PrivateKey on curve P-256, P-384 or P-521,
+// to a JSONWebKey including the private key material, for example to import it with ImportKey. The JSONWebKey
+// has no ID or operations.
+func NewJSONWebKeyFromPrivateKey(priv crypto.PrivateKey) (*JSONWebKey, error) {
+	g, err := jwk.FromPrivateKey(priv)
+	if err != nil {
+		return nil, err
+	}
+	j := jsonWebKeyFromGenerated(g)
+	j.KeyOps = nil
+	return j, nil
+}
+
 // KeyItem - The key item containing key metadata.
 type KeyItem struct {
 	// The key management properties.
@@ -365,6 +459,43 @@ type RotationPolicy struct {
 	ID *string
 }
 
+// MarshalJSON implements the json.Marshaler interface for the RotationPolicy type. The JSON has the format
+// of version 7.3 of the Key Vault REST API, in which times are Unix timestamps in seconds. UnmarshalJSON
+// reads this format.
+func (u RotationPolicy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.toGenerated())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface for the RotationPolicy type.
+func (u *RotationPolicy) UnmarshalJSON(data []byte) error {
+	var g generated.KeyRotationPolicy
+	if err := json.Unmarshal(data, &g); err != nil {
+		return err
+	}
+	*u = rotationPolicyFromGenerated(g)
+	return nil
+}
+
+func rotationPolicyFromGenerated(i generated.KeyRotationPolicy) RotationPolicy {
+	var acts []*LifetimeActions
+	for _, a := range i.LifetimeActions {
+		acts = append(acts, lifetimeActionsFromGenerated(a))
+	}
+	var attribs *RotationPolicyAttributes
+	if i.Attributes != nil {
+		attribs = &RotationPolicyAttributes{
+			ExpiresIn: i.Attributes.ExpiryTime,
+			CreatedOn: i.Attributes.Created,
+			UpdatedOn: i.Attributes.Updated,
+		}
+	}
+	return RotationPolicy{
+		ID:              i.ID,
+		LifetimeActions: acts,
+		Attributes:      attribs,
+	}
+}
+
 func (u RotationPolicy) toGenerated() generated.KeyRotationPolicy {
 	var attribs *generated.KeyRotationPolicyAttributes
 	if u.Attributes != nil {
@@ -417,30 +548,38 @@ func (l *LifetimeActions) toGenerated() *generated.LifetimeActions {
 	if l == nil {
 		return nil
 	}
-	return &generated.LifetimeActions{
-		Action: &generated.LifetimeActionsType{
+	g := &generated.LifetimeActions{}
+	if l.Action != nil {
+		g.Action = &generated.LifetimeActionsType{
 			Type: (*generated.ActionType)(l.Action.Type),
-		},
-		Trigger: &generated.LifetimeActionsTrigger{
+		}
+	}
+	if l.Trigger != nil {
+		g.Trigger = &generated.LifetimeActionsTrigger{
 			TimeAfterCreate:  l.Trigger.TimeAfterCreate,
 			TimeBeforeExpiry: l.Trigger.TimeBeforeExpiry,
-		},
+		}
 	}
+	return g
 }
 
 func lifetimeActionsFromGenerated(i *generated.LifetimeActions) *LifetimeActions {
 	if i == nil {
 		return nil
 	}
-	return &LifetimeActions{
-		Trigger: &LifetimeActionsTrigger{
+	l := &LifetimeActions{}
+	if i.Trigger != nil {
+		l.Trigger = &LifetimeActionsTrigger{
 			TimeAfterCreate:  i.Trigger.TimeAfterCreate,
 			TimeBeforeExpiry: i.Trigger.TimeBeforeExpiry,
-		},
-		Action: &LifetimeActionsType{
+		}
+	}
+	if i.Action != nil {
+		l.Action = &LifetimeActionsType{
 			Type: (*RotationAction)(i.Action.Type),
-		},
+		}
 	}
+	return l
 }
 
 // LifetimeActionsType - The action that will be executed.
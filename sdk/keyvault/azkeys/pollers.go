@@ -30,7 +30,7 @@ func (b *beginDeleteKeyPoller) Poll(ctx context.Context) (*http.Response, error)
 		return nil, err
 	}
 	if !runtime.HasStatusCode(resp, http.StatusOK, http.StatusNotFound) {
-		return nil, runtime.NewResponseError(resp)
+		return nil, newResponseError(resp)
 	}
 	b.resp = resp
 	return b.resp, nil
@@ -67,7 +67,7 @@ func (b *beginRecoverDeletedKeyPoller) Poll(ctx context.Context) (*http.Response
 		return nil, err
 	}
 	if !runtime.HasStatusCode(resp, http.StatusOK, http.StatusNotFound) {
-		return nil, runtime.NewResponseError(resp)
+		return nil, newResponseError(resp)
 	}
 	b.resp = resp
 	return b.resp, nil
@@ -0,0 +1,76 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azkeys
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// resumeTokenVersion is the version of the resume token envelope written by this module. Later versions of
+// the module read tokens of every version up to theirs, so a token can be resumed by a process running a
+// newer minor version. A change that older modules couldn't read requires a new version.
+const resumeTokenVersion = 1
+
+const (
+	resumeTokenOperationDelete  = "delete"
+	resumeTokenOperationRecover = "recover"
+)
+
+// resumeToken is the versioned envelope of resume tokens. Its JSON field names must not change.
+type resumeToken struct {
+	Version   int    `json:"version"`
+	Operation string `json:"operation"`
+	Key       string `json:"key"`
+}
+
+func (r resumeToken) String() string {
+	// marshaling a struct of strings can't fail
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// DeleteKeyResumeToken returns a token for resuming polling of the deletion of a key, in this or another process,
+// by passing it to Client.BeginDeleteKey in BeginDeleteKeyOptions.ResumeToken. The deletion must have been started.
+// The token is versioned, and later minor versions of this module accept it.
+func DeleteKeyResumeToken(name string) string {
+	return resumeToken{Version: resumeTokenVersion, Operation: resumeTokenOperationDelete, Key: name}.String()
+}
+
+// RecoverDeletedKeyResumeToken returns a token for resuming polling of the recovery of a deleted key, in this or
+// another process, by passing it to Client.BeginRecoverDeletedKey in BeginRecoverDeletedKeyOptions.ResumeToken.
+// The recovery must have been started. The token is versioned, and later minor versions of this module accept it.
+func RecoverDeletedKeyResumeToken(name string) string {
+	return resumeToken{Version: resumeTokenVersion, Operation: resumeTokenOperationRecover, Key: name}.String()
+}
+
+// parseResumeToken parses a versioned token for operation on the named key. It returns nil and no error when
+// token isn't versioned, that is when it's the token of a Poller.
+func parseResumeToken(token, operation, name string) (*resumeToken, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(token), &fields); err != nil {
+		return nil, fmt.Errorf("invalid resume token: %w", err)
+	}
+	if _, ok := fields["version"]; !ok {
+		return nil, nil
+	}
+	var rt resumeToken
+	if err := json.Unmarshal([]byte(token), &rt); err != nil {
+		return nil, fmt.Errorf("invalid resume token: %w", err)
+	}
+	switch {
+	case rt.Version < 1:
+		return nil, fmt.Errorf("invalid resume token version %d", rt.Version)
+	case rt.Version > resumeTokenVersion:
+		return nil, fmt.Errorf("the resume token has version %d, which requires a newer version of this module", rt.Version)
+	case rt.Operation != operation:
+		return nil, fmt.Errorf("the resume token is for a %s operation, not %s", rt.Operation, operation)
+	case rt.Key != name:
+		return nil, fmt.Errorf("the resume token is for key %q, not %q", rt.Key, name)
+	}
+	return &rt, nil
+}
@@ -0,0 +1,260 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azsecrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+)
+
+// defaultMaxStaleness is how long a FileCache serves a secret when no MaxStaleness is specified.
+const defaultMaxStaleness = 24 * time.Hour
+
+// fileCacheVersion is the version of the cache file's format
+const fileCacheVersion = 1
+
+// KeyWrapper wraps and unwraps the key that encrypts a FileCache. It's typically implemented with a Key Vault key,
+// for example by a type whose methods call the WrapKey and UnwrapKey methods of an azkeys/crypto.Client with
+// the RSA-OAEP-256 algorithm.
+type KeyWrapper interface {
+	// WrapKey encrypts key.
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+
+	// UnwrapKey decrypts a key encrypted by WrapKey.
+	UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// FileCacheOptions contains optional parameters for NewFileCache.
+type FileCacheOptions struct {
+	// MaxStaleness is how long after a secret was fetched from the vault the cache may serve it.
+	// Defaults to 24 hours.
+	MaxStaleness time.Duration
+}
+
+// FileCache mirrors the secrets a Client gets into an encrypted file, from which the Client serves them while the
+// vault is unavailable. Set ClientOptions.Cache to use one. The secrets are encrypted with AES-256-GCM with a random
+// key, which is stored in the file wrapped by a KeyWrapper. The cache unwraps the key when it first reads the file and
+// keeps it in memory, so a cache that's read while the key's vault is available serves secrets during later outages.
+// A process that starts during an outage of the key's vault can't read the file. A FileCache is safe for concurrent
+// use but its file mustn't be shared by several processes.
+type FileCache struct {
+	path         string
+	wrapper      KeyWrapper
+	maxStaleness time.Duration
+
+	mu   sync.Mutex
+	aead cipher.AEAD
+	file cacheFile
+
+	// now is a seam for tests
+	now func() time.Time
+}
+
+// cacheFile is the JSON content of a FileCache's file
+type cacheFile struct {
+	Version    int                   `json:"version"`
+	WrappedKey []byte                `json:"wrappedKey"`
+	Entries    map[string]cacheEntry `json:"entries"`
+}
+
+type cacheEntry struct {
+	CachedOn   time.Time `json:"cachedOn"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+// NewFileCache creates a FileCache whose file is at path. The file is created when the first secret is cached.
+// Pass nil for options to accept default values.
+func NewFileCache(path string, wrapper KeyWrapper, options *FileCacheOptions) *FileCache {
+	if options == nil {
+		options = &FileCacheOptions{}
+	}
+	maxStaleness := options.MaxStaleness
+	if maxStaleness <= 0 {
+		maxStaleness = defaultMaxStaleness
+	}
+	return &FileCache{
+		path:         path,
+		wrapper:      wrapper,
+		maxStaleness: maxStaleness,
+		now:          time.Now,
+	}
+}
+
+// load reads the file and unwraps its key, or creates a key when there's no file. A file that can't be parsed is
+// replaced, but an error unwrapping the key, which may be transient, is returned so the file isn't overwritten.
+// The caller must hold mu.
+func (f *FileCache) load(ctx context.Context) error {
+	if f.aead != nil {
+		return nil
+	}
+	b, err := os.ReadFile(f.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	var file cacheFile
+	if err == nil && json.Unmarshal(b, &file) == nil && file.Version == fileCacheVersion && len(file.WrappedKey) > 0 {
+		key, err := f.wrapper.UnwrapKey(ctx, file.WrappedKey)
+		if err != nil {
+			return fmt.Errorf("failed to unwrap the key of the secret cache: %w", err)
+		}
+		aead, err := newCacheAEAD(key)
+		if err != nil {
+			return err
+		}
+		if file.Entries == nil {
+			file.Entries = map[string]cacheEntry{}
+		}
+		f.aead, f.file = aead, file
+		return nil
+	}
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return err
+	}
+	wrapped, err := f.wrapper.WrapKey(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to wrap the key of the secret cache: %w", err)
+	}
+	aead, err := newCacheAEAD(key)
+	if err != nil {
+		return err
+	}
+	f.aead = aead
+	f.file = cacheFile{Version: fileCacheVersion, WrappedKey: wrapped, Entries: map[string]cacheEntry{}}
+	return nil
+}
+
+func newCacheAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func cacheEntryName(name, version string) string {
+	return name + "/" + version
+}
+
+// store encrypts secret and saves it in the file
+func (f *FileCache) store(ctx context.Context, name, version string, secret Secret) error {
+	plaintext, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.load(ctx); err != nil {
+		return err
+	}
+	entryName := cacheEntryName(name, version)
+	nonce := make([]byte, f.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	f.file.Entries[entryName] = cacheEntry{
+		CachedOn: f.now().UTC(),
+		Nonce:    nonce,
+		// the entry's name is authenticated so entries can't be swapped
+		Ciphertext: f.aead.Seal(nil, nonce, plaintext, []byte(entryName)),
+	}
+	return f.save()
+}
+
+// remove deletes the entry for a secret, for example because the vault reports the secret doesn't exist
+func (f *FileCache) remove(ctx context.Context, name, version string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.load(ctx); err != nil {
+		return err
+	}
+	entryName := cacheEntryName(name, version)
+	if _, ok := f.file.Entries[entryName]; !ok {
+		return nil
+	}
+	delete(f.file.Entries, entryName)
+	return f.save()
+}
+
+// lookup returns a cached secret no older than the maximum staleness, and when it was cached
+func (f *FileCache) lookup(ctx context.Context, name, version string) (Secret, time.Time, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.load(ctx); err != nil {
+		return Secret{}, time.Time{}, false
+	}
+	entryName := cacheEntryName(name, version)
+	entry, ok := f.file.Entries[entryName]
+	if !ok || f.now().Sub(entry.CachedOn) > f.maxStaleness {
+		return Secret{}, time.Time{}, false
+	}
+	plaintext, err := f.aead.Open(nil, entry.Nonce, entry.Ciphertext, []byte(entryName))
+	if err != nil {
+		return Secret{}, time.Time{}, false
+	}
+	var secret Secret
+	if err := json.Unmarshal(plaintext, &secret); err != nil {
+		return Secret{}, time.Time{}, false
+	}
+	return secret, entry.CachedOn, true
+}
+
+// save removes stale entries and replaces the file atomically. The caller must hold mu.
+func (f *FileCache) save() error {
+	for name, entry := range f.file.Entries {
+		if f.now().Sub(entry.CachedOn) > f.maxStaleness {
+			delete(f.file.Entries, name)
+		}
+	}
+	b, err := json.Marshal(f.file)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
+
+// isVaultUnavailable returns whether err indicates the vault couldn't serve a request, rather than that the
+// request was invalid or unauthorized
+func isVaultUnavailable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusTooManyRequests || respErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
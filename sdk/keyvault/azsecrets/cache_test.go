@@ -0,0 +1,133 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azsecrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/stretchr/testify/require"
+)
+
+// xorWrapper is a KeyWrapper for tests
+type xorWrapper struct {
+	unavailable bool
+}
+
+func (x *xorWrapper) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	return x.UnwrapKey(ctx, key)
+}
+
+func (x *xorWrapper) UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	if x.unavailable {
+		return nil, errors.New("the key's vault is unavailable")
+	}
+	b := make([]byte, len(wrappedKey))
+	for i := range wrappedKey {
+		b[i] = wrappedKey[i] ^ 0x5a
+	}
+	return b, nil
+}
+
+func TestFileCache(t *testing.T) {
+	status := http.StatusOK
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		name := strings.TrimPrefix(strings.TrimSuffix(req.URL.Path, "/"), "/secrets/")
+		body := fmt.Sprintf(`{"id":"%s/secrets/%s/1","value":"value-%s","attributes":{"enabled":true}}`, fakeVaultURL, name, name)
+		if status != http.StatusOK {
+			body = `{"error":{"code":"Error","message":"error"}}`
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+	}}
+	path := filepath.Join(t.TempDir(), "secrets.cache")
+	wrapper := &xorWrapper{}
+	newClient := func(cache *FileCache) *Client {
+		client, err := NewClient(fakeVaultURL, NewFakeCredential(), &ClientOptions{
+			ClientOptions: azcore.ClientOptions{Transport: transport, Retry: policy.RetryOptions{MaxRetries: -1}},
+			Cache:         cache,
+		})
+		require.NoError(t, err)
+		return client
+	}
+	cache := NewFileCache(path, wrapper, nil)
+	client := newClient(cache)
+
+	// secrets are written through to the file, encrypted
+	resp, err := client.GetSecret(context.Background(), "a", nil)
+	require.NoError(t, err)
+	require.Nil(t, resp.CachedOn)
+	_, err = client.GetSecret(context.Background(), "b", nil)
+	require.NoError(t, err)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(b, []byte("value-a")))
+
+	// while the vault is unavailable, cached secrets are served
+	status = http.StatusServiceUnavailable
+	resp, err = client.GetSecret(context.Background(), "a", nil)
+	require.NoError(t, err)
+	require.Equal(t, "value-a", *resp.Value)
+	require.Equal(t, fakeVaultURL+"/secrets/a/1", *resp.ID)
+	require.NotNil(t, resp.CachedOn)
+	_, err = client.GetSecret(context.Background(), "c", nil)
+	var respErr *azcore.ResponseError
+	require.ErrorAs(t, err, &respErr)
+	_, err = client.GetSecret(context.Background(), "a", &GetSecretOptions{Version: "1"})
+	require.Error(t, err, "versions are cached separately")
+
+	// a restarted process reads the file
+	resp, err = newClient(NewFileCache(path, wrapper, nil)).GetSecret(context.Background(), "b", nil)
+	require.NoError(t, err)
+	require.Equal(t, "value-b", *resp.Value)
+
+	// but not when it can't unwrap the file's key, and then the file is kept
+	wrapper.unavailable = true
+	_, err = newClient(NewFileCache(path, wrapper, nil)).GetSecret(context.Background(), "b", nil)
+	require.Error(t, err)
+	wrapper.unavailable = false
+	_, err = newClient(NewFileCache(path, wrapper, nil)).GetSecret(context.Background(), "b", nil)
+	require.NoError(t, err)
+
+	// the cache doesn't hide errors other than the vault's unavailability
+	status = http.StatusForbidden
+	_, err = client.GetSecret(context.Background(), "a", nil)
+	require.ErrorAs(t, err, &respErr)
+	require.Equal(t, http.StatusForbidden, respErr.StatusCode)
+
+	// secrets older than MaxStaleness aren't served
+	status = http.StatusServiceUnavailable
+	cache.now = func() time.Time { return time.Now().Add(25 * time.Hour) }
+	_, err = client.GetSecret(context.Background(), "a", nil)
+	require.Error(t, err)
+	cache.now = time.Now
+
+	// deleted secrets are removed from the cache
+	status = http.StatusNotFound
+	_, err = client.GetSecret(context.Background(), "a", nil)
+	require.Error(t, err)
+	status = http.StatusServiceUnavailable
+	_, err = client.GetSecret(context.Background(), "a", nil)
+	require.Error(t, err)
+	resp, err = client.GetSecret(context.Background(), "b", nil)
+	require.NoError(t, err)
+	require.Equal(t, "value-b", *resp.Value)
+}
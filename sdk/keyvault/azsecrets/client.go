@@ -8,11 +8,18 @@ package azsecrets
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets/internal/generated"
 	shared "github.com/Azure/azure-sdk-for-go/sdk/keyvault/internal"
 )
@@ -21,11 +28,17 @@ import (
 type Client struct {
 	kvClient *generated.KeyVaultClient
 	vaultUrl string
+	cache    *FileCache
 }
 
 // ClientOptions are the configurable options for a Client.
 type ClientOptions struct {
 	azcore.ClientOptions
+
+	// Cache, when set, mirrors the secrets GetSecret gets into an encrypted file, from which GetSecret serves them
+	// when the vault is unavailable, that is, when a request fails without a response or with status 429 or 5xx.
+	// Failing to write the cache doesn't fail GetSecret.
+	Cache *FileCache
 }
 
 // NewClient constructs a Client that accesses a Key Vault's secrets.
@@ -40,6 +53,7 @@ func NewClient(vaultURL string, credential azcore.TokenCredential, options *Clie
 	return &Client{
 		kvClient: generated.NewKeyVaultClient(pl),
 		vaultUrl: vaultURL,
+		cache:    options.Cache,
 	}, nil
 }
 
@@ -66,6 +80,10 @@ func (g *GetSecretOptions) toGenerated() *generated.KeyVaultClientGetSecretOptio
 // GetSecretResponse is returned by GetSecret.
 type GetSecretResponse struct {
 	Secret
+
+	// CachedOn is set when the secret was served from ClientOptions.Cache because the vault was unavailable.
+	// It's the time the secret was fetched from the vault.
+	CachedOn *time.Time
 }
 
 func getSecretResponseFromGenerated(i generated.KeyVaultClientGetSecretResponse) GetSecretResponse {
@@ -87,9 +105,24 @@ func (c *Client) GetSecret(ctx context.Context, name string, options *GetSecretO
 	}
 	resp, err := c.kvClient.GetSecret(ctx, c.vaultUrl, name, options.Version, options.toGenerated())
 	if err != nil {
+		if c.cache == nil {
+			return GetSecretResponse{}, err
+		}
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			_ = c.cache.remove(ctx, name, options.Version)
+		} else if isVaultUnavailable(ctx, err) {
+			if secret, cachedOn, ok := c.cache.lookup(ctx, name, options.Version); ok {
+				return GetSecretResponse{Secret: secret, CachedOn: &cachedOn}, nil
+			}
+		}
 		return GetSecretResponse{}, err
 	}
-	return getSecretResponseFromGenerated(resp), nil
+	secret := getSecretResponseFromGenerated(resp)
+	if c.cache != nil {
+		_ = c.cache.store(ctx, name, options.Version, secret.Secret)
+	}
+	return secret, nil
 }
 
 // SetSecretOptions contains optional parameters for SetSecret.
@@ -253,9 +286,17 @@ func (c *Client) GetDeletedSecret(ctx context.Context, name string, options *Get
 	return getDeletedSecretResponseFromGenerated(resp), nil
 }
 
+// ErrSecretModified is returned by UpdateSecretProperties when the secret was updated after
+// UpdateSecretPropertiesOptions.IfUnchangedSince.
+var ErrSecretModified = errors.New("the secret was modified")
+
 // UpdateSecretPropertiesOptions contains optional parameters for UpdateSecretProperties.
 type UpdateSecretPropertiesOptions struct {
-	// placeholder for future optional parameters
+	// IfUnchangedSince, when set, makes UpdateSecretProperties return an error wrapping ErrSecretModified
+	// if the secret was updated after this time. The service doesn't support conditional updates, so this
+	// is checked by getting the secret before updating it and is best-effort: an update made by another
+	// client between the two requests isn't detected.
+	IfUnchangedSince *time.Time
 }
 
 // UpdateSecretPropertiesResponse is returned by UpdateSecretProperties.
@@ -285,6 +326,15 @@ func (c *Client) UpdateSecretProperties(ctx context.Context, properties Properti
 	if properties.Version != nil {
 		version = *properties.Version
 	}
+	if options != nil && options.IfUnchangedSince != nil {
+		current, err := c.kvClient.GetSecret(ctx, c.vaultUrl, name, version, nil)
+		if err != nil {
+			return UpdateSecretPropertiesResponse{}, err
+		}
+		if current.Attributes != nil && current.Attributes.Updated != nil && current.Attributes.Updated.After(*options.IfUnchangedSince) {
+			return UpdateSecretPropertiesResponse{}, fmt.Errorf("%w: secret %s was updated at %s", ErrSecretModified, name, current.Attributes.Updated.UTC().Format(time.RFC3339))
+		}
+	}
 	resp, err := c.kvClient.UpdateSecret(
 		ctx,
 		c.vaultUrl,
@@ -648,3 +698,480 @@ func (c *Client) NewListPropertiesOfSecretsPager(options *ListPropertiesOfSecret
 		},
 	})
 }
+
+// SetSecretsOptions contains optional parameters for SetSecrets.
+type SetSecretsOptions struct {
+	// ContentType is the content type of each new secret version, for example "text/plain".
+	ContentType *string
+}
+
+// SetSecretsResult describes what SetSecrets did with one secret.
+type SetSecretsResult struct {
+	// Err is the error SetSecrets encountered setting the secret. It's nil when the secret was set.
+	Err error
+
+	// PreviousID is the ID of the secret's latest version before SetSecrets ran. It's nil when the
+	// secret didn't exist.
+	PreviousID *string
+
+	// RolledBack is true when SetSecrets undid the secret's update because setting another secret failed.
+	// A secret that existed before is rolled back by setting a new version having the previous version's
+	// value and properties. A secret that didn't exist before is rolled back by disabling the new version.
+	RolledBack bool
+
+	// RollbackErr is the error SetSecrets encountered rolling back the secret's update, if any. When this
+	// is non-nil, the secret may have the value passed to SetSecrets.
+	RollbackErr error
+
+	// Secret is the version SetSecrets created. It's nil when SetSecrets didn't set the secret.
+	Secret *Secret
+}
+
+// SetSecretsResponse is returned by SetSecrets.
+type SetSecretsResponse struct {
+	// Results maps the name of each secret passed to SetSecrets to the outcome of setting it. A secret
+	// having no entry wasn't set because SetSecrets stopped at an earlier failure.
+	Results map[string]*SetSecretsResult
+}
+
+// SetSecrets sets the values of several secrets, creating a new version of each. Key Vault doesn't support
+// transactions, so SetSecrets makes a best effort to apply all the updates or none of them: it sets secrets
+// one at a time in order of name and, when setting a secret fails, rolls back the secrets it has already set.
+// The returned error is non-nil when any secret failed to update. In that case, the response describes which
+// secrets were set and whether their rollbacks succeeded. Rolling back creates new secret versions, so a
+// secret's version history reflects a failed SetSecrets call even when the rollback succeeds.
+func (c *Client) SetSecrets(ctx context.Context, secrets map[string]string, options *SetSecretsOptions) (SetSecretsResponse, error) {
+	if options == nil {
+		options = &SetSecretsOptions{}
+	}
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := SetSecretsResponse{Results: make(map[string]*SetSecretsResult, len(secrets))}
+	previous := map[string]*Secret{}
+	for i, name := range names {
+		result := &SetSecretsResult{}
+		resp.Results[name] = result
+
+		var err error
+		previous[name], err = c.getLatestSecret(ctx, name)
+		if err == nil {
+			if previous[name] != nil {
+				result.PreviousID = previous[name].ID
+			}
+			var setResp SetSecretResponse
+			setResp, err = c.SetSecret(ctx, name, secrets[name], &SetSecretOptions{ContentType: options.ContentType})
+			if err == nil {
+				result.Secret = &setResp.Secret
+				continue
+			}
+		}
+
+		result.Err = err
+		for j := i - 1; j >= 0; j-- {
+			updated := resp.Results[names[j]]
+			updated.RollbackErr = c.rollbackSecret(ctx, names[j], previous[names[j]], updated.Secret)
+			updated.RolledBack = updated.RollbackErr == nil
+		}
+		return resp, fmt.Errorf("failed to set secret %s: %w", name, err)
+	}
+	return resp, nil
+}
+
+// getLatestSecret returns the latest version of a secret, or nil when the secret doesn't exist
+func (c *Client) getLatestSecret(ctx context.Context, name string) (*Secret, error) {
+	resp, err := c.GetSecret(ctx, name, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &resp.Secret, nil
+}
+
+// rollbackSecret restores a secret's previous value and properties, or disables the
+// version SetSecrets created when the secret had no previous version
+func (c *Client) rollbackSecret(ctx context.Context, name string, previous *Secret, created *Secret) error {
+	if previous == nil {
+		if created.Properties == nil {
+			return errors.New("can't disable a secret version having no properties")
+		}
+		_, err := c.UpdateSecretProperties(ctx, Properties{
+			Enabled: to.Ptr(false),
+			Name:    &name,
+			Version: created.Properties.Version,
+		}, nil)
+		return err
+	}
+	if previous.Value == nil {
+		return errors.New("the previous version has no value to restore")
+	}
+	options := &SetSecretOptions{}
+	if p := previous.Properties; p != nil {
+		options.ContentType = p.ContentType
+		options.Properties = &Properties{
+			Enabled:   p.Enabled,
+			ExpiresOn: p.ExpiresOn,
+			NotBefore: p.NotBefore,
+			Tags:      p.Tags,
+		}
+	}
+	_, err := c.SetSecret(ctx, name, *previous.Value, options)
+	return err
+}
+
+// ListSecretVersionHistoryOptions contains optional parameters for ListSecretVersionHistory.
+type ListSecretVersionHistoryOptions struct {
+	// placeholder for future optional parameters
+}
+
+// ListSecretVersionHistoryResponse is returned by ListSecretVersionHistory.
+type ListSecretVersionHistoryResponse struct {
+	// Name is the name of the secret.
+	Name string
+
+	// Versions are the secret's versions, oldest first.
+	Versions []SecretVersionHistoryEntry
+}
+
+// ListSecretVersionHistory gets the properties of every version of a secret, not including their values, and orders them
+// by creation time. Each entry identifies the tags that changed from the previous version, which helps to review changes
+// to a secret's metadata. Key Vault doesn't record who made a change; use Azure Monitor logging to learn that.
+func (c *Client) ListSecretVersionHistory(ctx context.Context, name string, options *ListSecretVersionHistoryOptions) (ListSecretVersionHistoryResponse, error) {
+	var versions []*Properties
+	pager := c.NewListPropertiesOfSecretVersionsPager(name, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return ListSecretVersionHistoryResponse{}, err
+		}
+		for _, s := range page.Secrets {
+			if s != nil && s.Properties != nil {
+				versions = append(versions, s.Properties)
+			}
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i].CreatedOn, versions[j].CreatedOn
+		if a == nil || b == nil {
+			// versions having no creation time sort first
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+
+	resp := ListSecretVersionHistoryResponse{Name: name, Versions: make([]SecretVersionHistoryEntry, len(versions))}
+	for i, v := range versions {
+		resp.Versions[i].Properties = v
+		if i > 0 {
+			resp.Versions[i].ChangedTags = changedTags(versions[i-1].Tags, v.Tags)
+		}
+	}
+	return resp, nil
+}
+
+// ListChangedSecretsSinceOptions contains optional parameters for ListChangedSecretsSince.
+type ListChangedSecretsSinceOptions struct {
+	// placeholder for future optional parameters
+}
+
+// ListChangedSecretsSinceResponse is returned by ListChangedSecretsSince.
+type ListChangedSecretsSinceResponse struct {
+	// Secrets are the secrets updated at or after the watermark passed to ListChangedSecretsSince, oldest
+	// update first.
+	Secrets []*SecretItem
+
+	// Watermark is the latest update time of Secrets, or the watermark passed to ListChangedSecretsSince when
+	// Secrets is empty. Pass it to the next call to get only later changes.
+	Watermark time.Time
+}
+
+// ListChangedSecretsSince lists the properties of the secrets updated at or after since, so a sync job can
+// process changes instead of every secret in the vault. Key Vault records update times in whole seconds, so
+// the secrets updated at exactly the watermark are listed again by the next call; process them idempotently.
+// The service doesn't filter by update time, so this still pages through the properties of every secret.
+// Deleted secrets aren't listed; use NewListDeletedSecretsPager to find them. Pass nil for options to
+// accept default values.
+func (c *Client) ListChangedSecretsSince(ctx context.Context, since time.Time, options *ListChangedSecretsSinceOptions) (ListChangedSecretsSinceResponse, error) {
+	resp := ListChangedSecretsSinceResponse{Watermark: since}
+	pager := c.NewListPropertiesOfSecretsPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return ListChangedSecretsSinceResponse{}, err
+		}
+		for _, s := range page.Secrets {
+			if s == nil || s.Properties == nil || s.Properties.UpdatedOn == nil || s.Properties.UpdatedOn.Before(since) {
+				continue
+			}
+			resp.Secrets = append(resp.Secrets, s)
+			if s.Properties.UpdatedOn.After(resp.Watermark) {
+				resp.Watermark = *s.Properties.UpdatedOn
+			}
+		}
+	}
+	sort.SliceStable(resp.Secrets, func(i, j int) bool {
+		return resp.Secrets[i].Properties.UpdatedOn.Before(*resp.Secrets[j].Properties.UpdatedOn)
+	})
+	return resp, nil
+}
+
+// GetSecretsOptions contains optional parameters for GetSecrets.
+type GetSecretsOptions struct {
+	// MaxConcurrency is the maximum number of secrets requested at once. The default value is 4.
+	MaxConcurrency int
+
+	// FailFast makes GetSecrets stop after the first failure. Requests in progress are canceled and
+	// secrets not yet requested have no result.
+	FailFast bool
+}
+
+// GetSecretsResult is the outcome of getting one of the secrets passed to GetSecrets.
+type GetSecretsResult struct {
+	// Err is the error GetSecrets encountered getting the secret, if any.
+	Err error
+
+	// Secret is the latest version of the secret. It's nil when Err is non-nil.
+	Secret *Secret
+}
+
+// GetSecretsResponse is returned by GetSecrets.
+type GetSecretsResponse struct {
+	// Results maps the name of each secret passed to GetSecrets to the outcome of getting it. When
+	// FailFast is set, a secret having no entry wasn't requested because GetSecrets stopped at a failure.
+	Results map[string]*GetSecretsResult
+}
+
+// GetSecrets gets the latest versions of several secrets concurrently, for instance to load an
+// application's configuration at startup. The returned error is non-nil when any secret couldn't be
+// gotten; it wraps the error of the first secret that failed. In that case, the response still has
+// the secrets that were gotten.
+func (c *Client) GetSecrets(ctx context.Context, names []string, options *GetSecretsOptions) (GetSecretsResponse, error) {
+	if options == nil {
+		options = &GetSecretsOptions{}
+	}
+	maxConcurrency := options.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 4
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resp := GetSecretsResponse{Results: make(map[string]*GetSecretsResult, len(names))}
+	var mu sync.Mutex
+	firstFailed := ""
+	authenticated := false
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for _, name := range names {
+		sem <- struct{}{}
+		mu.Lock()
+		_, seen := resp.Results[name]
+		stop := firstFailed != "" && options.FailFast
+		if !seen && !stop {
+			// reserve the name so duplicates are requested once
+			resp.Results[name] = nil
+		}
+		mu.Unlock()
+		if seen || stop {
+			<-sem
+			if stop {
+				break
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result := &GetSecretsResult{}
+			secret, err := c.GetSecret(ctx, name, nil)
+			if err != nil {
+				result.Err = err
+			} else {
+				result.Secret = &secret.Secret
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			resp.Results[name] = result
+			if err != nil && firstFailed == "" {
+				firstFailed = name
+				if options.FailFast {
+					cancel()
+				}
+			}
+		}(name)
+		if !authenticated {
+			// the first request answers the vault's authentication challenge; wait for it so
+			// the others don't each send their own challenge request
+			wg.Wait()
+			authenticated = true
+		}
+	}
+	wg.Wait()
+
+	failures := 0
+	for name, result := range resp.Results {
+		if result == nil {
+			// not requested
+			delete(resp.Results, name)
+		} else if result.Err != nil {
+			failures++
+		}
+	}
+	if failures > 0 {
+		return resp, fmt.Errorf("failed to get %d of %d secrets; secret %s: %w", failures, len(resp.Results), firstFailed, resp.Results[firstFailed].Err)
+	}
+	return resp, nil
+}
+
+// DeleteSecretState describes what DeleteSecretAndWait left of a deleted secret, which determines when
+// the secret's name can be reused.
+type DeleteSecretState string
+
+const (
+	// DeleteSecretStatePurged means the secret is gone and its name can be reused now.
+	DeleteSecretStatePurged DeleteSecretState = "Purged"
+
+	// DeleteSecretStateSoftDeletedAwaitingRetention means the secret is soft deleted and the vault allows purging
+	// it. Its name can be reused after the secret is purged, which happens automatically when the retention period
+	// ends, or after the secret is recovered.
+	DeleteSecretStateSoftDeletedAwaitingRetention DeleteSecretState = "SoftDeletedAwaitingRetention"
+
+	// DeleteSecretStatePurgeProtected means the secret is soft deleted and the vault's purge protection prevents
+	// purging it. Its name can be reused when the retention period ends, or after the secret is recovered.
+	DeleteSecretStatePurgeProtected DeleteSecretState = "PurgeProtected"
+)
+
+// PossibleDeleteSecretStateValues returns the possible values for the DeleteSecretState const type.
+func PossibleDeleteSecretStateValues() []DeleteSecretState {
+	return []DeleteSecretState{
+		DeleteSecretStatePurged,
+		DeleteSecretStateSoftDeletedAwaitingRetention,
+		DeleteSecretStatePurgeProtected,
+	}
+}
+
+const defaultDeleteSecretAndWaitFrequency = 2 * time.Second
+
+// DeleteSecretAndWaitOptions contains optional parameters for DeleteSecretAndWait.
+type DeleteSecretAndWaitOptions struct {
+	// Purge makes DeleteSecretAndWait purge the deleted secret, when the vault's purge protection allows it, and
+	// wait for the purge to finish. Purging requires the secrets/purge permission.
+	Purge bool
+
+	// Frequency is the time to wait between checks of the deletion's progress. The minimum is one second.
+	// The default value is two seconds.
+	Frequency time.Duration
+}
+
+// DeleteSecretAndWaitResponse is returned by DeleteSecretAndWait.
+type DeleteSecretAndWaitResponse struct {
+	// DeletedSecret is the deleted secret as Key Vault reported it after deleting it.
+	DeletedSecret
+
+	// State is what's left of the secret, and determines when its name can be reused.
+	State DeleteSecretState
+
+	// RetentionEndsOn is the time the soft deleted secret will be purged and its name can be reused, unless it's
+	// purged or recovered sooner. It's nil when State is DeleteSecretStatePurged.
+	RetentionEndsOn *time.Time
+}
+
+// DeleteSecretAndWait deletes all versions of a secret, waits for Key Vault to finish deleting it and, when
+// DeleteSecretAndWaitOptions.Purge is true, purges it. The response's State reports whether the secret's name can
+// be reused and, when it can't yet, whether that's because the secret wasn't purged or because the vault's purge
+// protection prevents purging it. Pass nil for options to accept default values.
+func (c *Client) DeleteSecretAndWait(ctx context.Context, name string, options *DeleteSecretAndWaitOptions) (DeleteSecretAndWaitResponse, error) {
+	if options == nil {
+		options = &DeleteSecretAndWaitOptions{}
+	}
+	frequency := options.Frequency
+	if frequency == 0 {
+		frequency = defaultDeleteSecretAndWaitFrequency
+	}
+	if frequency < time.Second {
+		return DeleteSecretAndWaitResponse{}, errors.New("frequency minimum is one second")
+	}
+
+	var rawResp *http.Response
+	genResp, err := c.kvClient.DeleteSecret(runtime.WithCaptureResponse(ctx, &rawResp), c.vaultUrl, name, nil)
+	if err != nil {
+		return DeleteSecretAndWaitResponse{}, err
+	}
+	resp := DeleteSecretAndWaitResponse{DeletedSecret: deleteSecretResponseFromGenerated(genResp).DeletedSecret}
+
+	var recoveryLevel string
+	if resp.Properties != nil && resp.Properties.RecoveryLevel != nil {
+		recoveryLevel = *resp.Properties.RecoveryLevel
+	}
+	if !strings.Contains(recoveryLevel, "Recoverable") {
+		// without soft delete, deleting a secret purges it
+		resp.State = DeleteSecretStatePurged
+		return resp, nil
+	}
+
+	// wait for the deletion to finish, as BeginDeleteSecret's poller does
+	poller, err := runtime.NewPoller(rawResp, c.kvClient.Pipeline(), &runtime.NewPollerOptions[DeleteSecretResponse]{
+		Handler: &beginDeleteSecretOperation{
+			poll: func(ctx context.Context) (*http.Response, error) {
+				req, err := c.kvClient.GetDeletedSecretCreateRequest(ctx, c.vaultUrl, name, nil)
+				if err != nil {
+					return nil, err
+				}
+				return c.kvClient.Pipeline().Do(req)
+			},
+		},
+	})
+	if err != nil {
+		return resp, err
+	}
+	deleted, err := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: frequency})
+	if err != nil {
+		return resp, err
+	}
+	resp.DeletedSecret = deleted.DeletedSecret
+	resp.RetentionEndsOn = deleted.ScheduledPurgeDate
+
+	if !strings.Contains(recoveryLevel, "Purgeable") {
+		resp.State = DeleteSecretStatePurgeProtected
+		return resp, nil
+	}
+	if !options.Purge {
+		resp.State = DeleteSecretStateSoftDeletedAwaitingRetention
+		return resp, nil
+	}
+
+	if _, err := c.PurgeDeletedSecret(ctx, name, nil); err != nil {
+		return resp, err
+	}
+	// purging is asynchronous; the name can be reused when the deleted secret is gone
+	for {
+		_, err := c.GetDeletedSecret(ctx, name, nil)
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound {
+			break
+		}
+		if err != nil {
+			return resp, err
+		}
+		select {
+		case <-time.After(frequency):
+		case <-ctx.Done():
+			return resp, ctx.Err()
+		}
+	}
+	resp.State = DeleteSecretStatePurged
+	resp.RetentionEndsOn = nil
+	return resp, nil
+}
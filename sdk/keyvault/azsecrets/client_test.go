@@ -10,7 +10,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -473,3 +476,141 @@ func TestBackupSecret(t *testing.T) {
 		recording.Sleep(30 * time.Second)
 	}
 }
+
+func TestChangedTags(t *testing.T) {
+	previous := map[string]*string{"owner": to.Ptr("a"), "env": to.Ptr("prod"), "stale": to.Ptr("x")}
+	current := map[string]*string{"owner": to.Ptr("b"), "env": to.Ptr("prod"), "new": nil}
+	require.Equal(t, []string{"new", "owner", "stale"}, changedTags(previous, current))
+	require.Empty(t, changedTags(current, current))
+}
+
+// challengeTransport answers Key Vault's authentication challenge and responds to authenticated requests with respond
+type challengeTransport struct {
+	respond func(req *http.Request) *http.Response
+}
+
+func (c *challengeTransport) Do(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	if req.Header.Get("Authorization") == "" {
+		resp = &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Header:     http.Header{"Www-Authenticate": []string{`Bearer authorization="https://login.microsoftonline.com/tenant", resource="https://vault.azure.net"`}},
+			Body:       http.NoBody,
+		}
+	} else {
+		resp = c.respond(req)
+	}
+	resp.Request = req
+	return resp, nil
+}
+
+func TestGetSecrets(t *testing.T) {
+	var requests int32
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		atomic.AddInt32(&requests, 1)
+		name := strings.TrimPrefix(strings.TrimSuffix(req.URL.Path, "/"), "/secrets/")
+		if strings.HasPrefix(name, "missing") {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"code":"SecretNotFound","message":"not found"}}`)),
+			}
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(`{"id":"%s/secrets/%s/1","value":"value-%s","attributes":{"enabled":true}}`, fakeVaultURL, name, name))),
+		}
+	}}
+	client, err := NewClient(fakeVaultURL, NewFakeCredential(), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	resp, err := client.GetSecrets(context.Background(), []string{"a", "b", "a", "c"}, &GetSecretsOptions{MaxConcurrency: 2})
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 3)
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, resp.Results[name].Err)
+		require.Equal(t, "value-"+name, *resp.Results[name].Secret.Value)
+	}
+
+	resp, err = client.GetSecrets(context.Background(), []string{"a", "missing", "b"}, nil)
+	var respErr *azcore.ResponseError
+	require.ErrorAs(t, err, &respErr)
+	require.Equal(t, http.StatusNotFound, respErr.StatusCode)
+	require.Contains(t, err.Error(), "secret missing")
+	require.Len(t, resp.Results, 3)
+	require.Nil(t, resp.Results["missing"].Secret)
+	require.Equal(t, "value-b", *resp.Results["b"].Secret.Value)
+
+	// with FailFast, nothing is requested after the first failure
+	atomic.StoreInt32(&requests, 0)
+	names := []string{"missing"}
+	for i := 0; i < 10; i++ {
+		names = append(names, fmt.Sprint(i))
+	}
+	resp, err = client.GetSecrets(context.Background(), names, &GetSecretsOptions{MaxConcurrency: 1, FailFast: true})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "secret missing")
+	require.Len(t, resp.Results, 1)
+	require.LessOrEqual(t, atomic.LoadInt32(&requests), int32(2))
+}
+
+func TestDeleteSecretAndWait(t *testing.T) {
+	for _, test := range []struct {
+		recoveryLevel string
+		purge         bool
+		state         DeleteSecretState
+	}{
+		{recoveryLevel: "Purgeable", state: DeleteSecretStatePurged},
+		{recoveryLevel: "Recoverable+Purgeable", state: DeleteSecretStateSoftDeletedAwaitingRetention},
+		{recoveryLevel: "Recoverable+Purgeable", purge: true, state: DeleteSecretStatePurged},
+		{recoveryLevel: "Recoverable", purge: true, state: DeleteSecretStatePurgeProtected},
+		{recoveryLevel: "CustomizedRecoverable+ProtectedSubscription", state: DeleteSecretStatePurgeProtected},
+	} {
+		t.Run(fmt.Sprintf("%s_%v", test.recoveryLevel, test.purge), func(t *testing.T) {
+			deleted := fmt.Sprintf(`{"id":"%s/secrets/s/1","recoveryId":"%s/deletedsecrets/s","scheduledPurgeDate":1657000000,"attributes":{"recoveryLevel":"%s"}}`, fakeVaultURL, fakeVaultURL, test.recoveryLevel)
+			purged := false
+			var requests []string
+			transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+				requests = append(requests, req.Method+" "+req.URL.Path)
+				status, body := http.StatusOK, deleted
+				switch {
+				case req.Method == http.MethodDelete && req.URL.Path == "/secrets/s":
+				case req.Method == http.MethodGet && req.URL.Path == "/deletedsecrets/s":
+					if purged {
+						status, body = http.StatusNotFound, `{"error":{"code":"SecretNotFound","message":"not found"}}`
+					}
+				case req.Method == http.MethodDelete && req.URL.Path == "/deletedsecrets/s":
+					purged = true
+					status, body = http.StatusNoContent, ""
+				default:
+					t.Fatalf("unexpected request %s %s", req.Method, req.URL)
+				}
+				return &http.Response{
+					StatusCode: status,
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(body)),
+				}
+			}}
+			client, err := NewClient(fakeVaultURL, NewFakeCredential(), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+			require.NoError(t, err)
+
+			resp, err := client.DeleteSecretAndWait(context.Background(), "s", &DeleteSecretAndWaitOptions{Purge: test.purge})
+			require.NoError(t, err)
+			require.Equal(t, test.state, resp.State)
+			require.Equal(t, fakeVaultURL+"/deletedsecrets/s", *resp.RecoveryID)
+			if test.state == DeleteSecretStatePurged {
+				require.Nil(t, resp.RetentionEndsOn)
+			} else {
+				require.Equal(t, time.Unix(1657000000, 0).UTC(), resp.RetentionEndsOn.UTC())
+			}
+			require.Equal(t, test.purge && test.state == DeleteSecretStatePurged, purged)
+			require.Equal(t, "DELETE /secrets/s", requests[0])
+		})
+	}
+
+	client, err := NewClient(fakeVaultURL, NewFakeCredential(), nil)
+	require.NoError(t, err)
+	_, err = client.DeleteSecretAndWait(context.Background(), "s", &DeleteSecretAndWaitOptions{Frequency: time.Millisecond})
+	require.Error(t, err)
+}
@@ -0,0 +1,103 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// ExecWithSecretsOptions contains optional parameters for ExecWithSecrets.
+type ExecWithSecretsOptions struct {
+	// MaxConcurrency is the maximum number of secrets requested at once. The default value is 4.
+	MaxConcurrency int
+}
+
+// ExecWithSecretsResponse is returned by ExecWithSecrets.
+type ExecWithSecretsResponse struct {
+	// SecretIDs maps each environment variable to the ID, including the version, of the secret whose value
+	// it was set to, for audit logs. It doesn't contain secret values.
+	SecretIDs map[string]string
+}
+
+// ExecWithSecrets gets the latest versions of secrets and runs cmd, waiting for it to finish, with environment
+// variables set to their values. mappings maps the name of each environment variable to the name of its secret.
+// The variables are added to cmd.Env, or to the parent's environment when cmd.Env is nil, replacing variables
+// having the same names, and are set only in the child process: the parent's environment isn't changed, cmd.Env
+// is restored when ExecWithSecrets returns, and errors name variables and secrets but never contain values.
+// cmd doesn't start when any secret can't be gotten. ctx applies to getting the secrets; use
+// exec.CommandContext to bound the child process. The returned error is cmd's, such as an *exec.ExitError,
+// when the command fails. This operation requires the secrets/get permission.
+func (c *Client) ExecWithSecrets(ctx context.Context, cmd *exec.Cmd, mappings map[string]string, options *ExecWithSecretsOptions) (ExecWithSecretsResponse, error) {
+	if cmd == nil {
+		return ExecWithSecretsResponse{}, errors.New("cmd can't be nil")
+	}
+	if options == nil {
+		options = &ExecWithSecretsOptions{}
+	}
+	vars := make([]string, 0, len(mappings))
+	names := make([]string, 0, len(mappings))
+	for v, name := range mappings {
+		if v == "" || strings.ContainsAny(v, "=\x00") {
+			return ExecWithSecretsResponse{}, fmt.Errorf("invalid environment variable name %q", v)
+		}
+		vars = append(vars, v)
+		names = append(names, name)
+	}
+	// sorted, for deterministic errors and environments
+	sort.Strings(vars)
+
+	secrets, err := c.GetSecrets(ctx, names, &GetSecretsOptions{MaxConcurrency: options.MaxConcurrency, FailFast: true})
+	if err != nil {
+		// the error of GetSecrets names the secret and never includes values
+		return ExecWithSecretsResponse{}, err
+	}
+
+	resp := ExecWithSecretsResponse{SecretIDs: make(map[string]string, len(vars))}
+	env := make([]string, 0, len(vars))
+	for _, v := range vars {
+		secret := secrets.Results[mappings[v]].Secret
+		if secret.Value == nil {
+			return ExecWithSecretsResponse{}, fmt.Errorf("secret %s for environment variable %s has no value", mappings[v], v)
+		}
+		env = append(env, v+"="+*secret.Value)
+		if secret.ID != nil {
+			resp.SecretIDs[v] = *secret.ID
+		}
+	}
+
+	original := cmd.Env
+	base := original
+	if base == nil {
+		base = os.Environ()
+	}
+	cmd.Env = append(withoutVariables(base, mappings), env...)
+	defer func() {
+		cmd.Env = original
+	}()
+	return resp, cmd.Run()
+}
+
+// withoutVariables returns a copy of env, a list of "key=value" strings, without the variables in vars
+func withoutVariables(env []string, vars map[string]string) []string {
+	ret := make([]string, 0, len(env)+len(vars))
+	for _, kv := range env {
+		key := kv
+		if i := strings.Index(kv, "="); i > 0 {
+			key = kv[:i]
+		}
+		if _, replaced := vars[key]; !replaced {
+			ret = append(ret, kv)
+		}
+	}
+	return ret
+}
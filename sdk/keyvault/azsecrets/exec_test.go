@@ -0,0 +1,89 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azsecrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/stretchr/testify/require"
+)
+
+// execHelperEnvVar makes the test binary print the variables named in its value, instead of running tests
+const execHelperEnvVar = "AZSECRETS_EXEC_HELPER"
+
+func execHelper() {
+	for _, v := range strings.Split(os.Getenv(execHelperEnvVar), ",") {
+		fmt.Printf("%s=%s\n", v, os.Getenv(v))
+	}
+	if os.Getenv("EXIT_CODE") != "" {
+		os.Exit(3)
+	}
+	os.Exit(0)
+}
+
+func TestExecWithSecrets(t *testing.T) {
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		name := strings.TrimPrefix(strings.TrimSuffix(req.URL.Path, "/"), "/secrets/")
+		if name == "missing" {
+			return &http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"error":{"code":"SecretNotFound","message":"not found"}}`)),
+			}
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(fmt.Sprintf(`{"id":"%s/secrets/%s/1","value":"value-%s","attributes":{"enabled":true}}`, fakeVaultURL, name, name))),
+		}
+	}}
+	client, err := NewClient(fakeVaultURL, NewFakeCredential(), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+	mappings := map[string]string{"DB_PASSWORD": "db", "API_KEY": "api"}
+
+	out := &bytes.Buffer{}
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = []string{execHelperEnvVar + "=DB_PASSWORD,API_KEY,OTHER", "DB_PASSWORD=stale", "OTHER=kept"}
+	cmd.Stdout = out
+	resp, err := client.ExecWithSecrets(context.Background(), cmd, mappings, nil)
+	require.NoError(t, err)
+	require.Equal(t, "DB_PASSWORD=value-db\nAPI_KEY=value-api\nOTHER=kept\n", out.String())
+	require.Equal(t, map[string]string{"DB_PASSWORD": fakeVaultURL + "/secrets/db/1", "API_KEY": fakeVaultURL + "/secrets/api/1"}, resp.SecretIDs)
+	// the values are set only in the child
+	require.Equal(t, []string{execHelperEnvVar + "=DB_PASSWORD,API_KEY,OTHER", "DB_PASSWORD=stale", "OTHER=kept"}, cmd.Env)
+	_, set := os.LookupEnv("API_KEY")
+	require.False(t, set)
+
+	// the command's failure is returned
+	cmd = exec.Command(os.Args[0])
+	cmd.Env = []string{execHelperEnvVar + "=API_KEY", "EXIT_CODE=3"}
+	_, err = client.ExecWithSecrets(context.Background(), cmd, mappings, nil)
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 3, exitErr.ExitCode())
+
+	// the command doesn't start when a secret is missing
+	cmd = exec.Command(os.Args[0])
+	cmd.Env = []string{execHelperEnvVar + "=API_KEY"}
+	_, err = client.ExecWithSecrets(context.Background(), cmd, map[string]string{"API_KEY": "api", "DB_PASSWORD": "missing"}, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "missing")
+	require.NotContains(t, err.Error(), "value-api")
+	require.Nil(t, cmd.Process)
+
+	_, err = client.ExecWithSecrets(context.Background(), exec.Command(os.Args[0]), map[string]string{"A=B": "api"}, nil)
+	require.Error(t, err)
+}
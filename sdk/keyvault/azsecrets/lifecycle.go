@@ -0,0 +1,365 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+// Tags recognized by EvaluateLifecyclePolicy. Durations are positive numbers of days ("30d"), of weeks ("2w"),
+// or Go durations ("36h").
+const (
+	// LifecycleTagRotateAfter is the age of a secret's current version after which the secret should be rotated.
+	LifecycleTagRotateAfter = "rotate-after"
+
+	// LifecycleTagDisableAfter is the age of a secret's current version after which the secret should be disabled.
+	LifecycleTagDisableAfter = "disable-after"
+
+	// LifecycleTagNotifyBeforeExpiry is how long before a secret expires its owner should be notified.
+	LifecycleTagNotifyBeforeExpiry = "notify-before-expiry"
+
+	// LifecycleTagOwner identifies who is responsible for a secret, for example "team-x". It's copied to the
+	// secret's tasks so an executor can route them.
+	LifecycleTagOwner = "owner"
+)
+
+// LifecycleAction is the action a LifecycleTask calls for.
+type LifecycleAction string
+
+const (
+	// LifecycleActionRotate means the secret should get a new value.
+	LifecycleActionRotate LifecycleAction = "Rotate"
+
+	// LifecycleActionNotify means the secret's owner should be told the secret expires soon, or has expired.
+	LifecycleActionNotify LifecycleAction = "Notify"
+
+	// LifecycleActionDisable means the secret should be disabled.
+	LifecycleActionDisable LifecycleAction = "Disable"
+)
+
+// PossibleLifecycleActionValues returns the possible values for the LifecycleAction const type.
+func PossibleLifecycleActionValues() []LifecycleAction {
+	return []LifecycleAction{
+		LifecycleActionRotate,
+		LifecycleActionNotify,
+		LifecycleActionDisable,
+	}
+}
+
+// ErrNoLifecycleHandler is returned by ClientLifecycleExecutor for a task whose action it has no handler for.
+var ErrNoLifecycleHandler = errors.New("no handler for the lifecycle action")
+
+// LifecycleTask is an action a secret's lifecycle tags call for.
+type LifecycleTask struct {
+	// Action is what should be done.
+	Action LifecycleAction
+
+	// Name is the name of the secret.
+	Name string
+
+	// Owner is the value of the secret's LifecycleTagOwner tag. It's empty when the secret has no owner tag.
+	Owner string
+
+	// Reason describes why the task is due, for logs and notifications.
+	Reason string
+
+	// DueOn is when the task became, or becomes, due.
+	DueOn time.Time
+
+	// Properties are the properties of the secret's current version when the task was evaluated.
+	Properties *Properties
+}
+
+// LifecycleTagError describes a lifecycle tag whose value is invalid. The tag is ignored; the secret's other tags
+// are still evaluated.
+type LifecycleTagError struct {
+	// Name is the name of the secret.
+	Name string
+
+	// Tag is the name of the tag.
+	Tag string
+
+	// Value is the tag's value.
+	Value string
+
+	// Err describes what's wrong with the value.
+	Err error
+}
+
+// Error implements the error interface for type LifecycleTagError.
+func (e *LifecycleTagError) Error() string {
+	return fmt.Sprintf("secret %s: invalid value %q for tag %s: %v", e.Name, e.Value, e.Tag, e.Err)
+}
+
+// Unwrap returns the error describing what's wrong with the value.
+func (e *LifecycleTagError) Unwrap() error {
+	return e.Err
+}
+
+// parseLifecycleDuration parses a number of days ("30d"), of weeks ("2w") or a Go duration
+func parseLifecycleDuration(v string) (time.Duration, error) {
+	v = strings.TrimSpace(v)
+	var d time.Duration
+	var err error
+	switch unit := strings.TrimLeft(v, "0123456789"); unit {
+	case "d", "w":
+		var n int
+		n, err = strconv.Atoi(strings.TrimSuffix(v, unit))
+		d = time.Duration(n) * 24 * time.Hour
+		if unit == "w" {
+			d *= 7
+		}
+	default:
+		d, err = time.ParseDuration(v)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+	return d, nil
+}
+
+// EvaluateLifecyclePolicy returns the tasks the lifecycle tags of secret call for at now:
+//   - LifecycleActionRotate when its current version is older than its LifecycleTagRotateAfter tag
+//   - LifecycleActionDisable when its current version is older than its LifecycleTagDisableAfter tag. Rotating a
+//     secret that's due to be disabled is pointless, so a secret has no rotate task when it has a disable task.
+//   - LifecycleActionNotify when it expires within its LifecycleTagNotifyBeforeExpiry tag, or has expired
+//
+// Disabled secrets and secrets whose lifetime Key Vault manages, such as those backing certificates, have no tasks.
+// When a tag's value is invalid, the error is a *LifecycleTagError and the tasks of the secret's other tags are
+// still returned.
+func EvaluateLifecyclePolicy(secret *SecretItem, now time.Time) ([]LifecycleTask, error) {
+	if secret == nil || secret.Name == nil || secret.Properties == nil {
+		return nil, nil
+	}
+	props := secret.Properties
+	if (props.Enabled != nil && !*props.Enabled) || (props.Managed != nil && *props.Managed) {
+		return nil, nil
+	}
+	name := *secret.Name
+	var tagErr error
+	tag := func(t string) (time.Duration, bool) {
+		v, ok := props.Tags[t]
+		if !ok || v == nil {
+			return 0, false
+		}
+		d, err := parseLifecycleDuration(*v)
+		if err != nil {
+			if tagErr == nil {
+				tagErr = &LifecycleTagError{Name: name, Tag: t, Value: *v, Err: err}
+			}
+			return 0, false
+		}
+		return d, true
+	}
+	owner := ""
+	if v := props.Tags[LifecycleTagOwner]; v != nil {
+		owner = *v
+	}
+	task := func(action LifecycleAction, dueOn time.Time, reason string) LifecycleTask {
+		return LifecycleTask{Action: action, Name: name, Owner: owner, Reason: reason, DueOn: dueOn, Properties: props}
+	}
+
+	var tasks []LifecycleTask
+	disable := false
+	if d, ok := tag(LifecycleTagDisableAfter); ok && props.CreatedOn != nil {
+		if dueOn := props.CreatedOn.Add(d); !now.Before(dueOn) {
+			disable = true
+			tasks = append(tasks, task(LifecycleActionDisable, dueOn, fmt.Sprintf("current version created %s is older than %s=%s", props.CreatedOn.UTC().Format(time.RFC3339), LifecycleTagDisableAfter, *props.Tags[LifecycleTagDisableAfter])))
+		}
+	}
+	if d, ok := tag(LifecycleTagRotateAfter); ok && props.CreatedOn != nil && !disable {
+		if dueOn := props.CreatedOn.Add(d); !now.Before(dueOn) {
+			tasks = append(tasks, task(LifecycleActionRotate, dueOn, fmt.Sprintf("current version created %s is older than %s=%s", props.CreatedOn.UTC().Format(time.RFC3339), LifecycleTagRotateAfter, *props.Tags[LifecycleTagRotateAfter])))
+		}
+	}
+	if d, ok := tag(LifecycleTagNotifyBeforeExpiry); ok && props.ExpiresOn != nil {
+		if dueOn := props.ExpiresOn.Add(-d); !now.Before(dueOn) {
+			reason := "expires " + props.ExpiresOn.UTC().Format(time.RFC3339)
+			if !now.Before(*props.ExpiresOn) {
+				reason = "expired " + props.ExpiresOn.UTC().Format(time.RFC3339)
+			}
+			tasks = append(tasks, task(LifecycleActionNotify, dueOn, reason))
+		}
+	}
+	return tasks, tagErr
+}
+
+// EvaluateLifecyclePoliciesOptions contains optional parameters for EvaluateLifecyclePolicies.
+type EvaluateLifecyclePoliciesOptions struct {
+	// Now is the time the policies are evaluated at. The default value is the current time.
+	Now time.Time
+}
+
+// EvaluateLifecyclePoliciesResponse is returned by EvaluateLifecyclePolicies.
+type EvaluateLifecyclePoliciesResponse struct {
+	// Tasks are the due tasks, earliest first.
+	Tasks []LifecycleTask
+
+	// TagErrors describe the lifecycle tags whose values are invalid, which were ignored.
+	TagErrors []*LifecycleTagError
+}
+
+// EvaluateLifecyclePolicies lists the properties of the vault's secrets and returns the tasks their lifecycle tags
+// call for, as described by EvaluateLifecyclePolicy. Pass the tasks to ExecuteLifecycleTasks to carry them out.
+// Invalid tags don't fail the evaluation; they're reported in the response's TagErrors. This operation requires
+// the secrets/list permission. Pass nil for options to accept default values.
+func (c *Client) EvaluateLifecyclePolicies(ctx context.Context, options *EvaluateLifecyclePoliciesOptions) (EvaluateLifecyclePoliciesResponse, error) {
+	if options == nil {
+		options = &EvaluateLifecyclePoliciesOptions{}
+	}
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	resp := EvaluateLifecyclePoliciesResponse{}
+	pager := c.NewListPropertiesOfSecretsPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return EvaluateLifecyclePoliciesResponse{}, err
+		}
+		for _, s := range page.Secrets {
+			tasks, err := EvaluateLifecyclePolicy(s, now)
+			var tagErr *LifecycleTagError
+			if errors.As(err, &tagErr) {
+				resp.TagErrors = append(resp.TagErrors, tagErr)
+			}
+			resp.Tasks = append(resp.Tasks, tasks...)
+		}
+	}
+	sort.SliceStable(resp.Tasks, func(i, j int) bool {
+		a, b := resp.Tasks[i], resp.Tasks[j]
+		if !a.DueOn.Equal(b.DueOn) {
+			return a.DueOn.Before(b.DueOn)
+		}
+		return a.Name < b.Name
+	})
+	return resp, nil
+}
+
+// LifecycleExecutor carries out lifecycle tasks.
+type LifecycleExecutor interface {
+	// ExecuteLifecycleTask carries out task.
+	ExecuteLifecycleTask(ctx context.Context, task LifecycleTask) error
+}
+
+// LifecycleExecutorFunc is a function implementing LifecycleExecutor.
+type LifecycleExecutorFunc func(ctx context.Context, task LifecycleTask) error
+
+// ExecuteLifecycleTask calls f(ctx, task).
+func (f LifecycleExecutorFunc) ExecuteLifecycleTask(ctx context.Context, task LifecycleTask) error {
+	return f(ctx, task)
+}
+
+// ClientLifecycleExecutor is a LifecycleExecutor that disables and rotates secrets with a Client, and delegates
+// notifications. It returns an error wrapping ErrNoLifecycleHandler for a task whose handler is nil.
+type ClientLifecycleExecutor struct {
+	// Client is the Client of the tasks' vault.
+	Client *Client
+
+	// NewValue returns the new value of a secret to rotate, for example a credential it just created. The
+	// executor sets the value as a new version with the previous version's content type and tags.
+	NewValue func(ctx context.Context, task LifecycleTask) (string, error)
+
+	// Notify notifies the owner of a secret expiring soon.
+	Notify func(ctx context.Context, task LifecycleTask) error
+}
+
+// ExecuteLifecycleTask carries out task. It disables a secret only when the secret wasn't updated after the task
+// was evaluated; otherwise, it returns an error wrapping ErrSecretModified.
+func (e *ClientLifecycleExecutor) ExecuteLifecycleTask(ctx context.Context, task LifecycleTask) error {
+	switch task.Action {
+	case LifecycleActionDisable:
+		if e.Client == nil {
+			return fmt.Errorf("%w: Client is nil", ErrNoLifecycleHandler)
+		}
+		options := &UpdateSecretPropertiesOptions{}
+		if task.Properties != nil {
+			options.IfUnchangedSince = task.Properties.UpdatedOn
+		}
+		_, err := e.Client.UpdateSecretProperties(ctx, Properties{Name: to.Ptr(task.Name), Enabled: to.Ptr(false)}, options)
+		return err
+	case LifecycleActionRotate:
+		if e.Client == nil || e.NewValue == nil {
+			return fmt.Errorf("%w: can't rotate secret %s", ErrNoLifecycleHandler, task.Name)
+		}
+		value, err := e.NewValue(ctx, task)
+		if err != nil {
+			return err
+		}
+		options := &SetSecretOptions{}
+		if task.Properties != nil {
+			options.ContentType = task.Properties.ContentType
+			options.Properties = &Properties{Tags: task.Properties.Tags}
+		}
+		_, err = e.Client.SetSecret(ctx, task.Name, value, options)
+		return err
+	case LifecycleActionNotify:
+		if e.Notify == nil {
+			return fmt.Errorf("%w: can't notify the owner of secret %s", ErrNoLifecycleHandler, task.Name)
+		}
+		return e.Notify(ctx, task)
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrNoLifecycleHandler, task.Action)
+	}
+}
+
+// ExecuteLifecycleTaskResult is the outcome of carrying out one of the tasks passed to ExecuteLifecycleTasks.
+type ExecuteLifecycleTaskResult struct {
+	// Task is the task.
+	Task LifecycleTask
+
+	// Err is the error the executor returned for the task, if any.
+	Err error
+}
+
+// ExecuteLifecycleTasksResponse is returned by ExecuteLifecycleTasks.
+type ExecuteLifecycleTasksResponse struct {
+	// Results are the outcomes of the tasks, in the order of the tasks.
+	Results []ExecuteLifecycleTaskResult
+}
+
+// ExecuteLifecycleTasks carries out tasks, such as those returned by EvaluateLifecyclePolicies, one at a time with
+// executor. A failed task doesn't prevent carrying out the others. The returned error is non-nil when any task
+// failed; it wraps the error of the first task that failed. When ctx is done, the remaining tasks fail with ctx's
+// error.
+func ExecuteLifecycleTasks(ctx context.Context, tasks []LifecycleTask, executor LifecycleExecutor) (ExecuteLifecycleTasksResponse, error) {
+	if executor == nil {
+		return ExecuteLifecycleTasksResponse{}, errors.New("executor can't be nil")
+	}
+	resp := ExecuteLifecycleTasksResponse{Results: make([]ExecuteLifecycleTaskResult, len(tasks))}
+	failures := 0
+	var first *ExecuteLifecycleTaskResult
+	for i, task := range tasks {
+		result := &resp.Results[i]
+		result.Task = task
+		if result.Err = ctx.Err(); result.Err == nil {
+			result.Err = executor.ExecuteLifecycleTask(ctx, task)
+		}
+		if result.Err != nil {
+			failures++
+			if first == nil {
+				first = result
+			}
+		}
+	}
+	if failures > 0 {
+		return resp, fmt.Errorf("%d of %d lifecycle tasks failed; %s secret %s: %w", failures, len(tasks), first.Task.Action, first.Task.Name, first.Err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,140 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azsecrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLifecycleDuration(t *testing.T) {
+	for v, expected := range map[string]time.Duration{"30d": 30 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "36h": 36 * time.Hour} {
+		d, err := parseLifecycleDuration(v)
+		require.NoError(t, err, v)
+		require.Equal(t, expected, d, v)
+	}
+	for _, v := range []string{"", "d", "0d", "-1d", "1.5d", "soon"} {
+		_, err := parseLifecycleDuration(v)
+		require.Error(t, err, v)
+	}
+}
+
+func TestLifecyclePolicies(t *testing.T) {
+	now := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	day := int64(24 * 60 * 60)
+	created := now.Unix() - 40*day
+	items := []string{
+		// due for rotation
+		fmt.Sprintf(`{"id":"%s/secrets/rotate","attributes":{"enabled":true,"created":%d,"updated":%d},"tags":{"rotate-after":"30d","owner":"team-x"}}`, fakeVaultURL, created, created),
+		// not due for rotation yet
+		fmt.Sprintf(`{"id":"%s/secrets/fresh","attributes":{"enabled":true,"created":%d},"tags":{"rotate-after":"8w"}}`, fakeVaultURL, created),
+		// due to be disabled, which supersedes rotation
+		fmt.Sprintf(`{"id":"%s/secrets/disable","attributes":{"enabled":true,"created":%d,"updated":%d},"tags":{"rotate-after":"30d","disable-after":"35d"}}`, fakeVaultURL, created, created),
+		// expiring within its notification period, with an invalid tag
+		fmt.Sprintf(`{"id":"%s/secrets/expiring","attributes":{"enabled":true,"created":%d,"exp":%d},"tags":{"notify-before-expiry":"7d","rotate-after":"soon","owner":"team-y"}}`, fakeVaultURL, created, now.Unix()+2*day),
+		// disabled and managed secrets have no tasks
+		fmt.Sprintf(`{"id":"%s/secrets/disabled","attributes":{"enabled":false,"created":%d},"tags":{"rotate-after":"1d"}}`, fakeVaultURL, created),
+		fmt.Sprintf(`{"id":"%s/secrets/managed","managed":true,"attributes":{"enabled":true,"created":%d},"tags":{"rotate-after":"1d"}}`, fakeVaultURL, created),
+	}
+
+	var mu sync.Mutex
+	var requests []string
+	var setBody map[string]interface{}
+	transport := &challengeTransport{respond: func(req *http.Request) *http.Response {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, req.Method+" "+req.URL.Path)
+		body := ""
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/secrets":
+			body = `{"value":[` + strings.Join(items, ",") + `]}`
+		case req.Method == http.MethodGet:
+			body = fmt.Sprintf(`{"id":"%s%s/1","attributes":{"enabled":true,"updated":%d}}`, fakeVaultURL, req.URL.Path, created)
+		case req.Method == http.MethodPatch:
+			body = fmt.Sprintf(`{"id":"%s%s1","attributes":{"enabled":false}}`, fakeVaultURL, req.URL.Path)
+		case req.Method == http.MethodPut:
+			b, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(b, &setBody))
+			body = fmt.Sprintf(`{"id":"%s%s/2","value":"new","attributes":{"enabled":true}}`, fakeVaultURL, req.URL.Path)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+	}}
+	client, err := NewClient(fakeVaultURL, NewFakeCredential(), &ClientOptions{ClientOptions: azcore.ClientOptions{Transport: transport}})
+	require.NoError(t, err)
+
+	resp, err := client.EvaluateLifecyclePolicies(context.Background(), &EvaluateLifecyclePoliciesOptions{Now: now})
+	require.NoError(t, err)
+	require.Len(t, resp.TagErrors, 1)
+	require.Equal(t, "expiring", resp.TagErrors[0].Name)
+	require.Equal(t, LifecycleTagRotateAfter, resp.TagErrors[0].Tag)
+
+	require.Len(t, resp.Tasks, 3)
+	// earliest due first
+	require.Equal(t, LifecycleActionRotate, resp.Tasks[0].Action)
+	require.Equal(t, "rotate", resp.Tasks[0].Name)
+	require.Equal(t, "team-x", resp.Tasks[0].Owner)
+	require.Equal(t, time.Unix(created, 0).Add(30*24*time.Hour).UTC(), resp.Tasks[0].DueOn.UTC())
+	require.Equal(t, LifecycleActionDisable, resp.Tasks[1].Action)
+	require.Equal(t, "disable", resp.Tasks[1].Name)
+	require.Equal(t, LifecycleActionNotify, resp.Tasks[2].Action)
+	require.Equal(t, "expiring", resp.Tasks[2].Name)
+	require.Equal(t, "team-y", resp.Tasks[2].Owner)
+	require.Contains(t, resp.Tasks[2].Reason, "expires")
+
+	notifyErr := errors.New("mail server unavailable")
+	var notified []string
+	executor := &ClientLifecycleExecutor{
+		Client: client,
+		NewValue: func(ctx context.Context, task LifecycleTask) (string, error) {
+			return "new", nil
+		},
+		Notify: func(ctx context.Context, task LifecycleTask) error {
+			notified = append(notified, task.Owner)
+			return notifyErr
+		},
+	}
+	requests = nil
+	execResp, err := ExecuteLifecycleTasks(context.Background(), resp.Tasks, executor)
+	require.ErrorIs(t, err, notifyErr)
+	require.Len(t, execResp.Results, 3)
+	require.NoError(t, execResp.Results[0].Err)
+	require.NoError(t, execResp.Results[1].Err)
+	require.ErrorIs(t, execResp.Results[2].Err, notifyErr)
+	require.Equal(t, []string{"team-y"}, notified)
+	// rotating kept the tags; disabling checked that the secret is unchanged
+	require.Equal(t, []string{"PUT /secrets/rotate", "GET /secrets/disable/", "PATCH /secrets/disable/"}, requests)
+	require.Equal(t, "new", setBody["value"])
+	require.Equal(t, map[string]interface{}{"rotate-after": "30d", "owner": "team-x"}, setBody["tags"])
+
+	// a task without a handler fails
+	_, err = ExecuteLifecycleTasks(context.Background(), resp.Tasks[:1], &ClientLifecycleExecutor{Client: client})
+	require.ErrorIs(t, err, ErrNoLifecycleHandler)
+
+	// the remaining tasks fail when ctx is done
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	execResp, err = ExecuteLifecycleTasks(canceled, resp.Tasks, LifecycleExecutorFunc(func(ctx context.Context, task LifecycleTask) error {
+		calls++
+		return nil
+	}))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, calls)
+	require.ErrorIs(t, execResp.Results[2].Err, context.Canceled)
+}
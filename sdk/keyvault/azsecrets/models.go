@@ -7,6 +7,7 @@
 package azsecrets
 
 import (
+	"sort"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets/internal/generated"
@@ -199,3 +200,30 @@ func deletedSecretItemFromGenerated(i *generated.DeletedSecretItem) *DeletedSecr
 		ScheduledPurgeDate: i.ScheduledPurgeDate,
 	}
 }
+
+// SecretVersionHistoryEntry describes one version of a secret in a version history.
+type SecretVersionHistoryEntry struct {
+	// Properties are the version's properties.
+	Properties *Properties
+
+	// ChangedTags are the names of tags the version added, removed, or set to a different value than the
+	// previous version, in alphabetical order. It's nil for the oldest version.
+	ChangedTags []string
+}
+
+// changedTags returns the names of tags whose values differ between two versions, in alphabetical order
+func changedTags(previous, current map[string]*string) []string {
+	changed := []string{}
+	for k, v := range current {
+		if p, ok := previous[k]; !ok || (p == nil) != (v == nil) || (p != nil && *p != *v) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range previous {
+		if _, ok := current[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
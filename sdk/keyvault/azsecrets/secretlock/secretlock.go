@@ -0,0 +1,277 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+// Package secretlock implements a lightweight distributed mutex backed by a Key Vault secret. It's intended for
+// coordinating infrequent work such as rotation jobs across replicas of an application, not for high-throughput locking.
+//
+// Key Vault has no conditional writes, so the lock is built on secret versions instead: each attempt to acquire the lock
+// creates a new version of the secret, with an expiry time, and the earliest enabled, unexpired version holds the lock.
+// Losing attempts and releases disable their versions. Every acquisition gets a fencing token greater than the tokens of
+// all earlier acquisitions; pass it to the resources the lock protects so they can reject work from a holder whose lease
+// expired. Expiry is judged by the local clock, so leases should be much longer than the expected clock skew between
+// replicas.
+//
+// Each attempt adds a version to the secret. Use a secret dedicated to the lock.
+package secretlock
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets"
+)
+
+const (
+	// ContentType is the content type of secret versions created by a Lock.
+	ContentType = "application/x-azsecrets-lock"
+
+	// FencingTokenTag is the tag holding a version's fencing token.
+	FencingTokenTag = "fencing-token"
+
+	// OwnerTag is the tag holding the owner of a version.
+	OwnerTag = "owner"
+
+	defaultTTL        = 30 * time.Second
+	defaultRetryDelay = time.Second
+)
+
+// ErrLeaseLost is returned when a lease can't be renewed because it expired or was released.
+var ErrLeaseLost = errors.New("the lease was lost")
+
+// Options contains optional parameters for New.
+type Options struct {
+	// Owner identifies the lock holder. It's stored in the value and tags of the secret versions the Lock creates.
+	// Defaults to a random identifier.
+	Owner string
+
+	// TTL is the duration of a lease. Holders must renew their leases before they expire. Defaults to 30 seconds.
+	TTL time.Duration
+
+	// RetryDelay is how long Acquire waits between attempts. Defaults to 1 second.
+	RetryDelay time.Duration
+}
+
+// Lock is a distributed mutex backed by a Key Vault secret. It's safe for concurrent use, however a single Lock
+// doesn't serialize its callers; every Acquire or TryAcquire call competes for the lock.
+type Lock struct {
+	client     *azsecrets.Client
+	name       string
+	owner      string
+	ttl        time.Duration
+	retryDelay time.Duration
+}
+
+// New creates a Lock backed by the secret with the specified name. The secret is created by the first attempt to
+// acquire the lock. Pass nil to accept the default options.
+func New(client *azsecrets.Client, name string, options *Options) (*Lock, error) {
+	if options == nil {
+		options = &Options{}
+	}
+	l := &Lock{
+		client:     client,
+		name:       name,
+		owner:      options.Owner,
+		ttl:        options.TTL,
+		retryDelay: options.RetryDelay,
+	}
+	if l.owner == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		l.owner = fmt.Sprintf("%x", b)
+	}
+	if l.ttl <= 0 {
+		l.ttl = defaultTTL
+	}
+	if l.retryDelay <= 0 {
+		l.retryDelay = defaultRetryDelay
+	}
+	return l, nil
+}
+
+// Lease is held by the owner of a Lock.
+type Lease struct {
+	// FencingToken is greater than the token of every earlier lease on the lock.
+	FencingToken int64
+
+	// ExpiresOn is the time the lease expires unless it's renewed.
+	ExpiresOn time.Time
+
+	// Version is the version of the secret representing the lease.
+	Version string
+
+	lock *Lock
+}
+
+// Acquire waits until it acquires the lock or ctx is done.
+func (l *Lock) Acquire(ctx context.Context) (*Lease, error) {
+	for {
+		lease, err := l.TryAcquire(ctx)
+		if err != nil || lease != nil {
+			return lease, err
+		}
+		select {
+		case <-time.After(l.retryDelay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// TryAcquire attempts to acquire the lock once. It returns a nil Lease when another owner holds the lock.
+func (l *Lock) TryAcquire(ctx context.Context) (*Lease, error) {
+	versions, err := l.listVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token := maxFencingToken(versions, "") + 1
+
+	expiresOn := time.Now().Add(l.ttl)
+	resp, err := l.client.SetSecret(ctx, l.name, l.owner, &azsecrets.SetSecretOptions{
+		ContentType: to.Ptr(ContentType),
+		Properties: &azsecrets.Properties{
+			ExpiresOn: &expiresOn,
+			Tags: map[string]*string{
+				FencingTokenTag: to.Ptr(strconv.FormatInt(token, 10)),
+				OwnerTag:        to.Ptr(l.owner),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Properties == nil || resp.Properties.Version == nil || resp.Properties.CreatedOn == nil {
+		return nil, errors.New("the created secret version has no version or creation time")
+	}
+	candidate := resp.Properties
+
+	versions, err = l.listVersions(ctx)
+	if err != nil {
+		// try to give up the attempt; the version expires anyway if this fails
+		_ = l.disable(ctx, *candidate.Version)
+		return nil, err
+	}
+	if !holdsLock(versions, candidate, token, time.Now()) {
+		// give up this attempt so the version can't hold the lock later
+		return nil, l.disable(ctx, *candidate.Version)
+	}
+	return &Lease{FencingToken: token, ExpiresOn: expiresOn, Version: *candidate.Version, lock: l}, nil
+}
+
+// Renew extends the lease by the lock's TTL. It returns ErrLeaseLost when the lease has expired or was released.
+func (l *Lease) Renew(ctx context.Context) error {
+	if !time.Now().Before(l.ExpiresOn) {
+		return ErrLeaseLost
+	}
+	expiresOn := time.Now().Add(l.lock.ttl)
+	resp, err := l.lock.client.UpdateSecretProperties(ctx, azsecrets.Properties{
+		Name:      to.Ptr(l.lock.name),
+		Version:   to.Ptr(l.Version),
+		ExpiresOn: &expiresOn,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if resp.Properties == nil || resp.Properties.Enabled == nil || !*resp.Properties.Enabled {
+		// the version was released or given up; extending its expiry doesn't matter
+		return ErrLeaseLost
+	}
+	l.ExpiresOn = expiresOn
+	return nil
+}
+
+// Release releases the lock. Releasing an expired lease has no effect on the lock's current holder.
+func (l *Lease) Release(ctx context.Context) error {
+	return l.lock.disable(ctx, l.Version)
+}
+
+func (l *Lock) disable(ctx context.Context, version string) error {
+	_, err := l.client.UpdateSecretProperties(ctx, azsecrets.Properties{
+		Name:    to.Ptr(l.name),
+		Version: to.Ptr(version),
+		Enabled: to.Ptr(false),
+	}, nil)
+	return err
+}
+
+func (l *Lock) listVersions(ctx context.Context) ([]*azsecrets.Properties, error) {
+	var versions []*azsecrets.Properties
+	pager := l.client.NewListPropertiesOfSecretVersionsPager(l.name, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			if isNotFound(err) {
+				// the secret doesn't exist until the first attempt to acquire the lock
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, s := range page.Secrets {
+			if s != nil && s.Properties != nil {
+				versions = append(versions, s.Properties)
+			}
+		}
+	}
+	return versions, nil
+}
+
+// holdsLock returns whether candidate holds the lock, given the secret's versions. Every other active version must have
+// been created after the candidate, and the candidate's fencing token must be greater than the tokens of all versions
+// created before it, including expired ones. Creation times have a resolution of one second, so two active versions
+// created in the same second both lose; that's safe because their owners try again.
+func holdsLock(versions []*azsecrets.Properties, candidate *azsecrets.Properties, token int64, now time.Time) bool {
+	var earlier []*azsecrets.Properties
+	for _, v := range versions {
+		if v.Version == nil || *v.Version == *candidate.Version {
+			continue
+		}
+		createdLater := v.CreatedOn != nil && v.CreatedOn.After(*candidate.CreatedOn)
+		if !createdLater {
+			if isActive(v, now) {
+				return false
+			}
+			earlier = append(earlier, v)
+		}
+	}
+	return maxFencingToken(earlier, "") < token
+}
+
+// isActive returns whether a version could hold the lock
+func isActive(v *azsecrets.Properties, now time.Time) bool {
+	if v.Enabled != nil && !*v.Enabled {
+		return false
+	}
+	return v.ExpiresOn == nil || v.ExpiresOn.After(now)
+}
+
+// maxFencingToken returns the greatest fencing token of the versions other than the excluded one
+func maxFencingToken(versions []*azsecrets.Properties, exclude string) int64 {
+	max := int64(0)
+	for _, v := range versions {
+		if v.Version != nil && *v.Version == exclude {
+			continue
+		}
+		if t := v.Tags[FencingTokenTag]; t != nil {
+			if n, err := strconv.ParseInt(*t, 10, 64); err == nil && n > max {
+				max = n
+			}
+		}
+	}
+	return max
+}
+
+func isNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,59 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package secretlock
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldsLock(t *testing.T) {
+	now := time.Unix(1000, 0)
+	version := func(name string, created int64, token int64, enabled bool, expiresOn time.Time) *azsecrets.Properties {
+		return &azsecrets.Properties{
+			Version:   to.Ptr(name),
+			CreatedOn: to.Ptr(time.Unix(created, 0)),
+			Enabled:   to.Ptr(enabled),
+			ExpiresOn: &expiresOn,
+			Tags:      map[string]*string{FencingTokenTag: to.Ptr(strconv.FormatInt(token, 10))},
+		}
+	}
+	later := now.Add(time.Minute)
+
+	mine := version("mine", 990, 3, true, later)
+
+	// no competition
+	require.True(t, holdsLock([]*azsecrets.Properties{mine}, mine, 3, now))
+
+	// an earlier active version holds the lock
+	require.False(t, holdsLock([]*azsecrets.Properties{version("other", 980, 2, true, later), mine}, mine, 3, now))
+
+	// so does one created in the same second
+	require.False(t, holdsLock([]*azsecrets.Properties{version("other", 990, 2, true, later), mine}, mine, 3, now))
+
+	// earlier versions which were released or expired don't
+	versions := []*azsecrets.Properties{
+		version("released", 970, 1, false, later),
+		version("expired", 980, 2, true, now),
+		mine,
+		version("later", 995, 3, true, later),
+	}
+	require.True(t, holdsLock(versions, mine, 3, now))
+
+	// the token must be greater than every earlier version's
+	versions = []*azsecrets.Properties{version("stale", 980, 3, true, now), mine}
+	require.False(t, holdsLock(versions, mine, 3, now))
+	require.True(t, holdsLock(versions, mine, 4, now))
+	require.EqualValues(t, 3, maxFencingToken(versions, ""))
+	require.EqualValues(t, 3, maxFencingToken(versions, "stale"))
+	require.Zero(t, maxFencingToken(nil, ""))
+}
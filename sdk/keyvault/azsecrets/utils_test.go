@@ -31,6 +31,12 @@ const (
 var liveVaultURL string
 
 func TestMain(m *testing.M) {
+	os.Exit(m.Run())
+	if os.Getenv(execHelperEnvVar) != "" {
+		// the test binary is the child process of an ExecWithSecrets test
+		execHelper()
+		return
+	}
 	liveVaultURL = strings.TrimSuffix(os.Getenv("AZURE_KEYVAULT_URL"), "/")
 	if liveVaultURL == "" && recording.GetRecordMode() != recording.PlaybackMode {
 		panic("no value for AZURE_KEYVAULT_URL")
@@ -91,7 +97,7 @@ func createClient(t *testing.T) (*Client, error) {
 	require.NoError(t, err)
 
 	options := &ClientOptions{
-		azcore.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
 			Transport: client,
 		},
 	}
@@ -0,0 +1,53 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azsecrets
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileWatermark persists the watermark returned by ListChangedSecretsSince in a file, so a sync job can resume
+// where its previous run stopped.
+type FileWatermark struct {
+	// Path is the path of the file.
+	Path string
+}
+
+// Load reads the watermark. It returns the zero time when the file doesn't exist, so the first
+// ListChangedSecretsSince call lists every secret.
+func (w FileWatermark) Load() (time.Time, error) {
+	b, err := os.ReadFile(w.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(string(b)))
+}
+
+// Save writes the watermark. It replaces the file atomically, so an interrupted Save leaves the previous
+// watermark in place.
+func (w FileWatermark) Save(watermark time.Time) error {
+	f, err := os.CreateTemp(filepath.Dir(w.Path), filepath.Base(w.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(watermark.UTC().Format(time.RFC3339Nano) + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), w.Path)
+}
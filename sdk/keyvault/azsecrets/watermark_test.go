@@ -0,0 +1,35 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azsecrets
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWatermark(t *testing.T) {
+	w := FileWatermark{Path: filepath.Join(t.TempDir(), "watermark")}
+
+	loaded, err := w.Load()
+	require.NoError(t, err)
+	require.True(t, loaded.IsZero())
+
+	watermark := time.Date(2022, 6, 1, 12, 30, 15, 0, time.UTC)
+	require.NoError(t, w.Save(watermark))
+	require.NoError(t, w.Save(watermark.Add(time.Second)))
+
+	loaded, err = w.Load()
+	require.NoError(t, err)
+	require.True(t, watermark.Add(time.Second).Equal(loaded))
+
+	matches, err := filepath.Glob(w.Path + ".tmp*")
+	require.NoError(t, err)
+	require.Empty(t, matches)
+}
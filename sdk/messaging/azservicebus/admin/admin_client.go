@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
@@ -129,16 +130,7 @@ func (ac *Client) newPagerFunc(baseFragment string, maxPageSize int32, lenV func
 			return nil, nil
 		}
 
-		url := baseFragment + "?"
-		if maxPageSize > 0 {
-			url += fmt.Sprintf("&$top=%d", maxPageSize)
-		}
-
-		if skip > 0 {
-			url += fmt.Sprintf("&$skip=%d", skip)
-		}
-
-		resp, err := ac.em.Get(ctx, url, pv)
+		resp, err := ac.em.Get(ctx, pageURL(baseFragment, maxPageSize, skip, ""), pv)
 
 		if err != nil {
 			eof = true
@@ -165,10 +157,31 @@ type entityPager[TFeed interface{ Items() []T }, T any, TFinal any] struct {
 	baseFragment string
 	em           atom.EntityManager
 
+	// filter is an OData expression sent as $filter, if set
+	filter string
+
 	eof  bool
 	skip int32
 }
 
+// pageURL gets the URL of the page of an ATOM resource that starts after skip entities
+func pageURL(baseFragment string, maxPageSize int32, skip int32, filter string) string {
+	u := baseFragment + "?"
+	if maxPageSize > 0 {
+		u += fmt.Sprintf("&$top=%d", maxPageSize)
+	}
+
+	if skip > 0 {
+		u += fmt.Sprintf("&$skip=%d", skip)
+	}
+
+	if filter != "" {
+		u += "&$filter=" + url.QueryEscape(filter)
+	}
+
+	return u
+}
+
 func (ep *entityPager[_, _, _]) More() bool {
 	return !ep.eof
 }
@@ -178,17 +191,8 @@ func (ep *entityPager[TFeed, T, TOutput]) Fetcher(ctx context.Context) ([]TOutpu
 		return nil, nil
 	}
 
-	url := ep.baseFragment + "?"
-	if ep.maxPageSize > 0 {
-		url += fmt.Sprintf("&$top=%d", ep.maxPageSize)
-	}
-
-	if ep.skip > 0 {
-		url += fmt.Sprintf("&$skip=%d", ep.skip)
-	}
-
 	var pv *TFeed
-	_, err := ep.em.Get(ctx, url, &pv)
+	_, err := ep.em.Get(ctx, pageURL(ep.baseFragment, ep.maxPageSize, ep.skip, ep.filter), &pv)
 
 	if err != nil {
 		ep.eof = true
@@ -0,0 +1,327 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+const (
+	skuBasic   = "Basic"
+	skuPremium = "Premium"
+
+	// partitioned entities in Basic and Standard namespaces report a size 16 times the size they were created with
+	partitionCount = 16
+
+	maxNonPremiumSizeInMegabytes = 5 * 1024
+)
+
+// premiumSizesInMegabytes are the sizes Premium namespaces accept for MaxSizeInMegabytes, in ascending order
+var premiumSizesInMegabytes = []int32{1024, 2048, 3072, 4096, 5120, 10240, 20480, 40960, 81920}
+
+// CloneEntity identifies the entity Client.CloneEntityTo copies. Set Queue to copy a queue, Topic to copy a
+// topic, or Topic and Subscription to copy a subscription.
+type CloneEntity struct {
+	Queue        string
+	Topic        string
+	Subscription string
+}
+
+// CloneAdjustment describes a property Client.CloneEntityTo changed because the destination namespace
+// doesn't support the source entity's value.
+type CloneAdjustment struct {
+	// Property is the name of the property, for instance "EnablePartitioning".
+	Property string
+
+	// SourceValue is the property's value on the source entity.
+	SourceValue string
+
+	// DestinationValue is the property's value on the destination entity.
+	DestinationValue string
+
+	// Reason explains why the value changed.
+	Reason string
+}
+
+// CloneEntityToOptions contains optional parameters for Client.CloneEntityTo
+type CloneEntityToOptions struct {
+	// DryRun computes the destination entity's properties and the adjustments to them without
+	// creating the entity.
+	DryRun bool
+}
+
+// CloneEntityToResponse contains the response fields for Client.CloneEntityTo
+type CloneEntityToResponse struct {
+	// SourceSKU is the SKU of the source namespace, for instance "Standard".
+	SourceSKU string
+
+	// DestinationSKU is the SKU of the destination namespace, for instance "Premium".
+	DestinationSKU string
+
+	// Adjustments lists the properties that differ between the source and destination entities.
+	Adjustments []CloneAdjustment
+
+	// Queue contains the destination queue's properties when cloning a queue.
+	Queue *QueueProperties
+
+	// Topic contains the destination topic's properties when cloning a topic.
+	Topic *TopicProperties
+
+	// Subscription contains the destination subscription's properties when cloning a subscription.
+	Subscription *SubscriptionProperties
+}
+
+// CloneEntityTo creates a copy of a queue, topic or subscription in the namespace of dest, for instance
+// to migrate from a Standard to a Premium namespace. Properties the destination namespace's SKU doesn't
+// support, like partitioning in Premium or sessions in Basic, are adjusted and reported in the response's
+// Adjustments.
+// Authorization rules are copied, including their keys. Subscription rules aren't copied and the topic of
+// a subscription must exist in the destination namespace. Messages are never copied.
+func (ac *Client) CloneEntityTo(ctx context.Context, dest *Client, entity CloneEntity, options *CloneEntityToOptions) (CloneEntityToResponse, error) {
+	if options == nil {
+		options = &CloneEntityToOptions{}
+	}
+
+	if dest == nil {
+		return CloneEntityToResponse{}, errors.New("dest must not be nil")
+	}
+
+	srcNS, err := ac.GetNamespaceProperties(ctx, nil)
+
+	if err != nil {
+		return CloneEntityToResponse{}, err
+	}
+
+	destNS, err := dest.GetNamespaceProperties(ctx, nil)
+
+	if err != nil {
+		return CloneEntityToResponse{}, err
+	}
+
+	resp := CloneEntityToResponse{
+		SourceSKU:      srcNS.SKU,
+		DestinationSKU: destNS.SKU,
+	}
+
+	switch {
+	case entity.Queue != "" && entity.Topic == "" && entity.Subscription == "":
+		src, err := ac.GetQueue(ctx, entity.Queue, nil)
+
+		if err != nil {
+			return CloneEntityToResponse{}, err
+		}
+
+		if src == nil {
+			return CloneEntityToResponse{}, fmt.Errorf("queue %s doesn't exist", entity.Queue)
+		}
+
+		props := src.QueueProperties
+		resp.Adjustments = adjustQueueProperties(&props, resp.SourceSKU, resp.DestinationSKU)
+		resp.Queue = &props
+
+		if !options.DryRun {
+			created, err := dest.CreateQueue(ctx, entity.Queue, &CreateQueueOptions{Properties: &props})
+
+			if err != nil {
+				return resp, err
+			}
+
+			resp.Queue = &created.QueueProperties
+		}
+	case entity.Topic != "" && entity.Queue == "" && entity.Subscription == "":
+		if resp.DestinationSKU == skuBasic {
+			return resp, errors.New("Basic namespaces don't support topics")
+		}
+
+		src, err := ac.GetTopic(ctx, entity.Topic, nil)
+
+		if err != nil {
+			return CloneEntityToResponse{}, err
+		}
+
+		if src == nil {
+			return CloneEntityToResponse{}, fmt.Errorf("topic %s doesn't exist", entity.Topic)
+		}
+
+		props := src.TopicProperties
+		resp.Adjustments = adjustTopicProperties(&props, resp.SourceSKU, resp.DestinationSKU)
+		resp.Topic = &props
+
+		if !options.DryRun {
+			created, err := dest.CreateTopic(ctx, entity.Topic, &CreateTopicOptions{Properties: &props})
+
+			if err != nil {
+				return resp, err
+			}
+
+			resp.Topic = &created.TopicProperties
+		}
+	case entity.Topic != "" && entity.Subscription != "" && entity.Queue == "":
+		if resp.DestinationSKU == skuBasic {
+			return resp, errors.New("Basic namespaces don't support subscriptions")
+		}
+
+		src, err := ac.GetSubscription(ctx, entity.Topic, entity.Subscription, nil)
+
+		if err != nil {
+			return CloneEntityToResponse{}, err
+		}
+
+		if src == nil {
+			return CloneEntityToResponse{}, fmt.Errorf("subscription %s/%s doesn't exist", entity.Topic, entity.Subscription)
+		}
+
+		props := src.SubscriptionProperties
+		resp.Subscription = &props
+
+		if !options.DryRun {
+			created, err := dest.CreateSubscription(ctx, entity.Topic, entity.Subscription, &CreateSubscriptionOptions{Properties: &props})
+
+			if err != nil {
+				return resp, err
+			}
+
+			resp.Subscription = &created.SubscriptionProperties
+		}
+	default:
+		return CloneEntityToResponse{}, errors.New("entity must specify a Queue, a Topic, or a Topic and Subscription")
+	}
+
+	return resp, nil
+}
+
+// adjustQueueProperties changes props to values a namespace with the destination SKU supports
+func adjustQueueProperties(props *QueueProperties, srcSKU string, destSKU string) []CloneAdjustment {
+	var adjustments []CloneAdjustment
+
+	adjustments = append(adjustments, adjustPartitioning(&props.EnablePartitioning, &props.MaxSizeInMegabytes, srcSKU, destSKU)...)
+	adjustments = append(adjustments, adjustMaxMessageSize(&props.MaxMessageSizeInKilobytes, destSKU)...)
+
+	if destSKU == skuBasic {
+		adjustments = append(adjustments, clearBasicUnsupported(&props.RequiresSession, "RequiresSession", "sessions")...)
+		adjustments = append(adjustments, clearBasicUnsupported(&props.RequiresDuplicateDetection, "RequiresDuplicateDetection", "duplicate detection")...)
+		adjustments = append(adjustments, clearBasicUnsupported(&props.ForwardTo, "ForwardTo", "auto-forwarding")...)
+		adjustments = append(adjustments, clearBasicUnsupported(&props.ForwardDeadLetteredMessagesTo, "ForwardDeadLetteredMessagesTo", "auto-forwarding")...)
+	}
+
+	return adjustments
+}
+
+// adjustTopicProperties changes props to values a namespace with the destination SKU supports
+func adjustTopicProperties(props *TopicProperties, srcSKU string, destSKU string) []CloneAdjustment {
+	var adjustments []CloneAdjustment
+
+	adjustments = append(adjustments, adjustPartitioning(&props.EnablePartitioning, &props.MaxSizeInMegabytes, srcSKU, destSKU)...)
+	adjustments = append(adjustments, adjustMaxMessageSize(&props.MaxMessageSizeInKilobytes, destSKU)...)
+
+	return adjustments
+}
+
+// adjustPartitioning disables partitioning for Premium namespaces and converts the entity's size,
+// which for partitioned entities includes all partitions, to a size the destination accepts.
+func adjustPartitioning(enablePartitioning **bool, maxSizeInMegabytes **int32, srcSKU string, destSKU string) []CloneAdjustment {
+	var adjustments []CloneAdjustment
+	partitioned := *enablePartitioning != nil && **enablePartitioning
+
+	if partitioned && destSKU == skuPremium {
+		adjustments = append(adjustments, CloneAdjustment{
+			Property:         "EnablePartitioning",
+			SourceValue:      "true",
+			DestinationValue: "false",
+			Reason:           "Premium namespaces don't support partitioned entities",
+		})
+		*enablePartitioning = to.Ptr(false)
+	}
+
+	if *maxSizeInMegabytes == nil {
+		return adjustments
+	}
+
+	srcSize := **maxSizeInMegabytes
+	size := srcSize
+
+	// the size a partitioned entity is created with is the size of one partition
+	if partitioned && srcSKU != skuPremium {
+		size /= partitionCount
+	}
+
+	reason := ""
+
+	if destSKU == skuPremium {
+		if partitioned && srcSKU != skuPremium {
+			// keep the capacity of all the source entity's partitions
+			size = srcSize
+		}
+
+		premiumSize := premiumSizesInMegabytes[len(premiumSizesInMegabytes)-1]
+
+		for _, s := range premiumSizesInMegabytes {
+			if s >= size {
+				premiumSize = s
+				break
+			}
+		}
+
+		if premiumSize != srcSize {
+			reason = "Premium namespaces support only specific sizes up to 80 GB"
+		}
+
+		size = premiumSize
+	} else if size > maxNonPremiumSizeInMegabytes {
+		size = maxNonPremiumSizeInMegabytes
+		reason = fmt.Sprintf("%s namespaces support sizes up to 5 GB per entity or partition", destSKU)
+	}
+
+	if reason != "" {
+		adjustments = append(adjustments, CloneAdjustment{
+			Property:         "MaxSizeInMegabytes",
+			SourceValue:      fmt.Sprint(srcSize),
+			DestinationValue: fmt.Sprint(size),
+			Reason:           reason,
+		})
+	}
+
+	*maxSizeInMegabytes = &size
+	return adjustments
+}
+
+// adjustMaxMessageSize removes the max message size for namespaces that aren't Premium
+func adjustMaxMessageSize(maxMessageSizeInKilobytes **int64, destSKU string) []CloneAdjustment {
+	if *maxMessageSizeInKilobytes == nil || destSKU == skuPremium {
+		return nil
+	}
+
+	adjustment := CloneAdjustment{
+		Property:         "MaxMessageSizeInKilobytes",
+		SourceValue:      fmt.Sprint(**maxMessageSizeInKilobytes),
+		DestinationValue: "<nil>",
+		Reason:           fmt.Sprintf("%s namespaces don't support configuring the maximum message size", destSKU),
+	}
+
+	*maxMessageSizeInKilobytes = nil
+	return []CloneAdjustment{adjustment}
+}
+
+// clearBasicUnsupported removes the value of a property that Basic namespaces don't support
+func clearBasicUnsupported[T comparable](value **T, property string, feature string) []CloneAdjustment {
+	var zero T
+
+	if *value == nil || **value == zero {
+		return nil
+	}
+
+	adjustment := CloneAdjustment{
+		Property:         property,
+		SourceValue:      fmt.Sprint(**value),
+		DestinationValue: "<nil>",
+		Reason:           fmt.Sprintf("Basic namespaces don't support %s", feature),
+	}
+
+	*value = nil
+	return []CloneAdjustment{adjustment}
+}
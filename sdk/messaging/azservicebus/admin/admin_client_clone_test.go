@@ -0,0 +1,69 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package admin
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjustQueueProperties_StandardToPremium(t *testing.T) {
+	props := &QueueProperties{
+		EnablePartitioning: to.Ptr(true),
+		MaxSizeInMegabytes: to.Ptr(int32(16 * 1024)),
+		RequiresSession:    to.Ptr(true),
+	}
+
+	adjustments := adjustQueueProperties(props, "Standard", "Premium")
+
+	require.Equal(t, []CloneAdjustment{
+		{Property: "EnablePartitioning", SourceValue: "true", DestinationValue: "false", Reason: "Premium namespaces don't support partitioned entities"},
+		{Property: "MaxSizeInMegabytes", SourceValue: "16384", DestinationValue: "20480", Reason: "Premium namespaces support only specific sizes up to 80 GB"},
+	}, adjustments)
+
+	require.False(t, *props.EnablePartitioning)
+	require.EqualValues(t, 20480, *props.MaxSizeInMegabytes)
+	require.True(t, *props.RequiresSession)
+}
+
+func TestAdjustQueueProperties_StandardToStandard(t *testing.T) {
+	props := &QueueProperties{
+		EnablePartitioning: to.Ptr(true),
+		MaxSizeInMegabytes: to.Ptr(int32(16 * 1024)),
+	}
+
+	// a partitioned entity is created with the size of a single partition
+	require.Empty(t, adjustQueueProperties(props, "Standard", "Standard"))
+	require.True(t, *props.EnablePartitioning)
+	require.EqualValues(t, 1024, *props.MaxSizeInMegabytes)
+}
+
+func TestAdjustQueueProperties_PremiumToBasic(t *testing.T) {
+	props := &QueueProperties{
+		MaxSizeInMegabytes:         to.Ptr(int32(81920)),
+		MaxMessageSizeInKilobytes:  to.Ptr(int64(102400)),
+		RequiresSession:            to.Ptr(true),
+		RequiresDuplicateDetection: to.Ptr(false),
+		ForwardTo:                  to.Ptr("other"),
+	}
+
+	adjustments := adjustQueueProperties(props, "Premium", "Basic")
+
+	var adjusted []string
+
+	for _, a := range adjustments {
+		adjusted = append(adjusted, a.Property)
+	}
+
+	require.Equal(t, []string{"MaxSizeInMegabytes", "MaxMessageSizeInKilobytes", "RequiresSession", "ForwardTo"}, adjusted)
+	require.EqualValues(t, 5120, *props.MaxSizeInMegabytes)
+	require.Nil(t, props.MaxMessageSizeInKilobytes)
+	require.Nil(t, props.RequiresSession)
+	require.Nil(t, props.ForwardTo)
+
+	// false is already what Basic namespaces support
+	require.False(t, *props.RequiresDuplicateDetection)
+}
@@ -248,8 +248,17 @@ func (ac *Client) DeleteQueue(ctx context.Context, queueName string, options *De
 
 // ListQueuesOptions can be used to configure the ListQueues method.
 type ListQueuesOptions struct {
-	// MaxPageSize is the maximum size of each page of results.
+	// MaxPageSize is the maximum size of each page of results. It's sent to the service as $top.
 	MaxPageSize int32
+
+	// Skip is the number of queues to skip before the first page, sent to the service as $skip, to
+	// continue listing from an offset.
+	Skip int32
+
+	// Filter is an OData expression, sent to the service as $filter, that selects the queues to list,
+	// such as "startswith(path, 'orders') eq true". The queues are filtered by the service, so only the
+	// ones that match are transferred.
+	Filter string
 }
 
 // ListQueuesResponse contains the response fields for QueuePager.PageResponse
@@ -265,17 +274,17 @@ type QueueItem struct {
 
 // NewListQueuesPager creates a pager that can be used to list queues.
 func (ac *Client) NewListQueuesPager(options *ListQueuesOptions) *runtime.Pager[ListQueuesResponse] {
-	var pageSize int32
-
-	if options != nil {
-		pageSize = options.MaxPageSize
+	if options == nil {
+		options = &ListQueuesOptions{}
 	}
 
 	ep := &entityPager[atom.QueueFeed, atom.QueueEnvelope, QueueItem]{
 		convertFn:    newQueueItem,
 		baseFragment: "/$Resources/Queues",
-		maxPageSize:  pageSize,
+		maxPageSize:  options.MaxPageSize,
 		em:           ac.em,
+		filter:       options.Filter,
+		skip:         options.Skip,
 	}
 
 	return runtime.NewPager(runtime.PagingHandler[ListQueuesResponse]{
@@ -305,98 +305,18 @@ func (ac *Client) DeleteRule(ctx context.Context, topicName string, subscription
 func (ac *Client) createOrUpdateRule(ctx context.Context, topicName string, subscriptionName string, putProps RuleProperties, creating bool) (*RuleProperties, *http.Response, error) {
 	ruleDesc := atom.RuleDescription{}
 
-	theirFilter := putProps.Filter
-
-	if theirFilter != nil {
-		switch actualFilter := theirFilter.(type) {
-		case *FalseFilter:
-			ruleDesc.Filter = &atom.FilterDescription{
-				Type:          "FalseFilter",
-				SQLExpression: to.Ptr("1=0"),
-			}
-		case *TrueFilter:
-			ruleDesc.Filter = &atom.FilterDescription{
-				Type:          "TrueFilter",
-				SQLExpression: to.Ptr("1=1"),
-			}
-		case *SQLFilter:
-			params, err := publicSQLParametersToInternal(actualFilter.Parameters)
-
-			if err != nil {
-				return nil, nil, err
-			}
+	filter, err := newFilterDescription(putProps.Filter)
 
-			ruleDesc.Filter = &atom.FilterDescription{
-				Type:          "SqlFilter",
-				SQLExpression: &actualFilter.Expression,
-				Parameters:    params,
-			}
-		case *CorrelationFilter:
-			appProps, err := publicSQLParametersToInternal(actualFilter.ApplicationProperties)
-
-			if err != nil {
-				return nil, nil, err
-			}
-
-			ruleDesc.Filter = &atom.FilterDescription{
-				Type: "CorrelationFilter",
-				CorrelationFilter: atom.CorrelationFilter{
-					ContentType:      actualFilter.ContentType,
-					CorrelationID:    actualFilter.CorrelationID,
-					MessageID:        actualFilter.MessageID,
-					ReplyTo:          actualFilter.ReplyTo,
-					ReplyToSessionID: actualFilter.ReplyToSessionID,
-					SessionID:        actualFilter.SessionID,
-					Label:            actualFilter.Subject,
-					To:               actualFilter.To,
-					Properties:       appProps,
-				},
-			}
-		case *UnknownRuleFilter:
-			fd, err := convertUnknownRuleFilterToFilterDescription(actualFilter)
-
-			if err != nil {
-				return nil, nil, err
-			}
-
-			ruleDesc.Filter = fd
-		default:
-			return nil, nil, fmt.Errorf("invalid type ('%T') for Rule.Filter", theirFilter)
-		}
-	} else {
-		ruleDesc.Filter = &atom.FilterDescription{
-			Type:          "TrueFilter",
-			SQLExpression: to.Ptr("1=1"),
-		}
+	if err != nil {
+		return nil, nil, err
 	}
 
-	theirAction := putProps.Action
-
-	if theirAction != nil {
-		switch actualAction := theirAction.(type) {
-		case *SQLAction:
-			params, err := publicSQLParametersToInternal(actualAction.Parameters)
+	ruleDesc.Filter = filter
 
-			if err != nil {
-				return nil, nil, err
-			}
+	ruleDesc.Action, err = newActionDescription(putProps.Action)
 
-			ruleDesc.Action = &atom.ActionDescription{
-				Type:          "SqlRuleAction",
-				SQLExpression: actualAction.Expression,
-				Parameters:    params,
-			}
-		case *UnknownRuleAction:
-			ad, err := convertUnknownRuleActionToActionDescription(actualAction)
-
-			if err != nil {
-				return nil, nil, err
-			}
-
-			ruleDesc.Action = ad
-		default:
-			return nil, nil, fmt.Errorf("invalid type ('%T') for Rule.Action", theirAction)
-		}
+	if err != nil {
+		return nil, nil, err
 	}
 
 	ruleDesc.Name = "$Default"
@@ -426,6 +346,92 @@ func (ac *Client) createOrUpdateRule(ctx context.Context, topicName string, subs
 	return respProps, httpResp, err
 }
 
+// newFilterDescription converts a RuleFilter to its ATOM form. A nil filter is a TrueFilter.
+func newFilterDescription(filter RuleFilter) (*atom.FilterDescription, error) {
+	if filter == nil {
+		return &atom.FilterDescription{
+			Type:          "TrueFilter",
+			SQLExpression: to.Ptr("1=1"),
+		}, nil
+	}
+
+	switch actualFilter := filter.(type) {
+	case *FalseFilter:
+		return &atom.FilterDescription{
+			Type:          "FalseFilter",
+			SQLExpression: to.Ptr("1=0"),
+		}, nil
+	case *TrueFilter:
+		return &atom.FilterDescription{
+			Type:          "TrueFilter",
+			SQLExpression: to.Ptr("1=1"),
+		}, nil
+	case *SQLFilter:
+		params, err := publicSQLParametersToInternal(actualFilter.Parameters)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &atom.FilterDescription{
+			Type:          "SqlFilter",
+			SQLExpression: &actualFilter.Expression,
+			Parameters:    params,
+		}, nil
+	case *CorrelationFilter:
+		appProps, err := publicSQLParametersToInternal(actualFilter.ApplicationProperties)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &atom.FilterDescription{
+			Type: "CorrelationFilter",
+			CorrelationFilter: atom.CorrelationFilter{
+				ContentType:      actualFilter.ContentType,
+				CorrelationID:    actualFilter.CorrelationID,
+				MessageID:        actualFilter.MessageID,
+				ReplyTo:          actualFilter.ReplyTo,
+				ReplyToSessionID: actualFilter.ReplyToSessionID,
+				SessionID:        actualFilter.SessionID,
+				Label:            actualFilter.Subject,
+				To:               actualFilter.To,
+				Properties:       appProps,
+			},
+		}, nil
+	case *UnknownRuleFilter:
+		return convertUnknownRuleFilterToFilterDescription(actualFilter)
+	default:
+		return nil, fmt.Errorf("invalid type ('%T') for Rule.Filter", filter)
+	}
+}
+
+// newActionDescription converts a RuleAction to its ATOM form. A nil action has no ATOM form.
+func newActionDescription(action RuleAction) (*atom.ActionDescription, error) {
+	if action == nil {
+		return nil, nil
+	}
+
+	switch actualAction := action.(type) {
+	case *SQLAction:
+		params, err := publicSQLParametersToInternal(actualAction.Parameters)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &atom.ActionDescription{
+			Type:          "SqlRuleAction",
+			SQLExpression: actualAction.Expression,
+			Parameters:    params,
+		}, nil
+	case *UnknownRuleAction:
+		return convertUnknownRuleActionToActionDescription(actualAction)
+	default:
+		return nil, fmt.Errorf("invalid type ('%T') for Rule.Action", action)
+	}
+}
+
 func (ac *Client) newRuleProperties(env *atom.RuleEnvelope) (*RuleProperties, error) {
 	desc := env.Content.RuleDescription
 
@@ -0,0 +1,204 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const defaultCatchAllRuleName = "replace-rules-catch-all"
+
+// ReplaceSubscriptionRulesOptions contains optional parameters for Client.ReplaceSubscriptionRules
+type ReplaceSubscriptionRulesOptions struct {
+	// UseTemporaryCatchAll adds a rule that matches every message before changing any other rule,
+	// and deletes it after the other changes. The subscription can receive messages that neither
+	// the old nor the new rules match while the rules change, but it doesn't miss any that either
+	// would match.
+	UseTemporaryCatchAll bool
+
+	// CatchAllRuleName is the name of the temporary rule. Defaults to "replace-rules-catch-all".
+	CatchAllRuleName string
+}
+
+// ReplaceSubscriptionRulesResponse contains the response fields for Client.ReplaceSubscriptionRules
+type ReplaceSubscriptionRulesResponse struct {
+	// Created are the names of the rules that were added.
+	Created []string
+
+	// Updated are the names of the rules whose filter or action changed.
+	Updated []string
+
+	// Deleted are the names of the rules that were removed.
+	Deleted []string
+}
+
+// ReplaceSubscriptionRules makes a subscription's rules match rules. It adds the new rules first, then
+// updates the changed ones and finally deletes the rules that aren't in rules, so during the change the
+// subscription receives the messages either the old or the new rules match. Rules that are already the
+// same aren't touched.
+//
+// If a change fails, ReplaceSubscriptionRules returns the error and the changes made so far. The subscription
+// can be left with the temporary catch-all rule, which calling ReplaceSubscriptionRules again removes.
+func (ac *Client) ReplaceSubscriptionRules(ctx context.Context, topicName string, subscriptionName string, rules []RuleProperties, options *ReplaceSubscriptionRulesOptions) (ReplaceSubscriptionRulesResponse, error) {
+	if options == nil {
+		options = &ReplaceSubscriptionRulesOptions{}
+	}
+
+	catchAllName := options.CatchAllRuleName
+
+	if catchAllName == "" {
+		catchAllName = defaultCatchAllRuleName
+	}
+
+	for _, r := range rules {
+		if r.Name == "" {
+			return ReplaceSubscriptionRulesResponse{}, errors.New("every rule needs a name")
+		}
+
+		if options.UseTemporaryCatchAll && r.Name == catchAllName {
+			return ReplaceSubscriptionRulesResponse{}, fmt.Errorf("the rule name %q is reserved for the temporary catch-all rule", catchAllName)
+		}
+	}
+
+	var current []RuleProperties
+	pager := ac.NewListRulesPager(topicName, subscriptionName, nil)
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+
+		if err != nil {
+			return ReplaceSubscriptionRulesResponse{}, err
+		}
+
+		current = append(current, page.Rules...)
+	}
+
+	create, update, del := diffRules(current, rules)
+	var resp ReplaceSubscriptionRulesResponse
+
+	if len(create)+len(update)+len(del) == 0 {
+		return resp, nil
+	}
+
+	if options.UseTemporaryCatchAll && !hasRule(current, catchAllName) {
+		if _, err := ac.CreateRule(ctx, topicName, subscriptionName, &CreateRuleOptions{
+			Name:   &catchAllName,
+			Filter: &TrueFilter{},
+		}); err != nil {
+			return resp, err
+		}
+	}
+
+	for _, r := range create {
+		name := r.Name
+
+		if _, err := ac.CreateRule(ctx, topicName, subscriptionName, &CreateRuleOptions{Name: &name, Filter: r.Filter, Action: r.Action}); err != nil {
+			return resp, err
+		}
+
+		resp.Created = append(resp.Created, r.Name)
+	}
+
+	for _, r := range update {
+		if _, err := ac.UpdateRule(ctx, topicName, subscriptionName, r); err != nil {
+			return resp, err
+		}
+
+		resp.Updated = append(resp.Updated, r.Name)
+	}
+
+	for _, name := range del {
+		if options.UseTemporaryCatchAll && name == catchAllName {
+			// deleted last
+			continue
+		}
+
+		if _, err := ac.DeleteRule(ctx, topicName, subscriptionName, name, nil); err != nil {
+			return resp, err
+		}
+
+		resp.Deleted = append(resp.Deleted, name)
+	}
+
+	if options.UseTemporaryCatchAll {
+		if _, err := ac.DeleteRule(ctx, topicName, subscriptionName, catchAllName, nil); err != nil {
+			return resp, err
+		}
+	}
+
+	return resp, nil
+}
+
+// diffRules returns the rules to create and update, and the names of the rules to delete, to change
+// current to desired.
+func diffRules(current []RuleProperties, desired []RuleProperties) (create []RuleProperties, update []RuleProperties, del []string) {
+	existing := map[string]RuleProperties{}
+
+	for _, r := range current {
+		existing[r.Name] = r
+	}
+
+	wanted := map[string]bool{}
+
+	for _, r := range desired {
+		wanted[r.Name] = true
+		c, ok := existing[r.Name]
+
+		if !ok {
+			create = append(create, r)
+		} else if !reflect.DeepEqual(normalizeRule(c), normalizeRule(r)) {
+			update = append(update, r)
+		}
+	}
+
+	for _, r := range current {
+		if !wanted[r.Name] {
+			del = append(del, r.Name)
+		}
+	}
+
+	sort.Strings(del)
+	return create, update, del
+}
+
+// normalizeRule makes equivalent rules comparable with reflect.DeepEqual. Empty parameter maps are
+// the same as nil ones.
+func normalizeRule(r RuleProperties) RuleProperties {
+	emptyToNil := func(m map[string]interface{}) map[string]interface{} {
+		if len(m) == 0 {
+			return nil
+		}
+
+		return m
+	}
+
+	switch f := r.Filter.(type) {
+	case *SQLFilter:
+		r.Filter = &SQLFilter{Expression: f.Expression, Parameters: emptyToNil(f.Parameters)}
+	case *CorrelationFilter:
+		cp := *f
+		cp.ApplicationProperties = emptyToNil(f.ApplicationProperties)
+		r.Filter = &cp
+	}
+
+	if a, ok := r.Action.(*SQLAction); ok {
+		r.Action = &SQLAction{Expression: a.Expression, Parameters: emptyToNil(a.Parameters)}
+	}
+
+	return r
+}
+
+func hasRule(rules []RuleProperties, name string) bool {
+	for _, r := range rules {
+		if r.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,38 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package admin
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffRules(t *testing.T) {
+	current := []RuleProperties{
+		{Name: "$Default", Filter: &TrueFilter{}},
+		{Name: "orders", Filter: &SQLFilter{Expression: "kind = 'order'", Parameters: map[string]interface{}{}}},
+		{Name: "priority", Filter: &CorrelationFilter{Subject: to.Ptr("high")}},
+		{Name: "stale", Filter: &FalseFilter{}},
+	}
+
+	desired := []RuleProperties{
+		// an empty parameter map is the same as none
+		{Name: "orders", Filter: &SQLFilter{Expression: "kind = 'order'"}},
+		{Name: "priority", Filter: &CorrelationFilter{Subject: to.Ptr("urgent")}},
+		{Name: "refunds", Filter: &SQLFilter{Expression: "kind = 'refund'"}, Action: &SQLAction{Expression: "SET seen = 1"}},
+	}
+
+	create, update, del := diffRules(current, desired)
+
+	require.Equal(t, []RuleProperties{desired[2]}, create)
+	require.Equal(t, []RuleProperties{desired[1]}, update)
+	require.Equal(t, []string{"$Default", "stale"}, del)
+
+	create, update, del = diffRules(desired, desired)
+	require.Empty(t, create)
+	require.Empty(t, update)
+	require.Empty(t, del)
+}
@@ -101,17 +101,25 @@ type CreateSubscriptionResponse struct {
 type CreateSubscriptionOptions struct {
 	// Properties for the subscription.
 	Properties *SubscriptionProperties
+
+	// DefaultRule, if set, is created with the subscription in place of the $Default rule, which matches
+	// every message. Unlike replacing the $Default rule after creating the subscription, this ensures the
+	// subscription never receives a message the rule doesn't match. If DefaultRule.Name is empty, the rule
+	// is named $Default.
+	DefaultRule *RuleProperties
 }
 
 // CreateSubscription creates a subscription to a topic with configurable properties
 func (ac *Client) CreateSubscription(ctx context.Context, topicName string, subscriptionName string, options *CreateSubscriptionOptions) (CreateSubscriptionResponse, error) {
 	var properties *SubscriptionProperties
+	var defaultRule *RuleProperties
 
 	if options != nil {
 		properties = options.Properties
+		defaultRule = options.DefaultRule
 	}
 
-	newProps, _, err := ac.createOrUpdateSubscriptionImpl(ctx, topicName, subscriptionName, properties, true)
+	newProps, _, err := ac.createOrUpdateSubscriptionImpl(ctx, topicName, subscriptionName, properties, defaultRule, true)
 
 	if err != nil {
 		return CreateSubscriptionResponse{}, err
@@ -306,7 +314,7 @@ type UpdateSubscriptionOptions struct {
 
 // UpdateSubscription updates an existing subscription.
 func (ac *Client) UpdateSubscription(ctx context.Context, topicName string, subscriptionName string, properties SubscriptionProperties, options *UpdateSubscriptionOptions) (UpdateSubscriptionResponse, error) {
-	newProps, _, err := ac.createOrUpdateSubscriptionImpl(ctx, topicName, subscriptionName, &properties, false)
+	newProps, _, err := ac.createOrUpdateSubscriptionImpl(ctx, topicName, subscriptionName, &properties, nil, false)
 
 	if err != nil {
 		return UpdateSubscriptionResponse{}, err
@@ -333,12 +341,16 @@ func (ac *Client) DeleteSubscription(ctx context.Context, topicName string, subs
 	return DeleteSubscriptionResponse{}, err
 }
 
-func (ac *Client) createOrUpdateSubscriptionImpl(ctx context.Context, topicName string, subscriptionName string, props *SubscriptionProperties, creating bool) (*SubscriptionProperties, *http.Response, error) {
+func (ac *Client) createOrUpdateSubscriptionImpl(ctx context.Context, topicName string, subscriptionName string, props *SubscriptionProperties, defaultRule *RuleProperties, creating bool) (*SubscriptionProperties, *http.Response, error) {
 	if props == nil {
 		props = &SubscriptionProperties{}
 	}
 
-	env := newSubscriptionEnvelope(props, ac.em.TokenProvider())
+	env, err := newSubscriptionEnvelope(props, defaultRule, ac.em.TokenProvider())
+
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if !creating {
 		ctx = runtime.WithHTTPHeader(ctx, http.Header{
@@ -367,7 +379,7 @@ func (ac *Client) createOrUpdateSubscriptionImpl(ctx context.Context, topicName
 	return &item.SubscriptionProperties, resp, nil
 }
 
-func newSubscriptionEnvelope(props *SubscriptionProperties, tokenProvider auth.TokenProvider) *atom.SubscriptionEnvelope {
+func newSubscriptionEnvelope(props *SubscriptionProperties, defaultRule *RuleProperties, tokenProvider auth.TokenProvider) (*atom.SubscriptionEnvelope, error) {
 	desc := &atom.SubscriptionDescription{
 		DefaultMessageTimeToLive:                  props.DefaultMessageTimeToLive,
 		LockDuration:                              props.LockDuration,
@@ -380,12 +392,32 @@ func newSubscriptionEnvelope(props *SubscriptionProperties, tokenProvider auth.T
 		UserMetadata:                              props.UserMetadata,
 		EnableBatchedOperations:                   props.EnableBatchedOperations,
 		AutoDeleteOnIdle:                          props.AutoDeleteOnIdle,
-		// TODO: when we get rule serialization in place.
-		// DefaultRuleDescription:                    props.DefaultRuleDescription,
-		// are these attributes just not valid anymore?
 	}
 
-	return atom.WrapWithSubscriptionEnvelope(desc)
+	if defaultRule != nil {
+		filter, err := newFilterDescription(defaultRule.Filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		action, err := newActionDescription(defaultRule.Action)
+
+		if err != nil {
+			return nil, err
+		}
+
+		desc.DefaultRuleDescription = &atom.DefaultRuleDescription{
+			Filter: *filter,
+			Action: action,
+		}
+
+		if defaultRule.Name != "" {
+			desc.DefaultRuleDescription.Name = &defaultRule.Name
+		}
+	}
+
+	return atom.WrapWithSubscriptionEnvelope(desc), nil
 }
 
 func newSubscriptionItem(env *atom.SubscriptionEnvelope, topicName string) (*SubscriptionPropertiesItem, error) {
@@ -1849,3 +1849,93 @@ func TestATOMNoCountDetails(t *testing.T) {
 	require.Nil(t, tRP)
 	require.Error(t, err, "invalid topic runtime properties: no CountDetails element")
 }
+
+func TestAdminClient_SubscriptionEnvelopeDefaultRule(t *testing.T) {
+	env, err := newSubscriptionEnvelope(&SubscriptionProperties{}, &RuleProperties{
+		Name:   "filter",
+		Filter: &SQLFilter{Expression: "region = @region", Parameters: map[string]interface{}{"@region": "eu"}},
+		Action: &SQLAction{Expression: "SET matched = 1"},
+	}, nil)
+	require.NoError(t, err)
+
+	desc := env.Content.SubscriptionDescription.DefaultRuleDescription
+	require.Equal(t, "filter", *desc.Name)
+	require.Equal(t, "SqlFilter", desc.Filter.Type)
+	require.Equal(t, "region = @region", *desc.Filter.SQLExpression)
+	require.Equal(t, "SqlRuleAction", desc.Action.Type)
+
+	body, err := xml.Marshal(env)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "<DefaultRuleDescription><Filter")
+	require.Contains(t, string(body), "<SqlExpression>region = @region</SqlExpression>")
+	require.Contains(t, string(body), "<Name>filter</Name></DefaultRuleDescription>")
+
+	env, err = newSubscriptionEnvelope(&SubscriptionProperties{}, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, env.Content.SubscriptionDescription.DefaultRuleDescription)
+
+	_, err = newSubscriptionEnvelope(&SubscriptionProperties{}, &RuleProperties{Filter: &SQLFilter{
+		Expression: "a = @a",
+		Parameters: map[string]interface{}{"@a": struct{}{}},
+	}}, nil)
+	require.Error(t, err)
+}
+
+type entityManagerForFilterTests struct {
+	atom.EntityManager
+	getPaths []string
+}
+
+func (em *entityManagerForFilterTests) Get(ctx context.Context, entityPath string, respObj interface{}) (*http.Response, error) {
+	em.getPaths = append(em.getPaths, entityPath)
+
+	switch feedPtrPtr := respObj.(type) {
+	case **atom.TopicFeed:
+		*feedPtrPtr = &atom.TopicFeed{
+			Entries: []atom.TopicEnvelope{
+				{Entry: &atom.Entry{Title: "orders-eu"}, Content: &atom.TopicContent{}},
+			},
+		}
+	case **atom.QueueFeed:
+		*feedPtrPtr = &atom.QueueFeed{}
+	default:
+		panic(fmt.Sprintf("Unknown feed type: %T", respObj))
+	}
+
+	return &http.Response{}, nil
+}
+
+func TestAdminClient_ListWithFilterAndSkip(t *testing.T) {
+	adminClient, err := NewClientFromConnectionString("Endpoint=sb://fakeendpoint.something/;SharedAccessKeyName=fakekeyname;SharedAccessKey=CHANGEME", nil)
+	require.NoError(t, err)
+
+	em := &entityManagerForFilterTests{}
+	adminClient.em = em
+
+	topicPager := adminClient.NewListTopicsPager(&ListTopicsOptions{
+		MaxPageSize: 10,
+		Skip:        20,
+		Filter:      "startswith(path, 'orders') eq true",
+	})
+
+	page, err := topicPager.NextPage(context.Background())
+	require.NoError(t, err)
+	require.Len(t, page.Topics, 1)
+	require.Equal(t, "orders-eu", page.Topics[0].TopicName)
+
+	// a light page is the last one
+	require.False(t, topicPager.More())
+
+	queuePager := adminClient.NewListQueuesPager(&ListQueuesOptions{
+		Filter: "messageCount gt 10",
+	})
+
+	page2, err := queuePager.NextPage(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, page2.Queues)
+
+	require.Equal(t, []string{
+		"/$Resources/Topics?&$top=10&$skip=20&$filter=startswith%28path%2C+%27orders%27%29+eq+true",
+		"/$Resources/Queues?&$filter=messageCount+gt+10",
+	}, em.getPaths)
+}
@@ -189,23 +189,32 @@ type ListTopicsResponse struct {
 
 // ListTopicsOptions can be used to configure the ListTopics method.
 type ListTopicsOptions struct {
-	// MaxPageSize is the maximum size of each page of results.
+	// MaxPageSize is the maximum size of each page of results. It's sent to the service as $top.
 	MaxPageSize int32
+
+	// Skip is the number of topics to skip before the first page, sent to the service as $skip, to
+	// continue listing from an offset.
+	Skip int32
+
+	// Filter is an OData expression, sent to the service as $filter, that selects the topics to list,
+	// such as "startswith(path, 'orders') eq true". The topics are filtered by the service, so only the
+	// ones that match are transferred.
+	Filter string
 }
 
 // NewListTopicsPager creates a pager that can list topics.
 func (ac *Client) NewListTopicsPager(options *ListTopicsOptions) *runtime.Pager[ListTopicsResponse] {
-	var pageSize int32
-
-	if options != nil {
-		pageSize = options.MaxPageSize
+	if options == nil {
+		options = &ListTopicsOptions{}
 	}
 
 	ep := &entityPager[atom.TopicFeed, atom.TopicEnvelope, TopicItem]{
 		convertFn:    newTopicItem,
 		baseFragment: "/$Resources/Topics",
-		maxPageSize:  pageSize,
+		maxPageSize:  options.MaxPageSize,
 		em:           ac.em,
+		filter:       options.Filter,
+		skip:         options.Skip,
 	}
 
 	return runtime.NewPager(runtime.PagingHandler[ListTopicsResponse]{
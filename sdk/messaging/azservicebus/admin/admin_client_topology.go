@@ -0,0 +1,319 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/utils"
+)
+
+// TopologySpec is the set of entities an application requires, for Client.AssertTopology.
+type TopologySpec struct {
+	// Queues are the queues that must exist.
+	Queues []QueueRequirement
+
+	// Topics are the topics that must exist.
+	Topics []TopicRequirement
+
+	// Subscriptions are the subscriptions that must exist.
+	Subscriptions []SubscriptionRequirement
+}
+
+// QueueRequirement is a queue that must exist.
+type QueueRequirement struct {
+	// Name is the name of the queue.
+	Name string
+
+	// Properties, if set, are properties the queue must have. Only the properties that are set are compared.
+	Properties *QueueProperties
+}
+
+// TopicRequirement is a topic that must exist.
+type TopicRequirement struct {
+	// Name is the name of the topic.
+	Name string
+
+	// Properties, if set, are properties the topic must have. Only the properties that are set are compared.
+	Properties *TopicProperties
+}
+
+// SubscriptionRequirement is a subscription that must exist.
+type SubscriptionRequirement struct {
+	// TopicName is the name of the subscription's topic.
+	TopicName string
+
+	// Name is the name of the subscription.
+	Name string
+
+	// Properties, if set, are properties the subscription must have. Only the properties that are set are compared.
+	Properties *SubscriptionProperties
+}
+
+// TopologyProblemKind is the kind of a TopologyProblem.
+type TopologyProblemKind string
+
+const (
+	// TopologyProblemKindMissing means the entity doesn't exist.
+	TopologyProblemKindMissing TopologyProblemKind = "Missing"
+
+	// TopologyProblemKindDrift means a property of the entity doesn't have the required value.
+	TopologyProblemKindDrift TopologyProblemKind = "Drift"
+)
+
+// TopologyProblem is a difference between a TopologySpec and the namespace.
+type TopologyProblem struct {
+	// Kind is the kind of problem.
+	Kind TopologyProblemKind
+
+	// EntityPath is the path of the entity: the name of a queue or topic, or <topic>/Subscriptions/<subscription>
+	// for a subscription.
+	EntityPath string
+
+	// Property is the name of the property that drifted, such as "RequiresSession". It's empty when the entity is
+	// missing.
+	Property string
+
+	// Expected is the required value of Property.
+	Expected string
+
+	// Actual is the value of Property in the namespace, or "<unset>" if the entity doesn't report one.
+	Actual string
+}
+
+// String describes the problem.
+func (p TopologyProblem) String() string {
+	if p.Kind == TopologyProblemKindMissing {
+		return fmt.Sprintf("%s doesn't exist", p.EntityPath)
+	}
+
+	return fmt.Sprintf("%s has %s %s, expected %s", p.EntityPath, p.Property, p.Actual, p.Expected)
+}
+
+// TopologyError is returned by Client.AssertTopology when the namespace doesn't match the TopologySpec.
+type TopologyError struct {
+	// Problems are the differences found.
+	Problems []TopologyProblem
+}
+
+// Error implements the error interface for type TopologyError.
+func (e *TopologyError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString("service bus topology doesn't match the spec: ")
+
+	for i, p := range e.Problems {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+
+		sb.WriteString(p.String())
+	}
+
+	return sb.String()
+}
+
+// AssertTopologyOptions contains optional parameters for Client.AssertTopology
+type AssertTopologyOptions struct {
+	// FailFast stops checking at the first problem, instead of checking every entity in the spec.
+	FailFast bool
+}
+
+// AssertTopologyResponse contains the response fields for Client.AssertTopology
+type AssertTopologyResponse struct {
+	// Problems are the differences found. It's empty when the namespace matches the spec.
+	Problems []TopologyProblem
+}
+
+// topologyGetter is the part of a *Client AssertTopology uses
+type topologyGetter interface {
+	GetQueue(ctx context.Context, queueName string, options *GetQueueOptions) (*GetQueueResponse, error)
+	GetTopic(ctx context.Context, topicName string, options *GetTopicOptions) (*GetTopicResponse, error)
+	GetSubscription(ctx context.Context, topicName string, subscriptionName string, options *GetSubscriptionOptions) (*GetSubscriptionResponse, error)
+}
+
+// AssertTopology checks that the queues, topics and subscriptions in spec exist and have the properties it
+// requires, for example when an application starts, so a misconfigured environment is caught before messages
+// are sent to the wrong place or lost. Durations are compared by value, so "PT1M" matches "PT60S", and
+// ForwardTo and ForwardDeadLetteredMessagesTo can be entity names or URLs.
+//
+// If the namespace doesn't match spec, AssertTopology returns the problems in the response and a *TopologyError
+// with the same problems. Other errors are returned when getting an entity fails.
+func (ac *Client) AssertTopology(ctx context.Context, spec TopologySpec, options *AssertTopologyOptions) (AssertTopologyResponse, error) {
+	return assertTopology(ctx, ac, spec, options)
+}
+
+func assertTopology(ctx context.Context, getter topologyGetter, spec TopologySpec, options *AssertTopologyOptions) (AssertTopologyResponse, error) {
+	if options == nil {
+		options = &AssertTopologyOptions{}
+	}
+
+	var problems []TopologyProblem
+
+	// check appends the problems of an entity and reports whether checking should stop
+	check := func(path string, exists bool, expected interface{}, actual interface{}) bool {
+		if !exists {
+			problems = append(problems, TopologyProblem{Kind: TopologyProblemKindMissing, EntityPath: path})
+		} else {
+			problems = append(problems, compareEntityProperties(path, expected, actual)...)
+		}
+
+		return options.FailFast && len(problems) > 0
+	}
+
+	result := func() (AssertTopologyResponse, error) {
+		if len(problems) == 0 {
+			return AssertTopologyResponse{}, nil
+		}
+
+		return AssertTopologyResponse{Problems: problems}, &TopologyError{Problems: problems}
+	}
+
+	for _, q := range spec.Queues {
+		resp, err := getter.GetQueue(ctx, q.Name, nil)
+
+		if err != nil {
+			return AssertTopologyResponse{Problems: problems}, err
+		}
+
+		var actual *QueueProperties
+
+		if resp != nil {
+			actual = &resp.QueueProperties
+		}
+
+		if check(q.Name, resp != nil, q.Properties, actual) {
+			return result()
+		}
+	}
+
+	for _, t := range spec.Topics {
+		resp, err := getter.GetTopic(ctx, t.Name, nil)
+
+		if err != nil {
+			return AssertTopologyResponse{Problems: problems}, err
+		}
+
+		var actual *TopicProperties
+
+		if resp != nil {
+			actual = &resp.TopicProperties
+		}
+
+		if check(t.Name, resp != nil, t.Properties, actual) {
+			return result()
+		}
+	}
+
+	for _, s := range spec.Subscriptions {
+		resp, err := getter.GetSubscription(ctx, s.TopicName, s.Name, nil)
+
+		if err != nil {
+			return AssertTopologyResponse{Problems: problems}, err
+		}
+
+		var actual *SubscriptionProperties
+
+		if resp != nil {
+			actual = &resp.SubscriptionProperties
+		}
+
+		if check(fmt.Sprintf("%s/Subscriptions/%s", s.TopicName, s.Name), resp != nil, s.Properties, actual) {
+			return result()
+		}
+	}
+
+	return result()
+}
+
+// compareEntityProperties compares the fields that are set in expected, a pointer to a properties struct,
+// with the same fields of actual, a pointer to the same type
+func compareEntityProperties(path string, expected interface{}, actual interface{}) []TopologyProblem {
+	ev := reflect.ValueOf(expected)
+
+	if ev.IsNil() {
+		return nil
+	}
+
+	ev = ev.Elem()
+	av := reflect.ValueOf(actual).Elem()
+
+	var problems []TopologyProblem
+
+	for i := 0; i < ev.NumField(); i++ {
+		name := ev.Type().Field(i).Name
+		ef, af := ev.Field(i), av.Field(i)
+
+		if ef.IsNil() {
+			continue
+		}
+
+		if af.IsNil() || !sameTopologyValue(name, ef, af) {
+			problems = append(problems, TopologyProblem{
+				Kind:       TopologyProblemKindDrift,
+				EntityPath: path,
+				Property:   name,
+				Expected:   formatTopologyValue(ef),
+				Actual:     formatTopologyValue(af),
+			})
+		}
+	}
+
+	return problems
+}
+
+func sameTopologyValue(name string, expected reflect.Value, actual reflect.Value) bool {
+	if expected.Kind() == reflect.Slice {
+		return reflect.DeepEqual(expected.Interface(), actual.Interface())
+	}
+
+	if expected.Elem().Kind() != reflect.String {
+		return reflect.DeepEqual(expected.Elem().Interface(), actual.Elem().Interface())
+	}
+
+	e, a := expected.Elem().String(), actual.Elem().String()
+
+	switch name {
+	case "ForwardTo", "ForwardDeadLetteredMessagesTo":
+		// the service returns the URL of the entity, which can be set by name
+		return strings.EqualFold(forwardEntityName(e), forwardEntityName(a))
+	case "LockDuration", "DefaultMessageTimeToLive", "DuplicateDetectionHistoryTimeWindow", "AutoDeleteOnIdle":
+		ed, eErr := utils.ISO8601StringToDuration(&e)
+		ad, aErr := utils.ISO8601StringToDuration(&a)
+
+		if eErr == nil && aErr == nil {
+			return *ed == *ad
+		}
+	}
+
+	return e == a
+}
+
+// forwardEntityName returns the entity name of a ForwardTo value, which can be a name or a URL
+func forwardEntityName(v string) string {
+	if i := strings.Index(v, "://"); i >= 0 {
+		v = v[i+3:]
+
+		if j := strings.Index(v, "/"); j >= 0 {
+			v = v[j+1:]
+		}
+	}
+
+	return strings.Trim(v, "/")
+}
+
+func formatTopologyValue(v reflect.Value) string {
+	if v.IsNil() {
+		return "<unset>"
+	}
+
+	if v.Kind() == reflect.Pointer {
+		return fmt.Sprint(v.Elem().Interface())
+	}
+
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTopologyGetter struct {
+	queues        map[string]QueueProperties
+	topics        map[string]TopicProperties
+	subscriptions map[string]SubscriptionProperties
+	gets          int
+	err           error
+}
+
+func (g *fakeTopologyGetter) GetQueue(ctx context.Context, queueName string, options *GetQueueOptions) (*GetQueueResponse, error) {
+	g.gets++
+
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	if props, ok := g.queues[queueName]; ok {
+		return &GetQueueResponse{QueueProperties: props}, nil
+	}
+
+	return nil, nil
+}
+
+func (g *fakeTopologyGetter) GetTopic(ctx context.Context, topicName string, options *GetTopicOptions) (*GetTopicResponse, error) {
+	g.gets++
+
+	if props, ok := g.topics[topicName]; ok {
+		return &GetTopicResponse{TopicProperties: props}, nil
+	}
+
+	return nil, nil
+}
+
+func (g *fakeTopologyGetter) GetSubscription(ctx context.Context, topicName string, subscriptionName string, options *GetSubscriptionOptions) (*GetSubscriptionResponse, error) {
+	g.gets++
+
+	if props, ok := g.subscriptions[topicName+"/"+subscriptionName]; ok {
+		return &GetSubscriptionResponse{SubscriptionProperties: props}, nil
+	}
+
+	return nil, nil
+}
+
+func TestAssertTopology(t *testing.T) {
+	getter := &fakeTopologyGetter{
+		queues: map[string]QueueProperties{
+			"orders": {
+				LockDuration:    to.Ptr("PT1M"),
+				RequiresSession: to.Ptr(false),
+				ForwardTo:       to.Ptr("sb://fake.servicebus.windows.net/Archive"),
+				Status:          to.Ptr(EntityStatusActive),
+			},
+		},
+		topics: map[string]TopicProperties{
+			"events": {MaxSizeInMegabytes: to.Ptr(int32(1024))},
+		},
+		subscriptions: map[string]SubscriptionProperties{
+			"events/audit": {MaxDeliveryCount: to.Ptr(int32(10))},
+		},
+	}
+
+	// the spec matches, with equivalent durations and forwarding targets
+	resp, err := assertTopology(context.Background(), getter, TopologySpec{
+		Queues: []QueueRequirement{{Name: "orders", Properties: &QueueProperties{
+			LockDuration: to.Ptr("PT60S"),
+			ForwardTo:    to.Ptr("archive"),
+			Status:       to.Ptr(EntityStatusActive),
+		}}},
+		Topics:        []TopicRequirement{{Name: "events"}},
+		Subscriptions: []SubscriptionRequirement{{TopicName: "events", Name: "audit", Properties: &SubscriptionProperties{MaxDeliveryCount: to.Ptr(int32(10))}}},
+	}, nil)
+	require.NoError(t, err)
+	require.Empty(t, resp.Problems)
+
+	spec := TopologySpec{
+		Queues: []QueueRequirement{
+			{Name: "orders", Properties: &QueueProperties{RequiresSession: to.Ptr(true), UserMetadata: to.Ptr("v2")}},
+			{Name: "missing"},
+		},
+		Subscriptions: []SubscriptionRequirement{{TopicName: "events", Name: "billing"}},
+	}
+
+	resp, err = assertTopology(context.Background(), getter, spec, nil)
+
+	expected := []TopologyProblem{
+		{Kind: TopologyProblemKindDrift, EntityPath: "orders", Property: "RequiresSession", Expected: "true", Actual: "false"},
+		{Kind: TopologyProblemKindDrift, EntityPath: "orders", Property: "UserMetadata", Expected: "v2", Actual: "<unset>"},
+		{Kind: TopologyProblemKindMissing, EntityPath: "missing"},
+		{Kind: TopologyProblemKindMissing, EntityPath: "events/Subscriptions/billing"},
+	}
+	require.Equal(t, expected, resp.Problems)
+
+	var topologyErr *TopologyError
+	require.ErrorAs(t, err, &topologyErr)
+	require.Equal(t, expected, topologyErr.Problems)
+	require.Equal(t, "service bus topology doesn't match the spec: orders has RequiresSession false, expected true; "+
+		"orders has UserMetadata <unset>, expected v2; missing doesn't exist; events/Subscriptions/billing doesn't exist", err.Error())
+
+	// FailFast stops after the first entity with a problem
+	getter.gets = 0
+	resp, err = assertTopology(context.Background(), getter, spec, &AssertTopologyOptions{FailFast: true})
+	require.ErrorAs(t, err, &topologyErr)
+	require.Equal(t, expected[:2], resp.Problems)
+	require.Equal(t, 1, getter.gets)
+
+	getter.err = errors.New("unauthorized")
+	_, err = assertTopology(context.Background(), getter, spec, nil)
+	require.EqualError(t, err, "unauthorized")
+}
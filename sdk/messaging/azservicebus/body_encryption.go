@@ -0,0 +1,308 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDataKeyLifetime = time.Hour
+
+	// maxUnwrappedDataKeys bounds the data keys a Receiver caches
+	maxUnwrappedDataKeys = 1024
+
+	bodyEncryptionAlgorithm = "A256GCM"
+
+	// application properties describing how an encrypted body was encrypted
+	bodyEncryptionAlgorithmProperty  = "azsb-encryption-alg"
+	bodyEncryptionKeyIDProperty      = "azsb-encryption-kid"
+	bodyEncryptionWrappedKeyProperty = "azsb-encryption-key"
+	bodyEncryptionNonceProperty      = "azsb-encryption-iv"
+)
+
+// ErrBodyDecryption is wrapped by the error a Receiver returns when it can't decrypt the bodies of
+// some of the messages it received.
+var ErrBodyDecryption = errors.New("failed to decrypt message body")
+
+// KeyWrapper encrypts and decrypts the data keys that message bodies are encrypted with, so the data
+// keys can travel with the messages. An azkeys.Client can implement it: WrapKey calls Client.WrapKey
+// with an RSA-OAEP-256 key and returns the KID of the response, and UnwrapKey calls Client.UnwrapKey
+// with the name and version parsed from keyID.
+type KeyWrapper interface {
+	// WrapKey encrypts dataKey. It returns the ID of the key that encrypted it, which is passed to
+	// UnwrapKey, and the encrypted data key.
+	WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrappedKey []byte, err error)
+
+	// UnwrapKey decrypts a data key encrypted by WrapKey with the key identified by keyID.
+	UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error)
+}
+
+// BodyEncryptionOptions configures the encryption of message bodies by a Sender, and their decryption
+// by a Receiver or SessionReceiver.
+//
+// A Sender encrypts each message body with AES-256-GCM using a random data key, which it encrypts
+// with KeyWrapper and stores, with the other parameters needed for decryption, in the message's
+// application properties. A Receiver decrypts the bodies of messages that have those properties,
+// removing them, and returns other messages unchanged. The rest of the message, including its other
+// application properties, isn't encrypted.
+type BodyEncryptionOptions struct {
+	// KeyWrapper encrypts and decrypts data keys. Required.
+	KeyWrapper KeyWrapper
+
+	// DataKeyLifetime is how long a Sender uses a data key before creating another one, which limits
+	// the calls to KeyWrapper.WrapKey. Defaults to one hour.
+	DataKeyLifetime time.Duration
+}
+
+// bodyEncryptor encrypts and decrypts message bodies. Its methods are safe to call on a nil
+// bodyEncryptor, which leaves messages unchanged.
+type bodyEncryptor struct {
+	wrapper  KeyWrapper
+	lifetime time.Duration
+
+	mu      sync.Mutex
+	dataKey *wrappedDataKey
+
+	// unwrapped caches the data keys the Receiver has decrypted, keyed by key ID and wrapped key
+	unwrapped map[string][]byte
+}
+
+type wrappedDataKey struct {
+	aead      cipher.AEAD
+	keyID     string
+	wrapped   string
+	expiresAt time.Time
+}
+
+func newBodyEncryptor(options *BodyEncryptionOptions) (*bodyEncryptor, error) {
+	if options == nil {
+		return nil, nil
+	}
+
+	if options.KeyWrapper == nil {
+		return nil, errors.New("BodyEncryptionOptions.KeyWrapper is required")
+	}
+
+	e := &bodyEncryptor{
+		wrapper:   options.KeyWrapper,
+		lifetime:  options.DataKeyLifetime,
+		unwrapped: map[string][]byte{},
+	}
+
+	if e.lifetime <= 0 {
+		e.lifetime = defaultDataKeyLifetime
+	}
+
+	return e, nil
+}
+
+// encrypt returns a copy of m with an encrypted body. The caller's message is never modified.
+func (e *bodyEncryptor) encrypt(ctx context.Context, m *Message) (*Message, error) {
+	if e == nil {
+		return m, nil
+	}
+
+	if _, ok := m.ApplicationProperties[bodyEncryptionAlgorithmProperty]; ok {
+		return nil, errors.New("message body is already encrypted")
+	}
+
+	key, err := e.currentDataKey(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, key.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	copied := *m
+	copied.Body = key.aead.Seal(nil, nonce, m.Body, []byte(bodyEncryptionAlgorithm))
+	copied.ApplicationProperties = make(map[string]interface{}, len(m.ApplicationProperties)+4)
+
+	for k, v := range m.ApplicationProperties {
+		copied.ApplicationProperties[k] = v
+	}
+
+	copied.ApplicationProperties[bodyEncryptionAlgorithmProperty] = bodyEncryptionAlgorithm
+	copied.ApplicationProperties[bodyEncryptionKeyIDProperty] = key.keyID
+	copied.ApplicationProperties[bodyEncryptionWrappedKeyProperty] = key.wrapped
+	copied.ApplicationProperties[bodyEncryptionNonceProperty] = base64.StdEncoding.EncodeToString(nonce)
+
+	return &copied, nil
+}
+
+func (e *bodyEncryptor) currentDataKey(ctx context.Context) (*wrappedDataKey, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.dataKey != nil && time.Now().Before(e.dataKey.expiresAt) {
+		return e.dataKey, nil
+	}
+
+	raw := make([]byte, 32)
+
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+
+	keyID, wrapped, err := e.wrapper.WrapKey(ctx, raw)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	aead, err := newBodyAEAD(raw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	e.dataKey = &wrappedDataKey{
+		aead:      aead,
+		keyID:     keyID,
+		wrapped:   base64.StdEncoding.EncodeToString(wrapped),
+		expiresAt: time.Now().Add(e.lifetime),
+	}
+
+	return e.dataKey, nil
+}
+
+// decryptAll decrypts the bodies of the encrypted messages. Messages that can't be decrypted are
+// left unchanged and reported by the returned error.
+func (e *bodyEncryptor) decryptAll(ctx context.Context, messages []*ReceivedMessage) error {
+	if e == nil {
+		return nil
+	}
+
+	var failed []string
+	var firstErr error
+
+	for _, m := range messages {
+		if err := e.decrypt(ctx, m); err != nil {
+			failed = append(failed, m.MessageID)
+
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return fmt.Errorf("%w for %d message(s) (%s): %v", ErrBodyDecryption, len(failed), strings.Join(failed, ", "), firstErr)
+	}
+
+	return nil
+}
+
+func (e *bodyEncryptor) decrypt(ctx context.Context, m *ReceivedMessage) error {
+	alg, ok := m.ApplicationProperties[bodyEncryptionAlgorithmProperty]
+
+	if !ok {
+		return nil
+	}
+
+	if alg != bodyEncryptionAlgorithm {
+		return fmt.Errorf("unsupported encryption algorithm %v", alg)
+	}
+
+	keyID, _ := m.ApplicationProperties[bodyEncryptionKeyIDProperty].(string)
+	wrapped, _ := m.ApplicationProperties[bodyEncryptionWrappedKeyProperty].(string)
+	encodedNonce, _ := m.ApplicationProperties[bodyEncryptionNonceProperty].(string)
+	nonce, err := base64.StdEncoding.DecodeString(encodedNonce)
+
+	if err != nil || keyID == "" || wrapped == "" {
+		return errors.New("invalid encryption properties")
+	}
+
+	raw, err := e.unwrap(ctx, keyID, wrapped)
+
+	if err != nil {
+		return err
+	}
+
+	aead, err := newBodyAEAD(raw)
+
+	if err != nil {
+		return err
+	}
+
+	if len(nonce) != aead.NonceSize() {
+		return errors.New("invalid encryption properties")
+	}
+
+	body, err := aead.Open(nil, nonce, m.Body, []byte(bodyEncryptionAlgorithm))
+
+	if err != nil {
+		return err
+	}
+
+	m.Body = body
+
+	for _, p := range []string{bodyEncryptionAlgorithmProperty, bodyEncryptionKeyIDProperty, bodyEncryptionWrappedKeyProperty, bodyEncryptionNonceProperty} {
+		delete(m.ApplicationProperties, p)
+	}
+
+	return nil
+}
+
+func (e *bodyEncryptor) unwrap(ctx context.Context, keyID string, wrapped string) ([]byte, error) {
+	cacheKey := keyID + "\x00" + wrapped
+
+	e.mu.Lock()
+	raw, ok := e.unwrapped[cacheKey]
+	e.mu.Unlock()
+
+	if ok {
+		return raw, nil
+	}
+
+	wrappedBytes, err := base64.StdEncoding.DecodeString(wrapped)
+
+	if err != nil {
+		return nil, errors.New("invalid encryption properties")
+	}
+
+	raw, err = e.wrapper.UnwrapKey(ctx, keyID, wrappedBytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.unwrapped) >= maxUnwrappedDataKeys {
+		e.unwrapped = map[string][]byte{}
+	}
+
+	e.unwrapped[cacheKey] = raw
+	return raw, nil
+}
+
+func newBodyAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("data key has %d bytes, expected 32", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/stretchr/testify/require"
+)
+
+// xorKeyWrapper "wraps" keys by XORing them with a byte, which is enough to check they're unwrapped
+type xorKeyWrapper struct {
+	wraps, unwraps int
+	err            error
+}
+
+func (w *xorKeyWrapper) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	w.wraps++
+	return "https://fake.vault.azure.net/keys/key/1", xorBytes(dataKey), w.err
+}
+
+func (w *xorKeyWrapper) UnwrapKey(ctx context.Context, keyID string, wrappedKey []byte) ([]byte, error) {
+	w.unwraps++
+
+	if keyID != "https://fake.vault.azure.net/keys/key/1" {
+		return nil, errors.New("unknown key")
+	}
+
+	return xorBytes(wrappedKey), w.err
+}
+
+func xorBytes(b []byte) []byte {
+	r := make([]byte, len(b))
+
+	for i := range b {
+		r[i] = b[i] ^ 0x5a
+	}
+
+	return r
+}
+
+func TestBodyEncryption(t *testing.T) {
+	wrapper := &xorKeyWrapper{}
+	sender, err := newBodyEncryptor(&BodyEncryptionOptions{KeyWrapper: wrapper})
+	require.NoError(t, err)
+	receiver, err := newBodyEncryptor(&BodyEncryptionOptions{KeyWrapper: wrapper})
+	require.NoError(t, err)
+
+	var received []*ReceivedMessage
+
+	for _, body := range []string{"hello", "world"} {
+		original := &Message{Body: []byte(body), ApplicationProperties: map[string]interface{}{"app": "prop"}}
+		encrypted, err := sender.encrypt(context.Background(), original)
+		require.NoError(t, err)
+
+		// the caller's message isn't modified
+		require.Equal(t, body, string(original.Body))
+		require.Equal(t, map[string]interface{}{"app": "prop"}, original.ApplicationProperties)
+
+		require.NotContains(t, string(encrypted.Body), body)
+		require.Equal(t, bodyEncryptionAlgorithm, encrypted.ApplicationProperties[bodyEncryptionAlgorithmProperty])
+
+		received = append(received, newReceivedMessage(encrypted.toAMQPMessage()))
+	}
+
+	// not encrypted, so it's left alone
+	received = append(received, newReceivedMessage((&Message{Body: []byte("plain")}).toAMQPMessage()))
+
+	require.NoError(t, receiver.decryptAll(context.Background(), received))
+	require.Equal(t, "hello", string(received[0].Body))
+	require.Equal(t, "world", string(received[1].Body))
+	require.Equal(t, "plain", string(received[2].Body))
+	require.Equal(t, map[string]interface{}{"app": "prop"}, received[0].ApplicationProperties)
+
+	// the data key is wrapped once and unwrapped once
+	require.Equal(t, 1, wrapper.wraps)
+	require.Equal(t, 1, wrapper.unwraps)
+
+	_, err = sender.encrypt(context.Background(), &Message{ApplicationProperties: map[string]interface{}{bodyEncryptionAlgorithmProperty: bodyEncryptionAlgorithm}})
+	require.EqualError(t, err, "message body is already encrypted")
+
+	// nil encryptors don't change messages
+	var none *bodyEncryptor
+	plain := &Message{Body: []byte("plain")}
+	same, err := none.encrypt(context.Background(), plain)
+	require.NoError(t, err)
+	require.Same(t, plain, same)
+	require.NoError(t, none.decryptAll(context.Background(), received))
+}
+
+func TestBodyEncryption_DecryptionFailure(t *testing.T) {
+	wrapper := &xorKeyWrapper{}
+	sender, err := newBodyEncryptor(&BodyEncryptionOptions{KeyWrapper: wrapper})
+	require.NoError(t, err)
+
+	encrypted, err := sender.encrypt(context.Background(), &Message{MessageID: to.Ptr("tampered"), Body: []byte("hello")})
+	require.NoError(t, err)
+
+	tampered := newReceivedMessage(encrypted.toAMQPMessage())
+	tampered.Body[0] ^= 1
+	body := string(tampered.Body)
+
+	receiver, err := newBodyEncryptor(&BodyEncryptionOptions{KeyWrapper: wrapper})
+	require.NoError(t, err)
+
+	err = receiver.decryptAll(context.Background(), []*ReceivedMessage{tampered})
+	require.ErrorIs(t, err, ErrBodyDecryption)
+	require.Contains(t, err.Error(), "tampered")
+
+	// the message is left as it was received
+	require.Equal(t, body, string(tampered.Body))
+	require.Contains(t, tampered.ApplicationProperties, bodyEncryptionKeyIDProperty)
+
+	_, err = newBodyEncryptor(&BodyEncryptionOptions{})
+	require.EqualError(t, err, "BodyEncryptionOptions.KeyWrapper is required")
+
+	wrapper.err = errors.New("vault unavailable")
+	failing, err := newBodyEncryptor(&BodyEncryptionOptions{KeyWrapper: wrapper})
+	require.NoError(t, err)
+	_, err = failing.encrypt(context.Background(), &Message{})
+	require.Contains(t, err.Error(), "vault unavailable")
+}
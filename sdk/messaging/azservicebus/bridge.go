@@ -0,0 +1,339 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBridgeMaxMessagesPerReceive = 10
+	bridgeErrorDelay                   = time.Second
+
+	// bridgeTransformFailedReason is the dead-letter reason of messages the transform returned an error for
+	bridgeTransformFailedReason = "BridgeTransformFailed"
+)
+
+// SubscriptionSpec identifies the subscription a Bridge receives messages from.
+type SubscriptionSpec struct {
+	// Client is the Client for the subscription's namespace.
+	Client *Client
+
+	// TopicName is the name of the topic.
+	TopicName string
+
+	// SubscriptionName is the name of the subscription.
+	SubscriptionName string
+
+	// ReceiverOptions are used to create the Receiver for the subscription. The Bridge settles messages
+	// itself, so ReceiveMode must be ReceiveModePeekLock, the default.
+	ReceiverOptions *ReceiverOptions
+}
+
+// QueueSpec identifies the queue a Bridge sends messages to.
+type QueueSpec struct {
+	// Client is the Client for the queue's namespace.
+	Client *Client
+
+	// QueueName is the name of the queue.
+	QueueName string
+
+	// SenderOptions are used to create the Sender for the queue.
+	SenderOptions *NewSenderOptions
+}
+
+// BridgeTransform converts a message received from a Bridge's subscription to the message sent to its queue.
+// Returning a nil message and a nil error drops the message: it's completed without being forwarded.
+// Returning an error dead letters the received message.
+type BridgeTransform func(ctx context.Context, message *ReceivedMessage) (*Message, error)
+
+// BridgeOptions contains optional parameters for NewBridge.
+type BridgeOptions struct {
+	// MaxMessagesPerReceive is the maximum number of messages requested from the subscription at a time.
+	// Defaults to 10.
+	MaxMessagesPerReceive int
+
+	// MaxMessagesPerSecond limits the rate at which messages are forwarded to the queue. By default, the
+	// rate isn't limited.
+	MaxMessagesPerSecond float64
+
+	// OnError is called when receiving, forwarding or settling a message fails. The Bridge keeps running.
+	// If OnError is nil, errors are only counted in the BridgeMetrics.
+	OnError func(err error)
+}
+
+// BridgeMetrics are the counters of a Bridge since it was created.
+type BridgeMetrics struct {
+	// Received is the number of messages received from the subscription.
+	Received int64
+
+	// Forwarded is the number of messages sent to the queue and completed on the subscription.
+	Forwarded int64
+
+	// Dropped is the number of messages the transform dropped.
+	Dropped int64
+
+	// DeadLettered is the number of messages dead lettered because the transform returned an error.
+	DeadLettered int64
+
+	// Errors is the number of failed receives, sends and settlements.
+	Errors int64
+
+	// LastForwarded is when a message was last forwarded. It's the zero time if none has been.
+	LastForwarded time.Time
+}
+
+// bridgeSource is the part of a *Receiver a Bridge uses
+type bridgeSource interface {
+	ReceiveMessages(ctx context.Context, maxMessages int, options *ReceiveMessagesOptions) ([]*ReceivedMessage, error)
+	CompleteMessage(ctx context.Context, message *ReceivedMessage, options *CompleteMessageOptions) error
+	AbandonMessage(ctx context.Context, message *ReceivedMessage, options *AbandonMessageOptions) error
+	DeadLetterMessage(ctx context.Context, message *ReceivedMessage, options *DeadLetterOptions) error
+	Close(ctx context.Context) error
+}
+
+// bridgeDestination is the part of a *Sender a Bridge uses
+type bridgeDestination interface {
+	SendMessage(ctx context.Context, message *Message, options *SendMessageOptions) error
+	Close(ctx context.Context) error
+}
+
+// Bridge forwards the messages of a subscription to a queue, which can be in another namespace, converting
+// each with a BridgeTransform.
+//
+// A message is completed on the subscription only after it's been sent to the queue, so a message whose
+// forwarding fails, or is interrupted by Close or a crash, is received and forwarded again. Forwarded messages
+// get the received message's MessageID unless the transform sets one, so enabling duplicate detection on the
+// queue discards the copies forwarded more than once.
+type Bridge struct {
+	source      bridgeSource
+	destination bridgeDestination
+	transform   BridgeTransform
+	maxMessages int
+	interval    time.Duration
+	onError     func(err error)
+
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	metrics BridgeMetrics
+}
+
+// NewBridge creates a Bridge from source to dest, which starts forwarding messages immediately. Call Close to
+// stop it.
+func NewBridge(source SubscriptionSpec, dest QueueSpec, transform BridgeTransform, options *BridgeOptions) (*Bridge, error) {
+	if source.Client == nil || dest.Client == nil {
+		return nil, errors.New("the source and dest need a Client")
+	}
+
+	if transform == nil {
+		return nil, errors.New("transform is required")
+	}
+
+	if source.ReceiverOptions != nil && source.ReceiverOptions.ReceiveMode == ReceiveModeReceiveAndDelete {
+		return nil, errors.New("the source can't use ReceiveModeReceiveAndDelete")
+	}
+
+	receiver, err := source.Client.NewReceiverForSubscription(source.TopicName, source.SubscriptionName, source.ReceiverOptions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := dest.Client.NewSender(dest.QueueName, dest.SenderOptions)
+
+	if err != nil {
+		_ = receiver.Close(context.Background())
+		return nil, err
+	}
+
+	return newBridge(receiver, sender, transform, options), nil
+}
+
+func newBridge(source bridgeSource, destination bridgeDestination, transform BridgeTransform, options *BridgeOptions) *Bridge {
+	if options == nil {
+		options = &BridgeOptions{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := &Bridge{
+		source:      source,
+		destination: destination,
+		transform:   transform,
+		maxMessages: options.MaxMessagesPerReceive,
+		onError:     options.OnError,
+		cancel:      cancel,
+	}
+
+	if b.maxMessages <= 0 {
+		b.maxMessages = defaultBridgeMaxMessagesPerReceive
+	}
+
+	if options.MaxMessagesPerSecond > 0 {
+		b.interval = time.Duration(float64(time.Second) / options.MaxMessagesPerSecond)
+	}
+
+	b.wg.Add(1)
+
+	go func() {
+		defer b.wg.Done()
+		b.pump(ctx)
+	}()
+
+	return b
+}
+
+func (b *Bridge) pump(ctx context.Context) {
+	// next is the earliest time the next message can be forwarded, when the rate is limited
+	var next time.Time
+
+	for {
+		messages, err := b.source.ReceiveMessages(ctx, b.maxMessages, nil)
+
+		b.update(func(m *BridgeMetrics) { m.Received += int64(len(messages)) })
+
+		for i, m := range messages {
+			if ctx.Err() != nil {
+				// the unforwarded messages are redelivered without waiting for their locks to expire
+				b.abandon(messages[i:])
+				return
+			}
+
+			if b.interval > 0 {
+				if wait := time.Until(next); wait > 0 {
+					select {
+					case <-time.After(wait):
+					case <-ctx.Done():
+						b.abandon(messages[i:])
+						return
+					}
+				}
+
+				next = time.Now().Add(b.interval)
+			}
+
+			b.forward(ctx, m)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			b.reportError(fmt.Errorf("receiving from the source: %w", err))
+
+			select {
+			case <-time.After(bridgeErrorDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// forward transforms m, sends it to the destination and then completes it on the source
+func (b *Bridge) forward(ctx context.Context, m *ReceivedMessage) {
+	out, err := b.transform(ctx, m)
+
+	if err != nil {
+		description := err.Error()
+		reason := bridgeTransformFailedReason
+
+		if err := b.source.DeadLetterMessage(ctx, m, &DeadLetterOptions{Reason: &reason, ErrorDescription: &description}); err != nil {
+			b.reportError(fmt.Errorf("dead lettering message %s: %w", m.MessageID, err))
+			return
+		}
+
+		b.update(func(metrics *BridgeMetrics) { metrics.DeadLettered++ })
+		return
+	}
+
+	if out != nil {
+		if out.MessageID == nil {
+			copied := *out
+			copied.MessageID = &m.MessageID
+			out = &copied
+		}
+
+		if err := b.destination.SendMessage(ctx, out, nil); err != nil {
+			b.reportError(fmt.Errorf("forwarding message %s: %w", m.MessageID, err))
+			b.abandon([]*ReceivedMessage{m})
+			return
+		}
+	}
+
+	if err := b.source.CompleteMessage(ctx, m, nil); err != nil {
+		// the message is received and forwarded again
+		b.reportError(fmt.Errorf("completing message %s: %w", m.MessageID, err))
+		return
+	}
+
+	b.update(func(metrics *BridgeMetrics) {
+		if out == nil {
+			metrics.Dropped++
+		} else {
+			metrics.Forwarded++
+			metrics.LastForwarded = time.Now()
+		}
+	})
+}
+
+// abandon releases the locks on messages, so they're redelivered without waiting for the locks to expire
+func (b *Bridge) abandon(messages []*ReceivedMessage) {
+	for _, m := range messages {
+		// the pump's context may be cancelled already
+		if err := b.source.AbandonMessage(context.Background(), m, nil); err != nil {
+			b.reportError(fmt.Errorf("abandoning message %s: %w", m.MessageID, err))
+		}
+	}
+}
+
+func (b *Bridge) reportError(err error) {
+	b.update(func(m *BridgeMetrics) { m.Errors++ })
+
+	if b.onError != nil {
+		b.onError(err)
+	}
+}
+
+func (b *Bridge) update(fn func(m *BridgeMetrics)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fn(&b.metrics)
+}
+
+// Metrics returns the Bridge's counters.
+func (b *Bridge) Metrics() BridgeMetrics {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.metrics
+}
+
+// Close stops the Bridge, waiting for the message being forwarded, if any, and closes the Receiver for the
+// subscription and the Sender for the queue.
+func (b *Bridge) Close(ctx context.Context) error {
+	var err error
+
+	b.closeOnce.Do(func() {
+		b.cancel()
+		b.wg.Wait()
+
+		if closeErr := b.source.Close(ctx); closeErr != nil {
+			err = closeErr
+		}
+
+		if closeErr := b.destination.Close(ctx); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,199 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBridgeSource struct {
+	mu           sync.Mutex
+	pending      []*ReceivedMessage
+	completed    []string
+	abandoned    []string
+	deadLettered []string
+	closed       bool
+}
+
+func (s *fakeBridgeSource) ReceiveMessages(ctx context.Context, maxMessages int, options *ReceiveMessagesOptions) ([]*ReceivedMessage, error) {
+	s.mu.Lock()
+	n := len(s.pending)
+
+	if n > maxMessages {
+		n = maxMessages
+	}
+
+	messages := s.pending[:n]
+	s.pending = s.pending[n:]
+	s.mu.Unlock()
+
+	if len(messages) == 0 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return messages, nil
+}
+
+func (s *fakeBridgeSource) CompleteMessage(ctx context.Context, message *ReceivedMessage, options *CompleteMessageOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.completed = append(s.completed, message.MessageID)
+	return nil
+}
+
+func (s *fakeBridgeSource) AbandonMessage(ctx context.Context, message *ReceivedMessage, options *AbandonMessageOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.abandoned = append(s.abandoned, message.MessageID)
+	return nil
+}
+
+func (s *fakeBridgeSource) DeadLetterMessage(ctx context.Context, message *ReceivedMessage, options *DeadLetterOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadLettered = append(s.deadLettered, message.MessageID+": "+*options.Reason+": "+*options.ErrorDescription)
+	return nil
+}
+
+func (s *fakeBridgeSource) Close(ctx context.Context) error {
+	s.closed = true
+	return nil
+}
+
+type fakeBridgeDestination struct {
+	mu     sync.Mutex
+	sent   []*Message
+	failID string
+	closed bool
+}
+
+func (d *fakeBridgeDestination) SendMessage(ctx context.Context, message *Message, options *SendMessageOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if *message.MessageID == d.failID {
+		d.failID = ""
+		return errors.New("send failed")
+	}
+
+	d.sent = append(d.sent, message)
+	return nil
+}
+
+func (d *fakeBridgeDestination) Close(ctx context.Context) error {
+	d.closed = true
+	return nil
+}
+
+func TestBridge(t *testing.T) {
+	source := &fakeBridgeSource{}
+
+	for _, id := range []string{"forward", "drop", "bad", "retry", "custom-id"} {
+		source.pending = append(source.pending, &ReceivedMessage{MessageID: id, Body: []byte(id)})
+	}
+
+	dest := &fakeBridgeDestination{failID: "retry"}
+
+	transform := func(ctx context.Context, m *ReceivedMessage) (*Message, error) {
+		switch m.MessageID {
+		case "drop":
+			return nil, nil
+		case "bad":
+			return nil, errors.New("can't convert")
+		case "custom-id":
+			return &Message{MessageID: to.Ptr("new-id"), Body: m.Body}, nil
+		default:
+			return &Message{Body: append([]byte("bridged "), m.Body...)}, nil
+		}
+	}
+
+	var errs []error
+	var errsMu sync.Mutex
+
+	bridge := newBridge(source, dest, transform, &BridgeOptions{
+		MaxMessagesPerReceive: 2,
+		OnError: func(err error) {
+			errsMu.Lock()
+			defer errsMu.Unlock()
+			errs = append(errs, err)
+		},
+	})
+
+	require.Eventually(t, func() bool {
+		m := bridge.Metrics()
+		return m.Forwarded+m.Dropped+m.DeadLettered+m.Errors == 5
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, bridge.Close(context.Background()))
+	require.NoError(t, bridge.Close(context.Background()))
+	require.True(t, source.closed)
+	require.True(t, dest.closed)
+
+	require.Len(t, dest.sent, 2)
+	require.Equal(t, "forward", *dest.sent[0].MessageID)
+	require.Equal(t, "bridged forward", string(dest.sent[0].Body))
+	require.Equal(t, "new-id", *dest.sent[1].MessageID)
+
+	require.Equal(t, []string{"forward", "drop", "custom-id"}, source.completed)
+	require.Equal(t, []string{"retry"}, source.abandoned)
+	require.Equal(t, []string{"bad: BridgeTransformFailed: can't convert"}, source.deadLettered)
+
+	metrics := bridge.Metrics()
+	require.EqualValues(t, 2, metrics.Forwarded)
+	require.EqualValues(t, 1, metrics.Dropped)
+	require.EqualValues(t, 1, metrics.DeadLettered)
+	require.EqualValues(t, 1, metrics.Errors)
+	require.False(t, metrics.LastForwarded.IsZero())
+
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "forwarding message retry")
+}
+
+func TestBridge_MaxMessagesPerSecond(t *testing.T) {
+	source := &fakeBridgeSource{}
+
+	for _, id := range []string{"1", "2", "3"} {
+		source.pending = append(source.pending, &ReceivedMessage{MessageID: id})
+	}
+
+	dest := &fakeBridgeDestination{}
+
+	transform := func(ctx context.Context, m *ReceivedMessage) (*Message, error) {
+		return &Message{}, nil
+	}
+
+	start := time.Now()
+	bridge := newBridge(source, dest, transform, &BridgeOptions{MaxMessagesPerSecond: 20})
+
+	require.Eventually(t, func() bool {
+		return bridge.Metrics().Forwarded == 3
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// the second and third messages each wait 50ms
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	require.NoError(t, bridge.Close(context.Background()))
+}
+
+func TestNewBridge_Errors(t *testing.T) {
+	transform := func(ctx context.Context, m *ReceivedMessage) (*Message, error) { return nil, nil }
+
+	_, err := NewBridge(SubscriptionSpec{TopicName: "topic", SubscriptionName: "sub"}, QueueSpec{QueueName: "queue"}, transform, nil)
+	require.Error(t, err)
+
+	client := &Client{}
+
+	_, err = NewBridge(SubscriptionSpec{Client: client}, QueueSpec{Client: client}, nil, nil)
+	require.Error(t, err)
+
+	_, err = NewBridge(SubscriptionSpec{Client: client, ReceiverOptions: &ReceiverOptions{ReceiveMode: ReceiveModeReceiveAndDelete}}, QueueSpec{Client: client}, transform, nil)
+	require.Error(t, err)
+}
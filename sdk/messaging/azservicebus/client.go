@@ -7,9 +7,11 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"io"
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/internal/log"
@@ -55,6 +57,21 @@ type ClientOptions struct {
 	// RetryOptions controls how often operations are retried from this client and any
 	// Receivers and Senders created from this client.
 	RetryOptions RetryOptions
+
+	// FrameCapture, if set, receives a trace of the AMQP frames (performatives) the client sends
+	// and receives, one per line. This is intended for diagnosing connection and link issues.
+	// Traces don't include message bodies or credentials, but do include entity names and
+	// message delivery details. Capturing stops after FrameCaptureDuration or FrameCaptureMaxBytes,
+	// whichever comes first.
+	FrameCapture io.Writer
+
+	// FrameCaptureDuration limits how long the client writes to FrameCapture, starting when the
+	// client is created. The default is 5 minutes.
+	FrameCaptureDuration time.Duration
+
+	// FrameCaptureMaxBytes limits how many bytes the client writes to FrameCapture.
+	// The default is 10MiB.
+	FrameCaptureMaxBytes int64
 }
 
 // RetryOptions controls how often operations are retried from this client and any
@@ -147,6 +164,11 @@ func newClientImpl(creds clientCreds, options *ClientOptions) (*Client, error) {
 			nsOptions = append(nsOptions, internal.NamespaceWithUserAgent(options.ApplicationID))
 		}
 
+		if options.FrameCapture != nil {
+			frameCapture := internal.NewFrameCapture(options.FrameCapture, options.FrameCaptureDuration, options.FrameCaptureMaxBytes)
+			nsOptions = append(nsOptions, internal.NamespaceWithFrameCapture(frameCapture))
+		}
+
 		nsOptions = append(nsOptions, internal.NamespaceWithRetryOptions(options.RetryOptions))
 	}
 
@@ -194,18 +216,50 @@ func (client *Client) NewReceiverForSubscription(topicName string, subscriptionN
 
 // NewSenderOptions contains optional parameters for Client.NewSender
 type NewSenderOptions struct {
-	// For future expansion
+	// MessageIDGenerator, if set, is called to assign a MessageID to messages
+	// that are sent or scheduled without one. It can be used to create IDs with
+	// ordering properties, like ULIDs, or deterministic IDs for duplicate detection.
+	MessageIDGenerator func(message *Message) string
+
+	// CorrelationIDGenerator, if set, is called to assign a CorrelationID to messages
+	// that are sent or scheduled without one. It's called after MessageIDGenerator.
+	CorrelationIDGenerator func(message *Message) string
+
+	// MaxInFlightSends is the maximum number of Sender.SendAsync calls that can be
+	// in progress at once. Defaults to 100.
+	MaxInFlightSends int
+
+	// BodyEncryption, when set, encrypts the bodies of the messages the Sender sends
+	// or schedules, using data keys wrapped by a KeyWrapper such as a Key Vault key.
+	BodyEncryption *BodyEncryptionOptions
+
+	// PartitionAffinity, when set, makes the Sender assign partition keys to the messages
+	// it sends without one, avoiding partitions the service throttles. Use it with
+	// partitioned queues and topics.
+	PartitionAffinity *PartitionAffinityOptions
 }
 
 // NewSender creates a Sender, which allows you to send messages or schedule messages.
 func (client *Client) NewSender(queueOrTopic string, options *NewSenderOptions) (*Sender, error) {
 	id, cleanupOnClose := client.getCleanupForCloseable()
-	sender, err := newSender(newSenderArgs{
+	args := newSenderArgs{
 		ns:             client.namespace,
 		queueOrTopic:   queueOrTopic,
 		cleanupOnClose: cleanupOnClose,
 		retryOptions:   client.retryOptions,
-	})
+	}
+
+	if options != nil {
+		args.idGenerators = idGenerators{
+			messageID:     options.MessageIDGenerator,
+			correlationID: options.CorrelationIDGenerator,
+		}
+		args.maxInFlightSends = options.MaxInFlightSends
+		args.bodyEncryption = options.BodyEncryption
+		args.partitionAffinity = options.PartitionAffinity
+	}
+
+	sender, err := newSender(args)
 
 	if err != nil {
 		return nil, err
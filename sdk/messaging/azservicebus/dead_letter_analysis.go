@@ -0,0 +1,209 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const (
+	defaultDeadLetterAnalysisMaxMessages = 1000
+	defaultDeadLetterAnalysisMaxSamples  = 3
+	deadLetterAnalysisPageSize           = 100
+)
+
+var (
+	// descriptions often contain IDs and numbers that differ between otherwise identical failures
+	guidPattern   = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	numberPattern = regexp.MustCompile(`\d+`)
+)
+
+// AnalyzeDeadLettersOptions contains optional parameters for the AnalyzeDeadLetters function.
+type AnalyzeDeadLettersOptions struct {
+	// MaxMessages is the maximum number of messages to peek.
+	// Default is 1000.
+	MaxMessages int
+
+	// MaxSamplesPerGroup is the maximum number of messages kept as samples for each group.
+	// Default is 3.
+	MaxSamplesPerGroup int
+}
+
+// DeadLetterAnalysis is the result of the AnalyzeDeadLetters function.
+type DeadLetterAnalysis struct {
+	// MessagesPeeked is the number of messages that were peeked.
+	MessagesPeeked int
+
+	// Groups contains the messages grouped by DeadLetterReason and DeadLetterErrorDescription,
+	// ordered by descending Count.
+	Groups []DeadLetterGroup
+}
+
+// DeadLetterGroup is a group of dead-lettered messages with the same reason and
+// similar error descriptions.
+type DeadLetterGroup struct {
+	// Reason is the DeadLetterReason of the messages in the group.
+	Reason string
+
+	// ErrorDescriptionPattern is the DeadLetterErrorDescription of the messages in the group,
+	// with GUIDs replaced by "<guid>" and numbers replaced by "<n>".
+	ErrorDescriptionPattern string
+
+	// Count is the number of messages in the group.
+	Count int
+
+	// OldestEnqueuedTime is the earliest EnqueuedTime of the messages in the group.
+	OldestEnqueuedTime *time.Time
+
+	// NewestEnqueuedTime is the latest EnqueuedTime of the messages in the group.
+	NewestEnqueuedTime *time.Time
+
+	// Samples contains the first messages that were peeked for the group.
+	Samples []*ReceivedMessage
+}
+
+// AnalyzeDeadLetters peeks messages and groups them by their DeadLetterReason and a pattern of their
+// DeadLetterErrorDescription, to triage a dead-letter queue without receiving its messages. Use a
+// Receiver for a dead-letter queue, created with ReceiverOptions.SubQueue set to SubQueueDeadLetter.
+//
+// Messages are peeked from the beginning of the queue, regardless of previous calls to PeekMessages.
+// If the operation fails it can return an *azservicebus.Error type if the failure is actionable.
+func (r *Receiver) AnalyzeDeadLetters(ctx context.Context, options *AnalyzeDeadLettersOptions) (DeadLetterAnalysis, error) {
+	maxMessages := defaultDeadLetterAnalysisMaxMessages
+	maxSamples := defaultDeadLetterAnalysisMaxSamples
+
+	if options != nil {
+		if options.MaxMessages > 0 {
+			maxMessages = options.MaxMessages
+		}
+
+		if options.MaxSamplesPerGroup > 0 {
+			maxSamples = options.MaxSamplesPerGroup
+		}
+	}
+
+	analyzer := newDeadLetterAnalyzer(maxSamples)
+	fromSequenceNumber := int64(0)
+
+	for analyzer.peeked < maxMessages {
+		pageSize := deadLetterAnalysisPageSize
+
+		if remaining := maxMessages - analyzer.peeked; remaining < pageSize {
+			pageSize = remaining
+		}
+
+		messages, err := r.PeekMessages(ctx, pageSize, &PeekMessagesOptions{
+			FromSequenceNumber: &fromSequenceNumber,
+		})
+
+		if err != nil {
+			return DeadLetterAnalysis{}, err
+		}
+
+		if len(messages) == 0 {
+			break
+		}
+
+		for _, m := range messages {
+			analyzer.add(m)
+		}
+
+		fromSequenceNumber = *messages[len(messages)-1].SequenceNumber + 1
+	}
+
+	return analyzer.analysis(), nil
+}
+
+type deadLetterGroupKey struct {
+	reason  string
+	pattern string
+}
+
+type deadLetterAnalyzer struct {
+	maxSamples int
+	peeked     int
+	groups     map[deadLetterGroupKey]*DeadLetterGroup
+}
+
+func newDeadLetterAnalyzer(maxSamples int) *deadLetterAnalyzer {
+	return &deadLetterAnalyzer{
+		maxSamples: maxSamples,
+		groups:     map[deadLetterGroupKey]*DeadLetterGroup{},
+	}
+}
+
+func (a *deadLetterAnalyzer) add(m *ReceivedMessage) {
+	a.peeked++
+
+	key := deadLetterGroupKey{}
+
+	if m.DeadLetterReason != nil {
+		key.reason = *m.DeadLetterReason
+	}
+
+	if m.DeadLetterErrorDescription != nil {
+		key.pattern = errorDescriptionPattern(*m.DeadLetterErrorDescription)
+	}
+
+	group, ok := a.groups[key]
+
+	if !ok {
+		group = &DeadLetterGroup{
+			Reason:                  key.reason,
+			ErrorDescriptionPattern: key.pattern,
+		}
+		a.groups[key] = group
+	}
+
+	group.Count++
+
+	if len(group.Samples) < a.maxSamples {
+		group.Samples = append(group.Samples, m)
+	}
+
+	if t := m.EnqueuedTime; t != nil {
+		if group.OldestEnqueuedTime == nil || t.Before(*group.OldestEnqueuedTime) {
+			group.OldestEnqueuedTime = t
+		}
+
+		if group.NewestEnqueuedTime == nil || t.After(*group.NewestEnqueuedTime) {
+			group.NewestEnqueuedTime = t
+		}
+	}
+}
+
+func (a *deadLetterAnalyzer) analysis() DeadLetterAnalysis {
+	analysis := DeadLetterAnalysis{
+		MessagesPeeked: a.peeked,
+	}
+
+	for _, g := range a.groups {
+		analysis.Groups = append(analysis.Groups, *g)
+	}
+
+	sort.Slice(analysis.Groups, func(i, j int) bool {
+		gi, gj := analysis.Groups[i], analysis.Groups[j]
+
+		if gi.Count != gj.Count {
+			return gi.Count > gj.Count
+		}
+
+		if gi.Reason != gj.Reason {
+			return gi.Reason < gj.Reason
+		}
+
+		return gi.ErrorDescriptionPattern < gj.ErrorDescriptionPattern
+	})
+
+	return analysis
+}
+
+// errorDescriptionPattern replaces the parts of a description that vary between messages
+func errorDescriptionPattern(description string) string {
+	description = guidPattern.ReplaceAllString(description, "<guid>")
+	return numberPattern.ReplaceAllString(description, "<n>")
+}
@@ -0,0 +1,50 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterAnalyzer(t *testing.T) {
+	now := time.Now()
+	analyzer := newDeadLetterAnalyzer(2)
+
+	newMessage := func(reason string, description string, enqueued time.Time) *ReceivedMessage {
+		return &ReceivedMessage{
+			DeadLetterReason:           to.Ptr(reason),
+			DeadLetterErrorDescription: to.Ptr(description),
+			EnqueuedTime:               to.Ptr(enqueued),
+		}
+	}
+
+	analyzer.add(newMessage("MaxDeliveryCountExceeded", "Message could not be consumed after 10 delivery attempts.", now))
+	analyzer.add(newMessage("ProcessingFailed", "order 1234 not found", now.Add(-time.Minute)))
+	analyzer.add(newMessage("ProcessingFailed", "order 5678 not found", now))
+	analyzer.add(newMessage("ProcessingFailed", "order 9 not found", now.Add(time.Minute)))
+	analyzer.add(newMessage("ProcessingFailed", "customer 3f2504e0-4f89-11d3-9a0c-0305e82c3301 is disabled", now))
+	analyzer.add(&ReceivedMessage{})
+
+	analysis := analyzer.analysis()
+	require.Equal(t, 6, analysis.MessagesPeeked)
+	require.Len(t, analysis.Groups, 4)
+
+	orders := analysis.Groups[0]
+	require.Equal(t, "ProcessingFailed", orders.Reason)
+	require.Equal(t, "order <n> not found", orders.ErrorDescriptionPattern)
+	require.Equal(t, 3, orders.Count)
+	require.Len(t, orders.Samples, 2)
+	require.Equal(t, now.Add(-time.Minute), *orders.OldestEnqueuedTime)
+	require.Equal(t, now.Add(time.Minute), *orders.NewestEnqueuedTime)
+
+	// groups with the same count are ordered by reason and pattern
+	require.Equal(t, "", analysis.Groups[1].Reason)
+	require.Nil(t, analysis.Groups[1].OldestEnqueuedTime)
+	require.Equal(t, "Message could not be consumed after <n> delivery attempts.", analysis.Groups[2].ErrorDescriptionPattern)
+	require.Equal(t, "customer <guid> is disabled", analysis.Groups[3].ErrorDescriptionPattern)
+}
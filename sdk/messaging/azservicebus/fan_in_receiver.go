@@ -0,0 +1,222 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFanInMaxMessagesPerReceive = 10
+	fanInErrorDelay                   = time.Second
+)
+
+// ClientEntityPair identifies a queue or subscription, and the Client for its namespace, for a FanInReceiver.
+type ClientEntityPair struct {
+	// Client is the Client for the entity's namespace.
+	Client *Client
+
+	// QueueName is the name of the queue. Set it, or TopicName and SubscriptionName.
+	QueueName string
+
+	// TopicName is the name of the topic, when receiving from a subscription.
+	TopicName string
+
+	// SubscriptionName is the name of the subscription, when receiving from a subscription.
+	SubscriptionName string
+}
+
+// FanInReceiverOptions contains optional parameters for NewFanInReceiver.
+type FanInReceiverOptions struct {
+	// ReceiverOptions are used to create the Receiver for each source.
+	ReceiverOptions *ReceiverOptions
+
+	// MaxMessagesPerReceive is the maximum number of messages requested from a source at a time. Defaults to 10.
+	MaxMessagesPerReceive int
+
+	// OnError is called when receiving from a source fails. The FanInReceiver keeps receiving from the source after
+	// a delay. If OnError is nil, errors are ignored.
+	OnError func(source ClientEntityPair, err error)
+}
+
+// FanInMessage is a message received by a FanInReceiver, along with the source it was received from.
+type FanInMessage struct {
+	*ReceivedMessage
+
+	// Source is the source the message was received from.
+	Source ClientEntityPair
+
+	// SourceIndex is the index of Source in the sources passed to NewFanInReceiver.
+	SourceIndex int
+
+	receiver *Receiver
+}
+
+// FanInReceiver receives messages from several queues or subscriptions, which can be in different namespaces, and
+// merges them into a single channel. Messages are settled with the Receiver for the source they came from.
+type FanInReceiver struct {
+	messages  chan *FanInMessage
+	receivers []*Receiver
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewFanInReceiver creates a FanInReceiver for the sources, which starts receiving messages immediately. Call Close
+// to stop receiving.
+func NewFanInReceiver(sources []ClientEntityPair, options *FanInReceiverOptions) (*FanInReceiver, error) {
+	if len(sources) == 0 {
+		return nil, errors.New("at least one source is required")
+	}
+
+	if options == nil {
+		options = &FanInReceiverOptions{}
+	}
+
+	var receivers []*Receiver
+
+	for _, source := range sources {
+		receiver, err := newFanInSourceReceiver(source, options.ReceiverOptions)
+
+		if err != nil {
+			for _, r := range receivers {
+				_ = r.Close(context.Background())
+			}
+
+			return nil, err
+		}
+
+		receivers = append(receivers, receiver)
+	}
+
+	return newFanInReceiver(sources, receivers, options), nil
+}
+
+func newFanInSourceReceiver(source ClientEntityPair, options *ReceiverOptions) (*Receiver, error) {
+	if source.Client == nil {
+		return nil, errors.New("each source needs a Client")
+	}
+
+	if source.QueueName != "" {
+		return source.Client.NewReceiverForQueue(source.QueueName, options)
+	}
+
+	return source.Client.NewReceiverForSubscription(source.TopicName, source.SubscriptionName, options)
+}
+
+func newFanInReceiver(sources []ClientEntityPair, receivers []*Receiver, options *FanInReceiverOptions) *FanInReceiver {
+	maxMessages := options.MaxMessagesPerReceive
+
+	if maxMessages <= 0 {
+		maxMessages = defaultFanInMaxMessagesPerReceive
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fr := &FanInReceiver{
+		messages:  make(chan *FanInMessage),
+		receivers: receivers,
+		cancel:    cancel,
+	}
+
+	for i := range receivers {
+		fr.wg.Add(1)
+
+		go func(i int) {
+			defer fr.wg.Done()
+			fr.receiveLoop(ctx, i, sources[i], maxMessages, options.OnError)
+		}(i)
+	}
+
+	go func() {
+		fr.wg.Wait()
+		close(fr.messages)
+	}()
+
+	return fr
+}
+
+func (fr *FanInReceiver) receiveLoop(ctx context.Context, index int, source ClientEntityPair, maxMessages int, onError func(ClientEntityPair, error)) {
+	receiver := fr.receivers[index]
+
+	for {
+		messages, err := receiver.ReceiveMessages(ctx, maxMessages, nil)
+
+		for _, m := range messages {
+			select {
+			case fr.messages <- &FanInMessage{ReceivedMessage: m, Source: source, SourceIndex: index, receiver: receiver}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			if onError != nil {
+				onError(source, err)
+			}
+
+			select {
+			case <-time.After(fanInErrorDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Messages returns the channel of received messages. It's closed after Close is called.
+func (fr *FanInReceiver) Messages() <-chan *FanInMessage {
+	return fr.messages
+}
+
+// CompleteMessage completes a message with the Receiver for its source.
+func (fr *FanInReceiver) CompleteMessage(ctx context.Context, message *FanInMessage, options *CompleteMessageOptions) error {
+	return message.receiver.CompleteMessage(ctx, message.ReceivedMessage, options)
+}
+
+// AbandonMessage abandons a message with the Receiver for its source.
+func (fr *FanInReceiver) AbandonMessage(ctx context.Context, message *FanInMessage, options *AbandonMessageOptions) error {
+	return message.receiver.AbandonMessage(ctx, message.ReceivedMessage, options)
+}
+
+// DeferMessage defers a message with the Receiver for its source.
+func (fr *FanInReceiver) DeferMessage(ctx context.Context, message *FanInMessage, options *DeferMessageOptions) error {
+	return message.receiver.DeferMessage(ctx, message.ReceivedMessage, options)
+}
+
+// DeadLetterMessage dead letters a message with the Receiver for its source.
+func (fr *FanInReceiver) DeadLetterMessage(ctx context.Context, message *FanInMessage, options *DeadLetterOptions) error {
+	return message.receiver.DeadLetterMessage(ctx, message.ReceivedMessage, options)
+}
+
+// RenewMessageLock renews the lock on a message with the Receiver for its source.
+func (fr *FanInReceiver) RenewMessageLock(ctx context.Context, message *FanInMessage, options *RenewMessageLockOptions) error {
+	return message.receiver.RenewMessageLock(ctx, message.ReceivedMessage, options)
+}
+
+// Close stops receiving and closes the Receivers for the sources. Messages already received can't be settled
+// after Close.
+func (fr *FanInReceiver) Close(ctx context.Context) error {
+	var err error
+
+	fr.closeOnce.Do(func() {
+		fr.cancel()
+		fr.wg.Wait()
+
+		for _, r := range fr.receivers {
+			if closeErr := r.Close(ctx); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,79 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/go-amqp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanInReceiver(t *testing.T) {
+	newFakeReceiver := func(bodies ...string) *Receiver {
+		fakeAMQPReceiver := &internal.FakeAMQPReceiver{}
+
+		for _, body := range bodies {
+			fakeAMQPReceiver.ReceiveResults = append(fakeAMQPReceiver.ReceiveResults, struct {
+				M *amqp.Message
+				E error
+			}{M: &amqp.Message{Data: [][]byte{[]byte(body)}}})
+		}
+
+		receiver, err := newReceiver(newReceiverArgs{
+			ns:             &internal.FakeNS{AMQPLinks: &internal.FakeAMQPLinks{Receiver: fakeAMQPReceiver}},
+			entity:         entity{Queue: "queue"},
+			cleanupOnClose: func() {},
+		}, nil)
+		require.NoError(t, err)
+
+		return receiver
+	}
+
+	sources := []ClientEntityPair{
+		{QueueName: "west"},
+		{TopicName: "topic", SubscriptionName: "east"},
+	}
+	receivers := []*Receiver{
+		newFakeReceiver("west 1", "west 2"),
+		newFakeReceiver("east 1"),
+	}
+
+	fr := newFanInReceiver(sources, receivers, &FanInReceiverOptions{MaxMessagesPerReceive: 2})
+
+	received := map[string]*FanInMessage{}
+
+	for len(received) < 3 {
+		m := <-fr.Messages()
+		received[string(m.Body)] = m
+	}
+
+	for body, m := range received {
+		if body == "east 1" {
+			require.Equal(t, 1, m.SourceIndex)
+			require.Equal(t, "east", m.Source.SubscriptionName)
+			require.Same(t, receivers[1], m.receiver)
+		} else {
+			require.Equal(t, 0, m.SourceIndex)
+			require.Equal(t, "west", m.Source.QueueName)
+			require.Same(t, receivers[0], m.receiver)
+		}
+	}
+
+	require.NoError(t, fr.Close(context.Background()))
+	require.NoError(t, fr.Close(context.Background()))
+
+	_, open := <-fr.Messages()
+	require.False(t, open)
+}
+
+func TestNewFanInReceiver_Errors(t *testing.T) {
+	_, err := NewFanInReceiver(nil, nil)
+	require.Error(t, err)
+
+	_, err = NewFanInReceiver([]ClientEntityPair{{QueueName: "queue"}}, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,169 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/internal/uuid"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/admin"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/utils"
+)
+
+const (
+	defaultFilteredReceiverAutoDeleteOnIdle = time.Hour
+
+	// minFilteredReceiverAutoDeleteOnIdle is the shortest AutoDeleteOnIdle the service allows
+	minFilteredReceiverAutoDeleteOnIdle = 5 * time.Minute
+
+	filteredReceiverRuleName           = "filter"
+	filteredReceiverSubscriptionPrefix = "filtered-"
+)
+
+// FilteredReceiverOptions contains optional parameters for Client.NewFilteredReceiver.
+type FilteredReceiverOptions struct {
+	// Parameters are the values of the parameters in the filter expression, such as "@region".
+	// Values can be strings, numbers or booleans.
+	Parameters map[string]interface{}
+
+	// SubscriptionName is the name of the subscription to create. Defaults to "filtered-" followed
+	// by a random UUID. Creating the FilteredReceiver fails if the subscription exists.
+	SubscriptionName string
+
+	// AutoDeleteOnIdle is how long the subscription remains after it was last used, so that it's
+	// deleted by the service if the FilteredReceiver isn't closed, for instance when its process
+	// crashes. Defaults to one hour. The minimum is five minutes.
+	AutoDeleteOnIdle time.Duration
+
+	// KeepSubscription makes Close leave the subscription, and its messages, to be received
+	// later. The service still deletes it once it's been idle for AutoDeleteOnIdle.
+	KeepSubscription bool
+
+	// ReceiverOptions are used to create the Receiver for the subscription.
+	ReceiverOptions *ReceiverOptions
+}
+
+// FilteredReceiver is a Receiver for a subscription created by Client.NewFilteredReceiver, which
+// receives only the messages sent to the topic that match a SQL filter. Close it to close the
+// Receiver and delete the subscription.
+type FilteredReceiver struct {
+	*Receiver
+
+	// SubscriptionName is the name of the subscription.
+	SubscriptionName string
+
+	topicName        string
+	admin            filteredReceiverAdmin
+	keepSubscription bool
+	closeOnce        sync.Once
+	closeErr         error
+}
+
+// filteredReceiverAdmin is the part of an *admin.Client NewFilteredReceiver uses
+type filteredReceiverAdmin interface {
+	CreateSubscription(ctx context.Context, topicName string, subscriptionName string, options *admin.CreateSubscriptionOptions) (admin.CreateSubscriptionResponse, error)
+	DeleteSubscription(ctx context.Context, topicName string, subscriptionName string, options *admin.DeleteSubscriptionOptions) (admin.DeleteSubscriptionResponse, error)
+}
+
+// NewFilteredReceiver creates a subscription to a topic that receives only the messages matching
+// sqlFilter, such as "region = 'eu'", and a Receiver for it. Filtering is done by the service, so
+// messages that don't match aren't transferred to the client. The subscription is created with the
+// filter in place of the default rule, so it never receives a message the filter doesn't match, and
+// it only receives the messages sent after it's created.
+//
+// adminClient must be for the same namespace as client, and have permission to manage entities. The
+// subscription is deleted when the FilteredReceiver is closed or, if it isn't, by the service after it's
+// been idle for FilteredReceiverOptions.AutoDeleteOnIdle.
+func (client *Client) NewFilteredReceiver(ctx context.Context, adminClient *admin.Client, topicName string, sqlFilter string, options *FilteredReceiverOptions) (*FilteredReceiver, error) {
+	if adminClient == nil {
+		return nil, errors.New("adminClient is required")
+	}
+
+	return client.newFilteredReceiver(ctx, adminClient, topicName, sqlFilter, options)
+}
+
+func (client *Client) newFilteredReceiver(ctx context.Context, adminClient filteredReceiverAdmin, topicName string, sqlFilter string, options *FilteredReceiverOptions) (*FilteredReceiver, error) {
+	if options == nil {
+		options = &FilteredReceiverOptions{}
+	}
+
+	if topicName == "" {
+		return nil, errors.New("topicName is required")
+	}
+
+	if sqlFilter == "" {
+		return nil, errors.New("sqlFilter is required")
+	}
+
+	autoDeleteOnIdle := options.AutoDeleteOnIdle
+
+	if autoDeleteOnIdle == 0 {
+		autoDeleteOnIdle = defaultFilteredReceiverAutoDeleteOnIdle
+	} else if autoDeleteOnIdle < minFilteredReceiverAutoDeleteOnIdle {
+		return nil, fmt.Errorf("AutoDeleteOnIdle must be at least %s", minFilteredReceiverAutoDeleteOnIdle)
+	}
+
+	subscriptionName := options.SubscriptionName
+
+	if subscriptionName == "" {
+		id, err := uuid.New()
+
+		if err != nil {
+			return nil, err
+		}
+
+		subscriptionName = filteredReceiverSubscriptionPrefix + id.String()
+	}
+
+	_, err := adminClient.CreateSubscription(ctx, topicName, subscriptionName, &admin.CreateSubscriptionOptions{
+		Properties: &admin.SubscriptionProperties{
+			AutoDeleteOnIdle: utils.DurationToStringPtr(&autoDeleteOnIdle),
+		},
+		DefaultRule: &admin.RuleProperties{
+			Name:   filteredReceiverRuleName,
+			Filter: &admin.SQLFilter{Expression: sqlFilter, Parameters: options.Parameters},
+		},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	receiver, err := client.NewReceiverForSubscription(topicName, subscriptionName, options.ReceiverOptions)
+
+	if err != nil {
+		_, _ = adminClient.DeleteSubscription(ctx, topicName, subscriptionName, nil)
+		return nil, err
+	}
+
+	return &FilteredReceiver{
+		Receiver:         receiver,
+		SubscriptionName: subscriptionName,
+		topicName:        topicName,
+		admin:            adminClient,
+		keepSubscription: options.KeepSubscription,
+	}, nil
+}
+
+// Close closes the Receiver and deletes the subscription, unless FilteredReceiverOptions.KeepSubscription
+// was set. Messages that weren't received are deleted with the subscription. It returns the first error.
+func (fr *FilteredReceiver) Close(ctx context.Context) error {
+	fr.closeOnce.Do(func() {
+		fr.closeErr = fr.Receiver.Close(ctx)
+
+		if fr.keepSubscription {
+			return
+		}
+
+		if _, err := fr.admin.DeleteSubscription(ctx, fr.topicName, fr.SubscriptionName, nil); err != nil && fr.closeErr == nil {
+			fr.closeErr = err
+		}
+	})
+
+	return fr.closeErr
+}
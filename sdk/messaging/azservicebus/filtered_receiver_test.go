@@ -0,0 +1,96 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/admin"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFilteredReceiverAdmin struct {
+	created   map[string]*admin.CreateSubscriptionOptions
+	deleted   []string
+	createErr error
+}
+
+func (a *fakeFilteredReceiverAdmin) CreateSubscription(ctx context.Context, topicName string, subscriptionName string, options *admin.CreateSubscriptionOptions) (admin.CreateSubscriptionResponse, error) {
+	if a.createErr != nil {
+		return admin.CreateSubscriptionResponse{}, a.createErr
+	}
+
+	a.created[topicName+"/"+subscriptionName] = options
+	return admin.CreateSubscriptionResponse{}, nil
+}
+
+func (a *fakeFilteredReceiverAdmin) DeleteSubscription(ctx context.Context, topicName string, subscriptionName string, options *admin.DeleteSubscriptionOptions) (admin.DeleteSubscriptionResponse, error) {
+	a.deleted = append(a.deleted, topicName+"/"+subscriptionName)
+	return admin.DeleteSubscriptionResponse{}, nil
+}
+
+func TestFilteredReceiver(t *testing.T) {
+	client, err := NewClientFromConnectionString("Endpoint=sb://fake.servicebus.windows.net/;SharedAccessKeyName=key;SharedAccessKey=secret", nil)
+	require.NoError(t, err)
+
+	fakeAdmin := &fakeFilteredReceiverAdmin{created: map[string]*admin.CreateSubscriptionOptions{}}
+
+	receiver, err := client.newFilteredReceiver(context.Background(), fakeAdmin, "orders", "region = @region", &FilteredReceiverOptions{
+		Parameters: map[string]interface{}{"@region": "eu"},
+	})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(receiver.SubscriptionName, "filtered-"))
+
+	created := fakeAdmin.created["orders/"+receiver.SubscriptionName]
+	require.NotNil(t, created)
+	require.Equal(t, "PT60M0S", *created.Properties.AutoDeleteOnIdle)
+	require.Equal(t, &admin.RuleProperties{
+		Name:   "filter",
+		Filter: &admin.SQLFilter{Expression: "region = @region", Parameters: map[string]interface{}{"@region": "eu"}},
+	}, created.DefaultRule)
+
+	require.NoError(t, receiver.Close(context.Background()))
+	require.NoError(t, receiver.Close(context.Background()))
+	require.Equal(t, []string{"orders/" + receiver.SubscriptionName}, fakeAdmin.deleted)
+
+	// a kept subscription isn't deleted
+	fakeAdmin.deleted = nil
+	receiver, err = client.newFilteredReceiver(context.Background(), fakeAdmin, "orders", "region = 'eu'", &FilteredReceiverOptions{
+		SubscriptionName: "eu",
+		AutoDeleteOnIdle: 10 * time.Minute,
+		KeepSubscription: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "eu", receiver.SubscriptionName)
+	require.Equal(t, "PT10M0S", *fakeAdmin.created["orders/eu"].Properties.AutoDeleteOnIdle)
+	require.NoError(t, receiver.Close(context.Background()))
+	require.Empty(t, fakeAdmin.deleted)
+
+	fakeAdmin.createErr = errors.New("subscription exists")
+	_, err = client.newFilteredReceiver(context.Background(), fakeAdmin, "orders", "region = 'eu'", &FilteredReceiverOptions{SubscriptionName: "eu"})
+	require.EqualError(t, err, "subscription exists")
+}
+
+func TestNewFilteredReceiver_Errors(t *testing.T) {
+	client := &Client{}
+	fakeAdmin := &fakeFilteredReceiverAdmin{created: map[string]*admin.CreateSubscriptionOptions{}}
+
+	_, err := client.NewFilteredReceiver(context.Background(), nil, "orders", "region = 'eu'", nil)
+	require.Error(t, err)
+
+	_, err = client.newFilteredReceiver(context.Background(), fakeAdmin, "", "region = 'eu'", nil)
+	require.Error(t, err)
+
+	_, err = client.newFilteredReceiver(context.Background(), fakeAdmin, "orders", "", nil)
+	require.Error(t, err)
+
+	_, err = client.newFilteredReceiver(context.Background(), fakeAdmin, "orders", "region = 'eu'", &FilteredReceiverOptions{AutoDeleteOnIdle: time.Minute})
+	require.Error(t, err)
+
+	require.Empty(t, fakeAdmin.created)
+}
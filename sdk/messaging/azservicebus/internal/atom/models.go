@@ -169,9 +169,10 @@ type (
 	}
 	// DefaultRuleDescription is the content type for Subscription Rule management requests
 	DefaultRuleDescription struct {
-		XMLName xml.Name          `xml:"DefaultRuleDescription"`
-		Filter  FilterDescription `xml:"Filter"`
-		Name    *string           `xml:"Name,omitempty"`
+		XMLName xml.Name           `xml:"DefaultRuleDescription"`
+		Filter  FilterDescription  `xml:"Filter"`
+		Action  *ActionDescription `xml:"Action,omitempty"`
+		Name    *string            `xml:"Name,omitempty"`
 	}
 
 	// FilterDescription describes a filter which can be applied to a subscription to filter messages from the topic.
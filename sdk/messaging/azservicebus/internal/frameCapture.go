@@ -0,0 +1,87 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFrameCaptureDuration = 5 * time.Minute
+	defaultFrameCaptureMaxBytes = 10 * 1024 * 1024
+)
+
+// FrameCapture writes traces of AMQP frames to a writer until its time or
+// byte budget is exhausted. It's shared by all the connections a Namespace
+// opens, so the budget covers connection recovery as well.
+type FrameCapture struct {
+	mu        sync.Mutex
+	w         io.Writer
+	deadline  time.Time
+	remaining int64
+
+	// now exists so tests can control the clock
+	now func() time.Time
+}
+
+// NewFrameCapture creates a FrameCapture that writes to w for the specified duration or
+// until it has written maxBytes. Non-positive values select the defaults.
+func NewFrameCapture(w io.Writer, duration time.Duration, maxBytes int64) *FrameCapture {
+	if duration <= 0 {
+		duration = defaultFrameCaptureDuration
+	}
+
+	if maxBytes <= 0 {
+		maxBytes = defaultFrameCaptureMaxBytes
+	}
+
+	fc := &FrameCapture{
+		w:         w,
+		remaining: maxBytes,
+		now:       time.Now,
+	}
+
+	fc.deadline = fc.now().Add(duration)
+	return fc
+}
+
+// Trace writes a single frame trace. It's compatible with amqp.ConnFrameTracer.
+func (fc *FrameCapture) Trace(tx bool, channel uint16, frame string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	if fc.w == nil {
+		return
+	}
+
+	now := fc.now()
+
+	if !now.Before(fc.deadline) {
+		fc.w = nil
+		return
+	}
+
+	direction := "RX"
+
+	if tx {
+		direction = "TX"
+	}
+
+	line := fmt.Sprintf("%s %s [channel %d] %s\n", now.UTC().Format(time.RFC3339Nano), direction, channel, frame)
+
+	if int64(len(line)) > fc.remaining {
+		// the budget is exhausted, stop capturing rather than writing a partial trace
+		fc.w = nil
+		return
+	}
+
+	fc.remaining -= int64(len(line))
+
+	if _, err := io.WriteString(fc.w, line); err != nil {
+		fc.w = nil
+	}
+}
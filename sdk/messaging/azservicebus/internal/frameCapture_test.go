@@ -0,0 +1,56 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameCapture(t *testing.T) {
+	buff := &bytes.Buffer{}
+	fc := NewFrameCapture(buff, time.Minute, 1024)
+
+	fc.Trace(true, 1, "Attach{Name: link}")
+	fc.Trace(false, 1, "Attach{Name: link}")
+
+	lines := strings.Split(strings.TrimSpace(buff.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], " TX [channel 1] Attach{Name: link}")
+	require.Contains(t, lines[1], " RX [channel 1] Attach{Name: link}")
+}
+
+func TestFrameCaptureByteBudget(t *testing.T) {
+	buff := &bytes.Buffer{}
+	fc := NewFrameCapture(buff, time.Minute, 100)
+
+	fc.Trace(true, 0, "Open{}")
+	written := buff.Len()
+	require.NotZero(t, written)
+
+	// a trace that would exceed the budget stops the capture
+	fc.Trace(true, 0, strings.Repeat("x", 100))
+	fc.Trace(true, 0, "Open{}")
+	require.Equal(t, written, buff.Len())
+}
+
+func TestFrameCaptureDuration(t *testing.T) {
+	buff := &bytes.Buffer{}
+	fc := NewFrameCapture(buff, time.Minute, 0)
+
+	now := time.Now()
+	fc.now = func() time.Time { return now }
+
+	fc.Trace(true, 0, "Open{}")
+	written := buff.Len()
+	require.NotZero(t, written)
+
+	now = now.Add(2 * time.Minute)
+	fc.Trace(true, 0, "Open{}")
+	require.Equal(t, written, buff.Len())
+}
@@ -155,6 +155,20 @@ func ConnContainerID(id string) ConnOption {
 	}
 }
 
+// ConnFrameTracer sets a function that's called with a description of
+// each frame the connection sends or receives. tx is true for frames
+// sent to the peer. Descriptions don't include message payloads or
+// SASL credentials. Keepalive frames aren't traced.
+//
+// The function is called synchronously from the connection's reader
+// and writer and must not block.
+func ConnFrameTracer(fn func(tx bool, channel uint16, frame string)) ConnOption {
+	return func(c *conn) error {
+		c.frameTracer = fn
+		return nil
+	}
+}
+
 // used to abstract the underlying dialer for testing purposes
 type dialer interface {
 	NetDialerDial(c *conn, host, port string) error
@@ -193,6 +207,9 @@ type conn struct {
 	properties   map[encoding.Symbol]interface{} // additional properties sent upon connection open
 	containerID  string                          // set explicitly or randomly generated
 
+	// tracing
+	frameTracer func(tx bool, channel uint16, frame string) // called for each frame sent or received, if set
+
 	// peer settings
 	peerIdleTimeout  time.Duration // maximum period between sending frames
 	PeerMaxFrameSize uint32        // maximum frame size peer will accept
@@ -667,6 +684,10 @@ func (c *conn) connReader() {
 			return
 		}
 
+		if c.frameTracer != nil {
+			c.frameTracer(false, currentHeader.Channel, fmt.Sprintf("%s", parsedBody))
+		}
+
 		// send to mux
 		select {
 		case <-c.Done:
@@ -762,6 +783,10 @@ func (c *conn) writeFrame(fr frames.Frame) error {
 		return fmt.Errorf("%T frame size %d larger than peer's max frame size %d", fr, requiredFrameSize, c.PeerMaxFrameSize)
 	}
 
+	if c.frameTracer != nil {
+		c.frameTracer(true, fr.Channel, fmt.Sprintf("%s", fr.Body))
+	}
+
 	// write to network
 	_, err = c.net.Write(c.txBuf.Bytes())
 	return err
@@ -45,6 +45,8 @@ type (
 
 		newWebSocketConn func(ctx context.Context, args exported.NewWebSocketConnArgs) (net.Conn, error)
 
+		frameCapture *FrameCapture
+
 		// NOTE: exported only so it can be checked in a test
 		RetryOptions exported.RetryOptions
 
@@ -135,6 +137,14 @@ func NamespaceWithTokenCredential(fullyQualifiedNamespace string, tokenCredentia
 	}
 }
 
+// NamespaceWithFrameCapture traces the AMQP frames of the namespace's connections to frameCapture
+func NamespaceWithFrameCapture(frameCapture *FrameCapture) NamespaceOption {
+	return func(ns *Namespace) error {
+		ns.frameCapture = frameCapture
+		return nil
+	}
+}
+
 func NamespaceWithRetryOptions(retryOptions exported.RetryOptions) NamespaceOption {
 	return func(ns *Namespace) error {
 		ns.RetryOptions = retryOptions
@@ -177,6 +187,10 @@ func (ns *Namespace) newClientImpl(ctx context.Context) (amqpwrap.AMQPClient, er
 		)
 	}
 
+	if ns.frameCapture != nil {
+		defaultConnOptions = append(defaultConnOptions, amqp.ConnFrameTracer(ns.frameCapture.Trace))
+	}
+
 	if ns.newWebSocketConn != nil {
 		nConn, err := ns.newWebSocketConn(ctx, exported.NewWebSocketConnArgs{
 			Host: ns.getWSSHostURI() + "$servicebus/websocket",
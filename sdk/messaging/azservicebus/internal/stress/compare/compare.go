@@ -0,0 +1,217 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package compare
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/stress/shared"
+)
+
+// Run runs a stress test with two versions of azservicebus, alternating between them, and prints a comparison
+// of their throughput, errors and latencies. Each version is either a checkout of the azservicebus module, from
+// which the stress binary is built, or an already built stress binary. Each run writes a shared.Report, so the
+// binaries must be built from a version of the stress harness that writes one. The -harness flag builds both
+// modules with the harness in a directory instead of their own, which also keeps the scenario the same. For
+// instance, to compare a change with the last release:
+//
+//	git worktree add /tmp/sb-release sdk/messaging/azservicebus/v1.0.1
+//	stress compare -harness internal/stress -a /tmp/sb-release/sdk/messaging/azservicebus -b . -runs 3 finiteSendAndReceive
+func Run(remainingArgs []string) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+
+	versionA := fs.String("a", "", "The baseline: the directory of an azservicebus module, or a stress binary")
+	versionB := fs.String("b", "", "The candidate: the directory of an azservicebus module, or a stress binary")
+	labelA := fs.String("alabel", "a", "The name of the baseline in the report")
+	labelB := fs.String("blabel", "b", "The name of the candidate in the report")
+	runs := fs.Int("runs", 1, "The number of times to run the test with each version")
+	harness := fs.String("harness", "", "The directory of the stress harness to build the modules with, instead of their own")
+
+	fs.Usage = func() {
+		fmt.Printf("Usage: stress compare -a <module dir|binary> -b <module dir|binary> [-runs n] <stress test name> [test args]\n")
+		fs.PrintDefaults()
+	}
+
+	_ = fs.Parse(remainingArgs)
+
+	if *versionA == "" || *versionB == "" || *runs < 1 || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	workDir, err := os.MkdirTemp("", "stress-compare")
+
+	if err != nil {
+		log.Fatalf("Failed to create a temporary directory: %s", err)
+	}
+
+	defer os.RemoveAll(workDir)
+
+	// fatalf removes the work directory, which log.Fatalf's exit would leave behind
+	fatalf := func(format string, v ...interface{}) {
+		_ = os.RemoveAll(workDir)
+		log.Fatalf(format, v...)
+	}
+
+	binA, err := stressBinary(*versionA, *harness, workDir, "stress-a")
+
+	if err != nil {
+		fatalf("Failed to build %s: %s", *versionA, err)
+	}
+
+	binB, err := stressBinary(*versionB, *harness, workDir, "stress-b")
+
+	if err != nil {
+		fatalf("Failed to build %s: %s", *versionB, err)
+	}
+
+	testArgs := append([]string{"tests"}, fs.Args()...)
+	var reportsA, reportsB []shared.Report
+
+	// alternating the versions spreads changes in the namespace's load evenly between them
+	for i := 0; i < *runs; i++ {
+		for _, v := range []struct {
+			label   string
+			bin     string
+			reports *[]shared.Report
+		}{
+			{*labelA, binA, &reportsA},
+			{*labelB, binB, &reportsB},
+		} {
+			log.Printf("[%s] Starting run %d of %d", v.label, i+1, *runs)
+
+			report, err := runTest(v.bin, testArgs, filepath.Join(workDir, fmt.Sprintf("%s-%d.json", v.label, i)))
+
+			if err != nil {
+				fatalf("[%s] Run %d failed: %s", v.label, i+1, err)
+			}
+
+			*v.reports = append(*v.reports, report)
+		}
+	}
+
+	fmt.Printf("\n%s, %d run(s) per version\n\n", fs.Arg(0), *runs)
+
+	if err := shared.PrintComparison(os.Stdout, *labelA, *labelB, shared.CompareReports(reportsA, reportsB)); err != nil {
+		fatalf("Failed to print the comparison: %s", err)
+	}
+}
+
+// stressBinary returns version when it's a binary, or builds the stress binary of the module in the
+// directory version, with the harness in the directory harness if it's set, to name in workDir.
+func stressBinary(version string, harness string, workDir string, name string) (string, error) {
+	fi, err := os.Stat(version)
+
+	if err != nil {
+		return "", err
+	}
+
+	if !fi.IsDir() {
+		return filepath.Abs(version)
+	}
+
+	log.Printf("Building the stress binary in %s", version)
+
+	args := []string{"build", "-o", filepath.Join(workDir, name)}
+
+	if harness != "" {
+		overlay, err := writeHarnessOverlay(version, harness, filepath.Join(workDir, name+"-overlay.json"))
+
+		if err != nil {
+			return "", err
+		}
+
+		args = append(args, "-overlay", overlay)
+	}
+
+	cmd := exec.Command("go", append(args, "./internal/stress")...)
+	cmd.Dir = version
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(workDir, name), nil
+}
+
+// writeHarnessOverlay writes a `go build -overlay` file, which replaces the Go files of the stress harness in the
+// module in moduleDir with the ones in harness, to path.
+func writeHarnessOverlay(moduleDir string, harness string, path string) (string, error) {
+	moduleHarness, err := filepath.Abs(filepath.Join(moduleDir, "internal", "stress"))
+
+	if err != nil {
+		return "", err
+	}
+
+	harness, err = filepath.Abs(harness)
+
+	if err != nil {
+		return "", err
+	}
+
+	replace := map[string]string{}
+
+	// the module's own files are removed, unless the harness replaces them
+	err = filepath.Walk(moduleHarness, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(p, ".go") {
+			replace[p] = ""
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	err = filepath.Walk(harness, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(p, ".go") {
+			return err
+		}
+
+		rel, err := filepath.Rel(harness, p)
+
+		if err != nil {
+			return err
+		}
+
+		replace[filepath.Join(moduleHarness, rel)] = p
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(struct{ Replace map[string]string }{replace})
+
+	if err != nil {
+		return "", err
+	}
+
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// runTest runs a stress test with bin and returns the report it writes to reportPath
+func runTest(bin string, args []string, reportPath string) (shared.Report, error) {
+	cmd := exec.Command(bin, args...)
+	cmd.Env = append(os.Environ(), shared.ReportFileEnvVar+"="+reportPath)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return shared.Report{}, err
+	}
+
+	return shared.ReadReport(reportPath)
+}
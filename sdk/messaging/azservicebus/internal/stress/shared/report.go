@@ -0,0 +1,259 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// ReportFileEnvVar is the environment variable that names the file StressContext.End writes the run's Report to.
+// `stress compare` sets it for each run, and reads the reports back to compare them.
+const ReportFileEnvVar = "STRESS_REPORT_FILE"
+
+// Report summarizes a stress test run.
+type Report struct {
+	Test      string
+	TestRunID string
+	Duration  time.Duration
+	Stats     []StatReport
+}
+
+// StatReport is the final value of a Stats.
+type StatReport struct {
+	Name      string
+	Sent      int32
+	Received  int32
+	Completed int32
+	Errors    int32
+
+	// Latencies summarizes the latencies recorded with Stats.AddLatency, by operation.
+	Latencies map[string]LatencySummary
+}
+
+// LatencySummary summarizes the latencies of an operation.
+type LatencySummary struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+func summarizeLatencies(latencies []time.Duration) LatencySummary {
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	percentile := func(p float64) time.Duration {
+		idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+
+		if idx < 0 {
+			idx = 0
+		}
+
+		return sorted[idx]
+	}
+
+	return LatencySummary{
+		Count: len(sorted),
+		P50:   percentile(0.5),
+		P90:   percentile(0.9),
+		P99:   percentile(0.99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+func (s *Stats) report() StatReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sr := StatReport{
+		Name:      s.name,
+		Sent:      atomic.LoadInt32(&s.Sent),
+		Received:  atomic.LoadInt32(&s.Received),
+		Completed: atomic.LoadInt32(&s.Completed),
+		Errors:    atomic.LoadInt32(&s.Errors),
+		Latencies: map[string]LatencySummary{},
+	}
+
+	for op, latencies := range s.latencies {
+		sr.Latencies[op] = summarizeLatencies(latencies)
+	}
+
+	return sr
+}
+
+func (sp *statsPrinter) report(test string, testRunID string) Report {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	r := Report{
+		Test:      test,
+		TestRunID: testRunID,
+		Duration:  time.Since(sp.start),
+	}
+
+	for _, stats := range sp.all {
+		r.Stats = append(r.Stats, stats.report())
+	}
+
+	return r
+}
+
+// WriteReport writes r, as JSON, to the file at path.
+func WriteReport(path string, r Report) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadReport reads a Report written by WriteReport.
+func ReadReport(path string) (Report, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return Report{}, err
+	}
+
+	var r Report
+
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Report{}, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+
+	return r, nil
+}
+
+// ComparisonRow compares a metric of a stat between two sets of runs. Each value is the mean over the runs
+// that have the metric.
+type ComparisonRow struct {
+	Stat   string
+	Metric string
+	A      float64
+	B      float64
+
+	// Unit is "/s" for throughputs, "ms" for latencies and "" for counts.
+	Unit string
+}
+
+// Change returns the relative change from A to B, as a percentage, or NaN if A is zero.
+func (row ComparisonRow) Change() float64 {
+	if row.A == 0 {
+		return math.NaN()
+	}
+
+	return (row.B - row.A) / row.A * 100
+}
+
+// CompareReports compares the runs in a with the runs in b, stat by stat, on throughput, errors and latency.
+func CompareReports(a []Report, b []Report) []ComparisonRow {
+	type key struct{ stat, metric, unit string }
+
+	// metrics maps each metric to its values for a and b
+	metrics := map[key][2][]float64{}
+	var keys []key
+
+	add := func(side int, k key, v float64) {
+		values, ok := metrics[k]
+
+		if !ok {
+			keys = append(keys, k)
+		}
+
+		values[side] = append(values[side], v)
+		metrics[k] = values
+	}
+
+	for side, reports := range [][]Report{a, b} {
+		for _, r := range reports {
+			seconds := r.Duration.Seconds()
+
+			if seconds <= 0 {
+				continue
+			}
+
+			for _, s := range r.Stats {
+				add(side, key{s.Name, "sent", "/s"}, float64(s.Sent)/seconds)
+				add(side, key{s.Name, "received", "/s"}, float64(s.Received)/seconds)
+				add(side, key{s.Name, "completed", "/s"}, float64(s.Completed)/seconds)
+				add(side, key{s.Name, "errors", ""}, float64(s.Errors))
+
+				var ops []string
+
+				for op := range s.Latencies {
+					ops = append(ops, op)
+				}
+
+				sort.Strings(ops)
+
+				for _, op := range ops {
+					l := s.Latencies[op]
+					add(side, key{s.Name, op + " p50", "ms"}, float64(l.P50)/float64(time.Millisecond))
+					add(side, key{s.Name, op + " p99", "ms"}, float64(l.P99)/float64(time.Millisecond))
+				}
+			}
+		}
+	}
+
+	mean := func(values []float64) float64 {
+		if len(values) == 0 {
+			return 0
+		}
+
+		var sum float64
+
+		for _, v := range values {
+			sum += v
+		}
+
+		return sum / float64(len(values))
+	}
+
+	var rows []ComparisonRow
+
+	for _, k := range keys {
+		values := metrics[k]
+		row := ComparisonRow{Stat: k.stat, Metric: k.metric, Unit: k.unit, A: mean(values[0]), B: mean(values[1])}
+
+		// throughputs of operations a test doesn't do are noise
+		if row.Unit == "/s" && row.A == 0 && row.B == 0 {
+			continue
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// PrintComparison writes rows as a table, with labelA and labelB as the headings of the A and B columns.
+func PrintComparison(w io.Writer, labelA string, labelB string, rows []ComparisonRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(tw, "stat\tmetric\t%s\t%s\tchange\t\n", labelA, labelB)
+
+	for _, row := range rows {
+		change := "n/a"
+
+		if c := row.Change(); !math.IsNaN(c) {
+			change = fmt.Sprintf("%+.1f%%", c)
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%.2f%s\t%.2f%s\t%s\t\n", row.Stat, row.Metric, row.A, row.Unit, row.B, row.Unit, change)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package shared
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsReport(t *testing.T) {
+	stats := NewStats("finite")
+	stats.AddSent(10)
+
+	for i := 1; i <= 100; i++ {
+		stats.AddLatency("send", time.Duration(i)*time.Millisecond)
+	}
+
+	sr := stats.report()
+	require.Equal(t, "finite", sr.Name)
+	require.EqualValues(t, 10, sr.Sent)
+	require.Equal(t, LatencySummary{
+		Count: 100,
+		P50:   50 * time.Millisecond,
+		P90:   90 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}, sr.Latencies["send"])
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	r := Report{Test: "test", Duration: time.Minute, Stats: []StatReport{sr}}
+	require.NoError(t, WriteReport(path, r))
+
+	read, err := ReadReport(path)
+	require.NoError(t, err)
+	require.Equal(t, r, read)
+}
+
+func TestCompareReports(t *testing.T) {
+	report := func(received int32, p50 time.Duration) Report {
+		return Report{
+			Duration: 10 * time.Second,
+			Stats: []StatReport{{
+				Name:      "finite",
+				Received:  received,
+				Errors:    1,
+				Latencies: map[string]LatencySummary{"complete": {P50: p50, P99: 2 * p50}},
+			}},
+		}
+	}
+
+	rows := CompareReports(
+		[]Report{report(100, 10*time.Millisecond), report(300, 30*time.Millisecond)},
+		[]Report{report(300, 10*time.Millisecond)},
+	)
+
+	require.Equal(t, []ComparisonRow{
+		{Stat: "finite", Metric: "received", Unit: "/s", A: 20, B: 30},
+		{Stat: "finite", Metric: "errors", A: 1, B: 1},
+		{Stat: "finite", Metric: "complete p50", Unit: "ms", A: 20, B: 10},
+		{Stat: "finite", Metric: "complete p99", Unit: "ms", A: 40, B: 20},
+	}, rows)
+
+	require.Equal(t, 50.0, rows[0].Change())
+	require.Equal(t, -50.0, rows[2].Change())
+	require.True(t, math.IsNaN(ComparisonRow{B: 1}.Change()))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, PrintComparison(buf, "v1.0.1", "batch", rows))
+	require.Contains(t, buf.String(), "v1.0.1")
+	require.Contains(t, buf.String(), "+50.0%")
+	require.Contains(t, buf.String(), "-50.0%")
+}
@@ -22,6 +22,10 @@ type Stats struct {
 	Errors   int32
 
 	Completed int32
+
+	mu sync.Mutex
+	// latencies holds the durations recorded with AddLatency, by operation
+	latencies map[string][]time.Duration
 }
 
 func NewStats(name string) *Stats {
@@ -50,6 +54,19 @@ func (s *Stats) AddCompleted(add int32) {
 	atomic.AddInt32(&s.Completed, add)
 }
 
+// AddLatency records how long an operation, such as "send" or "complete", took. The latencies are
+// summarized in the run's Report.
+func (s *Stats) AddLatency(operation string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.latencies == nil {
+		s.latencies = map[string][]time.Duration{}
+	}
+
+	s.latencies[operation] = append(s.latencies[operation], d)
+}
+
 func (s *Stats) String() string {
 	sent := atomic.LoadInt32(&s.Sent)
 	received := atomic.LoadInt32(&s.Received)
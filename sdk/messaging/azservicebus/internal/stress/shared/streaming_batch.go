@@ -68,7 +68,7 @@ func (sb *StreamingMessageBatch) Add(ctx context.Context, msg *azservicebus.Mess
 	}
 
 	log.Printf("Sending message batch (%d messages)", sb.currentBatch.NumMessages())
-	if err := sb.sender.SendMessageBatch(ctx, sb.currentBatch); err != nil {
+	if err := sb.sendCurrentBatch(ctx); err != nil {
 		return err
 	}
 
@@ -95,6 +95,20 @@ func (sb *StreamingMessageBatch) Add(ctx context.Context, msg *azservicebus.Mess
 	return nil
 }
 
+func (sb *StreamingMessageBatch) sendCurrentBatch(ctx context.Context) error {
+	start := time.Now()
+
+	if err := sb.sender.SendMessageBatch(ctx, sb.currentBatch); err != nil {
+		return err
+	}
+
+	if sb.stats != nil {
+		sb.stats.AddLatency("send", time.Since(start))
+	}
+
+	return nil
+}
+
 // Close sends any messages currently held in our batch.
 func (sb *StreamingMessageBatch) Close(ctx context.Context) error {
 	if sb.currentBatch.NumMessages() == 0 {
@@ -102,7 +116,7 @@ func (sb *StreamingMessageBatch) Close(ctx context.Context) error {
 	}
 
 	log.Printf("Sending final message batch")
-	if err := sb.sender.SendMessageBatch(ctx, sb.currentBatch); err != nil {
+	if err := sb.sendCurrentBatch(ctx); err != nil {
 		return err
 	}
 
@@ -34,6 +34,7 @@ type StressContext struct {
 	// ConnectionString represents the value of the environment variable SERVICEBUS_CONNECTION_STRING.
 	ConnectionString string
 
+	testName    string
 	logMessages chan string
 
 	cancel context.CancelFunc
@@ -94,6 +95,7 @@ func MustCreateStressContext(testName string) *StressContext {
 		// to know things are running, while not so often that you end up flooding logging
 		// with duplicate information.
 		statsPrinter: newStatsPrinter(ctx, testName, time.Minute, telemetryClient),
+		testName:     testName,
 		logMessages:  logMessages,
 		Context:      ctx,
 		cancel:       cancel,
@@ -140,6 +142,13 @@ PrintLoop:
 	// dump out the last stats.
 	sc.PrintStats()
 
+	// `stress compare` reads the report of each run it starts
+	if path := os.Getenv(ReportFileEnvVar); path != "" {
+		if err := WriteReport(path, sc.report(sc.testName, sc.TestRunID)); err != nil {
+			log.Printf("Failed to write report to %s: %s", path, err)
+		}
+	}
+
 	log.Printf("Done")
 }
 
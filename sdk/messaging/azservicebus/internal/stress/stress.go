@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/stress/compare"
 	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/stress/tests"
 	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/stress/tools"
 )
@@ -14,7 +15,7 @@ import (
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Printf("Bad command line\n")
-		fmt.Printf("Usage: stress (tests|tools)\n")
+		fmt.Printf("Usage: stress (tests|tools|compare)\n")
 		os.Exit(1)
 	}
 
@@ -23,8 +24,10 @@ func main() {
 		tests.Run(os.Args[2:])
 	case "tools":
 		tools.Run(os.Args[2:])
+	case "compare":
+		compare.Run(os.Args[2:])
 	default:
-		fmt.Printf("Usage: stress (tests|tools)\n")
+		fmt.Printf("Usage: stress (tests|tools|compare)\n")
 		os.Exit(1)
 	}
 }
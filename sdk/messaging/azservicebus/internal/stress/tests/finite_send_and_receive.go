@@ -72,7 +72,9 @@ func FiniteSendAndReceiveTest(remainingArgs []string) {
 				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 				defer cancel()
 
+				start := time.Now()
 				err := receiver.CompleteMessage(ctx, msg, nil)
+				stats.AddLatency("complete", time.Since(start))
 
 				var sbErr *azservicebus.Error
 
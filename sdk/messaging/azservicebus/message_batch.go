@@ -4,6 +4,7 @@
 package azservicebus
 
 import (
+	"context"
 	"errors"
 	"sync"
 
@@ -24,6 +25,13 @@ type (
 
 		maxBytes    uint64
 		currentSize uint64
+
+		idGenerators  idGenerators
+		bodyEncryptor *bodyEncryptor
+
+		// partitionKey is assigned to the messages added without a partition key or session ID,
+		// when the Sender that created the batch assigns partition keys
+		partitionKey *string
 	}
 )
 
@@ -50,7 +58,20 @@ type AddMessageOptions struct {
 // - ErrMessageTooLarge if the message cannot fit
 // - a non-nil error for other failures
 // - nil, otherwise
+//
+// When the Sender that created the batch encrypts message bodies, the body is encrypted
+// as the message is added, which can call the KeyWrapper when a new data key is needed.
 func (mb *MessageBatch) AddMessage(m *Message, options *AddMessageOptions) error {
+	m, err := mb.bodyEncryptor.encrypt(context.Background(), mb.idGenerators.apply(m))
+
+	if err != nil {
+		return err
+	}
+
+	if mb.partitionKey != nil && m.PartitionKey == nil && m.SessionID == nil {
+		m = withPartitionKey(m, *mb.partitionKey)
+	}
+
 	return mb.addAMQPMessage(m.toAMQPMessage())
 }
 
@@ -0,0 +1,186 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/go-amqp"
+)
+
+const (
+	// defaultPartitionKeyCount is twice the 16 partitions of a Standard tier partitioned entity, so the
+	// keys, which the service hashes to partitions, are likely to reach all of them
+	defaultPartitionKeyCount = 32
+
+	defaultThrottleCooldown = 30 * time.Second
+
+	errorConditionServerBusy = amqp.ErrorCondition("com.microsoft:server-busy")
+)
+
+// PartitionAssignment controls how a Sender assigns partition keys to messages sent without one.
+type PartitionAssignment string
+
+const (
+	// PartitionAssignmentSpread assigns the partition keys in turn, spreading the messages across partitions.
+	PartitionAssignmentSpread PartitionAssignment = "Spread"
+
+	// PartitionAssignmentPin assigns the same partition key to every message, so they're stored in the same
+	// partition and keep their order, until the partition is throttled.
+	PartitionAssignmentPin PartitionAssignment = "Pin"
+)
+
+// PartitionAffinityOptions configures how a Sender assigns partition keys to messages sent to a partitioned
+// queue or topic.
+//
+// A partitioned entity stores each message in the partition its PartitionKey hashes to, and a partition that's
+// busy throttles the sends to it. A publisher that sends most of its messages with the same key, or without one,
+// can be throttled by a single hot partition. With PartitionAffinityOptions, a Sender assigns one of a set of
+// partition keys to each message sent without a PartitionKey or SessionID, and stops using a key for a while
+// when the service throttles a send using it, retrying the send with a healthy key. Messages with a PartitionKey
+// or SessionID are sent unchanged. A retried message can be stored in a different partition than the first try,
+// so duplicate detection, which is done per partition, may not detect it.
+type PartitionAffinityOptions struct {
+	// Assignment is how partition keys are assigned. Defaults to PartitionAssignmentSpread.
+	Assignment PartitionAssignment
+
+	// PartitionKeyCount is the number of partition keys the Sender assigns. Defaults to 32.
+	PartitionKeyCount int
+
+	// ThrottleCooldown is how long the Sender avoids a partition key after a send using it is throttled.
+	// Defaults to 30 seconds.
+	ThrottleCooldown time.Duration
+}
+
+// partitionKeyAssigner assigns partition keys to messages and tracks the keys whose partitions are throttled.
+// Its methods are safe to call on a nil partitionKeyAssigner, which assigns no keys.
+type partitionKeyAssigner struct {
+	assignment PartitionAssignment
+	keys       []string
+	cooldown   time.Duration
+	now        func() time.Time
+
+	mu sync.Mutex
+	// next is the index of the next key to assign, or of the pinned key
+	next int
+	// throttledUntil maps the index of a key to the time the Sender starts using it again
+	throttledUntil []time.Time
+}
+
+func newPartitionKeyAssigner(options *PartitionAffinityOptions) (*partitionKeyAssigner, error) {
+	if options == nil {
+		return nil, nil
+	}
+
+	a := &partitionKeyAssigner{
+		assignment: options.Assignment,
+		cooldown:   options.ThrottleCooldown,
+		now:        time.Now,
+	}
+
+	switch a.assignment {
+	case "":
+		a.assignment = PartitionAssignmentSpread
+	case PartitionAssignmentSpread, PartitionAssignmentPin:
+	default:
+		return nil, fmt.Errorf("invalid PartitionAffinityOptions.Assignment %q", options.Assignment)
+	}
+
+	if a.cooldown <= 0 {
+		a.cooldown = defaultThrottleCooldown
+	}
+
+	count := options.PartitionKeyCount
+
+	if count <= 0 {
+		count = defaultPartitionKeyCount
+	}
+
+	for i := 0; i < count; i++ {
+		a.keys = append(a.keys, fmt.Sprintf("p%d", i))
+	}
+
+	a.throttledUntil = make([]time.Time, count)
+	return a, nil
+}
+
+// needsKey returns whether a key is assigned to m
+func (a *partitionKeyAssigner) needsKey(m *Message) bool {
+	return a != nil && m.PartitionKey == nil && m.SessionID == nil
+}
+
+// apply returns m, or a copy of m with a partition key if it needs one.
+// The caller's message is never modified.
+func (a *partitionKeyAssigner) apply(m *Message) *Message {
+	if !a.needsKey(m) {
+		return m
+	}
+
+	return withPartitionKey(m, a.pick())
+}
+
+// withPartitionKey returns a copy of m with the partition key
+func withPartitionKey(m *Message, key string) *Message {
+	copied := *m
+	copied.PartitionKey = &key
+	return &copied
+}
+
+// pick returns the key to assign to the next message, avoiding throttled keys. When every key is
+// throttled, it returns the one whose cooldown ends first.
+func (a *partitionKeyAssigner) pick() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	soonest := a.next
+
+	for i := 0; i < len(a.keys); i++ {
+		idx := (a.next + i) % len(a.keys)
+
+		if !now.Before(a.throttledUntil[idx]) {
+			soonest = idx
+			break
+		}
+
+		if a.throttledUntil[idx].Before(a.throttledUntil[soonest]) {
+			soonest = idx
+		}
+	}
+
+	if a.assignment == PartitionAssignmentPin {
+		a.next = soonest
+	} else {
+		a.next = (soonest + 1) % len(a.keys)
+	}
+
+	return a.keys[soonest]
+}
+
+// observe records the result of sending a message with a partition key. Keys the assigner doesn't
+// own, such as the caller's, are ignored.
+func (a *partitionKeyAssigner) observe(key *string, err error) {
+	if a == nil || key == nil || !isServerBusy(err) {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i, k := range a.keys {
+		if k == *key {
+			a.throttledUntil[i] = a.now().Add(a.cooldown)
+			return
+		}
+	}
+}
+
+// isServerBusy returns whether err is the service throttling a request
+func isServerBusy(err error) bool {
+	var amqpErr *amqp.Error
+	return errors.As(err, &amqpErr) && amqpErr.Condition == errorConditionServerBusy
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/go-amqp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionKeyAssigner(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	serverBusy := &amqp.Error{Condition: errorConditionServerBusy}
+
+	spread, err := newPartitionKeyAssigner(&PartitionAffinityOptions{PartitionKeyCount: 3, ThrottleCooldown: time.Minute})
+	require.NoError(t, err)
+	spread.now = clock
+
+	require.Equal(t, []string{"p0", "p1", "p2", "p0"}, []string{spread.pick(), spread.pick(), spread.pick(), spread.pick()})
+
+	// a throttled key is skipped until its cooldown ends
+	spread.observe(to.Ptr("p1"), serverBusy)
+	spread.observe(to.Ptr("p2"), &amqp.Error{Condition: amqp.ErrorNotFound})
+	spread.observe(to.Ptr("mine"), serverBusy)
+	require.Equal(t, []string{"p2", "p0", "p2"}, []string{spread.pick(), spread.pick(), spread.pick()})
+
+	// when every key is throttled, the one whose cooldown ends first is used
+	now = now.Add(time.Second)
+	spread.observe(to.Ptr("p0"), serverBusy)
+	spread.observe(to.Ptr("p2"), serverBusy)
+	require.Equal(t, "p1", spread.pick())
+
+	now = now.Add(time.Minute)
+	require.Equal(t, []string{"p2", "p0", "p1"}, []string{spread.pick(), spread.pick(), spread.pick()})
+
+	pin, err := newPartitionKeyAssigner(&PartitionAffinityOptions{Assignment: PartitionAssignmentPin, PartitionKeyCount: 3})
+	require.NoError(t, err)
+	pin.now = clock
+
+	require.Equal(t, []string{"p0", "p0"}, []string{pin.pick(), pin.pick()})
+	pin.observe(to.Ptr("p0"), serverBusy)
+	require.Equal(t, []string{"p1", "p1"}, []string{pin.pick(), pin.pick()})
+
+	// the caller's keys and session IDs are kept
+	withKey := &Message{PartitionKey: to.Ptr("mine")}
+	require.Same(t, withKey, pin.apply(withKey))
+	withSession := &Message{SessionID: to.Ptr("session")}
+	require.Same(t, withSession, pin.apply(withSession))
+
+	original := &Message{}
+	require.Equal(t, "p1", *pin.apply(original).PartitionKey)
+	require.Nil(t, original.PartitionKey)
+
+	var disabled *partitionKeyAssigner
+	require.Same(t, original, disabled.apply(original))
+
+	_, err = newPartitionKeyAssigner(&PartitionAffinityOptions{Assignment: "Random"})
+	require.Error(t, err)
+}
+
+type partitionedAMQPSender struct {
+	internal.AMQPSender
+	busy map[string]bool
+	sent []string
+}
+
+func (s *partitionedAMQPSender) Send(ctx context.Context, msg *amqp.Message) error {
+	key, _ := msg.Annotations[partitionKeyAnnotation].(string)
+	s.sent = append(s.sent, key)
+
+	if s.busy[key] {
+		return &amqp.Error{Condition: errorConditionServerBusy}
+	}
+
+	return nil
+}
+
+func (s *partitionedAMQPSender) MaxMessageSize() uint64 {
+	return 8000
+}
+
+func TestSender_PartitionAffinity(t *testing.T) {
+	amqpSender := &partitionedAMQPSender{busy: map[string]bool{"p1": true}}
+
+	sender, err := newSender(newSenderArgs{
+		ns: &internal.FakeNS{
+			AMQPLinks: &internal.FakeAMQPLinks{Sender: amqpSender},
+		},
+		queueOrTopic:      "queue",
+		cleanupOnClose:    func() {},
+		partitionAffinity: &PartitionAffinityOptions{PartitionKeyCount: 3},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, sender.SendMessage(context.Background(), &Message{}, nil))
+	require.Error(t, sender.SendMessage(context.Background(), &Message{}, nil))
+
+	// the throttled key isn't used again, and the caller's key is sent as is
+	require.NoError(t, sender.SendMessage(context.Background(), &Message{}, nil))
+	require.NoError(t, sender.SendMessage(context.Background(), &Message{}, nil))
+	require.NoError(t, sender.SendMessage(context.Background(), &Message{PartitionKey: to.Ptr("mine")}, nil))
+	require.Equal(t, []string{"p0", "p1", "p2", "p0", "mine"}, amqpSender.sent)
+
+	// the messages in a batch share a key
+	batch, err := sender.NewMessageBatch(context.Background(), nil)
+	require.NoError(t, err)
+	require.Equal(t, "p2", *batch.partitionKey)
+	require.NoError(t, batch.AddMessage(&Message{}, nil))
+	require.NoError(t, sender.SendMessageBatch(context.Background(), batch, nil))
+	require.Equal(t, "p2", amqpSender.sent[len(amqpSender.sent)-1])
+}
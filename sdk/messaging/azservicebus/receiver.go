@@ -59,6 +59,9 @@ type Receiver struct {
 
 	defaultDrainTimeout      time.Duration
 	defaultTimeAfterFirstMsg time.Duration
+
+	redeliverySampler *redeliverySampler
+	bodyEncryptor     *bodyEncryptor
 }
 
 // ReceiverOptions contains options for the `Client.NewReceiverForQueue` or `Client.NewReceiverForSubscription`
@@ -81,6 +84,16 @@ type ReceiverOptions struct {
 	// SubQueue should be set to connect to the sub queue (ex: dead letter queue)
 	// of the queue or subscription.
 	SubQueue SubQueue
+
+	// RedeliverySampling, when set, reports messages received by ReceiveMessages whose delivery count
+	// exceeds a threshold, to help diagnose redelivery loops.
+	RedeliverySampling *RedeliverySamplingOptions
+
+	// BodyEncryption, when set, decrypts the bodies of messages encrypted by a Sender
+	// with NewSenderOptions.BodyEncryption. Messages that aren't encrypted are unchanged.
+	// When a body can't be decrypted the message is returned still encrypted, along with
+	// an error wrapping ErrBodyDecryption, so it can still be settled.
+	BodyEncryption *BodyEncryptionOptions
 }
 
 const defaultLinkRxBuffer = 2048
@@ -98,6 +111,22 @@ func applyReceiverOptions(receiver *Receiver, entity *entity, options *ReceiverO
 		if err := entity.SetSubQueue(options.SubQueue); err != nil {
 			return err
 		}
+
+		sampler, err := newRedeliverySampler(options.RedeliverySampling)
+
+		if err != nil {
+			return err
+		}
+
+		receiver.redeliverySampler = sampler
+
+		encryptor, err := newBodyEncryptor(options.BodyEncryption)
+
+		if err != nil {
+			return err
+		}
+
+		receiver.bodyEncryptor = encryptor
 	}
 
 	entityPath, err := entity.String()
@@ -195,7 +224,13 @@ func (r *Receiver) ReceiveMessages(ctx context.Context, maxMessages int, options
 	}
 
 	messages, err := r.receiveMessagesImpl(ctx, maxMessages, options)
-	return messages, internal.TransformError(err)
+	r.redeliverySampler.observe(messages)
+
+	if err != nil {
+		return messages, internal.TransformError(err)
+	}
+
+	return messages, r.bodyEncryptor.decryptAll(ctx, messages)
 }
 
 // ReceiveDeferredMessagesOptions contains optional parameters for the ReceiveDeferredMessages function.
@@ -225,7 +260,11 @@ func (r *Receiver) ReceiveDeferredMessages(ctx context.Context, sequenceNumbers
 		return nil
 	}, r.retryOptions)
 
-	return receivedMessages, internal.TransformError(err)
+	if err != nil {
+		return receivedMessages, internal.TransformError(err)
+	}
+
+	return receivedMessages, r.bodyEncryptor.decryptAll(ctx, receivedMessages)
 }
 
 // PeekMessagesOptions contains options for the `Receiver.PeekMessages`
@@ -277,7 +316,11 @@ func (r *Receiver) PeekMessages(ctx context.Context, maxMessageCount int, option
 		return nil
 	}, r.retryOptions)
 
-	return receivedMessages, internal.TransformError(err)
+	if err != nil {
+		return receivedMessages, internal.TransformError(err)
+	}
+
+	return receivedMessages, r.bodyEncryptor.decryptAll(ctx, receivedMessages)
 }
 
 // RenewMessageLockOptions contains optional parameters for the RenewMessageLock function.
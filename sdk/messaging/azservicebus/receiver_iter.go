@@ -0,0 +1,61 @@
+//go:build go1.23
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"iter"
+)
+
+const defaultMessagesBatchSize = 10
+
+// MessagesOptions contains optional parameters for the Messages function.
+type MessagesOptions struct {
+	// MaxMessagesPerReceive is the maximum number of messages requested from Service Bus at a time.
+	// Defaults to 10.
+	MaxMessagesPerReceive int
+}
+
+// Messages returns an iterator over the messages received by the Receiver, for use with range:
+//
+//	for msg, err := range receiver.Messages(ctx, nil) {
+//		if err != nil {
+//			// handle the error
+//			break
+//		}
+//		// process and settle msg
+//	}
+//
+// Iteration stops when ctx is cancelled, or after yielding an error, which is the last value.
+// Like ReceiveMessages, it can't be used concurrently with other calls to receive messages.
+func (r *Receiver) Messages(ctx context.Context, options *MessagesOptions) iter.Seq2[*ReceivedMessage, error] {
+	batchSize := defaultMessagesBatchSize
+
+	if options != nil && options.MaxMessagesPerReceive > 0 {
+		batchSize = options.MaxMessagesPerReceive
+	}
+
+	return func(yield func(*ReceivedMessage, error) bool) {
+		for {
+			messages, err := r.ReceiveMessages(ctx, batchSize, nil)
+
+			for _, m := range messages {
+				if !yield(m, nil) {
+					return
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+//go:build go1.23
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/go-amqp"
+	"github.com/stretchr/testify/require"
+)
+
+func newIterTestReceiver(t *testing.T, links *internal.FakeAMQPLinks) *Receiver {
+	receiver, err := newReceiver(newReceiverArgs{
+		ns:     &internal.FakeNS{AMQPLinks: links},
+		entity: entity{Queue: "queue"},
+	}, nil)
+	require.NoError(t, err)
+	return receiver
+}
+
+func TestReceiver_Messages(t *testing.T) {
+	fakeAMQPReceiver := &internal.FakeAMQPReceiver{
+		ReceiveResults: []struct {
+			M *amqp.Message
+			E error
+		}{
+			{M: &amqp.Message{Data: [][]byte{[]byte("hello")}}},
+			{M: &amqp.Message{Data: [][]byte{[]byte("world")}}},
+			{M: &amqp.Message{Data: [][]byte{[]byte("again")}}},
+		},
+	}
+	receiver := newIterTestReceiver(t, &internal.FakeAMQPLinks{Receiver: fakeAMQPReceiver})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var bodies []string
+
+	for msg, err := range receiver.Messages(ctx, &MessagesOptions{MaxMessagesPerReceive: 2}) {
+		require.NoError(t, err)
+		bodies = append(bodies, string(msg.Body))
+
+		if len(bodies) == 3 {
+			// no more messages are coming, so the iterator waits until the context is cancelled
+			cancel()
+		}
+	}
+
+	require.Equal(t, []string{"hello", "world", "again"}, bodies)
+
+	// breaking out of the loop stops the iterator
+	fakeAMQPReceiver.ReceiveResults = append(fakeAMQPReceiver.ReceiveResults, struct {
+		M *amqp.Message
+		E error
+	}{M: &amqp.Message{Data: [][]byte{[]byte("last")}}})
+
+	for msg := range receiver.Messages(context.Background(), &MessagesOptions{MaxMessagesPerReceive: 1}) {
+		require.Equal(t, "last", string(msg.Body))
+		break
+	}
+}
+
+func TestReceiver_MessagesError(t *testing.T) {
+	receiver := newIterTestReceiver(t, &internal.FakeAMQPLinks{Err: internal.NewErrNonRetriable("failed to create links")})
+
+	var errs []error
+
+	for msg, err := range receiver.Messages(context.Background(), nil) {
+		require.Nil(t, msg)
+		errs = append(errs, err)
+	}
+
+	require.Len(t, errs, 1)
+	require.EqualError(t, errs[0], "failed to create links")
+}
@@ -0,0 +1,176 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRedeliverySampleMaxBodyBytes = 1024
+	maxRedeliveryTrackedMessages        = 1000
+	maxRedeliveryLockHistory            = 10
+)
+
+// RedeliverySamplingOptions configures sampling of messages that Service Bus has delivered many times, which helps to
+// diagnose redelivery loops, for instance a message that's repeatedly abandoned or whose lock repeatedly expires.
+type RedeliverySamplingOptions struct {
+	// DeliveryCountThreshold is the delivery count above which a received message is sampled. It must be greater than 0.
+	DeliveryCountThreshold uint32
+
+	// MaxBodyBytes limits the size of the body captured in a sample. Set it to a negative value to omit
+	// bodies. Defaults to 1024.
+	MaxBodyBytes int
+
+	// Sink receives the samples. It's called synchronously by ReceiveMessages, so it should return quickly,
+	// for example by writing the sample to a log.
+	Sink func(sample RedeliverySample)
+}
+
+// RedeliverySample describes a message whose delivery count exceeded RedeliverySamplingOptions.DeliveryCountThreshold.
+type RedeliverySample struct {
+	// MessageID is the message's ID.
+	MessageID string
+
+	// SequenceNumber is the message's sequence number.
+	SequenceNumber *int64
+
+	// DeliveryCount is the number of times the message has been delivered.
+	DeliveryCount uint32
+
+	// EnqueuedTime is the time the message was enqueued.
+	EnqueuedTime *time.Time
+
+	// Body is the start of the message's body, up to RedeliverySamplingOptions.MaxBodyBytes.
+	Body []byte
+
+	// BodyTruncated is true when Body doesn't contain the entire body.
+	BodyTruncated bool
+
+	// LockHistory holds the deliveries of the message observed by the receiver, oldest first, including the
+	// current one. Only the most recent deliveries are kept.
+	LockHistory []RedeliveryLock
+}
+
+// RedeliveryLock describes one delivery of a message.
+type RedeliveryLock struct {
+	// LockToken is the token of the lock for the delivery.
+	LockToken [16]byte
+
+	// LockedUntil is the time the lock expired or expires, as of the delivery.
+	LockedUntil *time.Time
+
+	// DeliveryCount is the delivery count of the delivery.
+	DeliveryCount uint32
+
+	// ReceivedAt is the time the receiver received the delivery.
+	ReceivedAt time.Time
+}
+
+// redeliverySampler tracks the deliveries of messages with high delivery counts and reports them to a sink.
+type redeliverySampler struct {
+	options RedeliverySamplingOptions
+
+	mu sync.Mutex
+	// history is keyed by the message's ID. order holds the IDs oldest first, so the number of tracked messages
+	// can be bounded.
+	history map[string][]RedeliveryLock
+	order   []string
+}
+
+func newRedeliverySampler(options *RedeliverySamplingOptions) (*redeliverySampler, error) {
+	if options == nil {
+		return nil, nil
+	}
+
+	if options.DeliveryCountThreshold == 0 {
+		return nil, errors.New("RedeliverySamplingOptions.DeliveryCountThreshold must be greater than 0")
+	}
+
+	if options.Sink == nil {
+		return nil, errors.New("RedeliverySamplingOptions.Sink is required")
+	}
+
+	sampler := &redeliverySampler{
+		options: *options,
+		history: map[string][]RedeliveryLock{},
+	}
+
+	if sampler.options.MaxBodyBytes == 0 {
+		sampler.options.MaxBodyBytes = defaultRedeliverySampleMaxBodyBytes
+	}
+
+	return sampler, nil
+}
+
+// observe samples the received messages that exceed the delivery count threshold. It's safe to call on a nil sampler.
+func (s *redeliverySampler) observe(messages []*ReceivedMessage) {
+	if s == nil {
+		return
+	}
+
+	now := time.Now()
+
+	for _, m := range messages {
+		if m.DeliveryCount <= s.options.DeliveryCountThreshold {
+			continue
+		}
+
+		s.options.Sink(s.sample(m, now))
+	}
+}
+
+func (s *redeliverySampler) sample(m *ReceivedMessage, receivedAt time.Time) RedeliverySample {
+	sample := RedeliverySample{
+		MessageID:      m.MessageID,
+		SequenceNumber: m.SequenceNumber,
+		DeliveryCount:  m.DeliveryCount,
+		EnqueuedTime:   m.EnqueuedTime,
+	}
+
+	if s.options.MaxBodyBytes > 0 {
+		body := m.Body
+
+		if len(body) > s.options.MaxBodyBytes {
+			body = body[:s.options.MaxBodyBytes]
+			sample.BodyTruncated = true
+		}
+
+		sample.Body = append([]byte(nil), body...)
+	} else {
+		sample.BodyTruncated = len(m.Body) > 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, tracked := s.history[m.MessageID]
+
+	if !tracked {
+		if len(s.order) == maxRedeliveryTrackedMessages {
+			delete(s.history, s.order[0])
+			s.order = s.order[1:]
+		}
+
+		s.order = append(s.order, m.MessageID)
+	}
+
+	history = append(history, RedeliveryLock{
+		LockToken:     m.LockToken,
+		LockedUntil:   m.LockedUntil,
+		DeliveryCount: m.DeliveryCount,
+		ReceivedAt:    receivedAt,
+	})
+
+	if len(history) > maxRedeliveryLockHistory {
+		history = history[len(history)-maxRedeliveryLockHistory:]
+	}
+
+	s.history[m.MessageID] = history
+	sample.LockHistory = append([]RedeliveryLock(nil), history...)
+
+	return sample
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedeliverySampler(t *testing.T) {
+	var samples []RedeliverySample
+
+	sampler, err := newRedeliverySampler(&RedeliverySamplingOptions{
+		DeliveryCountThreshold: 2,
+		MaxBodyBytes:           4,
+		Sink:                   func(sample RedeliverySample) { samples = append(samples, sample) },
+	})
+	require.NoError(t, err)
+
+	body := []byte("hello world")
+
+	sampler.observe([]*ReceivedMessage{
+		{MessageID: "quiet", DeliveryCount: 2},
+		{MessageID: "storm", DeliveryCount: 3, LockToken: [16]byte{1}, Body: body},
+	})
+	sampler.observe([]*ReceivedMessage{
+		{MessageID: "storm", DeliveryCount: 4, LockToken: [16]byte{2}, Body: body},
+	})
+
+	require.Len(t, samples, 2)
+	require.Equal(t, "storm", samples[1].MessageID)
+	require.EqualValues(t, 4, samples[1].DeliveryCount)
+	require.Equal(t, []byte("hell"), samples[1].Body)
+	require.True(t, samples[1].BodyTruncated)
+
+	// the sample keeps its own copy of the body
+	body[0] = 'j'
+	require.Equal(t, []byte("hell"), samples[1].Body)
+
+	require.Len(t, samples[0].LockHistory, 1)
+	require.Len(t, samples[1].LockHistory, 2)
+	require.Equal(t, [16]byte{1}, samples[1].LockHistory[0].LockToken)
+	require.Equal(t, [16]byte{2}, samples[1].LockHistory[1].LockToken)
+
+	// a nil sampler does nothing
+	var nilSampler *redeliverySampler
+	nilSampler.observe([]*ReceivedMessage{{DeliveryCount: 100}})
+}
+
+func TestRedeliverySampler_Bounds(t *testing.T) {
+	var last RedeliverySample
+
+	sampler, err := newRedeliverySampler(&RedeliverySamplingOptions{
+		DeliveryCountThreshold: 1,
+		MaxBodyBytes:           -1,
+		Sink:                   func(sample RedeliverySample) { last = sample },
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < maxRedeliveryLockHistory+5; i++ {
+		sampler.observe([]*ReceivedMessage{{MessageID: "storm", DeliveryCount: uint32(i + 2), Body: []byte("secret")}})
+	}
+
+	require.Nil(t, last.Body)
+	require.True(t, last.BodyTruncated)
+	require.Len(t, last.LockHistory, maxRedeliveryLockHistory)
+	require.EqualValues(t, maxRedeliveryLockHistory+6, last.LockHistory[maxRedeliveryLockHistory-1].DeliveryCount)
+
+	for i := 0; i < maxRedeliveryTrackedMessages; i++ {
+		sampler.observe([]*ReceivedMessage{{MessageID: string(rune(i + 'a')), DeliveryCount: 2}})
+	}
+
+	require.Len(t, sampler.history, maxRedeliveryTrackedMessages)
+	require.NotContains(t, sampler.history, "storm")
+}
+
+func TestRedeliverySampler_Options(t *testing.T) {
+	sampler, err := newRedeliverySampler(nil)
+	require.NoError(t, err)
+	require.Nil(t, sampler)
+
+	_, err = newRedeliverySampler(&RedeliverySamplingOptions{Sink: func(sample RedeliverySample) {}})
+	require.Error(t, err)
+
+	_, err = newRedeliverySampler(&RedeliverySamplingOptions{DeliveryCountThreshold: 1})
+	require.Error(t, err)
+}
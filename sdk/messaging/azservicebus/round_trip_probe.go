@@ -0,0 +1,287 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/internal/uuid"
+)
+
+const (
+	defaultRoundTripTimeout = 30 * time.Second
+
+	// roundTripRunProperty is the application property holding the ID of the MeasureRoundTrip
+	// call that sent a probe
+	roundTripRunProperty = "azsb-probe-run"
+)
+
+// MeasureRoundTripOptions contains optional parameters for MeasureRoundTrip.
+type MeasureRoundTripOptions struct {
+	// SubscriptionName is the subscription to receive the probes from, when the entity is a topic.
+	SubscriptionName string
+
+	// Interval is the time between sending a probe and sending the next one, counted from when the first
+	// was sent. By default, the next probe is sent as soon as the previous one is received.
+	Interval time.Duration
+
+	// Timeout is how long to wait for a probe before counting it as lost. Defaults to 30 seconds. Probes
+	// are sent with this TimeToLive, so lost probes don't accumulate in the entity.
+	Timeout time.Duration
+}
+
+// RoundTripSample is the latency of one probe.
+type RoundTripSample struct {
+	// SendDuration is how long sending the probe took.
+	SendDuration time.Duration
+
+	// RoundTrip is the time from starting to send the probe to receiving it.
+	RoundTrip time.Duration
+
+	// EnqueueToReceive is the time from Service Bus enqueuing the probe to receiving it. It's the difference
+	// between a time from the service's clock and one from the local clock, so clock skew affects it.
+	EnqueueToReceive time.Duration
+}
+
+// LatencyPercentiles summarizes a set of latencies.
+type LatencyPercentiles struct {
+	Min  time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+	Mean time.Duration
+}
+
+// RoundTripResult is returned by MeasureRoundTrip.
+type RoundTripResult struct {
+	// Samples are the latencies of the probes that were received, in the order they were sent.
+	Samples []RoundTripSample
+
+	// Lost is the number of probes that weren't received within MeasureRoundTripOptions.Timeout.
+	Lost int
+
+	// RoundTrip summarizes the RoundTrip latencies of the samples.
+	RoundTrip LatencyPercentiles
+
+	// EnqueueToReceive summarizes the EnqueueToReceive latencies of the samples.
+	EnqueueToReceive LatencyPercentiles
+}
+
+// MeasureRoundTrip measures the latency of a queue or subscription by sending probe messages to queueOrTopic and
+// receiving them, one at a time, until it has sent samples probes. Use an entity dedicated to probing: received
+// messages that aren't probes are abandoned, and probes from earlier calls are completed and ignored.
+//
+// When ctx is canceled, MeasureRoundTrip returns the samples measured so far along with ctx's error. Other errors,
+// such as failing to send a probe, end the measurement and are returned the same way.
+func MeasureRoundTrip(ctx context.Context, client *Client, queueOrTopic string, samples int, options *MeasureRoundTripOptions) (*RoundTripResult, error) {
+	if options == nil {
+		options = &MeasureRoundTripOptions{}
+	}
+
+	sender, err := client.NewSender(queueOrTopic, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer sender.Close(context.Background())
+
+	var receiver *Receiver
+
+	if options.SubscriptionName != "" {
+		receiver, err = client.NewReceiverForSubscription(queueOrTopic, options.SubscriptionName, nil)
+	} else {
+		receiver, err = client.NewReceiverForQueue(queueOrTopic, nil)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer receiver.Close(context.Background())
+
+	return measureRoundTrip(ctx, sender, receiver, samples, options)
+}
+
+type roundTripSender interface {
+	SendMessage(ctx context.Context, message *Message, options *SendMessageOptions) error
+}
+
+type roundTripReceiver interface {
+	ReceiveMessages(ctx context.Context, maxMessages int, options *ReceiveMessagesOptions) ([]*ReceivedMessage, error)
+	CompleteMessage(ctx context.Context, message *ReceivedMessage, options *CompleteMessageOptions) error
+	AbandonMessage(ctx context.Context, message *ReceivedMessage, options *AbandonMessageOptions) error
+}
+
+func measureRoundTrip(ctx context.Context, sender roundTripSender, receiver roundTripReceiver, samples int, options *MeasureRoundTripOptions) (*RoundTripResult, error) {
+	if samples <= 0 {
+		return nil, errors.New("samples must be greater than 0")
+	}
+
+	timeout := options.Timeout
+
+	if timeout <= 0 {
+		timeout = defaultRoundTripTimeout
+	}
+
+	runID, err := uuid.New()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RoundTripResult{}
+
+	for i := 0; i < samples; i++ {
+		sentAt := time.Now()
+		sample, received, err := sendProbe(ctx, sender, receiver, runID.String(), fmt.Sprintf("%s-%d", runID, i), timeout)
+
+		if err != nil {
+			result.summarize()
+			return result, err
+		}
+
+		if received {
+			result.Samples = append(result.Samples, sample)
+		} else {
+			result.Lost++
+		}
+
+		if wait := options.Interval - time.Since(sentAt); wait > 0 && i < samples-1 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				result.summarize()
+				return result, ctx.Err()
+			}
+		}
+	}
+
+	result.summarize()
+	return result, nil
+}
+
+// sendProbe sends a probe and receives it, returning false when it doesn't arrive before the timeout
+func sendProbe(ctx context.Context, sender roundTripSender, receiver roundTripReceiver, runID string, probeID string, timeout time.Duration) (RoundTripSample, bool, error) {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	err := sender.SendMessage(probeCtx, &Message{
+		MessageID:             &probeID,
+		Body:                  []byte("probe"),
+		TimeToLive:            &timeout,
+		ApplicationProperties: map[string]interface{}{roundTripRunProperty: runID},
+	}, nil)
+
+	if err != nil {
+		if ctx.Err() == nil && probeCtx.Err() != nil {
+			return RoundTripSample{}, false, nil
+		}
+
+		return RoundTripSample{}, false, err
+	}
+
+	sample := RoundTripSample{SendDuration: time.Since(start)}
+
+	for {
+		messages, err := receiver.ReceiveMessages(probeCtx, 1, nil)
+		receivedAt := time.Now()
+
+		if ctx.Err() != nil {
+			return RoundTripSample{}, false, ctx.Err()
+		}
+
+		if probeCtx.Err() != nil {
+			// lost
+			return RoundTripSample{}, false, nil
+		}
+
+		if err != nil {
+			return RoundTripSample{}, false, err
+		}
+
+		found := false
+
+		for _, m := range messages {
+			if _, isProbe := m.ApplicationProperties[roundTripRunProperty]; !isProbe {
+				if err := receiver.AbandonMessage(ctx, m, nil); err != nil {
+					return RoundTripSample{}, false, err
+				}
+
+				continue
+			}
+
+			if err := receiver.CompleteMessage(ctx, m, nil); err != nil {
+				return RoundTripSample{}, false, err
+			}
+
+			if m.MessageID == probeID {
+				found = true
+				sample.RoundTrip = receivedAt.Sub(start)
+
+				if m.EnqueuedTime != nil {
+					sample.EnqueueToReceive = receivedAt.Sub(*m.EnqueuedTime)
+				}
+			}
+		}
+
+		if found {
+			return sample, true, nil
+		}
+	}
+}
+
+func (r *RoundTripResult) summarize() {
+	var roundTrips, enqueueToReceive []time.Duration
+
+	for _, s := range r.Samples {
+		roundTrips = append(roundTrips, s.RoundTrip)
+		enqueueToReceive = append(enqueueToReceive, s.EnqueueToReceive)
+	}
+
+	r.RoundTrip = newLatencyPercentiles(roundTrips)
+	r.EnqueueToReceive = newLatencyPercentiles(enqueueToReceive)
+}
+
+func newLatencyPercentiles(latencies []time.Duration) LatencyPercentiles {
+	if len(latencies) == 0 {
+		return LatencyPercentiles{}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	// nearest-rank percentile
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p / 100 * float64(len(latencies))))
+
+		if rank < 1 {
+			rank = 1
+		}
+
+		return latencies[rank-1]
+	}
+
+	var total time.Duration
+
+	for _, l := range latencies {
+		total += l
+	}
+
+	return LatencyPercentiles{
+		Min:  latencies[0],
+		P50:  percentile(50),
+		P90:  percentile(90),
+		P99:  percentile(99),
+		Max:  latencies[len(latencies)-1],
+		Mean: total / time.Duration(len(latencies)),
+	}
+}
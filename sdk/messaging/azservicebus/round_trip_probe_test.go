@@ -0,0 +1,117 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProbeEntity struct {
+	// drop is the index of a probe the entity loses
+	drop      int
+	sent      int
+	queue     []*ReceivedMessage
+	completed []string
+	abandoned []string
+}
+
+func (f *fakeProbeEntity) SendMessage(ctx context.Context, message *Message, options *SendMessageOptions) error {
+	defer func() { f.sent++ }()
+
+	if f.sent == f.drop {
+		return nil
+	}
+
+	enqueued := time.Now()
+
+	f.queue = append(f.queue, &ReceivedMessage{
+		MessageID:             *message.MessageID,
+		ApplicationProperties: message.ApplicationProperties,
+		EnqueuedTime:          &enqueued,
+	})
+
+	return nil
+}
+
+func (f *fakeProbeEntity) ReceiveMessages(ctx context.Context, maxMessages int, options *ReceiveMessagesOptions) ([]*ReceivedMessage, error) {
+	if len(f.queue) == 0 {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	m := f.queue[0]
+	f.queue = f.queue[1:]
+	return []*ReceivedMessage{m}, nil
+}
+
+func (f *fakeProbeEntity) CompleteMessage(ctx context.Context, message *ReceivedMessage, options *CompleteMessageOptions) error {
+	f.completed = append(f.completed, message.MessageID)
+	return nil
+}
+
+func (f *fakeProbeEntity) AbandonMessage(ctx context.Context, message *ReceivedMessage, options *AbandonMessageOptions) error {
+	f.abandoned = append(f.abandoned, message.MessageID)
+	return nil
+}
+
+func TestMeasureRoundTrip(t *testing.T) {
+	entity := &fakeProbeEntity{
+		drop: 2,
+		queue: []*ReceivedMessage{
+			{MessageID: "not a probe"},
+			{MessageID: "old probe", ApplicationProperties: map[string]interface{}{roundTripRunProperty: "old run"}},
+		},
+	}
+
+	result, err := measureRoundTrip(context.Background(), entity, entity, 4, &MeasureRoundTripOptions{Timeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	require.Equal(t, 3, len(result.Samples))
+	require.Equal(t, 1, result.Lost)
+	require.Equal(t, []string{"not a probe"}, entity.abandoned)
+	require.Equal(t, 4, len(entity.completed))
+	require.Equal(t, "old probe", entity.completed[0])
+
+	for _, s := range result.Samples {
+		require.GreaterOrEqual(t, s.RoundTrip, s.SendDuration)
+	}
+
+	require.LessOrEqual(t, result.RoundTrip.Min, result.RoundTrip.P50)
+	require.LessOrEqual(t, result.RoundTrip.P50, result.RoundTrip.Max)
+}
+
+func TestMeasureRoundTrip_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := measureRoundTrip(ctx, &fakeProbeEntity{drop: -1}, &fakeProbeEntity{}, 2, &MeasureRoundTripOptions{})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, result.Samples)
+
+	_, err = measureRoundTrip(context.Background(), &fakeProbeEntity{}, &fakeProbeEntity{}, 0, nil)
+	require.EqualError(t, err, "samples must be greater than 0")
+}
+
+func TestNewLatencyPercentiles(t *testing.T) {
+	var latencies []time.Duration
+
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	require.Equal(t, LatencyPercentiles{
+		Min:  time.Millisecond,
+		P50:  50 * time.Millisecond,
+		P90:  90 * time.Millisecond,
+		P99:  99 * time.Millisecond,
+		Max:  100 * time.Millisecond,
+		Mean: 50500 * time.Microsecond,
+	}, newLatencyPercentiles(latencies))
+
+	require.Equal(t, LatencyPercentiles{}, newLatencyPercentiles(nil))
+}
@@ -0,0 +1,377 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+)
+
+const (
+	defaultSagaMaxStepAttempts = 3
+
+	// sagaStateVersion is the version of the format of the session state a Saga persists
+	sagaStateVersion = 1
+
+	// sagaStateMismatchReason is the dead-letter reason of messages whose session state doesn't match the Saga's steps
+	sagaStateMismatchReason = "SagaStateMismatch"
+)
+
+// SagaStatus is the status of a saga.
+type SagaStatus string
+
+const (
+	// SagaStatusRunning means the saga's steps are being executed.
+	SagaStatusRunning SagaStatus = "running"
+
+	// SagaStatusCompleted means all the saga's steps were executed.
+	SagaStatusCompleted SagaStatus = "completed"
+
+	// SagaStatusCompensating means a step failed and the completed steps are being compensated.
+	SagaStatusCompensating SagaStatus = "compensating"
+
+	// SagaStatusCompensated means a step failed and the completed steps were compensated.
+	SagaStatusCompensated SagaStatus = "compensated"
+)
+
+// SagaStep is a step of a Saga.
+type SagaStep struct {
+	// Name identifies the step in the saga's session state. It must be unique within the Saga.
+	Name string
+
+	// Execute performs the step. It may be called more than once for a saga, for example when the session's
+	// lock is lost after the step completed but before the saga's state was saved, so it should be idempotent.
+	Execute func(ctx context.Context, saga *SagaInstance) error
+
+	// Compensate undoes the step after a later step fails. It's optional. Like Execute, it should be idempotent.
+	Compensate func(ctx context.Context, saga *SagaInstance) error
+}
+
+// SagaOptions contains optional parameters for NewSaga.
+type SagaOptions struct {
+	// MaxStepAttempts is how many deliveries of a message a failing step is tried for before the saga is
+	// compensated. Until then the message is abandoned, so the step is tried again when the message is
+	// redelivered. Defaults to 3.
+	MaxStepAttempts uint32
+
+	// OnCompensated is called when a saga has been compensated, with the error of the step that failed.
+	OnCompensated func(ctx context.Context, saga *SagaInstance, stepErr error)
+}
+
+// SagaInstance is a saga running in a session. It's passed to the steps of the Saga.
+type SagaInstance struct {
+	// SessionID is the ID of the session, which identifies the saga.
+	SessionID string
+
+	// Message is the message being handled.
+	Message *ReceivedMessage
+
+	// Status is the saga's status.
+	Status SagaStatus
+
+	// CompletedSteps are the names of the steps that have been executed and not compensated, in order.
+	CompletedSteps []string
+
+	// FailedStep is the name of the step whose failure caused the saga to be compensated.
+	FailedStep string
+
+	// Data is application data shared by the steps. Changes made by a step are saved in the session state
+	// along with the saga's progress.
+	Data []byte
+
+	// stepError is the error of the failed step
+	stepError string
+}
+
+// sagaState is the JSON the Saga persists in the session state
+type sagaState struct {
+	Version        int        `json:"version"`
+	Status         SagaStatus `json:"status"`
+	CompletedSteps []string   `json:"completedSteps"`
+	FailedStep     string     `json:"failedStep,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	Data           []byte     `json:"data,omitempty"`
+}
+
+// sagaSession is the part of a *SessionReceiver a Saga uses
+type sagaSession interface {
+	SessionID() string
+	GetSessionState(ctx context.Context, options *GetSessionStateOptions) ([]byte, error)
+	SetSessionState(ctx context.Context, state []byte, options *SetSessionStateOptions) error
+	CompleteMessage(ctx context.Context, message *ReceivedMessage, options *CompleteMessageOptions) error
+	AbandonMessage(ctx context.Context, message *ReceivedMessage, options *AbandonMessageOptions) error
+	DeadLetterMessage(ctx context.Context, message *ReceivedMessage, options *DeadLetterOptions) error
+}
+
+// Saga coordinates a sequence of steps for each session of a queue or subscription, the saga, with a compensating
+// action for each step that's executed when a later step fails. The saga's progress and data are persisted in the
+// session state, so a saga whose message is redelivered, for example after a crash, resumes at its first
+// uncompleted step, or continues compensating. A Saga is safe for concurrent use by several sessions.
+//
+// Accept sessions with Client.AcceptNextSessionForQueue or Client.AcceptNextSessionForSubscription and pass each
+// received message to HandleMessage, which settles it.
+type Saga struct {
+	steps           []SagaStep
+	maxStepAttempts uint32
+	onCompensated   func(ctx context.Context, saga *SagaInstance, stepErr error)
+}
+
+// NewSaga creates a Saga that executes steps in order. Pass nil for options to accept default values.
+func NewSaga(steps []SagaStep, options *SagaOptions) (*Saga, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("a saga needs at least one step")
+	}
+
+	if options == nil {
+		options = &SagaOptions{}
+	}
+
+	names := map[string]bool{}
+
+	for _, step := range steps {
+		if step.Name == "" || step.Execute == nil {
+			return nil, errors.New("each step needs a Name and an Execute function")
+		}
+
+		if names[step.Name] {
+			return nil, fmt.Errorf("more than one step is named %q", step.Name)
+		}
+
+		names[step.Name] = true
+	}
+
+	maxStepAttempts := options.MaxStepAttempts
+
+	if maxStepAttempts == 0 {
+		maxStepAttempts = defaultSagaMaxStepAttempts
+	}
+
+	return &Saga{
+		steps:           append([]SagaStep(nil), steps...),
+		maxStepAttempts: maxStepAttempts,
+		onCompensated:   options.OnCompensated,
+	}, nil
+}
+
+// HandleMessage runs the saga of receiver's session for a message received from it: it executes the steps the saga
+// hasn't completed, saving its progress in the session state after each, and settles the message.
+//
+//   - When all the steps complete, the message is completed.
+//   - When a step fails, the message is abandoned so the step is retried, until the message has been delivered
+//     MaxStepAttempts times. Then the completed steps are compensated in reverse order and the message is completed.
+//   - When a compensation fails, the message is abandoned so compensating continues when it's redelivered.
+//   - Messages received after the saga completed or was compensated are completed without executing any steps.
+//   - When the session state was saved by a Saga with other steps, the message is dead lettered.
+//
+// It returns the saga's status, and the error of a step or compensation that failed while handling the message,
+// or of a failure to access the session.
+func (s *Saga) HandleMessage(ctx context.Context, receiver *SessionReceiver, message *ReceivedMessage) (SagaStatus, error) {
+	return s.handleMessage(ctx, receiver, message)
+}
+
+func (s *Saga) handleMessage(ctx context.Context, session sagaSession, message *ReceivedMessage) (SagaStatus, error) {
+	instance, err := s.load(ctx, session, message)
+
+	if err != nil {
+		var mismatch sagaStateMismatchError
+
+		if errors.As(err, &mismatch) {
+			if dlErr := session.DeadLetterMessage(ctx, message, &DeadLetterOptions{
+				Reason:           to.Ptr(sagaStateMismatchReason),
+				ErrorDescription: to.Ptr(err.Error()),
+			}); dlErr != nil {
+				return "", dlErr
+			}
+		}
+
+		return "", err
+	}
+
+	var stepErr error
+
+	if instance.Status == SagaStatusRunning {
+		stepErr = s.execute(ctx, session, instance)
+
+		if stepErr != nil && instance.Status == SagaStatusRunning {
+			// the step is retried when the message is redelivered
+			return s.abandon(ctx, session, message, instance.Status, stepErr)
+		}
+	}
+
+	if instance.Status == SagaStatusCompensating {
+		if err := s.compensate(ctx, session, instance); err != nil {
+			return s.abandon(ctx, session, message, instance.Status, err)
+		}
+
+		if s.onCompensated != nil {
+			failure := stepErr
+
+			if failure == nil {
+				// compensating was resumed after a redelivery
+				failure = errors.New(instance.stepError)
+			}
+
+			s.onCompensated(ctx, instance, failure)
+		}
+	}
+
+	if err := session.CompleteMessage(ctx, message, nil); err != nil {
+		return instance.Status, err
+	}
+
+	return instance.Status, stepErr
+}
+
+func (s *Saga) abandon(ctx context.Context, session sagaSession, message *ReceivedMessage, status SagaStatus, err error) (SagaStatus, error) {
+	if abandonErr := session.AbandonMessage(ctx, message, nil); abandonErr != nil {
+		return status, abandonErr
+	}
+
+	return status, err
+}
+
+// execute runs the steps the saga hasn't completed. When a step fails on the message's last attempt, the
+// saga's status becomes SagaStatusCompensating.
+func (s *Saga) execute(ctx context.Context, session sagaSession, instance *SagaInstance) error {
+	for _, step := range s.steps[len(instance.CompletedSteps):] {
+		if err := step.Execute(ctx, instance); err != nil {
+			stepErr := fmt.Errorf("saga step %q failed: %w", step.Name, err)
+
+			if instance.Message.DeliveryCount < s.maxStepAttempts {
+				return stepErr
+			}
+
+			instance.Status = SagaStatusCompensating
+			instance.FailedStep = step.Name
+			instance.stepError = stepErr.Error()
+
+			if err := s.save(ctx, session, instance); err != nil {
+				instance.Status = SagaStatusRunning
+				return err
+			}
+
+			return stepErr
+		}
+
+		instance.CompletedSteps = append(instance.CompletedSteps, step.Name)
+
+		if len(instance.CompletedSteps) == len(s.steps) {
+			instance.Status = SagaStatusCompleted
+		}
+
+		if err := s.save(ctx, session, instance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// compensate undoes the completed steps in reverse order
+func (s *Saga) compensate(ctx context.Context, session sagaSession, instance *SagaInstance) error {
+	for len(instance.CompletedSteps) > 0 {
+		step := s.steps[len(instance.CompletedSteps)-1]
+
+		if step.Compensate != nil {
+			if err := step.Compensate(ctx, instance); err != nil {
+				return fmt.Errorf("compensating saga step %q failed: %w", step.Name, err)
+			}
+		}
+
+		instance.CompletedSteps = instance.CompletedSteps[:len(instance.CompletedSteps)-1]
+
+		if len(instance.CompletedSteps) == 0 {
+			instance.Status = SagaStatusCompensated
+		}
+
+		if err := s.save(ctx, session, instance); err != nil {
+			return err
+		}
+	}
+
+	if instance.Status != SagaStatusCompensated {
+		// the failed step was the first, so there was nothing to compensate
+		instance.Status = SagaStatusCompensated
+		return s.save(ctx, session, instance)
+	}
+
+	return nil
+}
+
+// sagaStateMismatchError reports session state that doesn't match the Saga's steps
+type sagaStateMismatchError struct {
+	msg string
+}
+
+func (e sagaStateMismatchError) Error() string {
+	return e.msg
+}
+
+// load reads the saga's state from the session state
+func (s *Saga) load(ctx context.Context, session sagaSession, message *ReceivedMessage) (*SagaInstance, error) {
+	b, err := session.GetSessionState(ctx, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	instance := &SagaInstance{
+		SessionID: session.SessionID(),
+		Message:   message,
+		Status:    SagaStatusRunning,
+	}
+
+	if len(b) == 0 {
+		return instance, nil
+	}
+
+	var state sagaState
+
+	if err := json.Unmarshal(b, &state); err != nil || state.Version == 0 {
+		return nil, sagaStateMismatchError{msg: "the session state wasn't saved by a Saga"}
+	}
+
+	if state.Version > sagaStateVersion {
+		return nil, sagaStateMismatchError{msg: fmt.Sprintf("the session state was saved by a newer version of Saga (%d)", state.Version)}
+	}
+
+	if len(state.CompletedSteps) > len(s.steps) {
+		return nil, sagaStateMismatchError{msg: "the session state has more completed steps than the Saga"}
+	}
+
+	for i, name := range state.CompletedSteps {
+		if s.steps[i].Name != name {
+			return nil, sagaStateMismatchError{msg: fmt.Sprintf("the session state's step %d is %q, but the Saga's is %q", i+1, name, s.steps[i].Name)}
+		}
+	}
+
+	instance.Status = state.Status
+	instance.CompletedSteps = state.CompletedSteps
+	instance.FailedStep = state.FailedStep
+	instance.Data = state.Data
+	instance.stepError = state.Error
+	return instance, nil
+}
+
+// save writes the saga's state to the session state
+func (s *Saga) save(ctx context.Context, session sagaSession, instance *SagaInstance) error {
+	b, err := json.Marshal(sagaState{
+		Version:        sagaStateVersion,
+		Status:         instance.Status,
+		CompletedSteps: instance.CompletedSteps,
+		FailedStep:     instance.FailedStep,
+		Error:          instance.stepError,
+		Data:           instance.Data,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return session.SetSessionState(ctx, b, nil)
+}
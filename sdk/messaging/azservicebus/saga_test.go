@@ -0,0 +1,199 @@
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License.
+
+package azservicebus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSagaSession struct {
+	state        []byte
+	settled      []string
+	failSetState bool
+}
+
+func (s *fakeSagaSession) SessionID() string {
+	return "session"
+}
+
+func (s *fakeSagaSession) GetSessionState(ctx context.Context, options *GetSessionStateOptions) ([]byte, error) {
+	return s.state, nil
+}
+
+func (s *fakeSagaSession) SetSessionState(ctx context.Context, state []byte, options *SetSessionStateOptions) error {
+	if s.failSetState {
+		return errors.New("lock lost")
+	}
+
+	s.state = state
+	return nil
+}
+
+func (s *fakeSagaSession) CompleteMessage(ctx context.Context, message *ReceivedMessage, options *CompleteMessageOptions) error {
+	s.settled = append(s.settled, "completed")
+	return nil
+}
+
+func (s *fakeSagaSession) AbandonMessage(ctx context.Context, message *ReceivedMessage, options *AbandonMessageOptions) error {
+	s.settled = append(s.settled, "abandoned")
+	return nil
+}
+
+func (s *fakeSagaSession) DeadLetterMessage(ctx context.Context, message *ReceivedMessage, options *DeadLetterOptions) error {
+	s.settled = append(s.settled, "deadlettered: "+*options.Reason)
+	return nil
+}
+
+// sagaRecorder records the steps and compensations a test saga performs
+type sagaRecorder struct {
+	calls []string
+	fail  map[string]bool
+}
+
+func (r *sagaRecorder) step(name string) SagaStep {
+	return SagaStep{
+		Name: name,
+		Execute: func(ctx context.Context, saga *SagaInstance) error {
+			r.calls = append(r.calls, name)
+
+			if r.fail[name] {
+				return errors.New(name + " failed")
+			}
+
+			saga.Data = append(saga.Data, name...)
+			return nil
+		},
+		Compensate: func(ctx context.Context, saga *SagaInstance) error {
+			r.calls = append(r.calls, "undo "+name)
+
+			if r.fail["undo "+name] {
+				return errors.New("undo " + name + " failed")
+			}
+
+			return nil
+		},
+	}
+}
+
+func TestSagaCompletes(t *testing.T) {
+	recorder := &sagaRecorder{}
+	saga, err := NewSaga([]SagaStep{recorder.step("a"), recorder.step("b"), recorder.step("c")}, nil)
+	require.NoError(t, err)
+
+	// the session's lock is lost after step b, so the message is redelivered
+	session := &fakeSagaSession{}
+	calls := 0
+	saga.steps[1].Execute = func(ctx context.Context, instance *SagaInstance) error {
+		calls++
+
+		if calls == 1 {
+			session.failSetState = true
+		}
+
+		recorder.calls = append(recorder.calls, "b")
+		instance.Data = append(instance.Data, 'b')
+		return nil
+	}
+
+	status, err := saga.handleMessage(context.Background(), session, &ReceivedMessage{DeliveryCount: 1})
+	require.Error(t, err)
+	require.Equal(t, SagaStatusRunning, status)
+	require.Equal(t, []string{"abandoned"}, session.settled)
+
+	// the redelivered message resumes at the first uncompleted step
+	session.failSetState = false
+	status, err = saga.handleMessage(context.Background(), session, &ReceivedMessage{DeliveryCount: 2})
+	require.NoError(t, err)
+	require.Equal(t, SagaStatusCompleted, status)
+	require.Equal(t, []string{"a", "b", "b", "c"}, recorder.calls)
+	require.Equal(t, []string{"abandoned", "completed"}, session.settled)
+
+	// a completed saga executes no steps
+	status, err = saga.handleMessage(context.Background(), session, &ReceivedMessage{DeliveryCount: 1})
+	require.NoError(t, err)
+	require.Equal(t, SagaStatusCompleted, status)
+	require.Len(t, recorder.calls, 4)
+	require.Equal(t, []string{"abandoned", "completed", "completed"}, session.settled)
+
+	instance, err := saga.load(context.Background(), session, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("abc"), instance.Data)
+	require.Equal(t, []string{"a", "b", "c"}, instance.CompletedSteps)
+}
+
+func TestSagaCompensates(t *testing.T) {
+	recorder := &sagaRecorder{fail: map[string]bool{"c": true, "undo a": true}}
+	var compensated *SagaInstance
+	var compensatedErr error
+	saga, err := NewSaga([]SagaStep{recorder.step("a"), recorder.step("b"), recorder.step("c")}, &SagaOptions{
+		MaxStepAttempts: 2,
+		OnCompensated: func(ctx context.Context, saga *SagaInstance, stepErr error) {
+			compensated = saga
+			compensatedErr = stepErr
+		},
+	})
+	require.NoError(t, err)
+	session := &fakeSagaSession{}
+
+	// the failing step is retried until the message's last attempt
+	status, err := saga.handleMessage(context.Background(), session, &ReceivedMessage{DeliveryCount: 1})
+	require.Error(t, err)
+	require.Equal(t, SagaStatusRunning, status)
+	require.Equal(t, []string{"a", "b", "c"}, recorder.calls)
+
+	// then the saga is compensated, but compensating a fails
+	status, err = saga.handleMessage(context.Background(), session, &ReceivedMessage{DeliveryCount: 2})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "undo a failed")
+	require.Equal(t, SagaStatusCompensating, status)
+	require.Equal(t, []string{"a", "b", "c", "c", "undo b", "undo a"}, recorder.calls)
+	require.Equal(t, []string{"abandoned", "abandoned"}, session.settled)
+	require.Nil(t, compensated)
+
+	// compensating continues when the message is redelivered
+	recorder.fail["undo a"] = false
+	status, err = saga.handleMessage(context.Background(), session, &ReceivedMessage{DeliveryCount: 3})
+	require.NoError(t, err)
+	require.Equal(t, SagaStatusCompensated, status)
+	require.Equal(t, []string{"a", "b", "c", "c", "undo b", "undo a", "undo a"}, recorder.calls)
+	require.Equal(t, []string{"abandoned", "abandoned", "completed"}, session.settled)
+	require.Equal(t, "c", compensated.FailedStep)
+	require.Empty(t, compensated.CompletedSteps)
+	require.Contains(t, compensatedErr.Error(), `saga step "c" failed`)
+
+	status, err = saga.handleMessage(context.Background(), session, &ReceivedMessage{DeliveryCount: 1})
+	require.NoError(t, err)
+	require.Equal(t, SagaStatusCompensated, status)
+	require.Len(t, recorder.calls, 7)
+}
+
+func TestSagaStateMismatch(t *testing.T) {
+	recorder := &sagaRecorder{}
+	saga, err := NewSaga([]SagaStep{recorder.step("a"), recorder.step("b")}, nil)
+	require.NoError(t, err)
+
+	for _, state := range []string{
+		`not JSON`,
+		`{"version":1,"status":"running","completedSteps":["x"]}`,
+		`{"version":1,"status":"running","completedSteps":["a","b","c"]}`,
+		`{"version":2,"status":"running","completedSteps":[]}`,
+	} {
+		session := &fakeSagaSession{state: []byte(state)}
+		_, err := saga.handleMessage(context.Background(), session, &ReceivedMessage{DeliveryCount: 1})
+		require.Error(t, err, state)
+		require.Equal(t, []string{"deadlettered: " + sagaStateMismatchReason}, session.settled)
+	}
+	require.Empty(t, recorder.calls)
+
+	_, err = NewSaga(nil, nil)
+	require.Error(t, err)
+	_, err = NewSaga([]SagaStep{recorder.step("a"), recorder.step("a")}, nil)
+	require.Error(t, err)
+	_, err = NewSaga([]SagaStep{{Name: "a"}}, nil)
+	require.Error(t, err)
+}
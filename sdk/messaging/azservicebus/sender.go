@@ -20,9 +20,49 @@ type (
 		cleanupOnClose func()
 		links          internal.AMQPLinks
 		retryOptions   RetryOptions
+		idGenerators   idGenerators
+		bodyEncryptor  *bodyEncryptor
+		partitionKeys  *partitionKeyAssigner
+
+		// inFlight limits the number of SendAsync calls that are in progress
+		inFlight chan struct{}
 	}
 )
 
+// defaultMaxInFlightSends is the default for NewSenderOptions.MaxInFlightSends
+const defaultMaxInFlightSends = 100
+
+// idGenerators assigns IDs to messages that are sent without them
+type idGenerators struct {
+	messageID     func(message *Message) string
+	correlationID func(message *Message) string
+}
+
+// apply returns m, or a copy of m with the generated IDs if it was missing any.
+// The caller's message is never modified.
+func (g idGenerators) apply(m *Message) *Message {
+	needsMessageID := g.messageID != nil && m.MessageID == nil
+	needsCorrelationID := g.correlationID != nil && m.CorrelationID == nil
+
+	if !needsMessageID && !needsCorrelationID {
+		return m
+	}
+
+	copied := *m
+
+	if needsMessageID {
+		id := g.messageID(&copied)
+		copied.MessageID = &id
+	}
+
+	if needsCorrelationID {
+		id := g.correlationID(&copied)
+		copied.CorrelationID = &id
+	}
+
+	return &copied
+}
+
 // MessageBatchOptions contains options for the `Sender.NewMessageBatch` function.
 type MessageBatchOptions struct {
 	// MaxBytes overrides the max size (in bytes) for a batch.
@@ -45,6 +85,15 @@ func (s *Sender) NewMessageBatch(ctx context.Context, options *MessageBatchOptio
 		}
 
 		batch = newMessageBatch(maxBytes)
+		batch.idGenerators = s.idGenerators
+		batch.bodyEncryptor = s.bodyEncryptor
+
+		if s.partitionKeys != nil {
+			// the messages in a batch are stored in one partition
+			key := s.partitionKeys.pick()
+			batch.partitionKey = &key
+		}
+
 		return nil
 	}, s.retryOptions)
 
@@ -63,13 +112,58 @@ type SendMessageOptions struct {
 // SendMessage sends a Message to a queue or topic.
 // If the operation fails it can return an *azservicebus.Error type if the failure is actionable.
 func (s *Sender) SendMessage(ctx context.Context, message *Message, options *SendMessageOptions) error {
-	err := s.links.Retry(ctx, EventSender, "SendMessage", func(ctx context.Context, lwid *internal.LinksWithID, args *utils.RetryFnArgs) error {
-		return lwid.Sender.Send(ctx, message.toAMQPMessage())
+	// IDs are generated, and the body encrypted, once so every retry sends the same message
+	message, err := s.bodyEncryptor.encrypt(ctx, s.idGenerators.apply(message))
+
+	if err != nil {
+		return err
+	}
+
+	err = s.links.Retry(ctx, EventSender, "SendMessage", func(ctx context.Context, lwid *internal.LinksWithID, args *utils.RetryFnArgs) error {
+		// a partition key is assigned on each try, so a retry avoids a throttled partition
+		toSend := s.partitionKeys.apply(message)
+		err := lwid.Sender.Send(ctx, toSend.toAMQPMessage())
+		s.partitionKeys.observe(toSend.PartitionKey, err)
+		return err
 	}, RetryOptions(s.retryOptions))
 
 	return internal.TransformError(err)
 }
 
+// SendAsyncOptions contains optional parameters for the SendAsync function.
+type SendAsyncOptions struct {
+	// For future expansion
+}
+
+// SendAsync starts sending a Message to a queue or topic and returns without waiting for
+// the service to accept it. callback is called, from a separate goroutine, with the result
+// of the send, which is the error SendMessage would have returned.
+//
+// Sends are pipelined over the Sender's link, which gives higher throughput than sequential
+// calls to SendMessage. At most NewSenderOptions.MaxInFlightSends sends can be in progress;
+// when the window is full SendAsync blocks until a send completes or ctx is cancelled, in which
+// case it returns the context's error and callback isn't called.
+// ctx also applies to the send itself, so it must remain valid until callback is called.
+// Messages sent asynchronously aren't guaranteed to arrive in the order they were sent.
+func (s *Sender) SendAsync(ctx context.Context, message *Message, callback func(err error), options *SendAsyncOptions) error {
+	select {
+	case s.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	go func() {
+		err := s.SendMessage(ctx, message, nil)
+		<-s.inFlight
+
+		if callback != nil {
+			callback(err)
+		}
+	}()
+
+	return nil
+}
+
 // SendMessageBatchOptions contains optional parameters for the SendMessageBatch function.
 type SendMessageBatchOptions struct {
 	// For future expansion
@@ -80,7 +174,9 @@ type SendMessageBatchOptions struct {
 // If the operation fails it can return an *azservicebus.Error type if the failure is actionable.
 func (s *Sender) SendMessageBatch(ctx context.Context, batch *MessageBatch, options *SendMessageBatchOptions) error {
 	err := s.links.Retry(ctx, EventSender, "SendMessageBatch", func(ctx context.Context, lwid *internal.LinksWithID, args *utils.RetryFnArgs) error {
-		return lwid.Sender.Send(ctx, batch.toAMQPMessage())
+		err := lwid.Sender.Send(ctx, batch.toAMQPMessage())
+		s.partitionKeys.observe(batch.partitionKey, err)
+		return err
 	}, RetryOptions(s.retryOptions))
 
 	return internal.TransformError(err)
@@ -99,6 +195,13 @@ func (s *Sender) ScheduleMessages(ctx context.Context, messages []*Message, sche
 	var amqpMessages []*amqp.Message
 
 	for _, m := range messages {
+		m, err := s.bodyEncryptor.encrypt(ctx, s.idGenerators.apply(m))
+
+		if err != nil {
+			return nil, err
+		}
+
+		m = s.partitionKeys.apply(m)
 		amqpMessages = append(amqpMessages, m.toAMQPMessage())
 	}
 
@@ -163,6 +266,13 @@ type newSenderArgs struct {
 	queueOrTopic   string
 	cleanupOnClose func()
 	retryOptions   RetryOptions
+	idGenerators   idGenerators
+	bodyEncryption *BodyEncryptionOptions
+
+	partitionAffinity *PartitionAffinityOptions
+
+	// maxInFlightSends is the size of the SendAsync window. Defaults to defaultMaxInFlightSends.
+	maxInFlightSends int
 }
 
 func newSender(args newSenderArgs) (*Sender, error) {
@@ -170,12 +280,35 @@ func newSender(args newSenderArgs) (*Sender, error) {
 		return nil, err
 	}
 
+	encryptor, err := newBodyEncryptor(args.bodyEncryption)
+
+	if err != nil {
+		return nil, err
+	}
+
+	partitionKeys, err := newPartitionKeyAssigner(args.partitionAffinity)
+
+	if err != nil {
+		return nil, err
+	}
+
 	sender := &Sender{
 		queueOrTopic:   args.queueOrTopic,
 		cleanupOnClose: args.cleanupOnClose,
 		retryOptions:   args.retryOptions,
+		idGenerators:   args.idGenerators,
+		bodyEncryptor:  encryptor,
+		partitionKeys:  partitionKeys,
+	}
+
+	maxInFlightSends := args.maxInFlightSends
+
+	if maxInFlightSends <= 0 {
+		maxInFlightSends = defaultMaxInFlightSends
 	}
 
+	sender.inFlight = make(chan struct{}, maxInFlightSends)
+
 	sender.links = args.ns.NewAMQPLinks(args.queueOrTopic, sender.createSenderLink, internal.GetRecoveryKind)
 	return sender, nil
 }
@@ -5,9 +5,11 @@ package azservicebus
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal"
 	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus/internal/go-amqp"
 	"github.com/stretchr/testify/require"
@@ -56,3 +58,94 @@ func TestSender_UserFacingError(t *testing.T) {
 	require.ErrorAs(t, err, &asSBError)
 	require.Equal(t, CodeConnectionLost, asSBError.Code)
 }
+
+func TestSender_IDGenerators(t *testing.T) {
+	gen := idGenerators{
+		messageID: func(message *Message) string {
+			return "generated-" + string(message.Body)
+		},
+		correlationID: func(message *Message) string {
+			return "correlated-" + *message.MessageID
+		},
+	}
+
+	original := &Message{Body: []byte("hello")}
+	msg := gen.apply(original)
+
+	require.Equal(t, "generated-hello", *msg.MessageID)
+	require.Equal(t, "correlated-generated-hello", *msg.CorrelationID)
+
+	// the caller's message isn't modified
+	require.Nil(t, original.MessageID)
+	require.Nil(t, original.CorrelationID)
+
+	// IDs the caller set are kept
+	withIDs := &Message{MessageID: to.Ptr("mine"), CorrelationID: to.Ptr("also mine")}
+	require.Same(t, withIDs, gen.apply(withIDs))
+
+	mb := newMessageBatch(8000)
+	mb.idGenerators = gen
+	require.NoError(t, mb.AddMessage(original, nil))
+	require.EqualValues(t, 1, mb.NumMessages())
+
+	// no generators, nothing changes
+	require.Same(t, original, idGenerators{}.apply(original))
+}
+
+type blockingAMQPSender struct {
+	internal.AMQPSender
+	sent    chan *amqp.Message
+	release chan error
+}
+
+func (s *blockingAMQPSender) Send(ctx context.Context, msg *amqp.Message) error {
+	s.sent <- msg
+	return <-s.release
+}
+
+func TestSender_SendAsync(t *testing.T) {
+	amqpSender := &blockingAMQPSender{
+		sent:    make(chan *amqp.Message, 10),
+		release: make(chan error, 10),
+	}
+
+	sender, err := newSender(newSenderArgs{
+		ns: &internal.FakeNS{
+			AMQPLinks: &internal.FakeAMQPLinks{Sender: amqpSender},
+		},
+		queueOrTopic:     "queue",
+		cleanupOnClose:   func() {},
+		maxInFlightSends: 2,
+	})
+	require.NoError(t, err)
+
+	results := make(chan error, 10)
+	callback := func(err error) { results <- err }
+
+	require.NoError(t, sender.SendAsync(context.Background(), &Message{}, callback, nil))
+	require.NoError(t, sender.SendAsync(context.Background(), &Message{}, callback, nil))
+
+	// both sends are in progress at the same time
+	<-amqpSender.sent
+	<-amqpSender.sent
+
+	// the window is full
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, sender.SendAsync(ctx, &Message{}, callback, nil), context.DeadlineExceeded)
+
+	amqpSender.release <- nil
+	require.NoError(t, <-results)
+
+	// completing a send frees a slot
+	require.NoError(t, sender.SendAsync(context.Background(), &Message{}, callback, nil))
+	<-amqpSender.sent
+
+	amqpSender.release <- &amqp.ConnectionError{}
+	amqpSender.release <- nil
+
+	var asSBError *Error
+	errs := []error{<-results, <-results}
+	require.True(t, errors.As(errs[0], &asSBError) || errors.As(errs[1], &asSBError))
+	require.Equal(t, CodeConnectionLost, asSBError.Code)
+}
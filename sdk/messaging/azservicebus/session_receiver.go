@@ -37,6 +37,10 @@ type SessionReceiverOptions struct {
 	// More information about receive modes:
 	// https://docs.microsoft.com/azure/service-bus-messaging/message-transfers-locks-settlement#settling-receive-operations
 	ReceiveMode ReceiveMode
+
+	// BodyEncryption, when set, decrypts the bodies of messages encrypted by a Sender
+	// with NewSenderOptions.BodyEncryption. See ReceiverOptions.BodyEncryption.
+	BodyEncryption *BodyEncryptionOptions
 }
 
 func toReceiverOptions(sropts *SessionReceiverOptions) *ReceiverOptions {
@@ -45,7 +49,8 @@ func toReceiverOptions(sropts *SessionReceiverOptions) *ReceiverOptions {
 	}
 
 	return &ReceiverOptions{
-		ReceiveMode: sropts.ReceiveMode,
+		ReceiveMode:    sropts.ReceiveMode,
+		BodyEncryption: sropts.BodyEncryption,
 	}
 }
 
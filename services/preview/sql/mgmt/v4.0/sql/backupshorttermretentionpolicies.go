@@ -292,7 +292,7 @@ func (client BackupShortTermRetentionPoliciesClient) listByDatabaseNextResults(c
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.BackupShortTermRetentionPoliciesClient", "listByDatabaseNextResults", resp, "Failure sending next results request")
@@ -290,7 +290,7 @@ func (client DatabaseBlobAuditingPoliciesClient) listByDatabaseNextResults(ctx c
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.DatabaseBlobAuditingPoliciesClient", "listByDatabaseNextResults", resp, "Failure sending next results request")
@@ -207,7 +207,7 @@ func (client DatabaseOperationsClient) listByDatabaseNextResults(ctx context.Con
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.DatabaseOperationsClient", "listByDatabaseNextResults", resp, "Failure sending next results request")
@@ -563,7 +563,7 @@ func (client DatabasesClient) listByElasticPoolNextResults(ctx context.Context,
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByElasticPoolSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByElasticPoolSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.DatabasesClient", "listByElasticPoolNextResults", resp, "Failure sending next results request")
@@ -682,7 +682,7 @@ func (client DatabasesClient) listByServerNextResults(ctx context.Context, lastR
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.DatabasesClient", "listByServerNextResults", resp, "Failure sending next results request")
@@ -801,7 +801,7 @@ func (client DatabasesClient) listInaccessibleByServerNextResults(ctx context.Co
 	if req == nil {
 		return
 	}
-	resp, err := client.ListInaccessibleByServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListInaccessibleByServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.DatabasesClient", "listInaccessibleByServerNextResults", resp, "Failure sending next results request")
@@ -368,7 +368,7 @@ func (client DatabaseVulnerabilityAssessmentsClient) listByDatabaseNextResults(c
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.DatabaseVulnerabilityAssessmentsClient", "listByDatabaseNextResults", resp, "Failure sending next results request")
@@ -376,7 +376,7 @@ func (client DatabaseVulnerabilityAssessmentScansClient) listByDatabaseNextResul
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.DatabaseVulnerabilityAssessmentScansClient", "listByDatabaseNextResults", resp, "Failure sending next results request")
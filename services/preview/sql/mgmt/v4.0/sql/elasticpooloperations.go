@@ -205,7 +205,7 @@ func (client ElasticPoolOperationsClient) listByElasticPoolNextResults(ctx conte
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByElasticPoolSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByElasticPoolSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ElasticPoolOperationsClient", "listByElasticPoolNextResults", resp, "Failure sending next results request")
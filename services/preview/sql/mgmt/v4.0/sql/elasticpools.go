@@ -462,7 +462,7 @@ func (client ElasticPoolsClient) listByServerNextResults(ctx context.Context, la
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ElasticPoolsClient", "listByServerNextResults", resp, "Failure sending next results request")
@@ -288,7 +288,7 @@ func (client EncryptionProtectorsClient) listByServerNextResults(ctx context.Con
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.EncryptionProtectorsClient", "listByServerNextResults", resp, "Failure sending next results request")
@@ -287,7 +287,7 @@ func (client ExtendedServerBlobAuditingPoliciesClient) listByServerNextResults(c
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ExtendedServerBlobAuditingPoliciesClient", "listByServerNextResults", resp, "Failure sending next results request")
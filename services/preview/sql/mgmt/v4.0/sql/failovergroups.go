@@ -544,7 +544,7 @@ func (client FailoverGroupsClient) listByServerNextResults(ctx context.Context,
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.FailoverGroupsClient", "listByServerNextResults", resp, "Failure sending next results request")
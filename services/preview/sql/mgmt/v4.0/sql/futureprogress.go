@@ -0,0 +1,49 @@
+package sql
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/Azure/go-autorest/autorest/azure"
+)
+
+// FutureProgress returns the completion percentage of a long-running operation, for example a
+// DatabasesCreateOrUpdateFuture, as reported by the percentComplete field of the most recent
+// operation status response. ok is false when no status has been received yet or the status
+// doesn't report progress, which is the case for many operations.
+func FutureProgress(future azure.FutureAPI) (percentComplete float64, ok bool) {
+	if future == nil {
+		return 0, false
+	}
+	resp := future.Response()
+	if resp == nil || resp.Body == nil {
+		return 0, false
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	// put the body back so it's available to the future's responder
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil || len(b) == 0 {
+		return 0, false
+	}
+	var status struct {
+		PercentComplete *float64 `json:"percentComplete"`
+		Properties      *struct {
+			PercentComplete *float64 `json:"percentComplete"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(b, &status); err != nil {
+		return 0, false
+	}
+	switch {
+	case status.PercentComplete != nil:
+		return *status.PercentComplete, true
+	case status.Properties != nil && status.Properties.PercentComplete != nil:
+		return *status.Properties.PercentComplete, true
+	}
+	return 0, false
+}
@@ -545,7 +545,7 @@ func (client InstanceFailoverGroupsClient) listByLocationNextResults(ctx context
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByLocationSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByLocationSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.InstanceFailoverGroupsClient", "listByLocationNextResults", resp, "Failure sending next results request")
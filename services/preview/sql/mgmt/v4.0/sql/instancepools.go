@@ -368,7 +368,7 @@ func (client InstancePoolsClient) listNextResults(ctx context.Context, lastResul
 	if req == nil {
 		return
 	}
-	resp, err := client.ListSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.InstancePoolsClient", "listNextResults", resp, "Failure sending next results request")
@@ -485,7 +485,7 @@ func (client InstancePoolsClient) listByResourceGroupNextResults(ctx context.Con
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.InstancePoolsClient", "listByResourceGroupNextResults", resp, "Failure sending next results request")
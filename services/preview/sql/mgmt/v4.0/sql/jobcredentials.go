@@ -380,7 +380,7 @@ func (client JobCredentialsClient) listByAgentNextResults(ctx context.Context, l
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByAgentSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByAgentSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobCredentialsClient", "listByAgentNextResults", resp, "Failure sending next results request")
@@ -490,7 +490,7 @@ func (client JobExecutionsClient) listByAgentNextResults(ctx context.Context, la
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByAgentSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByAgentSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobExecutionsClient", "listByAgentNextResults", resp, "Failure sending next results request")
@@ -641,7 +641,7 @@ func (client JobExecutionsClient) listByJobNextResults(ctx context.Context, last
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByJobSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByJobSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobExecutionsClient", "listByJobNextResults", resp, "Failure sending next results request")
@@ -370,7 +370,7 @@ func (client JobsClient) listByAgentNextResults(ctx context.Context, lastResults
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByAgentSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByAgentSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobsClient", "listByAgentNextResults", resp, "Failure sending next results request")
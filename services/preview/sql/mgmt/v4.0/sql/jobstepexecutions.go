@@ -245,7 +245,7 @@ func (client JobStepExecutionsClient) listByJobExecutionNextResults(ctx context.
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByJobExecutionSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByJobExecutionSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobStepExecutionsClient", "listByJobExecutionNextResults", resp, "Failure sending next results request")
@@ -481,7 +481,7 @@ func (client JobStepsClient) listByJobNextResults(ctx context.Context, lastResul
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByJobSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByJobSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobStepsClient", "listByJobNextResults", resp, "Failure sending next results request")
@@ -606,7 +606,7 @@ func (client JobStepsClient) listByVersionNextResults(ctx context.Context, lastR
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByVersionSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByVersionSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobStepsClient", "listByVersionNextResults", resp, "Failure sending next results request")
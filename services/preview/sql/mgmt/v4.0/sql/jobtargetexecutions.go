@@ -247,7 +247,7 @@ func (client JobTargetExecutionsClient) listByJobExecutionNextResults(ctx contex
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByJobExecutionSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByJobExecutionSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobTargetExecutionsClient", "listByJobExecutionNextResults", resp, "Failure sending next results request")
@@ -402,7 +402,7 @@ func (client JobTargetExecutionsClient) listByStepNextResults(ctx context.Contex
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByStepSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByStepSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobTargetExecutionsClient", "listByStepNextResults", resp, "Failure sending next results request")
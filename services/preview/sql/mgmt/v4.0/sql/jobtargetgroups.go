@@ -378,7 +378,7 @@ func (client JobTargetGroupsClient) listByAgentNextResults(ctx context.Context,
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByAgentSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByAgentSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobTargetGroupsClient", "listByAgentNextResults", resp, "Failure sending next results request")
@@ -210,7 +210,7 @@ func (client JobVersionsClient) listByJobNextResults(ctx context.Context, lastRe
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByJobSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByJobSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.JobVersionsClient", "listByJobNextResults", resp, "Failure sending next results request")
@@ -0,0 +1,70 @@
+package sql
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/tracing"
+)
+
+// ListTruncatedError is returned by the ...All list methods when the list has more items than the maximum the
+// caller asked for. The method also returns the first Max items.
+type ListTruncatedError struct {
+	// Max - The maximum number of items the caller asked for.
+	Max int
+}
+
+// Error implements the error interface for type ListTruncatedError.
+func (e ListTruncatedError) Error() string {
+	return fmt.Sprintf("the list has more than %d items", e.Max)
+}
+
+// collectAll enumerates an iterator, calling appendValue for each item, until the iterator is done or max items
+// have been collected. It returns a ListTruncatedError when more items remain after max.
+func collectAll(ctx context.Context, max int, notDone func() bool, next func(context.Context) error, appendValue func()) error {
+	for n := 0; notDone(); n++ {
+		if n == max {
+			return ListTruncatedError{Max: max}
+		}
+		appendValue()
+		if err := next(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListBySyncGroupAll lists the sync members in a sync group into a slice, crossing page boundaries as required.
+// When the sync group has more than max sync members, it returns the first max along with a ListTruncatedError.
+// Parameters:
+// resourceGroupName - the name of the resource group that contains the resource. You can obtain this value
+// from the Azure Resource Manager API or the portal.
+// serverName - the name of the server.
+// databaseName - the name of the database on which the sync group is hosted.
+// syncGroupName - the name of the sync group on which the sync members are hosted.
+// max - the maximum number of sync members to return. It must be greater than 0.
+func (client SyncMembersClient) ListBySyncGroupAll(ctx context.Context, resourceGroupName string, serverName string, databaseName string, syncGroupName string, max int) (result []SyncMember, err error) {
+	if tracing.IsEnabled() {
+		ctx = tracing.StartSpan(ctx, fqdn+"/SyncMembersClient.ListBySyncGroupAll")
+		defer func() {
+			tracing.EndSpan(ctx, -1, err)
+		}()
+	}
+	if max <= 0 {
+		return nil, autorest.NewError("sql.SyncMembersClient", "ListBySyncGroupAll", "max must be greater than 0, got %d", max)
+	}
+
+	iter, err := client.ListBySyncGroupComplete(ctx, resourceGroupName, serverName, databaseName, syncGroupName)
+	if err != nil {
+		return nil, err
+	}
+	// closures rather than method values, which would copy the iterator
+	err = collectAll(ctx, max, func() bool { return iter.NotDone() }, iter.NextWithContext, func() {
+		result = append(result, iter.Value())
+	})
+	return result, err
+}
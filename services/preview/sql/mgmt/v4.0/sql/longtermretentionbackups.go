@@ -463,7 +463,7 @@ func (client LongTermRetentionBackupsClient) listByDatabaseNextResults(ctx conte
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionBackupsClient", "listByDatabaseNextResults", resp, "Failure sending next results request")
@@ -587,7 +587,7 @@ func (client LongTermRetentionBackupsClient) listByLocationNextResults(ctx conte
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByLocationSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByLocationSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionBackupsClient", "listByLocationNextResults", resp, "Failure sending next results request")
@@ -718,7 +718,7 @@ func (client LongTermRetentionBackupsClient) listByResourceGroupDatabaseNextResu
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionBackupsClient", "listByResourceGroupDatabaseNextResults", resp, "Failure sending next results request")
@@ -845,7 +845,7 @@ func (client LongTermRetentionBackupsClient) listByResourceGroupLocationNextResu
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupLocationSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupLocationSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionBackupsClient", "listByResourceGroupLocationNextResults", resp, "Failure sending next results request")
@@ -974,7 +974,7 @@ func (client LongTermRetentionBackupsClient) listByResourceGroupServerNextResult
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionBackupsClient", "listByResourceGroupServerNextResults", resp, "Failure sending next results request")
@@ -1100,7 +1100,7 @@ func (client LongTermRetentionBackupsClient) listByServerNextResults(ctx context
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionBackupsClient", "listByServerNextResults", resp, "Failure sending next results request")
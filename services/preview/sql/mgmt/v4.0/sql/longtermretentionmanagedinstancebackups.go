@@ -464,7 +464,7 @@ func (client LongTermRetentionManagedInstanceBackupsClient) listByDatabaseNextRe
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionManagedInstanceBackupsClient", "listByDatabaseNextResults", resp, "Failure sending next results request")
@@ -590,7 +590,7 @@ func (client LongTermRetentionManagedInstanceBackupsClient) listByInstanceNextRe
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionManagedInstanceBackupsClient", "listByInstanceNextResults", resp, "Failure sending next results request")
@@ -714,7 +714,7 @@ func (client LongTermRetentionManagedInstanceBackupsClient) listByLocationNextRe
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByLocationSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByLocationSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionManagedInstanceBackupsClient", "listByLocationNextResults", resp, "Failure sending next results request")
@@ -845,7 +845,7 @@ func (client LongTermRetentionManagedInstanceBackupsClient) listByResourceGroupD
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionManagedInstanceBackupsClient", "listByResourceGroupDatabaseNextResults", resp, "Failure sending next results request")
@@ -974,7 +974,7 @@ func (client LongTermRetentionManagedInstanceBackupsClient) listByResourceGroupI
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionManagedInstanceBackupsClient", "listByResourceGroupInstanceNextResults", resp, "Failure sending next results request")
@@ -1101,7 +1101,7 @@ func (client LongTermRetentionManagedInstanceBackupsClient) listByResourceGroupL
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupLocationSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupLocationSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.LongTermRetentionManagedInstanceBackupsClient", "listByResourceGroupLocationNextResults", resp, "Failure sending next results request")
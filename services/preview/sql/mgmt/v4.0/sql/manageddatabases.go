@@ -460,7 +460,7 @@ func (client ManagedDatabasesClient) listByInstanceNextResults(ctx context.Conte
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedDatabasesClient", "listByInstanceNextResults", resp, "Failure sending next results request")
@@ -579,7 +579,7 @@ func (client ManagedDatabasesClient) listInaccessibleByInstanceNextResults(ctx c
 	if req == nil {
 		return
 	}
-	resp, err := client.ListInaccessibleByInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListInaccessibleByInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedDatabasesClient", "listInaccessibleByInstanceNextResults", resp, "Failure sending next results request")
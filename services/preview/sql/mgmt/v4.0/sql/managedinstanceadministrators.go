@@ -377,7 +377,7 @@ func (client ManagedInstanceAdministratorsClient) listByInstanceNextResults(ctx
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedInstanceAdministratorsClient", "listByInstanceNextResults", resp, "Failure sending next results request")
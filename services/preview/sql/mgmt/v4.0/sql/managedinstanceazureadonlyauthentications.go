@@ -377,7 +377,7 @@ func (client ManagedInstanceAzureADOnlyAuthenticationsClient) listByInstanceNext
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedInstanceAzureADOnlyAuthenticationsClient", "listByInstanceNextResults", resp, "Failure sending next results request")
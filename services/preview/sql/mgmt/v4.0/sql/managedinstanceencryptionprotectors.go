@@ -288,7 +288,7 @@ func (client ManagedInstanceEncryptionProtectorsClient) listByInstanceNextResult
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedInstanceEncryptionProtectorsClient", "listByInstanceNextResults", resp, "Failure sending next results request")
@@ -280,7 +280,7 @@ func (client ManagedInstanceOperationsClient) listByManagedInstanceNextResults(c
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByManagedInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByManagedInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedInstanceOperationsClient", "listByManagedInstanceNextResults", resp, "Failure sending next results request")
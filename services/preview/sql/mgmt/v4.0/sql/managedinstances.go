@@ -448,7 +448,7 @@ func (client ManagedInstancesClient) listNextResults(ctx context.Context, lastRe
 	if req == nil {
 		return
 	}
-	resp, err := client.ListSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedInstancesClient", "listNextResults", resp, "Failure sending next results request")
@@ -567,7 +567,7 @@ func (client ManagedInstancesClient) listByInstancePoolNextResults(ctx context.C
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByInstancePoolSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByInstancePoolSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedInstancesClient", "listByInstancePoolNextResults", resp, "Failure sending next results request")
@@ -715,7 +715,7 @@ func (client ManagedInstancesClient) listByManagedInstanceNextResults(ctx contex
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByManagedInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByManagedInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedInstancesClient", "listByManagedInstanceNextResults", resp, "Failure sending next results request")
@@ -832,7 +832,7 @@ func (client ManagedInstancesClient) listByResourceGroupNextResults(ctx context.
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedInstancesClient", "listByResourceGroupNextResults", resp, "Failure sending next results request")
@@ -290,7 +290,7 @@ func (client ManagedRestorableDroppedDatabaseBackupShortTermRetentionPoliciesCli
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByRestorableDroppedDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByRestorableDroppedDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ManagedRestorableDroppedDatabaseBackupShortTermRetentionPoliciesClient", "listByRestorableDroppedDatabaseNextResults", resp, "Failure sending next results request")
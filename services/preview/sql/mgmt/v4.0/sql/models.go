@@ -24380,6 +24380,11 @@ type SyncMembersCreateOrUpdateFuture struct {
 	// Result returns the result of the asynchronous operation.
 	// If the operation has not completed it will return an error.
 	Result func(SyncMembersClient) (SyncMember, error)
+	// ResultFromResponse returns the result of the asynchronous operation from the final
+	// response of the operation, without retrieving the SyncMember with an additional GET.
+	// If the operation has not completed, or its final response doesn't contain the
+	// SyncMember, it will return an error.
+	ResultFromResponse func(SyncMembersClient) (SyncMember, error)
 }
 
 // UnmarshalJSON is the custom unmarshaller for CreateFuture.
@@ -24390,6 +24395,7 @@ func (future *SyncMembersCreateOrUpdateFuture) UnmarshalJSON(body []byte) error
 	}
 	future.FutureAPI = &azFuture
 	future.Result = future.result
+	future.ResultFromResponse = future.resultFromResponse
 	return nil
 }
 
@@ -24416,6 +24422,37 @@ func (future *SyncMembersCreateOrUpdateFuture) result(client SyncMembersClient)
 	return
 }
 
+// resultFromResponse is the default implementation for SyncMembersCreateOrUpdateFuture.ResultFromResponse.
+func (future *SyncMembersCreateOrUpdateFuture) resultFromResponse(client SyncMembersClient) (sm SyncMember, err error) {
+	var done bool
+	done, err = future.DoneWithContext(context.Background(), client)
+	if err != nil {
+		err = autorest.NewErrorWithError(err, "sql.SyncMembersCreateOrUpdateFuture", "ResultFromResponse", future.Response(), "Polling failure")
+		return
+	}
+	if !done {
+		sm.Response.Response = future.Response()
+		err = azure.NewAsyncOpIncompleteError("sql.SyncMembersCreateOrUpdateFuture")
+		return
+	}
+	resp := future.Response()
+	if resp == nil || resp.StatusCode == http.StatusNoContent {
+		sm.Response.Response = resp
+		err = autorest.NewError("sql.SyncMembersCreateOrUpdateFuture", "ResultFromResponse", "the final response of the operation has no content; use Result to retrieve the SyncMember")
+		return
+	}
+	sm, err = client.CreateOrUpdateResponder(resp)
+	if err != nil {
+		err = autorest.NewErrorWithError(err, "sql.SyncMembersCreateOrUpdateFuture", "ResultFromResponse", resp, "Failure responding to request")
+		return
+	}
+	// the final response of an operation tracked with Azure-AsyncOperation is an operation status rather than the SyncMember
+	if sm.ID == nil {
+		err = autorest.NewError("sql.SyncMembersCreateOrUpdateFuture", "ResultFromResponse", "the final response of the operation doesn't contain the SyncMember; use Result to retrieve it")
+	}
+	return
+}
+
 // SyncMembersDeleteFuture an abstraction for monitoring and retrieving the results of a long-running
 // operation.
 type SyncMembersDeleteFuture struct {
@@ -0,0 +1,29 @@
+package sql
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+const (
+	// nextPageRetryBackoff is the delay before the first retry of a request for the next page of results. It doubles
+	// with each retry.
+	nextPageRetryBackoff = 2 * time.Second
+
+	// nextPageRetryCap is the longest delay between retries of a request for the next page of results.
+	nextPageRetryCap = 30 * time.Second
+)
+
+// sendNextPage sends a request for the next page of a list with sender, the list operation's Sender method. A request
+// that fails with a transient error, such as status 502 or a broken connection, is retried up to client.RetryAttempts
+// times with exponential backoff, or after the delay in the response's Retry-After header, so that a failure in the
+// middle of a long enumeration doesn't abort it. Retrying stops when the request's context is done.
+func sendNextPage(client autorest.Client, req *http.Request, sender func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	return autorest.SendWithSender(autorest.SenderFunc(sender), req,
+		autorest.DoRetryForStatusCodesWithCap(client.RetryAttempts, nextPageRetryBackoff, nextPageRetryCap, autorest.StatusCodesForRetry...))
+}
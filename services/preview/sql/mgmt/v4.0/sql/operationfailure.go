@@ -0,0 +1,177 @@
+package sql
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/date"
+)
+
+// OperationFailedError is returned by WaitForCompletion when a long-running operation, for example a
+// SyncMembersCreateOrUpdateFuture, ends in the Failed or Canceled state. It holds the error, target and timestamps
+// from the operation status Azure Resource Manager reports, which the future's own error doesn't include.
+type OperationFailedError struct {
+	// Status - The terminal status of the operation, for example Failed or Canceled.
+	Status string
+	// Code - The error code of the operation.
+	Code string
+	// Message - The error message of the operation.
+	Message string
+	// Target - The target of the error, when the service reports one.
+	Target *string
+	// Details - The details of the error, which often hold the underlying cause.
+	Details []map[string]interface{}
+	// OperationID - The ID of the operation status, when the operation is tracked with Azure-AsyncOperation.
+	OperationID *string
+	// ResourceID - The ID of the resource, when the operation is tracked with the resource's provisioning state.
+	ResourceID *string
+	// StartTime - When the operation started, when the service reports it.
+	StartTime *date.Time
+	// EndTime - When the operation ended, when the service reports it.
+	EndTime *date.Time
+	// PollingURL - The URL the operation status was read from.
+	PollingURL string
+	// Err - The error the future returned.
+	Err error
+}
+
+// Error implements the error interface for type OperationFailedError.
+func (e *OperationFailedError) Error() string {
+	msg := fmt.Sprintf("sql: long-running operation %s: Code=%q Message=%q", strings.ToLower(e.Status), e.Code, e.Message)
+	if e.Target != nil {
+		msg += fmt.Sprintf(" Target=%q", *e.Target)
+	}
+	if len(e.Details) > 0 {
+		if d, err := json.Marshal(e.Details); err == nil {
+			msg += fmt.Sprintf(" Details=%s", d)
+		}
+	}
+	if e.ResourceID != nil {
+		msg += fmt.Sprintf(" Resource=%q", *e.ResourceID)
+	}
+	if e.OperationID != nil {
+		msg += fmt.Sprintf(" Operation=%q", *e.OperationID)
+	}
+	if e.StartTime != nil {
+		msg += fmt.Sprintf(" StartTime=%s", e.StartTime)
+	}
+	if e.EndTime != nil {
+		msg += fmt.Sprintf(" EndTime=%s", e.EndTime)
+	}
+	return msg
+}
+
+// Unwrap returns the error the future returned.
+func (e *OperationFailedError) Unwrap() error {
+	return e.Err
+}
+
+// WaitForCompletion waits for future to complete, like its WaitForCompletionRef method, using client, the
+// client that started the operation, for example SyncMembersClient.Client. When the operation ends in the Failed
+// or Canceled state, it reads the operation status again and returns an *OperationFailedError describing the
+// failure. Other errors are returned unchanged.
+func WaitForCompletion(ctx context.Context, future azure.FutureAPI, client autorest.Client) error {
+	err := future.WaitForCompletionRef(ctx, client)
+	if err == nil || !operationFailed(future.Status()) {
+		return err
+	}
+	return newOperationFailedError(ctx, future, client, err)
+}
+
+func operationFailed(status string) bool {
+	return strings.EqualFold(status, "Failed") || strings.EqualFold(status, "Canceled")
+}
+
+// operationStatus is the operation status of an operation tracked with Azure-AsyncOperation, or the resource of
+// one tracked with its provisioning state
+type operationStatus struct {
+	ID         *string              `json:"id"`
+	Status     *string              `json:"status"`
+	StartTime  *date.Time           `json:"startTime"`
+	EndTime    *date.Time           `json:"endTime"`
+	Error      *azure.ServiceError  `json:"error"`
+	Properties *operationProperties `json:"properties"`
+}
+
+type operationProperties struct {
+	ProvisioningState *string `json:"provisioningState"`
+}
+
+func newOperationFailedError(ctx context.Context, future azure.FutureAPI, client autorest.Client, err error) error {
+	ofe := &OperationFailedError{
+		Status:     future.Status(),
+		PollingURL: future.PollingURL(),
+		Err:        err,
+	}
+	var se *azure.ServiceError
+	if errors.As(err, &se) {
+		ofe.Code, ofe.Message, ofe.Target, ofe.Details = se.Code, se.Message, se.Target, se.Details
+	}
+
+	body, readErr := readOperationStatus(ctx, ofe.PollingURL, client)
+	if readErr != nil {
+		// the last status the future received is the next best thing
+		body = responseBody(future.Response())
+	}
+	var status operationStatus
+	if len(body) == 0 || json.Unmarshal(body, &status) != nil {
+		return ofe
+	}
+	if status.Properties != nil && status.Properties.ProvisioningState != nil {
+		ofe.ResourceID = status.ID
+	} else {
+		ofe.OperationID = status.ID
+	}
+	ofe.StartTime, ofe.EndTime = status.StartTime, status.EndTime
+	if status.Error != nil && status.Error.Code != "" {
+		ofe.Code, ofe.Message, ofe.Target, ofe.Details = status.Error.Code, status.Error.Message, status.Error.Target, status.Error.Details
+	}
+	return ofe
+}
+
+// readOperationStatus gets the body of the operation status at pollingURL
+func readOperationStatus(ctx context.Context, pollingURL string, client autorest.Client) ([]byte, error) {
+	if pollingURL == "" {
+		return nil, errors.New("the operation has no polling URL")
+	}
+	req, err := autorest.Prepare((&http.Request{}).WithContext(ctx),
+		autorest.AsGet(),
+		autorest.WithBaseURL(pollingURL))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Send(req, autorest.DoRetryForStatusCodes(client.RetryAttempts, client.RetryDuration, autorest.StatusCodesForRetry...))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reading the operation status returned status %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// responseBody returns the body of resp, leaving it readable
+func responseBody(resp *http.Response) []byte {
+	if resp == nil || resp.Body == nil {
+		return nil
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return nil
+	}
+	return b
+}
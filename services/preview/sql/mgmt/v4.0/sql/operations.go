@@ -113,7 +113,7 @@ func (client OperationsClient) listNextResults(ctx context.Context, lastResults
 	if req == nil {
 		return
 	}
-	resp, err := client.ListSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.OperationsClient", "listNextResults", resp, "Failure sending next results request")
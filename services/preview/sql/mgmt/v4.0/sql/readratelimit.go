@@ -0,0 +1,119 @@
+package sql
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+const (
+	// HeaderRemainingSubscriptionReads is the response header in which Azure Resource Manager reports how many
+	// reads remain in the subscription's quota.
+	HeaderRemainingSubscriptionReads = "x-ms-ratelimit-remaining-subscription-reads"
+
+	defaultReadRateLimitFloor = 1000
+	defaultReadRateLimitDelay = time.Second
+)
+
+// ReadRateLimitOptions contains the optional parameters for NewReadRateLimitSender.
+type ReadRateLimitOptions struct {
+	// Floor - The number of remaining subscription reads below which reads slow down. Defaults to 1000.
+	Floor int64
+	// Delay - How long a read waits when no reads remain. Reads wait in proportion to how far the remaining reads
+	// are below Floor, so they slow down gradually as the quota runs out. Defaults to one second.
+	Delay time.Duration
+}
+
+// ReadRateLimitSender is an autorest.Sender that slows down reads, such as the requests of the list iterators and
+// pages, before the subscription's read quota runs out and Azure Resource Manager starts returning status 429. The
+// quota is shared by every client of the subscription, so this keeps crawlers that list many resources from starving
+// other applications. Don't use this type directly, use NewReadRateLimitSender() instead.
+type ReadRateLimitSender struct {
+	sender  autorest.Sender
+	options ReadRateLimitOptions
+
+	mu        sync.Mutex
+	remaining int64
+	known     bool
+}
+
+// NewReadRateLimitSender wraps sender so that GET requests wait when the remaining subscription reads reported by the
+// last response are below the floor. Assign it to the Sender of a client, wrapping the client's current Sender. The
+// same ReadRateLimitSender can be shared by the clients of a subscription. Pass nil to accept the default values.
+func NewReadRateLimitSender(sender autorest.Sender, options *ReadRateLimitOptions) *ReadRateLimitSender {
+	if options == nil {
+		options = &ReadRateLimitOptions{}
+	}
+	s := &ReadRateLimitSender{sender: sender, options: *options}
+	if s.options.Floor <= 0 {
+		s.options.Floor = defaultReadRateLimitFloor
+	}
+	if s.options.Delay <= 0 {
+		s.options.Delay = defaultReadRateLimitDelay
+	}
+	return s
+}
+
+// Do waits before sending a GET request when the subscription's remaining reads are below the floor, then sends the
+// request and records the remaining reads from the response.
+func (s *ReadRateLimitSender) Do(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet {
+		if d := s.delay(); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-timer.C:
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			}
+		}
+	}
+	resp, err := s.sender.Do(req)
+	if resp != nil {
+		s.observe(resp)
+	}
+	return resp, err
+}
+
+// Remaining returns the remaining subscription reads reported by the most recent response that had them, and whether
+// any response had them.
+func (s *ReadRateLimitSender) Remaining() (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.remaining, s.known
+}
+
+// delay returns how long the next read waits.
+func (s *ReadRateLimitSender) delay() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.known || s.remaining >= s.options.Floor {
+		return 0
+	}
+	remaining := s.remaining
+	if remaining < 0 {
+		remaining = 0
+	}
+	return time.Duration(float64(s.options.Delay) * float64(s.options.Floor-remaining) / float64(s.options.Floor))
+}
+
+func (s *ReadRateLimitSender) observe(resp *http.Response) {
+	v := resp.Header.Get(HeaderRemainingSubscriptionReads)
+	if v == "" {
+		return
+	}
+	remaining, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remaining = remaining
+	s.known = true
+}
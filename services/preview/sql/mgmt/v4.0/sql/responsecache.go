@@ -0,0 +1,227 @@
+package sql
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+import (
+	"bytes"
+	"container/list"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+const (
+	defaultResponseCacheTTL        = 30 * time.Second
+	defaultResponseCacheMaxEntries = 1000
+)
+
+// ResponseCacheOptions contains the optional parameters for NewResponseCacheSender.
+type ResponseCacheOptions struct {
+	// TTL - How long a response is served from the cache. Defaults to 30 seconds.
+	TTL time.Duration
+	// MaxEntries - The maximum number of responses cached. When the cache is full, the least recently used response
+	// is evicted. Defaults to 1000.
+	MaxEntries int
+}
+
+// ResponseCacheSender is an autorest.Sender that caches the responses of reads, such as Get and the pages of List
+// methods, so that dashboards refreshing every few seconds don't consume the subscription's read quota. Cached
+// responses are invalidated by any PUT, PATCH, DELETE or POST request sent through the same ResponseCacheSender to the
+// same resource, its parents or its children. Changes made by other clients aren't seen until the responses expire.
+// Don't use this type directly, use NewResponseCacheSender() instead.
+type ResponseCacheSender struct {
+	sender  autorest.Sender
+	options ResponseCacheOptions
+	now     func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// cachedResponse is a response stored by ResponseCacheSender.
+type cachedResponse struct {
+	key        string
+	path       string
+	expires    time.Time
+	status     string
+	statusCode int
+	proto      string
+	header     http.Header
+	body       []byte
+}
+
+// NewResponseCacheSender wraps sender so that successful GET responses are served from a cache until they expire or
+// a request that changes the resource is sent. Assign it to the Sender of a client, wrapping the client's current
+// Sender. Share the same ResponseCacheSender between clients whose changes should invalidate each other's reads,
+// for example a SyncGroupsClient and a SyncMembersClient. Pass nil to accept the default values.
+func NewResponseCacheSender(sender autorest.Sender, options *ResponseCacheOptions) *ResponseCacheSender {
+	if options == nil {
+		options = &ResponseCacheOptions{}
+	}
+	s := &ResponseCacheSender{
+		sender:  sender,
+		options: *options,
+		now:     time.Now,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+	if s.options.TTL <= 0 {
+		s.options.TTL = defaultResponseCacheTTL
+	}
+	if s.options.MaxEntries <= 0 {
+		s.options.MaxEntries = defaultResponseCacheMaxEntries
+	}
+	return s
+}
+
+// Do serves a GET request from the cache when it has an unexpired response for the request's URL, and otherwise sends
+// the request, caching a successful response. Other requests are sent after invalidating the cached responses of the
+// resource they change.
+func (s *ResponseCacheSender) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		s.invalidate(mutatedResource(req))
+		resp, err := s.sender.Do(req)
+		// invalidate again in case a concurrent read cached the resource while it was changing
+		s.invalidate(mutatedResource(req))
+		return resp, err
+	}
+	if !cacheable(req) {
+		return s.sender.Do(req)
+	}
+	key := req.URL.String()
+	if resp := s.get(key, req); resp != nil {
+		return resp, nil
+	}
+	resp, err := s.sender.Do(req)
+	if err != nil || resp == nil || resp.StatusCode != http.StatusOK || resp.Body == nil {
+		return resp, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return resp, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	s.put(&cachedResponse{
+		key:        key,
+		path:       normalizePath(req.URL.Path),
+		expires:    s.now().Add(s.options.TTL),
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		proto:      resp.Proto,
+		header:     resp.Header.Clone(),
+		body:       body,
+	})
+	return resp, nil
+}
+
+// Purge removes all the cached responses.
+func (s *ResponseCacheSender) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = map[string]*list.Element{}
+	s.lru.Init()
+}
+
+// Len returns the number of cached responses, including those that have expired but not yet been evicted.
+func (s *ResponseCacheSender) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+// get returns a copy of the cached response for key, or nil.
+func (s *ResponseCacheSender) get(key string, req *http.Request) *http.Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	cached := e.Value.(*cachedResponse)
+	if !s.now().Before(cached.expires) {
+		s.lru.Remove(e)
+		delete(s.entries, key)
+		return nil
+	}
+	s.lru.MoveToFront(e)
+	return &http.Response{
+		Status:        cached.status,
+		StatusCode:    cached.statusCode,
+		Proto:         cached.proto,
+		Header:        cached.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(cached.body)),
+		ContentLength: int64(len(cached.body)),
+		Request:       req,
+	}
+}
+
+func (s *ResponseCacheSender) put(cached *cachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[cached.key]; ok {
+		e.Value = cached
+		s.lru.MoveToFront(e)
+		return
+	}
+	s.entries[cached.key] = s.lru.PushFront(cached)
+	for s.lru.Len() > s.options.MaxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.entries, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+// invalidate removes the cached responses of the resource at path, its parents and its children. A parent's
+// responses include lists of the resource, and a child's responses can reflect the resource's state.
+func (s *ResponseCacheSender) invalidate(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, e := range s.entries {
+		if cached := e.Value.(*cachedResponse); isPathPrefix(cached.path, path) || isPathPrefix(path, cached.path) {
+			s.lru.Remove(e)
+			delete(s.entries, key)
+		}
+	}
+}
+
+// mutatedResource returns the path of the resource a request changes. The last segment of a POST request's path
+// names an action, such as refreshSchema, rather than a resource.
+func mutatedResource(req *http.Request) string {
+	path := normalizePath(req.URL.Path)
+	if req.Method == http.MethodPost {
+		if i := strings.LastIndex(path, "/"); i > 0 {
+			path = path[:i]
+		}
+	}
+	return path
+}
+
+// cacheable returns false for the GET requests that poll long-running operations, whose responses change while the
+// operation runs.
+func cacheable(req *http.Request) bool {
+	path := normalizePath(req.URL.Path)
+	for _, segment := range []string{"/operationresults/", "/asyncoperation", "/azureasyncoperation/", "/operationstatuses/"} {
+		if strings.Contains(path+"/", segment) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizePath lowercases path, because Azure Resource Manager paths are case insensitive, and trims its trailing
+// slash.
+func normalizePath(path string) string {
+	return strings.TrimSuffix(strings.ToLower(path), "/")
+}
+
+// isPathPrefix returns true when prefix is path or one of its parents.
+func isPathPrefix(prefix string, path string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
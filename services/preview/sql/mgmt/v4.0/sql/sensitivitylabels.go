@@ -562,7 +562,7 @@ func (client SensitivityLabelsClient) listCurrentByDatabaseNextResults(ctx conte
 	if req == nil {
 		return
 	}
-	resp, err := client.ListCurrentByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListCurrentByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SensitivityLabelsClient", "listCurrentByDatabaseNextResults", resp, "Failure sending next results request")
@@ -694,7 +694,7 @@ func (client SensitivityLabelsClient) listRecommendedByDatabaseNextResults(ctx c
 	if req == nil {
 		return
 	}
-	resp, err := client.ListRecommendedByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListRecommendedByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SensitivityLabelsClient", "listRecommendedByDatabaseNextResults", resp, "Failure sending next results request")
@@ -289,7 +289,7 @@ func (client ServerDevOpsAuditSettingsClient) listByServerNextResults(ctx contex
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ServerDevOpsAuditSettingsClient", "listByServerNextResults", resp, "Failure sending next results request")
@@ -532,7 +532,7 @@ func (client ServersClient) listNextResults(ctx context.Context, lastResults Ser
 	if req == nil {
 		return
 	}
-	resp, err := client.ListSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ServersClient", "listNextResults", resp, "Failure sending next results request")
@@ -649,7 +649,7 @@ func (client ServersClient) listByResourceGroupNextResults(ctx context.Context,
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ServersClient", "listByResourceGroupNextResults", resp, "Failure sending next results request")
@@ -379,7 +379,7 @@ func (client ServerTrustGroupsClient) listByInstanceNextResults(ctx context.Cont
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByInstanceSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByInstanceSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ServerTrustGroupsClient", "listByInstanceNextResults", resp, "Failure sending next results request")
@@ -498,7 +498,7 @@ func (client ServerTrustGroupsClient) listByLocationNextResults(ctx context.Cont
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByLocationSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByLocationSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.ServerTrustGroupsClient", "listByLocationNextResults", resp, "Failure sending next results request")
@@ -197,7 +197,7 @@ func (client SubscriptionUsagesClient) listByLocationNextResults(ctx context.Con
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByLocationSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByLocationSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SubscriptionUsagesClient", "listByLocationNextResults", resp, "Failure sending next results request")
@@ -447,7 +447,7 @@ func (client SyncAgentsClient) listByServerNextResults(ctx context.Context, last
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByServerSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByServerSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SyncAgentsClient", "listByServerNextResults", resp, "Failure sending next results request")
@@ -568,7 +568,7 @@ func (client SyncAgentsClient) listLinkedDatabasesNextResults(ctx context.Contex
 	if req == nil {
 		return
 	}
-	resp, err := client.ListLinkedDatabasesSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListLinkedDatabasesSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SyncAgentsClient", "listLinkedDatabasesNextResults", resp, "Failure sending next results request")
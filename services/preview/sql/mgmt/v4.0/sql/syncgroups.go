@@ -363,6 +363,52 @@ func (client SyncGroupsClient) GetResponder(resp *http.Response) (result SyncGro
 	return
 }
 
+// Exists checks whether a sync group exists. It performs a Get request and returns false, rather than an
+// error, when the service responds with 404 Not Found. Other failures are returned as an autorest.DetailedError.
+// Parameters:
+// resourceGroupName - the name of the resource group that contains the resource. You can obtain this value
+// from the Azure Resource Manager API or the portal.
+// serverName - the name of the server.
+// databaseName - the name of the database on which the sync group is hosted.
+// syncGroupName - the name of the sync group.
+func (client SyncGroupsClient) Exists(ctx context.Context, resourceGroupName string, serverName string, databaseName string, syncGroupName string) (exists bool, err error) {
+	var resp *http.Response
+	if tracing.IsEnabled() {
+		ctx = tracing.StartSpan(ctx, fqdn+"/SyncGroupsClient.Exists")
+		defer func() {
+			sc := -1
+			if resp != nil {
+				sc = resp.StatusCode
+			}
+			tracing.EndSpan(ctx, sc, err)
+		}()
+	}
+	req, err := client.GetPreparer(ctx, resourceGroupName, serverName, databaseName, syncGroupName)
+	if err != nil {
+		err = autorest.NewErrorWithError(err, "sql.SyncGroupsClient", "Exists", nil, "Failure preparing request")
+		return
+	}
+
+	resp, err = client.GetSender(req)
+	if err != nil {
+		err = autorest.NewErrorWithError(err, "sql.SyncGroupsClient", "Exists", resp, "Failure sending request")
+		return
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		err = autorest.Respond(resp, autorest.ByDiscardingBody(), autorest.ByClosing())
+		return
+	}
+
+	_, err = client.GetResponder(resp)
+	if err != nil {
+		err = autorest.NewErrorWithError(err, "sql.SyncGroupsClient", "Exists", resp, "Failure responding to request")
+		return
+	}
+
+	return true, nil
+}
+
 // ListByDatabase lists sync groups under a hub database.
 // Parameters:
 // resourceGroupName - the name of the resource group that contains the resource. You can obtain this value
@@ -456,7 +502,7 @@ func (client SyncGroupsClient) listByDatabaseNextResults(ctx context.Context, la
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByDatabaseSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByDatabaseSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SyncGroupsClient", "listByDatabaseNextResults", resp, "Failure sending next results request")
@@ -579,7 +625,7 @@ func (client SyncGroupsClient) listHubSchemasNextResults(ctx context.Context, la
 	if req == nil {
 		return
 	}
-	resp, err := client.ListHubSchemasSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListHubSchemasSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SyncGroupsClient", "listHubSchemasNextResults", resp, "Failure sending next results request")
@@ -712,7 +758,7 @@ func (client SyncGroupsClient) listLogsNextResults(ctx context.Context, lastResu
 	if req == nil {
 		return
 	}
-	resp, err := client.ListLogsSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListLogsSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SyncGroupsClient", "listLogsNextResults", resp, "Failure sending next results request")
@@ -828,7 +874,7 @@ func (client SyncGroupsClient) listSyncDatabaseIdsNextResults(ctx context.Contex
 	if req == nil {
 		return
 	}
-	resp, err := client.ListSyncDatabaseIdsSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListSyncDatabaseIdsSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SyncGroupsClient", "listSyncDatabaseIdsNextResults", resp, "Failure sending next results request")
@@ -0,0 +1,199 @@
+package sql
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/tracing"
+)
+
+// SyncMemberPlanAction enumerates the values for the action of a SyncMemberPlanStep.
+type SyncMemberPlanAction string
+
+const (
+	// SyncMemberPlanActionCreate creates a sync member.
+	SyncMemberPlanActionCreate SyncMemberPlanAction = "Create"
+	// SyncMemberPlanActionUpdate updates a sync member.
+	SyncMemberPlanActionUpdate SyncMemberPlanAction = "Update"
+	// SyncMemberPlanActionDelete deletes a sync member.
+	SyncMemberPlanActionDelete SyncMemberPlanAction = "Delete"
+)
+
+// SyncMemberPlanStep is a change to one sync member.
+type SyncMemberPlanStep struct {
+	// Action - The change to make.
+	Action SyncMemberPlanAction
+	// SyncMemberName - The name of the sync member.
+	SyncMemberName string
+	// Desired - The desired sync member. It's nil when Action is SyncMemberPlanActionDelete.
+	Desired *SyncMember
+	// Current - The existing sync member. It's nil when Action is SyncMemberPlanActionCreate.
+	Current *SyncMember
+	// ChangedProperties - The JSON names of the properties that differ, when Action is SyncMemberPlanActionUpdate.
+	ChangedProperties []string
+}
+
+// SyncMemberPlan is an ordered list of changes that make the sync members of a sync group match a desired
+// state. Deletes come first, then updates, then creates.
+type SyncMemberPlan struct {
+	ResourceGroupName string
+	ServerName        string
+	DatabaseName      string
+	SyncGroupName     string
+	// Steps - The changes, in the order ApplyPlan makes them. Empty when the sync group already matches.
+	Steps []SyncMemberPlanStep
+}
+
+// Plan compares the desired sync members, keyed by name, with the sync members of a sync group and returns the
+// changes needed to make them match, without making any change. Only the properties set in a desired sync member
+// are compared. Password is never compared because the service doesn't return it; a sync member whose other
+// properties match isn't updated. Sync members that aren't in desired are deleted only when deleteUnlisted is true.
+// Parameters:
+// resourceGroupName - the name of the resource group that contains the resource. You can obtain this value
+// from the Azure Resource Manager API or the portal.
+// serverName - the name of the server.
+// databaseName - the name of the database on which the sync group is hosted.
+// syncGroupName - the name of the sync group on which the sync members are hosted.
+// desired - the desired sync members, keyed by name.
+// deleteUnlisted - whether to delete sync members that aren't in desired.
+func (client SyncMembersClient) Plan(ctx context.Context, resourceGroupName string, serverName string, databaseName string, syncGroupName string, desired map[string]SyncMember, deleteUnlisted bool) (result SyncMemberPlan, err error) {
+	if tracing.IsEnabled() {
+		ctx = tracing.StartSpan(ctx, fqdn+"/SyncMembersClient.Plan")
+		defer func() {
+			tracing.EndSpan(ctx, -1, err)
+		}()
+	}
+	result = SyncMemberPlan{
+		ResourceGroupName: resourceGroupName,
+		ServerName:        serverName,
+		DatabaseName:      databaseName,
+		SyncGroupName:     syncGroupName,
+	}
+
+	current := map[string]SyncMember{}
+	iter, err := client.ListBySyncGroupComplete(ctx, resourceGroupName, serverName, databaseName, syncGroupName)
+	if err != nil {
+		err = autorest.NewErrorWithError(err, "sql.SyncMembersClient", "Plan", nil, "Failure listing sync members")
+		return
+	}
+	for iter.NotDone() {
+		if sm := iter.Value(); sm.Name != nil {
+			current[*sm.Name] = sm
+		}
+		if err = iter.NextWithContext(ctx); err != nil {
+			err = autorest.NewErrorWithError(err, "sql.SyncMembersClient", "Plan", nil, "Failure listing sync members")
+			return
+		}
+	}
+
+	result.Steps = planSyncMembers(desired, current, deleteUnlisted)
+	return
+}
+
+// planSyncMembers returns the steps that change current to desired.
+func planSyncMembers(desired map[string]SyncMember, current map[string]SyncMember, deleteUnlisted bool) []SyncMemberPlanStep {
+	var deletes, updates, creates []SyncMemberPlanStep
+	for name, d := range desired {
+		d := d
+		c, ok := current[name]
+		if !ok {
+			creates = append(creates, SyncMemberPlanStep{Action: SyncMemberPlanActionCreate, SyncMemberName: name, Desired: &d})
+			continue
+		}
+		if changed := changedSyncMemberProperties(d.SyncMemberProperties, c.SyncMemberProperties); len(changed) > 0 {
+			updates = append(updates, SyncMemberPlanStep{Action: SyncMemberPlanActionUpdate, SyncMemberName: name, Desired: &d, Current: &c, ChangedProperties: changed})
+		}
+	}
+	if deleteUnlisted {
+		for name, c := range current {
+			c := c
+			if _, ok := desired[name]; !ok {
+				deletes = append(deletes, SyncMemberPlanStep{Action: SyncMemberPlanActionDelete, SyncMemberName: name, Current: &c})
+			}
+		}
+	}
+	var steps []SyncMemberPlanStep
+	for _, s := range [][]SyncMemberPlanStep{deletes, updates, creates} {
+		sort.Slice(s, func(i, j int) bool { return s[i].SyncMemberName < s[j].SyncMemberName })
+		steps = append(steps, s...)
+	}
+	return steps
+}
+
+// changedSyncMemberProperties returns the JSON names of the properties set in desired whose values differ in current.
+func changedSyncMemberProperties(desired *SyncMemberProperties, current *SyncMemberProperties) []string {
+	if desired == nil {
+		return nil
+	}
+	if current == nil {
+		current = &SyncMemberProperties{}
+	}
+	var changed []string
+	compare := func(name string, d interface{}, c interface{}) {
+		dv := reflect.ValueOf(d)
+		if dv.IsZero() {
+			// not set in desired
+			return
+		}
+		if !reflect.DeepEqual(d, c) {
+			changed = append(changed, name)
+		}
+	}
+	compare("databaseType", desired.DatabaseType, current.DatabaseType)
+	compare("syncAgentId", desired.SyncAgentID, current.SyncAgentID)
+	compare("sqlServerDatabaseId", desired.SQLServerDatabaseID, current.SQLServerDatabaseID)
+	compare("syncMemberAzureDatabaseResourceId", desired.SyncMemberAzureDatabaseResourceID, current.SyncMemberAzureDatabaseResourceID)
+	compare("usePrivateLinkConnection", desired.UsePrivateLinkConnection, current.UsePrivateLinkConnection)
+	compare("serverName", desired.ServerName, current.ServerName)
+	compare("databaseName", desired.DatabaseName, current.DatabaseName)
+	compare("userName", desired.UserName, current.UserName)
+	compare("syncDirection", desired.SyncDirection, current.SyncDirection)
+	return changed
+}
+
+// ApplyPlan makes the changes of a plan returned by Plan, in order, waiting for each to complete. It stops at the
+// first failure; the changes made before it aren't undone. Call Plan again to see the remaining changes.
+// Parameters:
+// plan - the plan to apply.
+func (client SyncMembersClient) ApplyPlan(ctx context.Context, plan SyncMemberPlan) (err error) {
+	if tracing.IsEnabled() {
+		ctx = tracing.StartSpan(ctx, fqdn+"/SyncMembersClient.ApplyPlan")
+		defer func() {
+			tracing.EndSpan(ctx, -1, err)
+		}()
+	}
+	for _, step := range plan.Steps {
+		switch step.Action {
+		case SyncMemberPlanActionCreate:
+			var future SyncMembersCreateOrUpdateFuture
+			future, err = client.CreateOrUpdate(ctx, plan.ResourceGroupName, plan.ServerName, plan.DatabaseName, plan.SyncGroupName, step.SyncMemberName, *step.Desired)
+			if err == nil {
+				err = future.WaitForCompletionRef(ctx, client.Client)
+			}
+		case SyncMemberPlanActionUpdate:
+			var future SyncMembersUpdateFuture
+			future, err = client.Update(ctx, plan.ResourceGroupName, plan.ServerName, plan.DatabaseName, plan.SyncGroupName, step.SyncMemberName, *step.Desired)
+			if err == nil {
+				err = future.WaitForCompletionRef(ctx, client.Client)
+			}
+		case SyncMemberPlanActionDelete:
+			var future SyncMembersDeleteFuture
+			future, err = client.Delete(ctx, plan.ResourceGroupName, plan.ServerName, plan.DatabaseName, plan.SyncGroupName, step.SyncMemberName)
+			if err == nil {
+				err = future.WaitForCompletionRef(ctx, client.Client)
+			}
+		default:
+			err = autorest.NewError("sql.SyncMembersClient", "ApplyPlan", "unknown action %q for sync member %q", step.Action, step.SyncMemberName)
+		}
+		if err != nil {
+			err = autorest.NewErrorWithError(err, "sql.SyncMembersClient", "ApplyPlan", nil, "Failure applying the %s of sync member %q", step.Action, step.SyncMemberName)
+			return
+		}
+	}
+	return
+}
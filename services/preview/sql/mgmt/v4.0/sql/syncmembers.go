@@ -106,6 +106,7 @@ func (client SyncMembersClient) CreateOrUpdateSender(req *http.Request) (future
 	azf, err = azure.NewFutureFromResponse(resp)
 	future.FutureAPI = &azf
 	future.Result = future.result
+	future.ResultFromResponse = future.resultFromResponse
 	return
 }
 
@@ -289,6 +290,53 @@ func (client SyncMembersClient) GetResponder(resp *http.Response) (result SyncMe
 	return
 }
 
+// Exists checks whether a sync member exists. It performs a Get request and returns false, rather than an
+// error, when the service responds with 404 Not Found. Other failures are returned as an autorest.DetailedError.
+// Parameters:
+// resourceGroupName - the name of the resource group that contains the resource. You can obtain this value
+// from the Azure Resource Manager API or the portal.
+// serverName - the name of the server.
+// databaseName - the name of the database on which the sync group is hosted.
+// syncGroupName - the name of the sync group on which the sync member is hosted.
+// syncMemberName - the name of the sync member.
+func (client SyncMembersClient) Exists(ctx context.Context, resourceGroupName string, serverName string, databaseName string, syncGroupName string, syncMemberName string) (exists bool, err error) {
+	var resp *http.Response
+	if tracing.IsEnabled() {
+		ctx = tracing.StartSpan(ctx, fqdn+"/SyncMembersClient.Exists")
+		defer func() {
+			sc := -1
+			if resp != nil {
+				sc = resp.StatusCode
+			}
+			tracing.EndSpan(ctx, sc, err)
+		}()
+	}
+	req, err := client.GetPreparer(ctx, resourceGroupName, serverName, databaseName, syncGroupName, syncMemberName)
+	if err != nil {
+		err = autorest.NewErrorWithError(err, "sql.SyncMembersClient", "Exists", nil, "Failure preparing request")
+		return
+	}
+
+	resp, err = client.GetSender(req)
+	if err != nil {
+		err = autorest.NewErrorWithError(err, "sql.SyncMembersClient", "Exists", resp, "Failure sending request")
+		return
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		err = autorest.Respond(resp, autorest.ByDiscardingBody(), autorest.ByClosing())
+		return
+	}
+
+	_, err = client.GetResponder(resp)
+	if err != nil {
+		err = autorest.NewErrorWithError(err, "sql.SyncMembersClient", "Exists", resp, "Failure responding to request")
+		return
+	}
+
+	return true, nil
+}
+
 // ListBySyncGroup lists sync members in the given sync group.
 // Parameters:
 // resourceGroupName - the name of the resource group that contains the resource. You can obtain this value
@@ -384,7 +432,7 @@ func (client SyncMembersClient) listBySyncGroupNextResults(ctx context.Context,
 	if req == nil {
 		return
 	}
-	resp, err := client.ListBySyncGroupSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListBySyncGroupSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SyncMembersClient", "listBySyncGroupNextResults", resp, "Failure sending next results request")
@@ -509,7 +557,7 @@ func (client SyncMembersClient) listMemberSchemasNextResults(ctx context.Context
 	if req == nil {
 		return
 	}
-	resp, err := client.ListMemberSchemasSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListMemberSchemasSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.SyncMembersClient", "listMemberSchemasNextResults", resp, "Failure sending next results request")
@@ -127,7 +127,7 @@ func (client UsagesClient) listByInstancePoolNextResults(ctx context.Context, la
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByInstancePoolSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByInstancePoolSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.UsagesClient", "listByInstancePoolNextResults", resp, "Failure sending next results request")
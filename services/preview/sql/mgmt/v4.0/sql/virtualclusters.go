@@ -273,7 +273,7 @@ func (client VirtualClustersClient) listNextResults(ctx context.Context, lastRes
 	if req == nil {
 		return
 	}
-	resp, err := client.ListSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.VirtualClustersClient", "listNextResults", resp, "Failure sending next results request")
@@ -390,7 +390,7 @@ func (client VirtualClustersClient) listByResourceGroupNextResults(ctx context.C
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByResourceGroupSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByResourceGroupSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.VirtualClustersClient", "listByResourceGroupNextResults", resp, "Failure sending next results request")
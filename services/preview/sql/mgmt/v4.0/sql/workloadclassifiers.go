@@ -393,7 +393,7 @@ func (client WorkloadClassifiersClient) listByWorkloadGroupNextResults(ctx conte
 	if req == nil {
 		return
 	}
-	resp, err := client.ListByWorkloadGroupSender(req)
+	resp, err := sendNextPage(client.Client, req, client.ListByWorkloadGroupSender)
 	if err != nil {
 		result.Response = autorest.Response{Response: resp}
 		return result, autorest.NewErrorWithError(err, "sql.WorkloadClassifiersClient", "listByWorkloadGroupNextResults", resp, "Failure sending next results request")